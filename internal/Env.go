@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"HostelApp/LogColor"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// DurationFromEnv read a Go duration from the variable name, fallback is used
+// when it is not set. A bad or negative value panic at startup
+func DurationFromEnv(name string, fallback string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		value = fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration < 0 {
+		err = fmt.Errorf("duration must not be negative")
+	}
+	if err != nil {
+		log.Panic(LogColor.Red("!!Panic!! invalid " + name + " " + err.Error()))
+	}
+	return duration
+}
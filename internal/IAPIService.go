@@ -40,9 +40,11 @@ func (m HTTPMethod) String() string {
 }
 
 type APIRoute struct {
-	Path    string
-	Method  HTTPMethod
-	Handler fiber.Handler
+	Path       string
+	Method     HTTPMethod
+	Handler    fiber.Handler
+	Protected  bool       // Protected routes require a valid JWT, the claims are available through JWTManager.GetClaims
+	Permission Permission // Permission required from the JWT access level, anything above NoPermission implies Protected
 }
 type IAPIService interface {
 	GetFiberRoutes() *[]APIRoute
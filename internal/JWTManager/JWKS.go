@@ -0,0 +1,75 @@
+package JWTManager
+
+import (
+	"HostelApp/internal"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"math/big"
+	"time"
+)
+
+// jwksCacheAge let verifiers cache the key set, generated keys are published at
+// least that long before they sign and configured keys should be too
+const jwksCacheAge = 5 * time.Minute
+
+// JWK is the public part of a SigningKey as described by RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet is the body of /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK return the public key in JWK form
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.method.Alg()}
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// JWKS return the keys other services verify tokens with
+func (m *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range m.keys.published(time.Now()) {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
+
+func (m *JWTManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/.well-known/jwks.json", Method: internal.GET, Handler: m.getJWKS},
+	}
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys of the tokens issued by the server, by kid, including the keys about to sign and the retiring ones
+// @Tags auth
+// @Produce json
+// @Success 200 {object} JWTManager.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (m *JWTManager) getJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(jwksCacheAge.Seconds())))
+	return c.JSON(m.JWKS())
+}
@@ -0,0 +1,43 @@
+package JWTManager_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJWKSInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a signed JWT; got %q", token)
+	}
+	rawHeader, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	var header map[string]string
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		t.Fatalf("invalid JWT header %q. Err: %v", rawHeader, err)
+	}
+
+	status, body := testutil.DoJSON(t, s, "GET", "/.well-known/jwks.json", "", nil)
+	if status != http.StatusOK || len(testutil.Path[[]interface{}](t, body, "keys")) != 1 {
+		t.Fatalf("jwks: expected one key; got %d %v", status, body)
+	}
+	// another service verify the token with the published key alone
+	key := testutil.Path[map[string]interface{}](t, body, "keys", 0)
+	if key["kid"] != header["kid"] || key["alg"] != header["alg"] || key["kty"] != "OKP" {
+		t.Fatalf("jwks: key %v does not match the token header %v", key, header)
+	}
+	public, _ := base64.RawURLEncoding.DecodeString(testutil.Path[string](t, key, "x"))
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if !ed25519.Verify(public, []byte(parts[0]+"."+parts[1]), signature) {
+		t.Fatal("jwks: token signature does not verify with the published key")
+	}
+}
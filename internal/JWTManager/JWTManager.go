@@ -1,133 +1,315 @@
 package JWTManager
 
 import (
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"strings"
 	"time"
 )
 
+const (
+	accessTokenType     = "access"
+	refreshTokenType    = "refresh"
+	challengeTokenType  = "2fa_challenge"
+	enrollmentTokenType = "2fa_enrollment"
+)
+
+// revocationTimeout bound the revocation list lookup of VerifyToken
+const revocationTimeout = 3 * time.Second
+
+// challengeDuration bound the time between the password step of a login and its second factor
+const challengeDuration = 5 * time.Minute
+
+const RoleAdmin = "admin"
+
+// Claims is the typed payload of every token issued by JWTManager,
+// Subject is the user _id, SessionID is the refresh token family and
+// AccessLevel is the Admin.ExcessType of the user when the token was issued
+type Claims struct {
+	Roles       []string         `json:"roles,omitempty"`
+	SessionID   string           `json:"sid,omitempty"`
+	AccessLevel Admin.ExcessType `json:"access_level,omitempty"`
+	TokenType   string           `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// SessionChecker tell if the session of an access token is still open, it is
+// run by Middleware on every protected request so a revocation apply at once
+type SessionChecker func(subject string, sessionID string, ctx context.Context) error
+
+// RevocationList keep the keys of revoked tokens until those tokens expire,
+// AdminDB.IRevocationDBService implement it
+type RevocationList interface {
+	Revoke(key string, expiresAt time.Time, ctx context.Context) error
+	AnyRevoked(keys []string, now time.Time, ctx context.Context) (bool, error)
+}
+
 type JWTManager struct {
-	signingKey           []byte
+	keys                 *KeySet
 	duration             int64 // JWT token exp time in min
 	refreshTokenDuration int64 // JWT token exp time in days
+	sessionChecker       SessionChecker
+	revocations          RevocationList
 }
 
-// NewJWTManager take key and duration in min
-func NewJWTManager(jwtKey string, duration int64, refreshTokenDuration int64) *JWTManager {
+// NewJWTManager take the signing keys, the access token duration in min and
+// the refresh token duration in days
+func NewJWTManager(keys *KeySet, duration int64, refreshTokenDuration int64) *JWTManager {
 	return &JWTManager{
-		signingKey:           []byte(jwtKey),
+		keys:                 keys,
 		duration:             duration,
 		refreshTokenDuration: refreshTokenDuration,
 	}
 }
 
-func (m *JWTManager) GenerateToken(userData string) (string, error) {
+// UseSessionChecker make Middleware reject access tokens of closed sessions
+func (m *JWTManager) UseSessionChecker(checker SessionChecker) {
+	m.sessionChecker = checker
+}
+
+// UseRevocationList make VerifyToken reject revoked tokens, every token must then carry a jti
+func (m *JWTManager) UseRevocationList(revocations RevocationList) {
+	m.revocations = revocations
+}
+
+// RevokeToken reject the token of claims until it expire
+func (m *JWTManager) RevokeToken(claims *Claims, ctx context.Context) error {
+	if m.revocations == nil {
+		return nil
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return fmt.Errorf("token can not be revoked without jti and exp")
+	}
+	return m.revocations.Revoke(tokenKey(claims.ID), claims.ExpiresAt.Time, ctx)
+}
+
+// RevokeSession reject every token issued for a session, access and refresh,
+// until the last of them expire
+func (m *JWTManager) RevokeSession(sessionID string, ctx context.Context) error {
+	if m.revocations == nil {
+		return nil
+	}
+	return m.revocations.Revoke(sessionKey(sessionID), m.RefreshTokenExpiry(time.Now()), ctx)
+}
+
+func tokenKey(jti string) string {
+	return "jti:" + jti
+}
+
+func sessionKey(sessionID string) string {
+	return "sid:" + sessionID
+}
+
+// RefreshTokenExpiry is when a refresh token issued at now expire
+func (m *JWTManager) RefreshTokenExpiry(now time.Time) time.Time {
+	return now.Add(time.Duration(m.refreshTokenDuration*24) * time.Hour)
+}
+
+func (m *JWTManager) GenerateToken(subject string, sessionID string, roles []string, accessLevel Admin.ExcessType) (string, error) {
 	expirationTime := time.Now().Add(time.Duration(m.duration) * time.Minute)
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
 
-	claims := jwt.MapClaims{
-		"userData": userData,
-		"exp":      expirationTime.Unix(), // exp must be a Unix timestamp
+	claims := &Claims{
+		Roles:       roles,
+		SessionID:   sessionID,
+		AccessLevel: accessLevel,
+		TokenType:   accessTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
 	}
+	return m.sign(claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(m.signingKey)
+// GenerateRefreshToken issues a refresh token bound to a session family,
+// every token rotated out of the same login shares the session id
+func (m *JWTManager) GenerateRefreshToken(subject string, sessionID string) (string, error) {
+	expirationTime := m.RefreshTokenExpiry(time.Now())
+	jti, err := NewTokenID()
 	if err != nil {
 		return "", err
 	}
-	return tokenString, nil
+
+	claims := &Claims{
+		SessionID: sessionID,
+		TokenType: refreshTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+	return m.sign(claims)
 }
 
-func (m *JWTManager) GenerateRefreshToken(userData string) (string, error) {
-	expirationTime := time.Now().Add(time.Duration(m.refreshTokenDuration*24) * time.Hour)
+// GenerateChallengeToken issues the token a login with 2FA enabled exchange
+// for a JWT once the second factor is checked
+func (m *JWTManager) GenerateChallengeToken(subject string) (string, error) {
+	return m.generateShortLived(subject, challengeTokenType)
+}
 
-	claims := jwt.MapClaims{
-		"userData": userData,
-		"exp":      expirationTime.Unix(), // exp must be a Unix timestamp
+// GenerateEnrollmentToken issues the token an admin required to use 2FA but
+// without it yet enroll with before getting a JWT
+func (m *JWTManager) GenerateEnrollmentToken(subject string) (string, error) {
+	return m.generateShortLived(subject, enrollmentTokenType)
+}
+
+func (m *JWTManager) generateShortLived(subject string, tokenType string) (string, error) {
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", err
 	}
+	claims := &Claims{
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeDuration)),
+		},
+	}
+	return m.sign(claims)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(m.signingKey)
+// sign use the current key of the KeySet, its id go in the kid header
+func (m *JWTManager) sign(claims *Claims) (string, error) {
+	key, err := m.keys.signingKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.private)
 	if err != nil {
 		return "", err
 	}
 	return tokenString, nil
 }
 
-func (m *JWTManager) ParseToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return m.signingKey, nil
-	})
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := m.keys.verificationKey(kid, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.private.Public(), nil
+}
+
+func (m *JWTManager) ParseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc)
 	if err != nil {
 		return nil, err
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+	if claims, ok := token.Claims.(*Claims); ok {
 		return claims, nil
 	}
-	return nil, err
+	return nil, fmt.Errorf("invalid token")
 }
 
-// VerifyToken return isExpired,claims,error
-func (m *JWTManager) VerifyToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return m.signingKey, nil
-	})
+// VerifyToken return claims of a valid, not expired and not revoked token
+func (m *JWTManager) VerifyToken(tokenString string, ctx context.Context) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc, jwt.WithExpirationRequired())
 	if err != nil {
 		return nil, fmt.Errorf("verifyToken error: %v", err)
 	}
 
 	// Extract claims
-	claimMap, ok := token.Claims.(jwt.MapClaims)
+	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
 		return nil, fmt.Errorf("invalid token")
 	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("subject claim missing")
+	}
+	if err = m.checkRevoked(claims, ctx); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
 
-	// Manual expiration check
-	if exp, ok := claimMap["exp"].(float64); ok {
-		if int64(exp) < time.Now().Unix() {
-			return nil, fmt.Errorf("token has expired")
-		}
-	} else {
-		return nil, fmt.Errorf("expiration claim missing or invalid")
+// checkRevoked look up the jti and the session of claims in the revocation list
+func (m *JWTManager) checkRevoked(claims *Claims, ctx context.Context) error {
+	if m.revocations == nil {
+		return nil
+	}
+	if claims.ID == "" {
+		return fmt.Errorf("jti claim missing")
+	}
+	keys := []string{tokenKey(claims.ID)}
+	if claims.SessionID != "" {
+		keys = append(keys, sessionKey(claims.SessionID))
+	}
+	ctx, cancel := context.WithTimeout(ctx, revocationTimeout)
+	defer cancel()
+	revoked, err := m.revocations.AnyRevoked(keys, time.Now(), ctx)
+	if err != nil {
+		return err
 	}
-	return claimMap, nil
+	if revoked {
+		return fmt.Errorf("token has been revoked")
+	}
+	return nil
+}
+
+// VerifyRefreshToken return claims of a valid refresh token
+func (m *JWTManager) VerifyRefreshToken(tokenString string, ctx context.Context) (*Claims, error) {
+	return m.verifyType(tokenString, refreshTokenType, ctx)
+}
+
+// VerifyChallengeToken return claims of a valid 2FA challenge token
+func (m *JWTManager) VerifyChallengeToken(tokenString string, ctx context.Context) (*Claims, error) {
+	return m.verifyType(tokenString, challengeTokenType, ctx)
 }
 
-func (m *JWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := m.ParseToken(tokenString)
+// VerifyEnrollmentToken return claims of a valid 2FA enrollment token
+func (m *JWTManager) VerifyEnrollmentToken(tokenString string, ctx context.Context) (*Claims, error) {
+	return m.verifyType(tokenString, enrollmentTokenType, ctx)
+}
+
+func (m *JWTManager) verifyType(tokenString string, tokenType string, ctx context.Context) (*Claims, error) {
+	claims, err := m.VerifyToken(tokenString, ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("not a %s token", tokenType)
 	}
-	userData, ok := claims["userData"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid token")
-	}
-	return m.GenerateToken(userData)
-}
-
-//func (m *JWTManager) IsValid(r *http.Request) (bool, jwt.Claims, error) {
-//	authHeader := r.Header.Get("Authorization")
-//	if authHeader == "" {
-//		return false, nil, fmt.Errorf("Missing Authorization header")
-//	}
-//	// Expecting "Bearer <token>"
-//	parts := strings.Split(authHeader, " ")
-//	if len(parts) != 2 || parts[0] != "Bearer" {
-//		return false, nil, fmt.Errorf("Invalid Authorization header")
-//	}
-//	tokenStr := parts[1]
-//
-//	// Validate JWT
-//	claims, err := m.VerifyToken(tokenStr)
-//	if err != nil {
-//		return false, nil, err
-//	}
-//
-//	return true, claims, nil
-//}
-
-// IsValid this return isExpired ,claims
-func (m *JWTManager) IsValid(authHeader string) (jwt.MapClaims, error) {
+	return claims, nil
+}
+
+// HashToken is the SHA-256 of a token, stored in place of the token so a
+// database leak does not hand out working tokens
+func HashToken(tokenString string) string {
+	sum := sha256.Sum256([]byte(tokenString))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTokenID generate a random id used for jti and refresh token families
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// IsValid return claims of the access token in the Authorization header
+func (m *JWTManager) IsValid(authHeader string, ctx context.Context) (*Claims, error) {
 	if authHeader == "" {
 		return nil, fmt.Errorf("invalid Authorization header")
 	}
@@ -136,9 +318,12 @@ func (m *JWTManager) IsValid(authHeader string) (jwt.MapClaims, error) {
 		return nil, fmt.Errorf("invalid Authorization header")
 	}
 	tokenStr := parts[1]
-	claims, err := m.VerifyToken(tokenStr)
+	claims, err := m.VerifyToken(tokenStr, ctx)
 	if err != nil {
 		return nil, err
 	}
+	if claims.TokenType != accessTokenType {
+		return nil, fmt.Errorf("%s token can not be used as access token", claims.TokenType)
+	}
 	return claims, nil
 }
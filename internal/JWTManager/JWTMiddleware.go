@@ -0,0 +1,48 @@
+package JWTManager
+
+import (
+	"HostelApp/internal"
+	"github.com/gofiber/fiber/v2"
+)
+
+const claimsLocalKey = "jwtClaims"
+
+// Middleware reject requests without a valid access token of an open session
+// and store the parsed Claims in fiber.Locals for the next handlers
+func (m *JWTManager) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, jwtErr := m.IsValid(c.Get("Authorization"), c.Context())
+		if jwtErr == nil && m.sessionChecker != nil {
+			jwtErr = m.sessionChecker(claims.Subject, claims.SessionID, c.Context())
+		}
+		if jwtErr != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed to validate credentials at jwt",
+				"error":   jwtErr.Error(),
+			})
+		}
+		c.Locals(claimsLocalKey, claims)
+		return c.Next()
+	}
+}
+
+// RequirePermission must run after Middleware, it reject with 403 when
+// the access level in the claims does not grant the permission
+func (m *JWTManager) RequirePermission(permission internal.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := GetClaims(c)
+		if claims == nil || !internal.HasPermission(claims.AccessLevel, permission) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "access denied",
+				"error":   "permission " + permission.String() + " required",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// GetClaims return the claims stored by Middleware, nil on public routes
+func GetClaims(c *fiber.Ctx) *Claims {
+	claims, _ := c.Locals(claimsLocalKey).(*Claims)
+	return claims
+}
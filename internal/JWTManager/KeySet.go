@@ -0,0 +1,303 @@
+package JWTManager
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/internal"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"log"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultKeyGrace cover the refresh token duration, a token signed just before
+	// a rotation stay verifiable until it expire
+	DefaultKeyGrace = "720h"
+	// DefaultKeyRotation is how often generated keys are replaced
+	DefaultKeyRotation = "168h"
+)
+
+// minRSABits is the smallest RSA key accepted for RS256
+const minRSABits = 2048
+
+// SigningKey is one asymmetric key of a KeySet, identified in tokens by the kid
+// header. ActiveFrom is when it start signing, it is published in the JWKS
+// before so other services already know it
+type SigningKey struct {
+	ID         string
+	ActiveFrom time.Time
+	method     jwt.SigningMethod
+	private    crypto.Signer
+}
+
+// NewSigningKey take an *rsa.PrivateKey for RS256 or an ed25519.PrivateKey for EdDSA
+func NewSigningKey(kid string, private crypto.Signer, activeFrom time.Time) (*SigningKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("key id is required")
+	}
+	key := &SigningKey{ID: kid, ActiveFrom: activeFrom, private: private}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("key %s: RSA keys must have at least %d bits", kid, minRSABits)
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("key %s: only RSA and Ed25519 keys are supported", kid)
+	}
+	return key, nil
+}
+
+// ParseSigningKey read a PEM private key, PKCS#8 or PKCS#1 for RSA
+func ParseSigningKey(kid string, pemData []byte, activeFrom time.Time) (*SigningKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM block found", kid)
+	}
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unexpected PEM block %s", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", kid, err)
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key %s: only RSA and Ed25519 keys are supported", kid)
+	}
+	return NewSigningKey(kid, signer, activeFrom)
+}
+
+// GenerateSigningKey create a random Ed25519 key active from activeFrom
+func GenerateSigningKey(activeFrom time.Time) (*SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %v", err)
+	}
+	kid, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(kid, private, activeFrom)
+}
+
+// KeySet hold the keys of a JWTManager. The signing key is the last one whose
+// ActiveFrom is passed, a key replaced that way still verify tokens for the
+// grace period then it is dropped
+type KeySet struct {
+	mu       sync.Mutex
+	keys     []*SigningKey // sorted by ActiveFrom
+	grace    time.Duration
+	rotation time.Duration // 0 when keys are configured, not generated
+}
+
+// NewKeySet take configured keys, they must have distinct ids and activation times
+func NewKeySet(keys []*SigningKey, grace time.Duration) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one signing key is required")
+	}
+	sorted := append([]*SigningKey{}, keys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+	ids := make(map[string]bool)
+	for i, key := range sorted {
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %s", key.ID)
+		}
+		ids[key.ID] = true
+		if i > 0 && key.ActiveFrom.Equal(sorted[i-1].ActiveFrom) {
+			return nil, fmt.Errorf("keys %s and %s have the same activation time", sorted[i-1].ID, key.ID)
+		}
+	}
+	return &KeySet{keys: sorted, grace: grace}, nil
+}
+
+// NewGeneratedKeySet create Ed25519 keys in memory and replace them every
+// rotation. Tokens do not survive a restart and instances do not share keys,
+// configure keys when more than one instance run
+func NewGeneratedKeySet(rotation time.Duration, grace time.Duration) (*KeySet, error) {
+	if rotation <= 0 {
+		return nil, fmt.Errorf("rotation must be positive")
+	}
+	key, err := GenerateSigningKey(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keys: []*SigningKey{key}, grace: grace, rotation: rotation}, nil
+}
+
+// retired tell if key i has been replaced for longer than the grace period, the caller hold the lock
+func (s *KeySet) retired(i int, now time.Time) bool {
+	return i+1 < len(s.keys) && !now.Before(s.keys[i+1].ActiveFrom.Add(s.grace))
+}
+
+// rotate generate the next key once the last one is in the final keyLead of its
+// rotation. The new key start signing keyLead after it is created so verifiers
+// caching the JWKS fetch it before they see a token signed with it, the caller
+// hold the lock
+func (s *KeySet) rotate(now time.Time) error {
+	if s.rotation == 0 {
+		return nil
+	}
+	last := s.keys[len(s.keys)-1]
+	lead := s.keyLead()
+	if last.ActiveFrom.After(now) || now.Before(last.ActiveFrom.Add(s.rotation-lead)) {
+		return nil
+	}
+	key, err := GenerateSigningKey(now.Add(lead))
+	if err != nil {
+		return err
+	}
+	s.keys = append(s.keys, key)
+	slog.Info(LogColor.Yellow("JWT signing key " + key.ID + " active from " + key.ActiveFrom.Format(time.RFC3339)))
+	return nil
+}
+
+// keyLead is how long a generated key is published before it sign, a quarter
+// of the rotation but never less than the JWKS cache age
+func (s *KeySet) keyLead() time.Duration {
+	if lead := s.rotation / 4; lead > jwksCacheAge {
+		return lead
+	}
+	return jwksCacheAge
+}
+
+// signingKey return the key to sign with at now, it rotate generated keys and
+// drop the retired ones
+func (s *KeySet) signingKey(now time.Time) (*SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rotate(now); err != nil {
+		return nil, err
+	}
+	for len(s.keys) > 1 && s.retired(0, now) {
+		s.keys = s.keys[1:]
+	}
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveFrom.After(now) {
+			return s.keys[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no signing key active yet")
+}
+
+// verificationKey return the key of kid when it is not retired, keys not active
+// yet are accepted as another instance may have switched a bit earlier
+func (s *KeySet) verificationKey(kid string, now time.Time) (*SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, key := range s.keys {
+		if key.ID != kid {
+			continue
+		}
+		if s.retired(i, now) {
+			return nil, fmt.Errorf("signing key %s is retired", kid)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// published return the keys other services must know at now, the upcoming ones
+// included. It rotate generated keys too so the next key is published even when
+// nothing is signed before it is due
+func (s *KeySet) published(now time.Time) []*SigningKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rotate(now); err != nil {
+		slog.Error(LogColor.Red("failed to rotate JWT signing key " + err.Error()))
+	}
+	keys := []*SigningKey{}
+	for i, key := range s.keys {
+		if !s.retired(i, now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// ParseKeyList read the JWT_KEYS format, comma separated kid=source entries with
+// an optional @RFC3339 activation time. The source is a PEM file path, or
+// env:NAME to read the PEM from the NAME variable
+func ParseKeyList(value string) ([]*SigningKey, error) {
+	keys := []*SigningKey{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, source, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || source == "" {
+			return nil, fmt.Errorf("entry %q must be kid=source", entry)
+		}
+		var activeFrom time.Time
+		if at := strings.LastIndex(source, "@"); at >= 0 {
+			parsed, err := time.Parse(time.RFC3339, source[at+1:])
+			if err != nil {
+				return nil, fmt.Errorf("key %s: activation time must be RFC3339: %v", kid, err)
+			}
+			source, activeFrom = source[:at], parsed
+		}
+		var pemData []byte
+		if name, fromEnv := strings.CutPrefix(source, "env:"); fromEnv {
+			pemData = []byte(os.Getenv(name))
+		} else {
+			data, err := os.ReadFile(source)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %v", kid, err)
+			}
+			pemData = data
+		}
+		key, err := ParseSigningKey(kid, pemData, activeFrom)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeySetFromEnv load the keys of JWT_KEYS, or generate keys rotated every
+// JWT_KEY_ROTATION when it is not set. JWT_KEY_GRACE is how long a replaced key
+// still verify tokens
+func KeySetFromEnv() *KeySet {
+	grace := internal.DurationFromEnv("JWT_KEY_GRACE", DefaultKeyGrace)
+	value := os.Getenv("JWT_KEYS")
+	if value == "" {
+		slog.Warn(LogColor.Yellow("JWT_KEYS is not set, using generated signing keys, tokens will not survive a restart"))
+		keys, err := NewGeneratedKeySet(internal.DurationFromEnv("JWT_KEY_ROTATION", DefaultKeyRotation), grace)
+		if err != nil {
+			log.Panic(LogColor.Red("!!Panic!! invalid JWT_KEY_ROTATION " + err.Error()))
+		}
+		return keys
+	}
+	configured, err := ParseKeyList(value)
+	if err != nil {
+		log.Panic(LogColor.Red("!!Panic!! invalid JWT_KEYS " + err.Error()))
+	}
+	keys, err := NewKeySet(configured, grace)
+	if err != nil {
+		log.Panic(LogColor.Red("!!Panic!! invalid JWT_KEYS " + err.Error()))
+	}
+	return keys
+}
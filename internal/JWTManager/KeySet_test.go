@@ -0,0 +1,143 @@
+package JWTManager
+
+import (
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func pemKey(t *testing.T, private interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed. Err: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestParseKeyList(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := filepath.Join(t.TempDir(), "rsa.pem")
+	if err := os.WriteFile(path, pemKey(t, rsaKey), 0o600); err != nil {
+		t.Fatalf("WriteFile failed. Err: %v", err)
+	}
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	t.Setenv("TEST_JWT_KEY", string(pemKey(t, edKey)))
+
+	keys, err := ParseKeyList("old=" + path + ", new=env:TEST_JWT_KEY@2030-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("ParseKeyList failed. Err: %v", err)
+	}
+	if len(keys) != 2 || keys[0].method.Alg() != "RS256" || keys[1].method.Alg() != "EdDSA" {
+		t.Fatalf("expected an RS256 then an EdDSA key; got %+v", keys)
+	}
+	if !keys[1].ActiveFrom.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected activation time %v", keys[1].ActiveFrom)
+	}
+
+	for _, bad := range []string{"nokid", "a=" + path + "@tomorrow", "a=env:MISSING_JWT_KEY"} {
+		if _, err = ParseKeyList(bad); err == nil {
+			t.Fatalf("%q: expected an error", bad)
+		}
+	}
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	if _, err = NewSigningKey("small", small, time.Time{}); err == nil {
+		t.Fatal("expected a 1024 bits RSA key to be rejected")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	now := time.Now()
+	_, oldPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	_, newPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	oldKey, _ := NewSigningKey("old", oldPrivate, time.Time{})
+	newKey, _ := NewSigningKey("new", newPrivate, now.Add(-time.Hour))
+
+	oldSet, _ := NewKeySet([]*SigningKey{oldKey}, time.Hour)
+	token, err := NewJWTManager(oldSet, 30, 30).GenerateToken("user-1", "session-1", nil, Admin.ReadOnly)
+	if err != nil {
+		t.Fatalf("GenerateToken failed. Err: %v", err)
+	}
+
+	// the old key is replaced since an hour, still verifying with a longer grace
+	rotated, _ := NewKeySet([]*SigningKey{newKey, oldKey}, 2*time.Hour)
+	m := NewJWTManager(rotated, 30, 30)
+	if _, err = m.VerifyToken(token, context.Background()); err != nil {
+		t.Fatalf("token of the previous key in its grace period: expected valid. Err: %v", err)
+	}
+	if claims, _ := m.ParseToken(mustToken(t, m)); claims == nil {
+		t.Fatal("expected the rotated manager to verify its own tokens")
+	}
+	if key, _ := rotated.signingKey(now); key.ID != "new" {
+		t.Fatalf("expected new to sign; got %s", key.ID)
+	}
+	if jwks := m.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].KeyType != "OKP" {
+		t.Fatalf("expected both keys published; got %+v", jwks)
+	}
+
+	retired, _ := NewKeySet([]*SigningKey{oldKey, newKey}, time.Minute)
+	m = NewJWTManager(retired, 30, 30)
+	if _, err = m.VerifyToken(token, context.Background()); err == nil {
+		t.Fatal("token of a retired key: expected an error")
+	}
+	if jwks := m.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != "new" {
+		t.Fatalf("expected only the new key published; got %+v", jwks)
+	}
+
+	if _, err = NewKeySet([]*SigningKey{oldKey, newKey, newKey}, time.Hour); err == nil {
+		t.Fatal("expected duplicate key ids to be rejected")
+	}
+}
+
+func TestGeneratedKeyRotation(t *testing.T) {
+	keys, err := NewGeneratedKeySet(time.Hour, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("NewGeneratedKeySet failed. Err: %v", err)
+	}
+	start := time.Now()
+	first, _ := keys.signingKey(start)
+	// the next key is published a quarter of the rotation ahead, the first one still sign
+	if published := keys.published(start.Add(50 * time.Minute)); len(published) != 2 {
+		t.Fatalf("expected the next key to be published before it sign; got %d keys", len(published))
+	}
+	if key, _ := keys.signingKey(start.Add(time.Hour)); key.ID != first.ID {
+		t.Fatal("expected the first key to sign until the next one is active")
+	}
+	later := start.Add(65 * time.Minute)
+	second, _ := keys.signingKey(later)
+	if second.ID == first.ID {
+		t.Fatal("expected a new key once the rotation is due")
+	}
+	if _, err = keys.verificationKey(first.ID, later); err != nil {
+		t.Fatalf("expected the first key to verify during the grace period. Err: %v", err)
+	}
+	if _, err = keys.signingKey(later.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("signingKey failed. Err: %v", err)
+	}
+	if _, err = keys.verificationKey(first.ID, later.Add(2*time.Hour)); err == nil {
+		t.Fatal("expected the first key to be dropped after the grace period")
+	}
+
+	// nothing signed nor published during the lead, the late key is still published before it sign
+	idle, _ := NewGeneratedKeySet(time.Hour, 2*time.Hour)
+	idleStart := time.Now()
+	current, _ := idle.signingKey(idleStart)
+	if key, _ := idle.signingKey(idleStart.Add(3 * time.Hour)); key.ID != current.ID || len(idle.published(idleStart.Add(3*time.Hour))) != 2 {
+		t.Fatal("expected a late rotation to publish the next key before it sign")
+	}
+}
+
+func mustToken(t *testing.T, m *JWTManager) string {
+	token, err := m.GenerateToken("user-2", "session-2", nil, Admin.ReadOnly)
+	if err != nil {
+		t.Fatalf("GenerateToken failed. Err: %v", err)
+	}
+	return token
+}
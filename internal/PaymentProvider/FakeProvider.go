@@ -0,0 +1,149 @@
+package PaymentProvider
+
+import (
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+const FakeProviderName = "fake"
+
+// FakeProvider is a local gateway for development and tests, orders only live
+// in memory and webhooks are signed with HMAC-SHA256 like a real gateway does
+type FakeProvider struct {
+	mu       sync.Mutex
+	secret   []byte
+	orders   map[string]*Payment.ProviderOrder // key is provider order id
+	checkout bool
+}
+
+func NewFakeProvider(secret string) *FakeProvider {
+	return &FakeProvider{
+		secret: []byte(secret),
+		orders: make(map[string]*Payment.ProviderOrder),
+	}
+}
+
+// EnableCheckout expose the checkout page of the fake gateway as an admin route,
+// anyone with Write access can then mark an order paid so it is development only
+func (p *FakeProvider) EnableCheckout() *FakeProvider {
+	p.checkout = true
+	return p
+}
+
+// CheckoutEnabled tell if the fake checkout route must be registered
+func (p *FakeProvider) CheckoutEnabled() bool {
+	return p.checkout
+}
+
+func randomID(prefix string) string {
+	raw := make([]byte, 12)
+	_, _ = rand.Read(raw)
+	return prefix + hex.EncodeToString(raw)
+}
+
+func (p *FakeProvider) Name() string {
+	return FakeProviderName
+}
+
+func (p *FakeProvider) SignatureHeader() string {
+	return "X-Fake-Signature"
+}
+
+// Sign return the hex HMAC-SHA256 of a webhook payload
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *FakeProvider) CreateOrder(order *Payment.PaymentOrder, ctx context.Context) (*Payment.ProviderOrder, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	providerOrder := &Payment.ProviderOrder{
+		ProviderOrderID: randomID("fake_order_"),
+		Amount:          order.Amount,
+		Currency:        order.Currency,
+		Status:          Payment.Created,
+	}
+	providerOrder.CheckoutURL = "/admin/payment/fake/" + providerOrder.ProviderOrderID
+	stored := *providerOrder
+	p.orders[stored.ProviderOrderID] = &stored
+	return providerOrder, nil
+}
+
+func (p *FakeProvider) VerifySignature(payload []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (p *FakeProvider) ParseEvent(payload []byte) (*Payment.WebhookEvent, error) {
+	var event Payment.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook event: %v", err)
+	}
+	if event.EventID == "" || event.ProviderOrderID == "" {
+		return nil, fmt.Errorf("failed to parse webhook event: missing event or order id")
+	}
+	return &event, nil
+}
+
+func (p *FakeProvider) FetchStatus(providerOrderID string, ctx context.Context) (*Payment.ProviderOrder, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stored, ok := p.orders[providerOrderID]
+	if !ok {
+		return nil, ErrUnknownOrder
+	}
+	order := *stored
+	return &order, nil
+}
+
+// Complete settle an order the way a customer would on the checkout page and
+// return the signed webhook the gateway would send. Completing an order twice
+// return the same event so duplicate deliveries can be exercised
+func (p *FakeProvider) Complete(providerOrderID string, success bool) ([]byte, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stored, ok := p.orders[providerOrderID]
+	if !ok {
+		return nil, "", ErrUnknownOrder
+	}
+	if stored.Status == Payment.Created {
+		stored.ProviderPaymentID = randomID("fake_pay_")
+		stored.Status = Payment.Failed
+		if success {
+			stored.Status = Payment.Paid
+		}
+	}
+	event := Payment.WebhookEvent{
+		EventID:           "evt_" + stored.ProviderPaymentID,
+		Type:              Payment.PaymentFailed,
+		ProviderOrderID:   stored.ProviderOrderID,
+		ProviderPaymentID: stored.ProviderPaymentID,
+		Amount:            stored.Amount,
+		Currency:          stored.Currency,
+	}
+	if stored.Status == Payment.Paid {
+		event.Type = Payment.PaymentCaptured
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, p.Sign(payload), nil
+}
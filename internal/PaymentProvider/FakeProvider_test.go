@@ -0,0 +1,50 @@
+package PaymentProvider
+
+import (
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakeProvider("test_secret")
+	order, err := p.CreateOrder(&Payment.PaymentOrder{Amount: 500000, Currency: "INR"}, ctx)
+	if err != nil || order.Status != Payment.Created {
+		t.Fatalf("CreateOrder failed %+v. Err: %v", order, err)
+	}
+
+	payload, signature, err := p.Complete(order.ProviderOrderID, true)
+	if err != nil {
+		t.Fatalf("Complete failed. Err: %v", err)
+	}
+	if err = p.VerifySignature(payload, signature); err != nil {
+		t.Fatalf("expected the webhook signature to verify. Err: %v", err)
+	}
+	if err = NewFakeProvider("other_secret").VerifySignature(payload, signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature with another secret; got %v", err)
+	}
+	if err = p.VerifySignature(append(payload, ' '), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a changed payload; got %v", err)
+	}
+	if err = p.VerifySignature(payload, "not hex"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for a malformed signature; got %v", err)
+	}
+
+	event, err := p.ParseEvent(payload)
+	if err != nil || event.Type != Payment.PaymentCaptured || event.Amount != 500000 {
+		t.Fatalf("expected a captured event of 500000; got %+v %v", event, err)
+	}
+	// completing again must replay the same event, not fail the paid order
+	again, _, err := p.Complete(order.ProviderOrderID, false)
+	if err != nil || string(again) != string(payload) {
+		t.Fatalf("expected the same event on a second completion; got %s %v", again, err)
+	}
+	if status, err := p.FetchStatus(order.ProviderOrderID, ctx); err != nil || status.Status != Payment.Paid {
+		t.Fatalf("expected the order paid; got %+v %v", status, err)
+	}
+	if _, _, err = p.Complete("missing", true); !errors.Is(err, ErrUnknownOrder) {
+		t.Fatalf("expected ErrUnknownOrder; got %v", err)
+	}
+}
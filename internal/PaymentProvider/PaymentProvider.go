@@ -0,0 +1,66 @@
+package PaymentProvider
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownOrder     = errors.New("order unknown to the payment provider")
+)
+
+// PaymentProvider hide the payment gateway, the server only deal with
+// provider independent orders and webhook events
+type PaymentProvider interface {
+	Name() string
+	// SignatureHeader is the request header carrying the webhook signature
+	SignatureHeader() string
+	CreateOrder(order *Payment.PaymentOrder, ctx context.Context) (*Payment.ProviderOrder, error)
+	// VerifySignature check the webhook payload against its signature, it must run before ParseEvent
+	VerifySignature(payload []byte, signature string) error
+	ParseEvent(payload []byte) (*Payment.WebhookEvent, error)
+	FetchStatus(providerOrderID string, ctx context.Context) (*Payment.ProviderOrder, error)
+}
+
+// NewFromEnv build the provider named by PAYMENT_PROVIDER with the webhook secret
+// PAYMENT_WEBHOOK_SECRET, both are required. The fake provider is refused unless
+// PAYMENT_FAKE_ENABLED is true, it must never run in production
+func NewFromEnv() PaymentProvider {
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		log.Panic(LogColor.Red("!!Panic!! PAYMENT_WEBHOOK_SECRET is required"))
+	}
+	switch name := os.Getenv("PAYMENT_PROVIDER"); name {
+	case "":
+		log.Panic(LogColor.Red("!!Panic!! PAYMENT_PROVIDER is required"))
+		return nil
+	case FakeProviderName:
+		if !fakeEnabledFromEnv() {
+			log.Panic(LogColor.Red("!!Panic!! the fake payment provider need PAYMENT_FAKE_ENABLED=true, it is for development only"))
+		}
+		slog.Warn(LogColor.Red("using the fake payment provider, orders can be marked paid from the admin API"))
+		return NewFakeProvider(secret).EnableCheckout()
+	default:
+		log.Panic(LogColor.Red("!!Panic!! unknown PAYMENT_PROVIDER " + name))
+		return nil
+	}
+}
+
+func fakeEnabledFromEnv() bool {
+	value := os.Getenv("PAYMENT_FAKE_ENABLED")
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Panic(LogColor.Red("!!Panic!! invalid PAYMENT_FAKE_ENABLED " + err.Error()))
+	}
+	return enabled
+}
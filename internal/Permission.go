@@ -0,0 +1,42 @@
+package internal
+
+import "HostelApp/internal/storageData/Admin"
+
+type Permission int
+
+// Define enum-like constants, NoPermission only needs a valid JWT
+const (
+	NoPermission Permission = iota
+	ReadPermission
+	WritePermission
+	ManageAdminPermission
+)
+
+func (p Permission) String() string {
+	switch p {
+	case NoPermission:
+		return "NONE"
+	case ReadPermission:
+		return "READ"
+	case WritePermission:
+		return "WRITE"
+	case ManageAdminPermission:
+		return "MANAGE_ADMIN"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// HasPermission map Admin.ExcessType to the permissions it grants
+func HasPermission(level Admin.ExcessType, permission Permission) bool {
+	switch level {
+	case Admin.Full:
+		return true
+	case Admin.ReadAndWrite:
+		return permission == NoPermission || permission == ReadPermission || permission == WritePermission
+	case Admin.ReadOnly:
+		return permission == NoPermission || permission == ReadPermission
+	default:
+		return permission == NoPermission
+	}
+}
@@ -0,0 +1,212 @@
+package Spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var ErrInvalidXLSX = errors.New("file is not a valid xlsx workbook")
+
+// maxPartSize bound every xml part read from the archive so a small upload can
+// not expand into an unbounded amount of memory
+const maxPartSize = 32 << 20
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, rich text keep its value in runs
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var value strings.Builder
+	for _, run := range t.Runs {
+		value.WriteString(run.T)
+	}
+	return value.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Line  int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Row is a row of a sheet with its line, the first line is 1
+type Row struct {
+	Line   int
+	Values []string
+}
+
+// ReadXLSX return the cells of the first worksheet as text, row by row. Empty
+// cells are empty strings and trailing empty rows are dropped. Rows that are
+// not stored are skipped, the line of a row is read from its reference
+func ReadXLSX(reader io.ReaderAt, size int64) ([]Row, error) {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err = decodePart(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+	file, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidXLSX, sheetPath)
+	}
+	var sheet xlsxSheet
+	if err = decodePart(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	line := 0
+	for _, row := range sheet.Rows {
+		switch {
+		case row.Line == 0:
+			line++
+		case row.Line <= line:
+			return nil, fmt.Errorf("%w: row %d is out of order", ErrInvalidXLSX, row.Line)
+		default:
+			line = row.Line
+		}
+		var values []string
+		for position, cell := range row.Cells {
+			column := position
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			switch cell.Type {
+			case "s":
+				var index int
+				if _, err = fmt.Sscan(cell.Value, &index); err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: bad shared string in %s", ErrInvalidXLSX, cell.Ref)
+				}
+				values[column] = shared.Items[index].String()
+			case "inlineStr":
+				values[column] = cell.Inline.String()
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows = append(rows, Row{Line: line, Values: values})
+	}
+	for len(rows) > 0 && isBlank(rows[len(rows)-1].Values) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// firstSheetPath follow the workbook relationships to the first worksheet
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	file, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("%w: missing xl/workbook.xml", ErrInvalidXLSX)
+	}
+	if err := decodePart(file, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no sheet", ErrInvalidXLSX)
+	}
+	var relationships xlsxRelationships
+	if file, ok = files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodePart(file, &relationships); err != nil {
+			return "", err
+		}
+	}
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == workbook.Sheets[0].RelID {
+			if strings.HasPrefix(relationship.Target, "/") {
+				return strings.TrimPrefix(relationship.Target, "/"), nil
+			}
+			return path.Join("xl", relationship.Target), nil
+		}
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodePart(file *zip.File, target interface{}) error {
+	part, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidXLSX, err)
+	}
+	defer part.Close()
+	if err = xml.NewDecoder(io.LimitReader(part, maxPartSize)).Decode(target); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidXLSX, file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex turn the letters of a cell reference like "AB12" into a 0 based column
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		column = column*26 + int(char-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("%w: bad cell reference %q", ErrInvalidXLSX, ref)
+	}
+	return column - 1, nil
+}
+
+func isBlank(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
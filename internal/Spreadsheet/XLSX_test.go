@@ -0,0 +1,81 @@
+package Spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewXLSXWriter(&buffer)
+	if err != nil {
+		t.Fatalf("NewXLSXWriter failed. Err: %v", err)
+	}
+	written := [][]string{{"name", "note"}, {"A & B", "<tag> \"quoted\""}, {"", "only second"}}
+	for _, row := range written {
+		if err = writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow failed. Err: %v", err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf("Close failed. Err: %v", err)
+	}
+
+	rows, err := ReadXLSX(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX failed. Err: %v", err)
+	}
+	values := make([][]string, 0, len(rows))
+	for i, row := range rows {
+		if row.Line != i+1 {
+			t.Fatalf("row %d: expected line %d; got %d", i, i+1, row.Line)
+		}
+		values = append(values, row.Values)
+	}
+	if fmt.Sprintf("%q", values) != fmt.Sprintf("%q", written) {
+		t.Fatalf("unexpected rows %q", values)
+	}
+}
+
+func TestReadXLSXSharedStrings(t *testing.T) {
+	// the layout spreadsheet applications write, shared strings and sparse cells
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Colleges" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId3" Target="worksheets/colleges.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>name</t></si><si><r><t>Rich </t></r><r><t>text</t></r></si></sst>`,
+		"xl/worksheets/colleges.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1"><v>42</v></c></row><row r="4"><c r="B4" t="s"><v>1</v></c></row><row r="5"></row></sheetData></worksheet>`,
+	}
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, body := range parts {
+		entry, _ := archive.Create(name)
+		_, _ = io.WriteString(entry, body)
+	}
+	_ = archive.Close()
+
+	rows, err := ReadXLSX(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX failed. Err: %v", err)
+	}
+	// the line of a row is its reference, not its position
+	if fmt.Sprintf("%v", rows) != `[{1 [name  42]} {4 [ Rich text]}]` {
+		t.Fatalf("unexpected rows %q", rows)
+	}
+	if _, err = ReadXLSX(bytes.NewReader([]byte("a,b\n")), 4); err == nil {
+		t.Fatal("expected a csv file to be rejected")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for column, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(column); got != name {
+			t.Fatalf("columnName(%d): expected %s; got %s", column, name, got)
+		}
+		if got, _ := columnIndex(name + "7"); got != column {
+			t.Fatalf("columnIndex(%s7): expected %d; got %d", name, column, got)
+		}
+	}
+}
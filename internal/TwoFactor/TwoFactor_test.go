@@ -0,0 +1,70 @@
+package TwoFactor
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodeRFC6238(t *testing.T) {
+	// SHA1 vectors of RFC 6238 appendix B, truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := Code(secret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Code failed. Err: %v", err)
+		}
+		if code != expected {
+			t.Fatalf("code at %d: expected %s; got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret failed. Err: %v", err)
+	}
+	now := time.Now()
+	previous, _ := Code(secret, Step(now)-1)
+	if step, ok := Verify(secret, previous, now); !ok || step != Step(now)-1 {
+		t.Fatalf("code of the previous period: expected step %d; got %d %v", Step(now)-1, step, ok)
+	}
+	old, _ := Code(secret, Step(now)-2)
+	if _, ok := Verify(secret, old, now); ok {
+		t.Fatal("code of two periods ago: expected a refusal")
+	}
+	if _, ok := Verify(secret, "12345", now); ok {
+		t.Fatal("short code: expected a refusal")
+	}
+
+	uri := URI("HostelApp", "admin", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/HostelApp:admin?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("NewRecoveryCodes failed. Err: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("expected %d codes; got %d", RecoveryCodeCount, len(codes))
+	}
+	typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", " "))
+	if HashRecoveryCode(typed) != hashes[0] {
+		t.Fatalf("code typed as %q does not match its hash", typed)
+	}
+	if codes[0] == codes[1] || hashes[0] == codes[0] {
+		t.Fatal("expected distinct codes stored hashed")
+	}
+}
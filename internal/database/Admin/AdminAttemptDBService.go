@@ -0,0 +1,127 @@
+package Admin
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// AttemptRetention is how long a failure is kept, a lockout can not be longer
+const AttemptRetention = 24 * time.Hour
+
+type AttemptDBManager struct {
+	client            *mongo.Client
+	attemptCollection *mongo.Collection
+}
+
+func NewAttemptDBManager(client *mongo.Client) *AttemptDBManager {
+	slog.Info(LogHelper.LogServiceStarting("AttemptDBManager"))
+	instance := &AttemptDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("AttemptDBManager"))
+	return instance
+}
+
+func (m *AttemptDBManager) init() {
+	m.attemptCollection = m.client.Database("admindb").Collection("loginAttempts")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *AttemptDBManager) createIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "last_failure", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(AttemptRetention.Seconds())),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.attemptCollection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for attemptDB error: %v", err)))
+	}
+	return nil
+}
+
+// nextFailures is the count of a key after a failure at now over seen
+func nextFailures(seen *Admin.LoginAttempt, now time.Time, window time.Duration) int {
+	if seen == nil || seen.LastFailure.Before(now.Add(-window)) {
+		return 1
+	}
+	return seen.Failures + 1
+}
+
+func (m *AttemptDBManager) RecordFailure(key string, seen *Admin.LoginAttempt, now time.Time, window time.Duration, ctx context.Context) (bool, error) {
+	attempt := Admin.LoginAttempt{Key: key, Failures: nextFailures(seen, now, window), LastFailure: now}
+	if seen == nil {
+		// the key of a concurrent first failure is already taken
+		if _, err := m.attemptCollection.InsertOne(ctx, attempt); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	filter := bson.M{"_id": key, "failures": seen.Failures, "last_failure": seen.LastFailure}
+	result, err := m.attemptCollection.ReplaceOne(ctx, filter, attempt)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (m *AttemptDBManager) ForgetFailure(key string, previous *Admin.LoginAttempt, now time.Time, ctx context.Context) error {
+	// put back previous when the failure at now is still the last one
+	latest := bson.M{"_id": key, "last_failure": now}
+	var restored int64
+	if previous == nil {
+		result, err := m.attemptCollection.DeleteOne(ctx, latest)
+		if err != nil {
+			return err
+		}
+		restored = result.DeletedCount
+	} else {
+		result, err := m.attemptCollection.ReplaceOne(ctx, latest, previous)
+		if err != nil {
+			return err
+		}
+		restored = result.MatchedCount
+	}
+	if restored == 1 {
+		return nil
+	}
+	// another failure was counted since, only this one is taken back
+	_, err := m.attemptCollection.UpdateOne(ctx, bson.M{"_id": key, "failures": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"failures": -1}})
+	return err
+}
+
+func (m *AttemptDBManager) FetchAttempt(key string, ctx context.Context) (*Admin.LoginAttempt, error) {
+	var attempt Admin.LoginAttempt
+	if err := m.attemptCollection.FindOne(ctx, bson.M{"_id": key}).Decode(&attempt); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (m *AttemptDBManager) ClearAttempts(key string, ctx context.Context) error {
+	_, err := m.attemptCollection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
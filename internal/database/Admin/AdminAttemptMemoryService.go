@@ -0,0 +1,72 @@
+package Admin
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AttemptMemoryManager is the in-memory IAttemptDBService, failures are never
+// dropped but a stale count restart like with MongoDB
+type AttemptMemoryManager struct {
+	mu       sync.Mutex
+	attempts map[string]*Admin.LoginAttempt
+}
+
+func NewAttemptMemoryManager() *AttemptMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("AttemptMemoryManager"))
+	instance := &AttemptMemoryManager{
+		attempts: make(map[string]*Admin.LoginAttempt),
+	}
+	slog.Info(LogHelper.LogServiceStarted("AttemptMemoryManager"))
+	return instance
+}
+
+func (m *AttemptMemoryManager) RecordFailure(key string, seen *Admin.LoginAttempt, now time.Time, window time.Duration, ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempt, ok := m.attempts[key]
+	if ok != (seen != nil) || ok && (attempt.Failures != seen.Failures || !attempt.LastFailure.Equal(seen.LastFailure)) {
+		return false, nil
+	}
+	m.attempts[key] = &Admin.LoginAttempt{Key: key, Failures: nextFailures(seen, now, window), LastFailure: now}
+	return true, nil
+}
+
+func (m *AttemptMemoryManager) ForgetFailure(key string, previous *Admin.LoginAttempt, now time.Time, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempt, ok := m.attempts[key]
+	switch {
+	case !ok:
+	case !attempt.LastFailure.Equal(now):
+		attempt.Failures = max(attempt.Failures-1, 0)
+	case previous == nil:
+		delete(m.attempts, key)
+	default:
+		saved := *previous
+		m.attempts[key] = &saved
+	}
+	return nil
+}
+
+func (m *AttemptMemoryManager) FetchAttempt(key string, ctx context.Context) (*Admin.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempt, ok := m.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	saved := *attempt
+	return &saved, nil
+}
+
+func (m *AttemptMemoryManager) ClearAttempts(key string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.attempts, key)
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"HostelApp/LogColor"
 	"HostelApp/LogHelper"
 	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,6 +14,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"log/slog"
+	"strconv"
 	"time"
 )
 
@@ -45,6 +48,10 @@ func (m *CollegeDBManager) createIndexes() error {
 			Keys:    bson.D{{Key: "collage_unique_name", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			// full text search of the college list
+			Keys: bson.D{{Key: "collage_name", Value: "text"}, {Key: "collage_address", Value: "text"}},
+		},
 	}
 
 	// Create all indexes
@@ -52,7 +59,7 @@ func (m *CollegeDBManager) createIndexes() error {
 	defer cancel()
 	_, err := m.collegeCollection.Indexes().CreateMany(ctx, indexModels)
 	if err != nil {
-		return fmt.Errorf(LogColor.Red(fmt.Sprintf("failed to create indexes for loginDB error: %v", err)))
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for loginDB error: %v", err)))
 		// Application can still run, but queries will be slower
 		// and uniqueness won't be enforced at database level
 	}
@@ -62,29 +69,139 @@ func (m *CollegeDBManager) createIndexes() error {
 func (m *CollegeDBManager) addDefaultData() {
 
 }
-func (m *CollegeDBManager) AddCollege(colleges *[]Admin.CollegeData, ctx context.Context) ([]Admin.CollegeNameData, error) {
-	var addedColleges []Admin.CollegeNameData
-	for _, college := range *colleges {
-		// Set default value if not explicitly set
-		if !college.MarkAsDeleted {
-			college.MarkAsDeleted = false
-		}
 
-		// Insert the document (MongoDB will enforce uniqueness)
-		_, err := m.collegeCollection.InsertOne(ctx, college)
-		if err != nil {
-			if mongo.IsDuplicateKeyError(err) {
-				return nil, fmt.Errorf("college with unique name '%s' already exists", college.CollageUniqueName)
-			}
-			return nil, fmt.Errorf("failed to insert college '%s': %v", college.CollageUniqueName, err)
+// illegalOperation is the server error of a transaction on a standalone mongod
+const illegalOperation = 20
+
+func (m *CollegeDBManager) AddCollege(colleges []Admin.CollegeData, mode Admin.ImportMode, ctx context.Context) ([]string, error) {
+	if len(colleges) == 0 {
+		return nil, nil
+	}
+	documents := make([]interface{}, 0, len(colleges))
+	for _, college := range colleges {
+		college.MarkAsDeleted = false
+		documents = append(documents, college)
+	}
+	if mode == Admin.Partial {
+		// unordered so one existing college does not stop the others
+		_, err := m.collegeCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		return duplicateNames(colleges, err)
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+	var duplicates []string
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		existing, findErr := m.FetchExistingNames(uniqueNames(colleges), sc)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if len(existing) > 0 {
+			duplicates = existing
+			return nil, ErrCollegeDuplicate
 		}
+		_, insertErr := m.collegeCollection.InsertMany(sc, documents)
+		return nil, insertErr
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(illegalOperation) {
+		slog.Warn("mongo does not support transactions, importing colleges with a compensating delete")
+		return m.addWithoutTransaction(colleges, documents, ctx)
+	}
+	if errors.Is(err, ErrCollegeDuplicate) {
+		return duplicates, err
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		// a college added by a concurrent request between the check and the insert
+		duplicates, _ = duplicateNames(colleges, err)
+		return duplicates, ErrCollegeDuplicate
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert colleges: %v", err)
+	}
+	return nil, nil
+}
 
-		addedColleges = append(addedColleges, Admin.CollegeNameData{
-			CollageUniqueName: college.CollageUniqueName,
-		})
+// addWithoutTransaction insert in order and delete the inserted colleges again
+// when one fail, for deployments without replica set
+func (m *CollegeDBManager) addWithoutTransaction(colleges []Admin.CollegeData, documents []interface{}, ctx context.Context) ([]string, error) {
+	duplicates, err := m.FetchExistingNames(uniqueNames(colleges), ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) > 0 {
+		return duplicates, ErrCollegeDuplicate
 	}
+	_, err = m.collegeCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(true))
+	if err == nil {
+		return nil, nil
+	}
+	inserted := len(colleges)
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+		inserted = bulkErr.WriteErrors[0].Index
+	}
+	names := make([]string, 0, inserted)
+	for _, college := range colleges[:inserted] {
+		names = append(names, college.CollageUniqueName)
+	}
+	if _, deleteErr := m.collegeCollection.DeleteMany(context.WithoutCancel(ctx), bson.M{"collage_unique_name": bson.M{"$in": names}}); deleteErr != nil {
+		slog.Error(fmt.Sprintf("failed to roll back college import: %v", deleteErr))
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		duplicates, _ = duplicateNames(colleges, err)
+		return duplicates, ErrCollegeDuplicate
+	}
+	return nil, fmt.Errorf("failed to insert colleges: %v", err)
+}
 
-	return addedColleges, nil
+func uniqueNames(colleges []Admin.CollegeData) []string {
+	names := make([]string, 0, len(colleges))
+	for _, college := range colleges {
+		names = append(names, college.CollageUniqueName)
+	}
+	return names
+}
+
+func (m *CollegeDBManager) FetchExistingNames(names []string, ctx context.Context) ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"collage_unique_name": 1})
+	cursor, err := m.collegeCollection.Find(ctx, bson.M{"collage_unique_name": bson.M{"$in": names}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var existing []Admin.CollegeNameData
+	if err = cursor.All(ctx, &existing); err != nil {
+		return nil, err
+	}
+	duplicates := make([]string, 0, len(existing))
+	for _, college := range existing {
+		duplicates = append(duplicates, college.CollageUniqueName)
+	}
+	return duplicates, nil
+}
+
+// duplicateNames return the colleges rejected by the unique index of an
+// InsertMany error, any other write error is returned as is
+func duplicateNames(colleges []Admin.CollegeData, err error) ([]string, error) {
+	if err == nil {
+		return nil, nil
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return nil, fmt.Errorf("failed to insert colleges: %v", err)
+	}
+	var duplicates []string
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return nil, fmt.Errorf("failed to insert college '%s': %v", colleges[writeErr.Index].CollageUniqueName, writeErr)
+		}
+		duplicates = append(duplicates, colleges[writeErr.Index].CollageUniqueName)
+	}
+	return duplicates, nil
 }
 
 // Helper function to validate college data
@@ -93,64 +210,139 @@ func validateCollegeData(college *Admin.CollegeData) error {
 	return validate.Struct(college)
 }
 
-func (m *CollegeDBManager) UpdateCollage(college *Admin.CollegeData, ctx context.Context) error {
-	result, err := m.collegeCollection.UpdateOne(ctx, bson.M{"collage_unique_name": college.CollageUniqueName}, college)
+func (m *CollegeDBManager) UpdateCollage(uniqueName string, update *Admin.CollegeUpdate, ctx context.Context) (*Admin.CollegeData, error) {
+	filter := bson.M{"collage_unique_name": uniqueName, "mark_as_deleted": false}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var college Admin.CollegeData
+	// nil fields are omitted from the $set so they keep their value
+	err := m.collegeCollection.FindOneAndUpdate(ctx, filter, bson.M{"$set": update}, opts).Decode(&college)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, err = m.FetchCollegeByName(uniqueName, ctx); err != nil {
+				return nil, err
+			}
+			return nil, ErrCollegeDeleted
+		}
+		return nil, err
+	}
+	return &college, nil
+}
+
+func (m *CollegeDBManager) setDeleted(uniqueName string, deleted bool, ctx context.Context) error {
+	filter := bson.M{"collage_unique_name": uniqueName}
+	update := bson.M{"$set": bson.M{"mark_as_deleted": deleted}}
+
+	result, err := m.collegeCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("college_unique_name not found")
+		return fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, uniqueName)
 	}
 	return nil
 }
 
 func (m *CollegeDBManager) DeleteCollage(data *Admin.CollegeNameData, ctx context.Context) error {
-	filter := bson.M{"collage_unique_name": data.CollageUniqueName}
-	update := bson.M{"$set": bson.M{"mark_as_deleted": true}}
+	return m.setDeleted(data.CollageUniqueName, true, ctx)
+}
 
-	result, err := m.collegeCollection.UpdateOne(ctx, filter, update)
+func (m *CollegeDBManager) RestoreCollage(data *Admin.CollegeNameData, ctx context.Context) error {
+	return m.setDeleted(data.CollageUniqueName, false, ctx)
+}
+
+func (m *CollegeDBManager) PurgeCollage(data *Admin.CollegeNameData, ctx context.Context) error {
+	result, err := m.collegeCollection.DeleteOne(ctx, bson.M{"collage_unique_name": data.CollageUniqueName, "mark_as_deleted": true})
 	if err != nil {
 		return err
 	}
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("no document found with collage_unique_name: %s", data.CollageUniqueName)
+	if result.DeletedCount == 0 {
+		if _, err = m.FetchCollegeByName(data.CollageUniqueName, ctx); err != nil {
+			return err
+		}
+		return ErrCollegeNotDeleted
 	}
 	return nil
 }
 
-func (m *CollegeDBManager) FetchCollege(filter *Admin.CollegeFilter, ctx context.Context) ([]Admin.CollegeData, error) {
-	// Convert Page and Limit
-	page := filter.Page
-	if page < 1 {
-		page = 1
-	}
-
-	limit := filter.Limit
-	if limit < 1 || limit > 20 {
-		limit = 10
+func (m *CollegeDBManager) FetchCollege(filter *Admin.CollegeFilter, ctx context.Context) (*Listing.Page[Admin.CollegeData], error) {
+	listing, err := parseCollegeListing(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	skip := (page - 1) * limit
-
 	// Build MongoDB filter
 	query := bson.M{}
 	if filter.PinCode != "" {
 		query["pin_code"] = filter.PinCode
 	}
 	query["mark_as_deleted"] = filter.MarkAsDeleted
+	if filter.Search != "" {
+		query["$text"] = bson.M{"$search": filter.Search}
+	}
+	strength := bson.M{}
+	if filter.MinStrength != nil {
+		strength["$gte"] = *filter.MinStrength
+	}
+	if filter.MaxStrength != nil {
+		strength["$lte"] = *filter.MaxStrength
+	}
+	if len(strength) > 0 {
+		query["collage_strength"] = strength
+	}
+	total, err := m.collegeCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, after := 1, "$gt"
+	if listing.sort.Desc {
+		direction, after = -1, "$lt"
+	}
+	sort := bson.D{{Key: listing.sort.Field, Value: direction}}
+	if listing.sort.Field != "collage_unique_name" {
+		// the unique name break ties so a cursor never skip or repeat a college
+		sort = append(sort, bson.E{Key: "collage_unique_name", Value: direction})
+	}
+	if cursor := listing.cursor; cursor != nil {
+		if listing.sort.Field == "collage_unique_name" {
+			query["collage_unique_name"] = bson.M{after: cursor.Key}
+		} else {
+			var value interface{} = cursor.Value
+			if listing.sort.Field == "collage_strength" {
+				value, _ = strconv.ParseInt(cursor.Value, 10, 64)
+			}
+			query["$or"] = bson.A{
+				bson.M{listing.sort.Field: bson.M{after: value}},
+				bson.M{listing.sort.Field: value, "collage_unique_name": bson.M{after: cursor.Key}},
+			}
+		}
+	}
 
 	// MongoDB find options
 	opts := options.Find().
-		SetLimit(limit).
-		SetSkip(skip)
+		SetSort(sort).
+		SetLimit(listing.limit).
+		SetSkip(listing.skip)
 	cursor, err := m.collegeCollection.Find(ctx, query, opts)
-	defer cursor.Close(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var colleges []Admin.CollegeData
+	defer cursor.Close(ctx)
+	colleges := []Admin.CollegeData{}
 	if err = cursor.All(ctx, &colleges); err != nil {
 		return nil, err
 	}
-	return colleges, nil
+	return &Listing.Page[Admin.CollegeData]{Items: colleges, Total: total, NextCursor: listing.nextCursor(colleges)}, nil
+}
+
+func (m *CollegeDBManager) FetchCollegeByName(uniqueName string, ctx context.Context) (*Admin.CollegeData, error) {
+	var college Admin.CollegeData
+	err := m.collegeCollection.FindOne(ctx, bson.M{"collage_unique_name": uniqueName}).Decode(&college)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, uniqueName)
+		}
+		return nil, err
+	}
+	return &college, nil
 }
@@ -0,0 +1,241 @@
+package Admin
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CollegeMemoryManager is the in-memory ICollegeDBService, lists are sorted
+// and filtered the same way as the MongoDB find of CollegeDBManager
+type CollegeMemoryManager struct {
+	mu       sync.RWMutex
+	colleges []*Admin.CollegeData
+	byName   map[string]*Admin.CollegeData // unique index on collage_unique_name
+}
+
+func NewCollegeMemoryManager() *CollegeMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("CollegeMemoryManager"))
+	instance := &CollegeMemoryManager{
+		byName: make(map[string]*Admin.CollegeData),
+	}
+	slog.Info(LogHelper.LogServiceStarted("CollegeMemoryManager"))
+	return instance
+}
+
+func (m *CollegeMemoryManager) AddCollege(colleges []Admin.CollegeData, mode Admin.ImportMode, ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var duplicates []string
+	for _, college := range colleges {
+		if _, exists := m.byName[college.CollageUniqueName]; exists {
+			duplicates = append(duplicates, college.CollageUniqueName)
+		}
+	}
+	if len(duplicates) > 0 && mode != Admin.Partial {
+		return duplicates, ErrCollegeDuplicate
+	}
+	for _, college := range colleges {
+		if _, exists := m.byName[college.CollageUniqueName]; exists {
+			continue
+		}
+		stored := college
+		stored.MarkAsDeleted = false
+		m.colleges = append(m.colleges, &stored)
+		m.byName[stored.CollageUniqueName] = &stored
+	}
+	return duplicates, nil
+}
+
+func (m *CollegeMemoryManager) UpdateCollage(uniqueName string, update *Admin.CollegeUpdate, ctx context.Context) (*Admin.CollegeData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.byName[uniqueName]
+	if !ok {
+		return nil, fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, uniqueName)
+	}
+	if stored.MarkAsDeleted {
+		return nil, ErrCollegeDeleted
+	}
+	if update.CollageName != nil {
+		stored.CollageName = *update.CollageName
+	}
+	if update.CollageAddress != nil {
+		stored.CollageAddress = *update.CollageAddress
+	}
+	if update.PinCode != nil {
+		stored.PinCode = *update.PinCode
+	}
+	if update.CollageIcon != nil {
+		stored.CollageIcon = *update.CollageIcon
+	}
+	if update.CollageStrength != nil {
+		stored.CollageStrength = *update.CollageStrength
+	}
+	college := *stored
+	return &college, nil
+}
+
+func (m *CollegeMemoryManager) setDeleted(uniqueName string, deleted bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.byName[uniqueName]
+	if !ok {
+		return fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, uniqueName)
+	}
+	stored.MarkAsDeleted = deleted
+	return nil
+}
+
+func (m *CollegeMemoryManager) DeleteCollage(data *Admin.CollegeNameData, ctx context.Context) error {
+	return m.setDeleted(data.CollageUniqueName, true)
+}
+
+func (m *CollegeMemoryManager) RestoreCollage(data *Admin.CollegeNameData, ctx context.Context) error {
+	return m.setDeleted(data.CollageUniqueName, false)
+}
+
+func (m *CollegeMemoryManager) PurgeCollage(data *Admin.CollegeNameData, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.byName[data.CollageUniqueName]
+	if !ok {
+		return fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, data.CollageUniqueName)
+	}
+	if !stored.MarkAsDeleted {
+		return ErrCollegeNotDeleted
+	}
+	delete(m.byName, data.CollageUniqueName)
+	for i, college := range m.colleges {
+		if college == stored {
+			m.colleges = append(m.colleges[:i], m.colleges[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *CollegeMemoryManager) FetchCollege(filter *Admin.CollegeFilter, ctx context.Context) (*Listing.Page[Admin.CollegeData], error) {
+	listing, err := parseCollegeListing(filter)
+	if err != nil {
+		return nil, err
+	}
+	terms := searchWords(filter.Search)
+
+	m.mu.RLock()
+	var matched []Admin.CollegeData
+	for _, college := range m.colleges {
+		if filter.PinCode != "" && college.PinCode != filter.PinCode {
+			continue
+		}
+		if college.MarkAsDeleted != filter.MarkAsDeleted {
+			continue
+		}
+		if filter.MinStrength != nil && college.CollageStrength < *filter.MinStrength {
+			continue
+		}
+		if filter.MaxStrength != nil && college.CollageStrength > *filter.MaxStrength {
+			continue
+		}
+		if len(terms) > 0 && !matchesSearch(college, terms) {
+			continue
+		}
+		matched = append(matched, *college)
+	}
+	m.mu.RUnlock()
+
+	field := listing.sort.Field
+	order := func(value string, key string, otherValue string, otherKey string) int {
+		result := compareSortValue(field, value, otherValue)
+		if result == 0 {
+			result = strings.Compare(key, otherKey)
+		}
+		if listing.sort.Desc {
+			return -result
+		}
+		return result
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return order(collegeSortValue(&matched[i], field), matched[i].CollageUniqueName,
+			collegeSortValue(&matched[j], field), matched[j].CollageUniqueName) < 0
+	})
+
+	colleges := []Admin.CollegeData{}
+	skip := listing.skip
+	for i := range matched {
+		if listing.cursor != nil && order(collegeSortValue(&matched[i], field), matched[i].CollageUniqueName, listing.cursor.Value, listing.cursor.Key) <= 0 {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if int64(len(colleges)) >= listing.limit {
+			break
+		}
+		colleges = append(colleges, matched[i])
+	}
+	return &Listing.Page[Admin.CollegeData]{Items: colleges, Total: int64(len(matched)), NextCursor: listing.nextCursor(colleges)}, nil
+}
+
+// compareSortValue compare collage_strength as numbers and other fields as text
+func compareSortValue(field string, a string, b string) int {
+	if field == "collage_strength" {
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(a, b)
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchesSearch approximate the MongoDB text index, a college match when its
+// name or address contain one of the words
+func matchesSearch(college *Admin.CollegeData, terms []string) bool {
+	words := searchWords(college.CollageName + " " + college.CollageAddress)
+	for _, term := range terms {
+		for _, word := range words {
+			if word == term {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *CollegeMemoryManager) FetchCollegeByName(uniqueName string, ctx context.Context) (*Admin.CollegeData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.byName[uniqueName]
+	if !ok {
+		return nil, fmt.Errorf("%w with collage_unique_name: %s", ErrCollegeNotFound, uniqueName)
+	}
+	college := *stored
+	return &college, nil
+}
+
+func (m *CollegeMemoryManager) FetchExistingNames(uniqueNames []string, ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	existing := []string{}
+	for _, name := range uniqueNames {
+		if _, ok := m.byName[name]; ok {
+			existing = append(existing, name)
+		}
+	}
+	return existing, nil
+}
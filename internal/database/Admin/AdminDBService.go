@@ -8,17 +8,38 @@ import (
 
 type DbManager struct {
 	client    *mongo.Client
-	LoginDB   *LoginDBManager
-	CollegeDB *CollegeDBManager
+	LoginDB   ILoginDBService
+	AttemptDB IAttemptDBService
+	SessionDB ISessionDBService
+	// RevocationDB is the revocation list of JWTManager
+	RevocationDB IRevocationDBService
+	CollegeDB    ICollegeDBService
 }
 
 func NewService(client *mongo.Client) *DbManager {
 	slog.Info(LogHelper.LogServiceStarted("MongoDBManager"))
 	adminDBManager := &DbManager{
-		client:    client,
-		LoginDB:   NewLoginDBManager(client),
-		CollegeDB: NewCollageDBManager(client),
+		client:       client,
+		LoginDB:      NewLoginDBManager(client),
+		AttemptDB:    NewAttemptDBManager(client),
+		SessionDB:    NewSessionDBManager(client),
+		RevocationDB: NewRevocationDBManager(client),
+		CollegeDB:    NewCollageDBManager(client),
 	}
 	slog.Info(LogHelper.LogServiceStarted("MongoDBManager"))
 	return adminDBManager
 }
+
+// NewMemoryService is the in-memory counterpart of NewService, used to run and test without MongoDB
+func NewMemoryService() *DbManager {
+	slog.Info(LogHelper.LogServiceStarting("MemoryDBManager"))
+	adminDBManager := &DbManager{
+		LoginDB:      NewLoginMemoryManager(),
+		AttemptDB:    NewAttemptMemoryManager(),
+		SessionDB:    NewSessionMemoryManager(),
+		RevocationDB: NewRevocationMemoryManager(),
+		CollegeDB:    NewCollegeMemoryManager(),
+	}
+	slog.Info(LogHelper.LogServiceStarted("MemoryDBManager"))
+	return adminDBManager
+}
@@ -0,0 +1,39 @@
+package Admin
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+	"time"
+)
+
+func TestLoginDBManager(t *testing.T) {
+	testLoginManager(t, NewLoginDBManager(testutil.MongoClient(t)))
+}
+
+func TestAdminUserDBManager(t *testing.T) {
+	testAdminUserManager(t, NewLoginDBManager(testutil.MongoClient(t)))
+}
+
+func TestTwoFactorDBManager(t *testing.T) {
+	testTwoFactorManager(t, NewLoginDBManager(testutil.MongoClient(t)))
+}
+
+func TestAttemptDBManager(t *testing.T) {
+	testAttemptManager(t, NewAttemptDBManager(testutil.MongoClient(t)))
+}
+
+func TestSessionDBManager(t *testing.T) {
+	testSessionManager(t, NewSessionDBManager(testutil.MongoClient(t)))
+}
+
+func TestRevocationDBManager(t *testing.T) {
+	testRevocationManager(t, NewRevocationDBManager(testutil.MongoClient(t)), time.Now().Truncate(time.Millisecond))
+}
+
+func TestCollegeDBManager(t *testing.T) {
+	testCollegeManager(t, NewCollageDBManager(testutil.MongoClient(t)))
+}
+
+func TestCollegeListingDBManager(t *testing.T) {
+	testCollegeListing(t, NewCollageDBManager(testutil.MongoClient(t)))
+}
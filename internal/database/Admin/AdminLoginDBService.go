@@ -4,6 +4,7 @@ import (
 	"HostelApp/LogColor"
 	"HostelApp/LogHelper"
 	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
 	"context"
 	"errors"
 	"fmt"
@@ -18,8 +19,9 @@ import (
 )
 
 type LoginDBManager struct {
-	client         *mongo.Client
-	userCollection *mongo.Collection
+	client          *mongo.Client
+	userCollection  *mongo.Collection
+	guardCollection *mongo.Collection
 }
 
 func NewLoginDBManager(client *mongo.Client) *LoginDBManager {
@@ -33,11 +35,30 @@ func NewLoginDBManager(client *mongo.Client) *LoginDBManager {
 }
 func (m *LoginDBManager) init() {
 	m.userCollection = m.client.Database("admindb").Collection("adminUsers")
+	m.guardCollection = m.client.Database("admindb").Collection("adminGuards")
 	err := m.createIndexes()
 	m.addDefaultData()
 	if err != nil {
 		log.Panicf("!!!panic %v", err)
 	}
+	m.dropLegacySessions()
+}
+
+// dropLegacySessions remove the single refresh token users had before the
+// sessions collection, it was written as refreshToken on insert and
+// refresh_token on update
+func (m *LoginDBManager) dropLegacySessions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"refresh_token": bson.M{"$exists": true}},
+		bson.M{"refreshToken": bson.M{"$exists": true}},
+		bson.M{"refresh_family": bson.M{"$exists": true}},
+	}}
+	update := bson.M{"$unset": bson.M{"refresh_token": "", "refreshToken": "", "refresh_family": ""}}
+	if _, err := m.userCollection.UpdateMany(ctx, filter, update); err != nil {
+		slog.Error(LogColor.Red(fmt.Sprintf("failed to drop legacy refresh tokens error: %v", err)))
+	}
 }
 
 func (m *LoginDBManager) createIndexes() error {
@@ -58,7 +79,7 @@ func (m *LoginDBManager) createIndexes() error {
 	defer cancel()
 	_, err := m.userCollection.Indexes().CreateMany(ctx, indexModels)
 	if err != nil {
-		return fmt.Errorf(LogColor.Red(fmt.Sprintf("failed to create indexes for loginDB error: %v", err)))
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for loginDB error: %v", err)))
 		// Application can still run, but queries will be slower
 		// and uniqueness won't be enforced at database level
 	}
@@ -72,15 +93,14 @@ func (m *LoginDBManager) addDefaultData() {
 	if err != nil {
 		slog.Info(LogColor.Pink(fmt.Sprintf("failed to find admin login database error: %v", err)))
 		admin := &Admin.AdminUserDetail{
-			Username:     "admin",
-			Password:     "password@123",
-			Email:        "admin@admin.com",
-			ExcessLevel:  Admin.Full,
-			RefreshToken: "",
+			Username:    "admin",
+			Password:    "password@123",
+			Email:       "admin@admin.com",
+			ExcessLevel: Admin.Full,
 		}
 		insertErr := m.UserCreate(admin, ctx)
 		if insertErr != nil {
-			log.Panicf(LogColor.Red(fmt.Sprintf("failed to insert admin user insert error: %v", insertErr)))
+			log.Panic(LogColor.Red(fmt.Sprintf("failed to insert admin user insert error: %v", insertErr)))
 		}
 	}
 }
@@ -91,7 +111,9 @@ func (m *LoginDBManager) IsValidCredentials(credentials *Admin.AdminLogin, ctx c
 	err := m.userCollection.FindOne(ctx, bson.M{"username": credentials.Username}).Decode(&result)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("user not found") // user not found
+			// spend the time of a password check so the answer does not tell the username is unknown
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(credentials.Password))
+			return nil, ErrAdminNotFound // user not found
 		}
 		return nil, fmt.Errorf("internal error: %v", err) // some other DB error
 	}
@@ -99,7 +121,10 @@ func (m *LoginDBManager) IsValidCredentials(credentials *Admin.AdminLogin, ctx c
 	// Compare passwords (NOTE: consider using hashed passwords in production)
 	err = bcrypt.CompareHashAndPassword([]byte(result["password"].(string)), []byte(credentials.Password))
 	if err != nil {
-		return nil, fmt.Errorf("password mismatch")
+		return nil, ErrPasswordMismatch
+	}
+	if disabled, _ := result["disabled"].(bool); disabled {
+		return nil, ErrAdminDisabled
 	}
 	// Get and return _id as string
 	objectID, ok := result["_id"].(primitive.ObjectID)
@@ -110,28 +135,21 @@ func (m *LoginDBManager) IsValidCredentials(credentials *Admin.AdminLogin, ctx c
 
 	return &idStr, nil // success
 }
-func (m *LoginDBManager) UpdateRefreshToken(_id string, refreshToken string, ctx context.Context) error {
-	// Convert string ID to ObjectID
+func (m *LoginDBManager) FetchExcessLevel(_id string, ctx context.Context) (Admin.ExcessType, error) {
 	objectID, err := primitive.ObjectIDFromHex(_id)
 	if err != nil {
-		return fmt.Errorf("invalid user ID: %v", err)
+		return 0, fmt.Errorf("invalid user ID: %v", err)
 	}
 
-	//update refresh token for the user
-	update := bson.M{
-		"$set": bson.M{
-			"refresh_token": refreshToken,
-		},
-	}
-	result, err := m.userCollection.UpdateByID(ctx, objectID, update)
+	var user Admin.AdminUserDetail
+	err = m.userCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
 	if err != nil {
-		return fmt.Errorf("failed to update refresh token: %v", err)
-	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("user not found")
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, fmt.Errorf("user not found")
+		}
+		return 0, fmt.Errorf("internal error: %v", err)
 	}
-	return nil
+	return user.ExcessLevel, nil
 }
 
 func (m *LoginDBManager) UserExit(userDetail *Admin.AdminUserDetail, ctx context.Context) (bool, error) {
@@ -165,33 +183,407 @@ func (m *LoginDBManager) UserCreate(userDetail *Admin.AdminUserDetail, ctx conte
 		"email":        userDetail.Email,
 		"created":      time.Now(),
 		"excess_level": userDetail.ExcessLevel,
-		"refreshToken": userDetail.RefreshToken,
+		"disabled":     false,
 	}
 
 	if _, err = m.userCollection.InsertOne(ctx, newUser); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: username '%s' or email '%s'", ErrAdminDuplicate, userDetail.Username, userDetail.Email)
+		}
 		return fmt.Errorf("false to hash password error: %v", err)
 	}
 	return nil
 }
 
-func (m *LoginDBManager) logoutUser(userDetail *Admin.AdminUserDetail, ctx context.Context) error {
-	// Hash password (never store plain text passwords)
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userDetail.Password), bcrypt.DefaultCost)
+// adminProjection keep the password hash and 2FA secrets out of an AdminUser
+var adminProjection = bson.M{"password": 0, "totp_secret": 0, "totp_pending_secret": 0, "totp_last_step": 0, "recovery_codes": 0}
+
+func adminObjectID(_id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(_id)
 	if err != nil {
-		return fmt.Errorf("false to hash password error: %v", err)
+		return objectID, fmt.Errorf("%w: invalid user ID: %v", ErrAdminNotFound, err)
+	}
+	return objectID, nil
+}
+
+func (m *LoginDBManager) FetchAdmins(filter *Admin.AdminUserFilter, ctx context.Context) (*Listing.Page[Admin.AdminUser], error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.ExcessLevel != 0 {
+		query["excess_level"] = filter.ExcessLevel
+	}
+	total, err := m.userCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
 	}
 
-	// Insert new user
-	newUser := bson.M{
-		"username":     userDetail.Username,
-		"password":     string(hashedPassword),
-		"email":        userDetail.Email,
-		"created":      time.Now(),
-		"refreshToken": userDetail.RefreshToken,
+	opts := options.Find().
+		SetProjection(adminProjection).
+		SetSort(bson.D{{Key: "username", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.userCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	admins := []Admin.AdminUser{}
+	if err = cursor.All(ctx, &admins); err != nil {
+		return nil, err
 	}
+	return &Listing.Page[Admin.AdminUser]{Items: admins, Total: total}, nil
+}
 
-	if _, err = m.userCollection.InsertOne(ctx, newUser); err != nil {
+func (m *LoginDBManager) FetchAdmin(_id string, ctx context.Context) (*Admin.AdminUser, error) {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return nil, err
+	}
+	var admin Admin.AdminUser
+	err = m.userCollection.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(adminProjection)).Decode(&admin)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// otherFullAdmins count the enabled Full admins other than objectID
+func (m *LoginDBManager) otherFullAdmins(objectID primitive.ObjectID, ctx context.Context) (int64, error) {
+	// users created before disabling existed have no disabled field
+	return m.userCollection.CountDocuments(ctx, bson.M{"_id": bson.M{"$ne": objectID}, "excess_level": Admin.Full, "disabled": bson.M{"$ne": true}})
+}
+
+// fullAdminGuard is the document every change removing a Full admin write in
+// its transaction, two of them then conflict instead of both counting the other
+const fullAdminGuard = "full_admins"
+
+// guardLastFullAdmin run change unless it would leave no enabled Full admin,
+// removes tell that change demote, disable or delete the admin. Without replica
+// set change is undone with restore when no other Full admin remain after it
+func (m *LoginDBManager) guardLastFullAdmin(objectID primitive.ObjectID, removes bool, change func(ctx context.Context) error,
+	restore func(stored bson.Raw, ctx context.Context) error, ctx context.Context) error {
+	// check return the stored admin and whether change must keep another Full admin
+	check := func(ctx context.Context, lock bool) (bson.Raw, bool, error) {
+		var stored bson.Raw
+		if err := m.userCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&stored); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, false, ErrAdminNotFound
+			}
+			return nil, false, err
+		}
+		var admin Admin.AdminUser
+		if err := bson.Unmarshal(stored, &admin); err != nil {
+			return nil, false, err
+		}
+		if !removes || admin.ExcessLevel != Admin.Full || admin.Disabled {
+			return stored, false, nil
+		}
+		if lock {
+			update := bson.M{"$inc": bson.M{"version": 1}}
+			if _, err := m.guardCollection.UpdateByID(ctx, fullAdminGuard, update, options.Update().SetUpsert(true)); err != nil {
+				return nil, false, err
+			}
+		}
+		others, err := m.otherFullAdmins(objectID, ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to count Full admins: %w", err)
+		}
+		if others == 0 {
+			return nil, false, ErrLastFullAdmin
+		}
+		return stored, true, nil
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, _, checkErr := check(sc, true); checkErr != nil {
+			return nil, checkErr
+		}
+		return nil, change(sc)
+	})
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) || !serverErr.HasErrorCode(illegalOperation) {
+		return err
+	}
+
+	slog.Warn("mongo does not support transactions, checking the last Full admin again after the change")
+	stored, guarded, err := check(ctx, false)
+	if err != nil {
+		return err
+	}
+	if err = change(ctx); err != nil || !guarded {
+		return err
+	}
+	// two Full admins removed at the same time can both pass the check, both
+	// are undone then so one always remain
+	others, err := m.otherFullAdmins(objectID, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count Full admins: %w", err)
+	}
+	if others > 0 {
+		return nil
+	}
+	if err = restore(stored, context.WithoutCancel(ctx)); err != nil {
+		return fmt.Errorf("failed to restore the last Full admin %s: %v", objectID.Hex(), err)
+	}
+	return ErrLastFullAdmin
+}
+
+func (m *LoginDBManager) UpdateAdmin(_id string, update *Admin.AdminUserUpdate, ctx context.Context) (*Admin.AdminUser, error) {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{}
+	if update.Email != nil {
+		set["email"] = *update.Email
+	}
+	if update.ExcessLevel != nil {
+		set["excess_level"] = *update.ExcessLevel
+	}
+	if update.Disabled != nil {
+		set["disabled"] = *update.Disabled
+	}
+	var admin Admin.AdminUser
+	change := func(ctx context.Context) error {
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(adminProjection)
+		err := m.userCollection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": set}, opts).Decode(&admin)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAdminNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: email '%s'", ErrAdminDuplicate, *update.Email)
+		}
+		return err
+	}
+	restore := func(stored bson.Raw, ctx context.Context) error {
+		var previous Admin.AdminUser
+		if err := bson.Unmarshal(stored, &previous); err != nil {
+			return err
+		}
+		_, err := m.userCollection.UpdateByID(ctx, objectID, bson.M{"$set": bson.M{"excess_level": previous.ExcessLevel, "disabled": previous.Disabled}})
+		return err
+	}
+	if err = m.guardLastFullAdmin(objectID, update.DemotesFull(), change, restore, ctx); err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+func (m *LoginDBManager) DeleteAdmin(_id string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	change := func(ctx context.Context) error {
+		result, err := m.userCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+		if err == nil && result.DeletedCount == 0 {
+			return ErrAdminNotFound
+		}
+		return err
+	}
+	// the whole document is inserted back
+	restore := func(stored bson.Raw, ctx context.Context) error {
+		_, err := m.userCollection.InsertOne(ctx, stored)
+		return err
+	}
+	return m.guardLastFullAdmin(objectID, true, change, restore, ctx)
+}
+
+func (m *LoginDBManager) ChangePassword(_id string, oldPassword string, newPassword string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	var stored struct {
+		Password string `bson:"password"`
+	}
+	if err = m.userCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&stored); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrAdminNotFound
+		}
+		return err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte(oldPassword)); err != nil {
+		return ErrPasswordMismatch
+	}
+	return m.SetPassword(_id, newPassword, ctx)
+}
+
+func (m *LoginDBManager) SetPassword(_id string, newPassword string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
 		return fmt.Errorf("false to hash password error: %v", err)
 	}
+	update := bson.M{
+		"$set": bson.M{
+			"password": string(hashedPassword),
+		},
+	}
+	result, err := m.userCollection.UpdateByID(ctx, objectID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrAdminNotFound
+	}
+	return nil
+}
+
+func (m *LoginDBManager) FetchTwoFactor(_id string, ctx context.Context) (*Admin.TwoFactorState, error) {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return nil, err
+	}
+	var state Admin.TwoFactorState
+	if err = m.userCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&state); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// twoFactorMiss tell why a conditional 2FA update matched nothing
+func (m *LoginDBManager) twoFactorMiss(_id string, whenEnabled error, ctx context.Context) error {
+	state, err := m.FetchTwoFactor(_id, ctx)
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	return whenEnabled
+}
+
+func (m *LoginDBManager) StartTwoFactor(_id string, secret string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID, "totp_enabled": bson.M{"$ne": true}}
+	result, err := m.userCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"totp_pending_secret": secret}})
+	if err != nil {
+		return fmt.Errorf("failed to start two factor: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		if _, err = m.FetchTwoFactor(_id, ctx); err != nil {
+			return err
+		}
+		return ErrTwoFactorEnabled
+	}
+	return nil
+}
+
+func (m *LoginDBManager) EnableTwoFactor(_id string, secret string, step int64, recoveryHashes []string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	// the pending secret must still be the confirmed one, a new enrollment
+	// started in between replaced it
+	filter := bson.M{"_id": objectID, "totp_enabled": bson.M{"$ne": true}, "totp_pending_secret": secret}
+	update := bson.M{
+		"$set": bson.M{
+			"totp_enabled":   true,
+			"totp_secret":    secret,
+			"totp_last_step": step,
+			"recovery_codes": recoveryHashes,
+		},
+		"$unset": bson.M{"totp_pending_secret": ""},
+	}
+	result, err := m.userCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to enable two factor: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		state, fetchErr := m.FetchTwoFactor(_id, ctx)
+		if fetchErr != nil {
+			return fetchErr
+		}
+		if state.Enabled {
+			return ErrTwoFactorEnabled
+		}
+		return ErrTwoFactorNotPending
+	}
+	return nil
+}
+
+func (m *LoginDBManager) UseTOTPStep(_id string, step int64, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID, "totp_enabled": true, "totp_last_step": bson.M{"$lt": step}}
+	result, err := m.userCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"totp_last_step": step}})
+	if err != nil {
+		return fmt.Errorf("failed to use totp code: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return m.twoFactorMiss(_id, ErrTOTPCodeUsed, ctx)
+	}
+	return nil
+}
+
+func (m *LoginDBManager) UseRecoveryCode(_id string, hash string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID, "totp_enabled": true, "recovery_codes": hash}
+	result, err := m.userCollection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"recovery_codes": hash}})
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return m.twoFactorMiss(_id, ErrRecoveryCodeNotFound, ctx)
+	}
+	return nil
+}
+
+func (m *LoginDBManager) SetRecoveryCodes(_id string, recoveryHashes []string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": objectID, "totp_enabled": true}
+	result, err := m.userCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"recovery_codes": recoveryHashes}})
+	if err != nil {
+		return fmt.Errorf("failed to update recovery codes: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return m.twoFactorMiss(_id, ErrTwoFactorNotEnabled, ctx)
+	}
+	return nil
+}
+
+func (m *LoginDBManager) DisableTwoFactor(_id string, ctx context.Context) error {
+	objectID, err := adminObjectID(_id)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set":   bson.M{"totp_enabled": false},
+		"$unset": bson.M{"totp_secret": "", "totp_pending_secret": "", "totp_last_step": "", "recovery_codes": ""},
+	}
+	result, err := m.userCollection.UpdateByID(ctx, objectID, update)
+	if err != nil {
+		return fmt.Errorf("failed to disable two factor: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrAdminNotFound
+	}
 	return nil
 }
@@ -0,0 +1,371 @@
+package Admin
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryAdminUser struct {
+	Admin.AdminUserDetail
+	created   time.Time
+	disabled  bool
+	twoFactor Admin.TwoFactorState
+}
+
+func (u *memoryAdminUser) view(_id string) *Admin.AdminUser {
+	return &Admin.AdminUser{
+		ID:          _id,
+		Username:    u.Username,
+		Email:       u.Email,
+		ExcessLevel: u.ExcessLevel,
+		Disabled:    u.disabled,
+		TwoFactor:   u.twoFactor.Enabled,
+		Created:     u.created,
+	}
+}
+
+// LoginMemoryManager is the in-memory ILoginDBService, it enforce the same
+// unique username and email rules as the MongoDB indexes
+type LoginMemoryManager struct {
+	mu    sync.RWMutex
+	users map[string]*memoryAdminUser // key is the _id hex
+}
+
+func NewLoginMemoryManager() *LoginMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("LoginMemoryManager"))
+	instance := &LoginMemoryManager{
+		users: make(map[string]*memoryAdminUser),
+	}
+	instance.addDefaultData()
+	slog.Info(LogHelper.LogServiceStarted("LoginMemoryManager"))
+	return instance
+}
+
+func (m *LoginMemoryManager) addDefaultData() {
+	admin := &Admin.AdminUserDetail{
+		Username:    "admin",
+		Password:    "password@123",
+		Email:       "admin@admin.com",
+		ExcessLevel: Admin.Full,
+	}
+	if err := m.UserCreate(admin, context.Background()); err != nil {
+		log.Panic(LogColor.Red(fmt.Sprintf("failed to insert admin user insert error: %v", err)))
+	}
+}
+
+func (m *LoginMemoryManager) findUser(_id string) (*memoryAdminUser, error) {
+	if _, err := primitive.ObjectIDFromHex(_id); err != nil {
+		return nil, fmt.Errorf("%w: invalid user ID: %v", ErrAdminNotFound, err)
+	}
+	user, ok := m.users[_id]
+	if !ok {
+		return nil, ErrAdminNotFound
+	}
+	return user, nil
+}
+
+func (m *LoginMemoryManager) IsValidCredentials(credentials *Admin.AdminLogin, ctx context.Context) (*string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _id, user := range m.users {
+		if user.Username != credentials.Username {
+			continue
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(credentials.Password)); err != nil {
+			return nil, ErrPasswordMismatch
+		}
+		if user.disabled {
+			return nil, ErrAdminDisabled
+		}
+		idStr := _id
+		return &idStr, nil
+	}
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(credentials.Password))
+	return nil, ErrAdminNotFound
+}
+
+func (m *LoginMemoryManager) FetchExcessLevel(_id string, ctx context.Context) (Admin.ExcessType, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return 0, err
+	}
+	return user.ExcessLevel, nil
+}
+
+func (m *LoginMemoryManager) UserExit(userDetail *Admin.AdminUserDetail, ctx context.Context) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, user := range m.users {
+		if user.Email == userDetail.Email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *LoginMemoryManager) UserCreate(userDetail *Admin.AdminUserDetail, ctx context.Context) error {
+	// Hash password (never store plain text passwords)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userDetail.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("false to hash password error: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, user := range m.users {
+		if user.Username == userDetail.Username {
+			return fmt.Errorf("%w: username '%s'", ErrAdminDuplicate, userDetail.Username)
+		}
+		if user.Email == userDetail.Email {
+			return fmt.Errorf("%w: email '%s'", ErrAdminDuplicate, userDetail.Email)
+		}
+	}
+
+	newUser := &memoryAdminUser{
+		AdminUserDetail: *userDetail,
+		created:         time.Now(),
+	}
+	newUser.Password = string(hashedPassword)
+	m.users[primitive.NewObjectID().Hex()] = newUser
+	return nil
+}
+
+func (m *LoginMemoryManager) FetchAdmins(filter *Admin.AdminUserFilter, ctx context.Context) (*Listing.Page[Admin.AdminUser], error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Admin.AdminUser
+	for _id, user := range m.users {
+		if filter.ExcessLevel != 0 && user.ExcessLevel != filter.ExcessLevel {
+			continue
+		}
+		matched = append(matched, *user.view(_id))
+	}
+	m.mu.RUnlock()
+
+	// same order as the mongo query
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Username < matched[j].Username
+	})
+	admins := []Admin.AdminUser{}
+	if skip := (page - 1) * limit; skip < int64(len(matched)) {
+		admins = matched[skip:min(skip+limit, int64(len(matched)))]
+	}
+	return &Listing.Page[Admin.AdminUser]{Items: admins, Total: int64(len(matched))}, nil
+}
+
+func (m *LoginMemoryManager) FetchAdmin(_id string, ctx context.Context) (*Admin.AdminUser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return nil, err
+	}
+	return user.view(_id), nil
+}
+
+// otherFullAdmins count the enabled Full admins other than _id, the caller hold the lock
+func (m *LoginMemoryManager) otherFullAdmins(_id string) int {
+	count := 0
+	for other, user := range m.users {
+		if other != _id && user.ExcessLevel == Admin.Full && !user.disabled {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *LoginMemoryManager) UpdateAdmin(_id string, update *Admin.AdminUserUpdate, ctx context.Context) (*Admin.AdminUser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return nil, err
+	}
+	if user.ExcessLevel == Admin.Full && !user.disabled && update.DemotesFull() && m.otherFullAdmins(_id) == 0 {
+		return nil, ErrLastFullAdmin
+	}
+	if update.Email != nil {
+		for other, existing := range m.users {
+			if other != _id && existing.Email == *update.Email {
+				return nil, fmt.Errorf("%w: email '%s'", ErrAdminDuplicate, *update.Email)
+			}
+		}
+		user.Email = *update.Email
+	}
+	if update.ExcessLevel != nil {
+		user.ExcessLevel = *update.ExcessLevel
+	}
+	if update.Disabled != nil {
+		user.disabled = *update.Disabled
+	}
+	return user.view(_id), nil
+}
+
+func (m *LoginMemoryManager) DeleteAdmin(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if user.ExcessLevel == Admin.Full && !user.disabled && m.otherFullAdmins(_id) == 0 {
+		return ErrLastFullAdmin
+	}
+	delete(m.users, _id)
+	return nil
+}
+
+func (m *LoginMemoryManager) ChangePassword(_id string, oldPassword string, newPassword string, ctx context.Context) error {
+	m.mu.RLock()
+	user, err := m.findUser(_id)
+	var hash string
+	if err == nil {
+		hash = user.Password
+	}
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(oldPassword)); err != nil {
+		return ErrPasswordMismatch
+	}
+	return m.SetPassword(_id, newPassword, ctx)
+}
+
+func (m *LoginMemoryManager) SetPassword(_id string, newPassword string, ctx context.Context) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("false to hash password error: %v", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	return nil
+}
+
+func (m *LoginMemoryManager) FetchTwoFactor(_id string, ctx context.Context) (*Admin.TwoFactorState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return nil, err
+	}
+	state := user.twoFactor
+	state.RecoveryCodes = append([]string(nil), state.RecoveryCodes...)
+	return &state, nil
+}
+
+func (m *LoginMemoryManager) StartTwoFactor(_id string, secret string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if user.twoFactor.Enabled {
+		return ErrTwoFactorEnabled
+	}
+	user.twoFactor.PendingSecret = secret
+	return nil
+}
+
+func (m *LoginMemoryManager) EnableTwoFactor(_id string, secret string, step int64, recoveryHashes []string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if user.twoFactor.Enabled {
+		return ErrTwoFactorEnabled
+	}
+	if user.twoFactor.PendingSecret == "" || user.twoFactor.PendingSecret != secret {
+		return ErrTwoFactorNotPending
+	}
+	user.twoFactor = Admin.TwoFactorState{
+		Enabled:       true,
+		Secret:        secret,
+		LastStep:      step,
+		RecoveryCodes: append([]string(nil), recoveryHashes...),
+	}
+	return nil
+}
+
+func (m *LoginMemoryManager) UseTOTPStep(_id string, step int64, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if !user.twoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if step <= user.twoFactor.LastStep {
+		return ErrTOTPCodeUsed
+	}
+	user.twoFactor.LastStep = step
+	return nil
+}
+
+func (m *LoginMemoryManager) UseRecoveryCode(_id string, hash string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if !user.twoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	for i, stored := range user.twoFactor.RecoveryCodes {
+		if stored == hash {
+			user.twoFactor.RecoveryCodes = append(user.twoFactor.RecoveryCodes[:i:i], user.twoFactor.RecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrRecoveryCodeNotFound
+}
+
+func (m *LoginMemoryManager) SetRecoveryCodes(_id string, recoveryHashes []string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	if !user.twoFactor.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	user.twoFactor.RecoveryCodes = append([]string(nil), recoveryHashes...)
+	return nil
+}
+
+func (m *LoginMemoryManager) DisableTwoFactor(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, err := m.findUser(_id)
+	if err != nil {
+		return err
+	}
+	user.twoFactor = Admin.TwoFactorState{}
+	return nil
+}
@@ -0,0 +1,520 @@
+package Admin
+
+import (
+	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoginMemoryManager(t *testing.T) {
+	testLoginManager(t, NewLoginMemoryManager())
+}
+
+// testLoginManager check an ILoginDBService seeded with the default admin
+func testLoginManager(t *testing.T, m ILoginDBService) {
+	ctx := context.Background()
+
+	_id, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "admin", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("default admin login failed. Err: %v", err)
+	}
+	if _, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "admin", Password: "wrong-password"}, ctx); err == nil {
+		t.Fatal("expected password mismatch")
+	}
+
+	duplicate := &Admin.AdminUserDetail{Username: "admin", Email: "other@admin.com", Password: "password@123", ExcessLevel: Admin.ReadOnly}
+	if err := m.UserCreate(duplicate, ctx); err == nil {
+		t.Fatal("expected duplicate username to be rejected")
+	}
+	duplicate = &Admin.AdminUserDetail{Username: "other", Email: "admin@admin.com", Password: "password@123", ExcessLevel: Admin.ReadOnly}
+	if err := m.UserCreate(duplicate, ctx); err == nil {
+		t.Fatal("expected duplicate email to be rejected")
+	}
+
+	if err := m.DeleteAdmin(*_id, ctx); !errors.Is(err, ErrLastFullAdmin) {
+		t.Fatalf("expected ErrLastFullAdmin deleting the only Full admin; got %v", err)
+	}
+	if err := m.SetPassword(*_id, "changed@123", ctx); err != nil {
+		t.Fatalf("SetPassword failed. Err: %v", err)
+	}
+	if err := m.ChangePassword(*_id, "password@123", "other@1234", ctx); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("expected ErrPasswordMismatch with the old password; got %v", err)
+	}
+}
+
+func TestAdminUserMemoryManager(t *testing.T) {
+	testAdminUserManager(t, NewLoginMemoryManager())
+}
+
+// testAdminUserManager check a fresh ILoginDBService, holding only the default
+// admin, always keep one enabled Full admin
+func testAdminUserManager(t *testing.T, m ILoginDBService) {
+	ctx := context.Background()
+	adminID, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "admin", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("default admin login failed. Err: %v", err)
+	}
+	if err = m.UserCreate(&Admin.AdminUserDetail{Username: "root2", Email: "root2@admin.com", Password: "password@123", ExcessLevel: Admin.Full}, ctx); err != nil {
+		t.Fatalf("UserCreate failed. Err: %v", err)
+	}
+	rootID, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "root2", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("root2 login failed. Err: %v", err)
+	}
+	if admins, err := m.FetchAdmins(&Admin.AdminUserFilter{Page: 1, Limit: 10, ExcessLevel: Admin.Full}, ctx); err != nil || admins.Total != 2 {
+		t.Fatalf("expected 2 Full admins; got %+v %v", admins, err)
+	}
+
+	readOnly, disabled := Admin.ReadOnly, true
+	demoted, err := m.UpdateAdmin(*rootID, &Admin.AdminUserUpdate{ExcessLevel: &readOnly}, ctx)
+	if err != nil || demoted.ExcessLevel != Admin.ReadOnly {
+		t.Fatalf("expected root2 demoted while admin stay Full %+v. Err: %v", demoted, err)
+	}
+	if _, err = m.UpdateAdmin(*adminID, &Admin.AdminUserUpdate{Disabled: &disabled}, ctx); !errors.Is(err, ErrLastFullAdmin) {
+		t.Fatalf("expected ErrLastFullAdmin disabling the last Full admin; got %v", err)
+	}
+	if _, err = m.UpdateAdmin(*adminID, &Admin.AdminUserUpdate{ExcessLevel: &readOnly}, ctx); !errors.Is(err, ErrLastFullAdmin) {
+		t.Fatalf("expected ErrLastFullAdmin demoting the last Full admin; got %v", err)
+	}
+	if admin, err := m.FetchAdmin(*adminID, ctx); err != nil || admin.ExcessLevel != Admin.Full || admin.Disabled {
+		t.Fatalf("expected admin left untouched; got %+v %v", admin, err)
+	}
+
+	email := "reader@admin.com"
+	if updated, err := m.UpdateAdmin(*rootID, &Admin.AdminUserUpdate{Email: &email, Disabled: &disabled}, ctx); err != nil || updated.Email != email || !updated.Disabled {
+		t.Fatalf("expected root2 disabled with a new email %+v. Err: %v", updated, err)
+	}
+	if _, err = m.IsValidCredentials(&Admin.AdminLogin{Username: "root2", Password: "password@123"}, ctx); !errors.Is(err, ErrAdminDisabled) {
+		t.Fatalf("expected ErrAdminDisabled logging in as a disabled admin; got %v", err)
+	}
+	if err = m.DeleteAdmin(*rootID, ctx); err != nil {
+		t.Fatalf("DeleteAdmin failed. Err: %v", err)
+	}
+	if _, err = m.FetchAdmin(*rootID, ctx); !errors.Is(err, ErrAdminNotFound) {
+		t.Fatalf("expected ErrAdminNotFound after delete; got %v", err)
+	}
+	if err = m.DeleteAdmin(*adminID, ctx); !errors.Is(err, ErrLastFullAdmin) {
+		t.Fatalf("expected ErrLastFullAdmin deleting the last Full admin; got %v", err)
+	}
+	if err = m.ChangePassword(*adminID, "password@123", "changed@123", ctx); err != nil {
+		t.Fatalf("ChangePassword failed. Err: %v", err)
+	}
+	if _, err = m.IsValidCredentials(&Admin.AdminLogin{Username: "admin", Password: "changed@123"}, ctx); err != nil {
+		t.Fatalf("expected the new password to login. Err: %v", err)
+	}
+
+	// demoting one Full admin while deleting the other leave exactly one
+	if err = m.UserCreate(&Admin.AdminUserDetail{Username: "root3", Email: "root3@admin.com", Password: "password@123", ExcessLevel: Admin.Full}, ctx); err != nil {
+		t.Fatalf("UserCreate failed. Err: %v", err)
+	}
+	root3ID, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "root3", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("root3 login failed. Err: %v", err)
+	}
+	results := make(chan error, 2)
+	go func() {
+		_, err := m.UpdateAdmin(*adminID, &Admin.AdminUserUpdate{ExcessLevel: &readOnly}, ctx)
+		results <- err
+	}()
+	go func() { results <- m.DeleteAdmin(*root3ID, ctx) }()
+	first, second := <-results, <-results
+	if (first == nil) == (second == nil) || !errors.Is(errors.Join(first, second), ErrLastFullAdmin) {
+		t.Fatalf("expected one change to fail with ErrLastFullAdmin; got %v and %v", first, second)
+	}
+	if admins, err := m.FetchAdmins(&Admin.AdminUserFilter{Page: 1, Limit: 10, ExcessLevel: Admin.Full}, ctx); err != nil || admins.Total != 1 {
+		t.Fatalf("expected 1 Full admin left; got %+v %v", admins, err)
+	}
+}
+
+func TestTwoFactorMemoryManager(t *testing.T) {
+	testTwoFactorManager(t, NewLoginMemoryManager())
+}
+
+func testTwoFactorManager(t *testing.T, m ILoginDBService) {
+	ctx := context.Background()
+	adminID, err := m.IsValidCredentials(&Admin.AdminLogin{Username: "admin", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("default admin login failed. Err: %v", err)
+	}
+	id := *adminID
+	if err = m.UseTOTPStep(id, 1, ctx); !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("expected ErrTwoFactorNotEnabled before enrollment; got %v", err)
+	}
+	if err = m.EnableTwoFactor(id, "SECRET1", 10, []string{"h1"}, ctx); !errors.Is(err, ErrTwoFactorNotPending) {
+		t.Fatalf("expected ErrTwoFactorNotPending without a started enrollment; got %v", err)
+	}
+	// a second start replace the unconfirmed secret
+	_ = m.StartTwoFactor(id, "SECRET1", ctx)
+	if err = m.StartTwoFactor(id, "SECRET2", ctx); err != nil {
+		t.Fatalf("StartTwoFactor failed. Err: %v", err)
+	}
+	if err = m.EnableTwoFactor(id, "SECRET1", 10, []string{"h1"}, ctx); !errors.Is(err, ErrTwoFactorNotPending) {
+		t.Fatalf("expected ErrTwoFactorNotPending for a replaced secret; got %v", err)
+	}
+	if err = m.EnableTwoFactor(id, "SECRET2", 10, []string{"h1", "h2"}, ctx); err != nil {
+		t.Fatalf("EnableTwoFactor failed. Err: %v", err)
+	}
+	if err = m.StartTwoFactor(id, "SECRET3", ctx); !errors.Is(err, ErrTwoFactorEnabled) {
+		t.Fatalf("expected ErrTwoFactorEnabled starting again; got %v", err)
+	}
+	state, err := m.FetchTwoFactor(id, ctx)
+	if err != nil || !state.Enabled || state.Secret != "SECRET2" || state.LastStep != 10 || len(state.RecoveryCodes) != 2 {
+		t.Fatalf("expected 2FA enabled with SECRET2; got %+v. Err: %v", state, err)
+	}
+
+	if err = m.UseTOTPStep(id, 10, ctx); !errors.Is(err, ErrTOTPCodeUsed) {
+		t.Fatalf("expected ErrTOTPCodeUsed for the enrollment step; got %v", err)
+	}
+	if err = m.UseTOTPStep(id, 11, ctx); err != nil {
+		t.Fatalf("UseTOTPStep failed. Err: %v", err)
+	}
+	if err = m.UseTOTPStep(id, 9, ctx); !errors.Is(err, ErrTOTPCodeUsed) {
+		t.Fatalf("expected ErrTOTPCodeUsed for an older step; got %v", err)
+	}
+	if err = m.UseRecoveryCode(id, "h1", ctx); err != nil {
+		t.Fatalf("UseRecoveryCode failed. Err: %v", err)
+	}
+	if err = m.UseRecoveryCode(id, "h1", ctx); !errors.Is(err, ErrRecoveryCodeNotFound) {
+		t.Fatalf("expected ErrRecoveryCodeNotFound reusing a code; got %v", err)
+	}
+	if err = m.SetRecoveryCodes(id, []string{"h3"}, ctx); err != nil {
+		t.Fatalf("SetRecoveryCodes failed. Err: %v", err)
+	}
+	if err = m.UseRecoveryCode(id, "h2", ctx); !errors.Is(err, ErrRecoveryCodeNotFound) {
+		t.Fatalf("expected the old codes to be replaced; got %v", err)
+	}
+
+	if err = m.DisableTwoFactor(id, ctx); err != nil {
+		t.Fatalf("DisableTwoFactor failed. Err: %v", err)
+	}
+	if state, err = m.FetchTwoFactor(id, ctx); err != nil || state.Enabled || state.Secret != "" || len(state.RecoveryCodes) != 0 {
+		t.Fatalf("expected 2FA to be cleared; got %+v. Err: %v", state, err)
+	}
+}
+
+func TestAttemptMemoryManager(t *testing.T) {
+	testAttemptManager(t, NewAttemptMemoryManager())
+}
+
+func testAttemptManager(t *testing.T, m IAttemptDBService) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+	if attempt, err := m.FetchAttempt("user:nobody", ctx); err != nil || attempt != nil {
+		t.Fatalf("expected no attempt for an unknown key; got %+v. Err: %v", attempt, err)
+	}
+	record := func(key string, at time.Time) *Admin.LoginAttempt {
+		t.Helper()
+		seen, err := m.FetchAttempt(key, ctx)
+		if err != nil {
+			t.Fatalf("FetchAttempt failed. Err: %v", err)
+		}
+		if counted, err := m.RecordFailure(key, seen, at, time.Minute, ctx); err != nil || !counted {
+			t.Fatalf("expected the failure over %+v to be counted; got %v. Err: %v", seen, counted, err)
+		}
+		attempt, err := m.FetchAttempt(key, ctx)
+		if err != nil {
+			t.Fatalf("FetchAttempt failed. Err: %v", err)
+		}
+		return attempt
+	}
+	for i := 1; i <= 3; i++ {
+		if attempt := record("user:admin", now.Add(time.Duration(i)*time.Second)); attempt.Failures != i {
+			t.Fatalf("expected failure %d to be counted; got %+v", i, attempt)
+		}
+	}
+	// a failure after the window start counting again
+	later := now.Add(5 * time.Minute)
+	if attempt := record("user:admin", later); attempt.Failures != 1 || !attempt.LastFailure.Equal(later) {
+		t.Fatalf("expected the count to restart after the window; got %+v", attempt)
+	}
+
+	// of concurrent failures over the same attempt only one is counted
+	const workers = 10
+	first := record("ip:10.0.0.1", now)
+	var counted atomic.Int32
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := m.RecordFailure("ip:10.0.0.1", first, now.Add(time.Duration(i+1)*time.Millisecond), time.Minute, ctx)
+			if err != nil {
+				t.Errorf("RecordFailure failed. Err: %v", err)
+			}
+			if ok {
+				counted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	attempt, err := m.FetchAttempt("ip:10.0.0.1", ctx)
+	if counted.Load() != 1 || err != nil || attempt.Failures != 2 {
+		t.Fatalf("expected exactly one of the concurrent failures counted; got %d %+v. Err: %v", counted.Load(), attempt, err)
+	}
+	if ok, err := m.RecordFailure("ip:10.0.0.1", first, now, time.Minute, ctx); err != nil || ok {
+		t.Fatalf("expected a stale attempt not to be counted; got %v. Err: %v", ok, err)
+	}
+
+	// the last failure is put back to what it was, an older one is only uncounted
+	third := now.Add(time.Second)
+	record("ip:10.0.0.1", third)
+	if err = m.ForgetFailure("ip:10.0.0.1", attempt, third, ctx); err != nil {
+		t.Fatalf("ForgetFailure failed. Err: %v", err)
+	}
+	restored, err := m.FetchAttempt("ip:10.0.0.1", ctx)
+	if err != nil || restored.Failures != 2 || !restored.LastFailure.Equal(attempt.LastFailure) {
+		t.Fatalf("expected the attempt to be put back to %+v; got %+v. Err: %v", attempt, restored, err)
+	}
+	if err = m.ForgetFailure("ip:10.0.0.1", first, now, ctx); err != nil {
+		t.Fatalf("ForgetFailure failed. Err: %v", err)
+	}
+	if attempt, err = m.FetchAttempt("ip:10.0.0.1", ctx); err != nil || attempt.Failures != 1 {
+		t.Fatalf("expected an older failure to be uncounted; got %+v. Err: %v", attempt, err)
+	}
+	record("user:writer", now)
+	if err = m.ForgetFailure("user:writer", nil, now, ctx); err != nil {
+		t.Fatalf("ForgetFailure failed. Err: %v", err)
+	}
+	if attempt, err = m.FetchAttempt("user:writer", ctx); err != nil || attempt != nil {
+		t.Fatalf("expected a first failure to be taken back; got %+v. Err: %v", attempt, err)
+	}
+
+	if err = m.ClearAttempts("user:admin", ctx); err != nil {
+		t.Fatalf("ClearAttempts failed. Err: %v", err)
+	}
+	if attempt, err = m.FetchAttempt("user:admin", ctx); err != nil || attempt != nil {
+		t.Fatalf("expected the attempts to be cleared; got %+v. Err: %v", attempt, err)
+	}
+	if attempt, err = m.FetchAttempt("ip:10.0.0.1", ctx); err != nil || attempt == nil {
+		t.Fatalf("expected the IP attempts to be kept; got %+v. Err: %v", attempt, err)
+	}
+}
+
+func TestSessionMemoryManager(t *testing.T) {
+	testSessionManager(t, NewSessionMemoryManager())
+}
+
+func testSessionManager(t *testing.T, m ISessionDBService) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+	for i, id := range []string{"s1", "s2"} {
+		session := &Admin.AdminSession{ID: id, AdminID: "a1", Created: now, LastSeen: now.Add(time.Duration(i) * time.Minute),
+			RefreshHash: "h1", ExpiresAt: now.Add(time.Hour)}
+		if err := m.CreateSession(session, ctx); err != nil {
+			t.Fatalf("CreateSession failed. Err: %v", err)
+		}
+	}
+	expired := &Admin.AdminSession{ID: "s3", AdminID: "a1", ExpiresAt: now.Add(-time.Second)}
+	_ = m.CreateSession(expired, ctx)
+
+	rotation := &Admin.SessionRotation{OldHash: "h1", NewHash: "h2", Now: now, ExpiresAt: now.Add(time.Hour)}
+	if err := m.RotateSession("s1", rotation, ctx); err != nil {
+		t.Fatalf("RotateSession failed. Err: %v", err)
+	}
+	if err := m.RotateSession("s1", rotation, ctx); !errors.Is(err, ErrRefreshTokenMismatch) {
+		t.Fatalf("expected ErrRefreshTokenMismatch rotating twice; got %v", err)
+	}
+	if _, err := m.FetchSession("s3", ctx); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected an expired session to be missing; got %v", err)
+	}
+	if err := m.TouchSession("s1", "a2", now, ctx); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected the session of another admin to be missing; got %v", err)
+	}
+	sessions, err := m.FetchSessions("a1", ctx)
+	if err != nil || len(sessions) != 2 || sessions[0].ID != "s2" {
+		t.Fatalf("expected the open sessions last seen first; got %+v. Err: %v", sessions, err)
+	}
+
+	if err = m.DeleteSession("s2", "a1", ctx); err != nil {
+		t.Fatalf("DeleteSession failed. Err: %v", err)
+	}
+	if err = m.DeleteSessions("a1", ctx); err != nil {
+		t.Fatalf("DeleteSessions failed. Err: %v", err)
+	}
+	if sessions, _ = m.FetchSessions("a1", ctx); len(sessions) != 0 {
+		t.Fatalf("expected no session left; got %+v", sessions)
+	}
+}
+
+func TestRevocationMemoryManager(t *testing.T) {
+	m := NewRevocationMemoryManager()
+	now := time.Now()
+	testRevocationManager(t, m, now)
+
+	m.lastSweep = now.Add(-revocationSweep)
+	if _, _ = m.AnyRevoked(nil, now, context.Background()); len(m.revoked) != 1 {
+		t.Fatalf("expected the sweep to drop the expired key; got %v", m.revoked)
+	}
+}
+
+func testRevocationManager(t *testing.T, m IRevocationDBService, now time.Time) {
+	ctx := context.Background()
+	if err := m.Revoke("jti:t1", now.Add(time.Hour), ctx); err != nil {
+		t.Fatalf("Revoke failed. Err: %v", err)
+	}
+	// revoking again with an earlier expiry keep the later one
+	_ = m.Revoke("jti:t1", now.Add(time.Second), ctx)
+	_ = m.Revoke("sid:s1", now.Add(-time.Second), ctx)
+
+	if revoked, err := m.AnyRevoked([]string{"jti:t2", "jti:t1"}, now.Add(time.Minute), ctx); err != nil || !revoked {
+		t.Fatalf("expected jti:t1 to be revoked. Err: %v", err)
+	}
+	if revoked, _ := m.AnyRevoked([]string{"sid:s1"}, now, ctx); revoked {
+		t.Fatal("expected an expired key to be ignored")
+	}
+	if revoked, err := m.AnyRevoked(nil, now, ctx); err != nil || revoked {
+		t.Fatalf("expected no keys to not be revoked. Err: %v", err)
+	}
+}
+
+func TestCollegeMemoryManager(t *testing.T) {
+	testCollegeManager(t, NewCollegeMemoryManager())
+}
+
+func TestCollegeListingMemoryManager(t *testing.T) {
+	testCollegeListing(t, NewCollegeMemoryManager())
+}
+
+// testCollegeManager check an empty ICollegeDBService
+func testCollegeManager(t *testing.T, m ICollegeDBService) {
+	ctx := context.Background()
+
+	var colleges []Admin.CollegeData
+	for _, name := range []string{"college-a", "college-b", "college-c"} {
+		colleges = append(colleges, Admin.CollegeData{CollageName: name, CollageUniqueName: name, PinCode: "560001"})
+	}
+	if _, err := m.AddCollege(colleges, Admin.AllOrNothing, ctx); err != nil {
+		t.Fatalf("AddCollege failed. Err: %v", err)
+	}
+	duplicates, err := m.AddCollege([]Admin.CollegeData{{CollageUniqueName: "college-d"}, {CollageUniqueName: "college-a"}}, Admin.AllOrNothing, ctx)
+	if !errors.Is(err, ErrCollegeDuplicate) || len(duplicates) != 1 || duplicates[0] != "college-a" {
+		t.Fatalf("expected duplicate college to reject the batch; got %v %v", duplicates, err)
+	}
+	if _, err := m.FetchCollegeByName("college-d", ctx); !errors.Is(err, ErrCollegeNotFound) {
+		t.Fatalf("expected nothing inserted from a rejected batch; got %v", err)
+	}
+
+	if err := m.DeleteCollage(&Admin.CollegeNameData{CollageUniqueName: "college-b"}, ctx); err != nil {
+		t.Fatalf("DeleteCollage failed. Err: %v", err)
+	}
+	active, err := m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 10}, ctx)
+	if err != nil || len(active.Items) != 2 || active.Items[0].CollageUniqueName != "college-a" || active.Items[1].CollageUniqueName != "college-c" {
+		t.Fatalf("unexpected active colleges %+v", active)
+	}
+	deleted, err := m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 10, MarkAsDeleted: true}, ctx)
+	if err != nil || len(deleted.Items) != 1 || deleted.Items[0].CollageUniqueName != "college-b" {
+		t.Fatalf("unexpected deleted colleges %+v", deleted)
+	}
+	secondPage, err := m.FetchCollege(&Admin.CollegeFilter{Page: 2, Limit: 1}, ctx)
+	if err != nil || len(secondPage.Items) != 1 || secondPage.Items[0].CollageUniqueName != "college-c" {
+		t.Fatalf("unexpected second page %+v", secondPage)
+	}
+
+	pinCode := "110001"
+	updated, err := m.UpdateCollage("college-a", &Admin.CollegeUpdate{PinCode: &pinCode}, ctx)
+	if err != nil || updated.PinCode != pinCode || updated.CollageName != "college-a" {
+		t.Fatalf("unexpected partial update %+v. Err: %v", updated, err)
+	}
+	if _, err := m.UpdateCollage("college-b", &Admin.CollegeUpdate{PinCode: &pinCode}, ctx); !errors.Is(err, ErrCollegeDeleted) {
+		t.Fatalf("expected ErrCollegeDeleted updating a deleted college; got %v", err)
+	}
+	if err := m.DeleteCollage(&Admin.CollegeNameData{CollageUniqueName: "college-c"}, ctx); err != nil {
+		t.Fatalf("DeleteCollage failed. Err: %v", err)
+	}
+	if err := m.RestoreCollage(&Admin.CollegeNameData{CollageUniqueName: "college-c"}, ctx); err != nil {
+		t.Fatalf("RestoreCollage failed. Err: %v", err)
+	}
+	if restored, err := m.UpdateCollage("college-c", &Admin.CollegeUpdate{PinCode: &pinCode}, ctx); err != nil || restored.MarkAsDeleted {
+		t.Fatalf("expected a restored college to be updatable %+v. Err: %v", restored, err)
+	}
+	if err := m.RestoreCollage(&Admin.CollegeNameData{CollageUniqueName: "college-z"}, ctx); !errors.Is(err, ErrCollegeNotFound) {
+		t.Fatalf("expected ErrCollegeNotFound restoring an unknown college; got %v", err)
+	}
+	if err := m.PurgeCollage(&Admin.CollegeNameData{CollageUniqueName: "college-a"}, ctx); !errors.Is(err, ErrCollegeNotDeleted) {
+		t.Fatalf("expected ErrCollegeNotDeleted purging an active college; got %v", err)
+	}
+	if err := m.PurgeCollage(&Admin.CollegeNameData{CollageUniqueName: "college-b"}, ctx); err != nil {
+		t.Fatalf("PurgeCollage failed. Err: %v", err)
+	}
+	if _, err := m.FetchCollegeByName("college-b", ctx); !errors.Is(err, ErrCollegeNotFound) {
+		t.Fatalf("expected ErrCollegeNotFound after purge; got %v", err)
+	}
+
+	duplicates, err = m.AddCollege([]Admin.CollegeData{{CollageUniqueName: "college-e"}, {CollageUniqueName: "college-a"}}, Admin.Partial, ctx)
+	if err != nil || len(duplicates) != 1 || duplicates[0] != "college-a" {
+		t.Fatalf("expected a partial import to skip only college-a; got %v %v", duplicates, err)
+	}
+	if _, err := m.FetchCollegeByName("college-e", ctx); err != nil {
+		t.Fatalf("expected college-e inserted by the partial import. Err: %v", err)
+	}
+	if err := m.DeleteCollage(&Admin.CollegeNameData{CollageUniqueName: "college-c"}, ctx); err != nil {
+		t.Fatalf("DeleteCollage failed. Err: %v", err)
+	}
+	existing, err := m.FetchExistingNames([]string{"college-a", "college-b", "college-c", "college-e", "college-x"}, ctx)
+	sort.Strings(existing)
+	if err != nil || fmt.Sprint(existing) != "[college-a college-c college-e]" {
+		t.Fatalf("expected the taken names, deleted college-c included; got %v %v", existing, err)
+	}
+}
+
+// testCollegeListing check an empty ICollegeDBService search, filter, sort and
+// walk pages by cursor the same way
+func testCollegeListing(t *testing.T, m ICollegeDBService) {
+	ctx := context.Background()
+	var colleges []Admin.CollegeData
+	for i, address := range []string{"MG Road", "Park Street", "MG Road", "Lake View", "Park Street"} {
+		name := fmt.Sprintf("college-%d", i)
+		colleges = append(colleges, Admin.CollegeData{CollageName: name, CollageUniqueName: name, CollageAddress: address, PinCode: "560001", CollageStrength: int64(i%3+1) * 5})
+	}
+	if _, err := m.AddCollege(colleges, Admin.AllOrNothing, ctx); err != nil {
+		t.Fatalf("AddCollege failed. Err: %v", err)
+	}
+	names := func(page *Listing.Page[Admin.CollegeData]) string {
+		var unique []string
+		for _, college := range page.Items {
+			unique = append(unique, college.CollageUniqueName)
+		}
+		return fmt.Sprint(unique)
+	}
+
+	page, err := m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 10, Search: "park"}, ctx)
+	if err != nil || names(page) != "[college-1 college-4]" || page.Total != 2 {
+		t.Fatalf("search: expected the Park Street colleges; got %+v %v", page, err)
+	}
+	minStrength, maxStrength := int64(10), int64(15)
+	page, err = m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 10, MinStrength: &minStrength, MaxStrength: &maxStrength, Sort: "-collage_strength"}, ctx)
+	if err != nil || names(page) != "[college-2 college-4 college-1]" {
+		t.Fatalf("strength range: expected 3 colleges by strength descending; got %+v %v", page, err)
+	}
+
+	var walked []string
+	filter := &Admin.CollegeFilter{Page: 1, Limit: 2, Sort: "collage_strength"}
+	for pages := 0; pages < 4; pages++ {
+		if page, err = m.FetchCollege(filter, ctx); err != nil {
+			t.Fatalf("cursor page failed. Err: %v", err)
+		}
+		for _, college := range page.Items {
+			walked = append(walked, college.CollageUniqueName)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if fmt.Sprint(walked) != "[college-0 college-3 college-1 college-4 college-2]" {
+		t.Fatalf("cursor pages: expected every college once in order; got %v", walked)
+	}
+	if _, err = m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 2, Sort: "-collage_name", Cursor: "college-0"}, ctx); !errors.Is(err, Listing.ErrInvalidCursor) {
+		t.Fatalf("expected Listing.ErrInvalidCursor; got %v", err)
+	}
+	if _, err = m.FetchCollege(&Admin.CollegeFilter{Page: 1, Limit: 2, Sort: "collage_icon"}, ctx); !errors.Is(err, Listing.ErrInvalidSort) {
+		t.Fatalf("expected Listing.ErrInvalidSort; got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package Admin
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type RevocationDBManager struct {
+	client               *mongo.Client
+	revocationCollection *mongo.Collection
+}
+
+func NewRevocationDBManager(client *mongo.Client) *RevocationDBManager {
+	slog.Info(LogHelper.LogServiceStarting("RevocationDBManager"))
+	instance := &RevocationDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("RevocationDBManager"))
+	return instance
+}
+
+func (m *RevocationDBManager) init() {
+	m.revocationCollection = m.client.Database("admindb").Collection("revokedTokens")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *RevocationDBManager) createIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			// MongoDB drop a key once its tokens are expired, AnyRevoked still
+			// check the expiry for the ones the TTL monitor has not reached yet
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.revocationCollection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for revocationDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *RevocationDBManager) Revoke(key string, expiresAt time.Time, ctx context.Context) error {
+	update := bson.M{"$max": bson.M{"expires_at": expiresAt}}
+	if _, err := m.revocationCollection.UpdateByID(ctx, key, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+func (m *RevocationDBManager) AnyRevoked(keys []string, now time.Time, ctx context.Context) (bool, error) {
+	if len(keys) == 0 {
+		return false, nil
+	}
+	filter := bson.M{"_id": bson.M{"$in": keys}, "expires_at": bson.M{"$gt": now}}
+	count, err := m.revocationCollection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %v", err)
+	}
+	return count > 0, nil
+}
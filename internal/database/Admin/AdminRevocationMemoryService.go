@@ -0,0 +1,65 @@
+package Admin
+
+import (
+	"HostelApp/LogHelper"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// revocationSweep is how often RevocationMemoryManager drop expired keys, the
+// in-memory counterpart of the MongoDB TTL monitor
+const revocationSweep = time.Minute
+
+// RevocationMemoryManager is the in-memory IRevocationDBService
+type RevocationMemoryManager struct {
+	mu        sync.Mutex
+	revoked   map[string]time.Time // key to expiry
+	lastSweep time.Time
+}
+
+func NewRevocationMemoryManager() *RevocationMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("RevocationMemoryManager"))
+	instance := &RevocationMemoryManager{
+		revoked:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+	slog.Info(LogHelper.LogServiceStarted("RevocationMemoryManager"))
+	return instance
+}
+
+// sweep drop the expired keys at most once per revocationSweep, the caller hold the lock
+func (m *RevocationMemoryManager) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < revocationSweep {
+		return
+	}
+	for key, expiresAt := range m.revoked {
+		if !expiresAt.After(now) {
+			delete(m.revoked, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+func (m *RevocationMemoryManager) Revoke(key string, expiresAt time.Time, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(time.Now())
+	if expiresAt.After(m.revoked[key]) {
+		m.revoked[key] = expiresAt
+	}
+	return nil
+}
+
+func (m *RevocationMemoryManager) AnyRevoked(keys []string, now time.Time, ctx context.Context) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+	for _, key := range keys {
+		if expiresAt, ok := m.revoked[key]; ok && expiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
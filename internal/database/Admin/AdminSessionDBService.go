@@ -0,0 +1,146 @@
+package Admin
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type SessionDBManager struct {
+	client            *mongo.Client
+	sessionCollection *mongo.Collection
+}
+
+func NewSessionDBManager(client *mongo.Client) *SessionDBManager {
+	slog.Info(LogHelper.LogServiceStarting("SessionDBManager"))
+	instance := &SessionDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("SessionDBManager"))
+	return instance
+}
+
+func (m *SessionDBManager) init() {
+	m.sessionCollection = m.client.Database("admindb").Collection("adminSessions")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *SessionDBManager) createIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "admin_id", Value: 1}, {Key: "last_seen", Value: -1}},
+		},
+		{
+			// expired sessions are removed by MongoDB, queries still skip the
+			// ones the TTL monitor has not reached yet
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.sessionCollection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for sessionDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *SessionDBManager) CreateSession(session *Admin.AdminSession, ctx context.Context) error {
+	if _, err := m.sessionCollection.InsertOne(ctx, session); err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	return nil
+}
+
+func (m *SessionDBManager) FetchSession(_id string, ctx context.Context) (*Admin.AdminSession, error) {
+	var session Admin.AdminSession
+	err := m.sessionCollection.FindOne(ctx, bson.M{"_id": _id, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (m *SessionDBManager) FetchSessions(adminID string, ctx context.Context) ([]Admin.AdminSession, error) {
+	filter := bson.M{"admin_id": adminID, "expires_at": bson.M{"$gt": time.Now()}}
+	opts := options.Find().SetSort(bson.D{{Key: "last_seen", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := m.sessionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	sessions := []Admin.AdminSession{}
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (m *SessionDBManager) TouchSession(_id string, adminID string, now time.Time, ctx context.Context) error {
+	filter := bson.M{"_id": _id, "admin_id": adminID, "expires_at": bson.M{"$gt": now}}
+	result, err := m.sessionCollection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_seen": now}})
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RotateSession swap the refresh token hash only if it still equals the old one,
+// so two concurrent refresh calls with the same token can not both succeed
+func (m *SessionDBManager) RotateSession(_id string, rotation *Admin.SessionRotation, ctx context.Context) error {
+	filter := bson.M{"_id": _id, "refresh_hash": rotation.OldHash, "expires_at": bson.M{"$gt": rotation.Now}}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_hash": rotation.NewHash,
+			"ip":           rotation.IP,
+			"last_seen":    rotation.Now,
+			"expires_at":   rotation.ExpiresAt,
+		},
+	}
+	result, err := m.sessionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrRefreshTokenMismatch
+	}
+	return nil
+}
+
+func (m *SessionDBManager) DeleteSession(_id string, adminID string, ctx context.Context) error {
+	result, err := m.sessionCollection.DeleteOne(ctx, bson.M{"_id": _id, "admin_id": adminID})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func (m *SessionDBManager) DeleteSessions(adminID string, ctx context.Context) error {
+	if _, err := m.sessionCollection.DeleteMany(ctx, bson.M{"admin_id": adminID}); err != nil {
+		return fmt.Errorf("failed to delete sessions: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package Admin
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionMemoryManager is the in-memory ISessionDBService, expired sessions
+// are kept but never returned like with MongoDB before its TTL monitor run
+type SessionMemoryManager struct {
+	mu       sync.Mutex
+	sessions map[string]*Admin.AdminSession
+}
+
+func NewSessionMemoryManager() *SessionMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("SessionMemoryManager"))
+	instance := &SessionMemoryManager{
+		sessions: make(map[string]*Admin.AdminSession),
+	}
+	slog.Info(LogHelper.LogServiceStarted("SessionMemoryManager"))
+	return instance
+}
+
+// openSession return the session when it is not expired, the caller hold the lock
+func (m *SessionMemoryManager) openSession(_id string, now time.Time) (*Admin.AdminSession, bool) {
+	session, ok := m.sessions[_id]
+	if !ok || !session.ExpiresAt.After(now) {
+		return nil, false
+	}
+	return session, true
+}
+
+func (m *SessionMemoryManager) CreateSession(session *Admin.AdminSession, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := *session
+	m.sessions[session.ID] = &saved
+	return nil
+}
+
+func (m *SessionMemoryManager) FetchSession(_id string, ctx context.Context) (*Admin.AdminSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.openSession(_id, time.Now())
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	saved := *session
+	return &saved, nil
+}
+
+func (m *SessionMemoryManager) FetchSessions(adminID string, ctx context.Context) ([]Admin.AdminSession, error) {
+	m.mu.Lock()
+	now := time.Now()
+	sessions := []Admin.AdminSession{}
+	for _id, session := range m.sessions {
+		if _, ok := m.openSession(_id, now); ok && session.AdminID == adminID {
+			sessions = append(sessions, *session)
+		}
+	}
+	m.mu.Unlock()
+
+	// same order as the mongo query
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeen.Equal(sessions[j].LastSeen) {
+			return sessions[i].LastSeen.After(sessions[j].LastSeen)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+func (m *SessionMemoryManager) TouchSession(_id string, adminID string, now time.Time, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.openSession(_id, now)
+	if !ok || session.AdminID != adminID {
+		return ErrSessionNotFound
+	}
+	session.LastSeen = now
+	return nil
+}
+
+func (m *SessionMemoryManager) RotateSession(_id string, rotation *Admin.SessionRotation, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.openSession(_id, rotation.Now)
+	if !ok || session.RefreshHash != rotation.OldHash {
+		return ErrRefreshTokenMismatch
+	}
+	session.RefreshHash = rotation.NewHash
+	session.IP = rotation.IP
+	session.LastSeen = rotation.Now
+	session.ExpiresAt = rotation.ExpiresAt
+	return nil
+}
+
+func (m *SessionMemoryManager) DeleteSession(_id string, adminID string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[_id]
+	if !ok || session.AdminID != adminID {
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, _id)
+	return nil
+}
+
+func (m *SessionMemoryManager) DeleteSessions(adminID string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _id, session := range m.sessions {
+		if session.AdminID == adminID {
+			delete(m.sessions, _id)
+		}
+	}
+	return nil
+}
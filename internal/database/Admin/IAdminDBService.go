@@ -0,0 +1,194 @@
+package Admin
+
+import (
+	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAdminNotFound    = errors.New("user not found")
+	ErrAdminDisabled    = errors.New("user is disabled")
+	ErrAdminDuplicate   = errors.New("user with the same username or email already exists")
+	ErrLastFullAdmin    = errors.New("the last enabled Full admin can not be deleted, disabled or demoted")
+	ErrPasswordMismatch = errors.New("password mismatch")
+
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrRefreshTokenMismatch = errors.New("refresh token mismatch")
+
+	ErrTwoFactorEnabled     = errors.New("two factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two factor authentication is not enabled")
+	ErrTwoFactorNotPending  = errors.New("no two factor enrollment to confirm")
+	ErrTOTPCodeUsed         = errors.New("code was already used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code is invalid or already used")
+
+	ErrCollegeNotFound   = errors.New("college not found")
+	ErrCollegeDeleted    = errors.New("college is deleted")
+	ErrCollegeNotDeleted = errors.New("college must be deleted before it is purged")
+	ErrCollegeDuplicate  = errors.New("college with the same unique name already exists")
+)
+
+// ILoginDBService is the storage of admin users, LoginDBManager is backed by
+// MongoDB and LoginMemoryManager keep everything in memory
+type ILoginDBService interface {
+	IsValidCredentials(credentials *Admin.AdminLogin, ctx context.Context) (*string, error)
+	FetchExcessLevel(_id string, ctx context.Context) (Admin.ExcessType, error)
+	UserExit(userDetail *Admin.AdminUserDetail, ctx context.Context) (bool, error)
+	UserCreate(userDetail *Admin.AdminUserDetail, ctx context.Context) error
+	FetchAdmins(filter *Admin.AdminUserFilter, ctx context.Context) (*Listing.Page[Admin.AdminUser], error)
+	FetchAdmin(_id string, ctx context.Context) (*Admin.AdminUser, error)
+	// UpdateAdmin and DeleteAdmin return ErrLastFullAdmin when no enabled Full
+	// admin would remain, also when two admins are removed at the same time
+	UpdateAdmin(_id string, update *Admin.AdminUserUpdate, ctx context.Context) (*Admin.AdminUser, error)
+	DeleteAdmin(_id string, ctx context.Context) error
+	// ChangePassword replace the password after checking the old one, SetPassword
+	// replace it unchecked
+	ChangePassword(_id string, oldPassword string, newPassword string, ctx context.Context) error
+	SetPassword(_id string, newPassword string, ctx context.Context) error
+	FetchTwoFactor(_id string, ctx context.Context) (*Admin.TwoFactorState, error)
+	// StartTwoFactor store a secret waiting for its first code, it replace an
+	// unconfirmed one and fail with ErrTwoFactorEnabled once 2FA is on
+	StartTwoFactor(_id string, secret string, ctx context.Context) error
+	// EnableTwoFactor turn on the pending secret when it is still secret,
+	// step is the period of the code that confirmed it
+	EnableTwoFactor(_id string, secret string, step int64, recoveryHashes []string, ctx context.Context) error
+	// UseTOTPStep accept a code period only once and only after the last accepted one
+	UseTOTPStep(_id string, step int64, ctx context.Context) error
+	// UseRecoveryCode remove a recovery code hash so it work only once
+	UseRecoveryCode(_id string, hash string, ctx context.Context) error
+	SetRecoveryCodes(_id string, recoveryHashes []string, ctx context.Context) error
+	DisableTwoFactor(_id string, ctx context.Context) error
+}
+
+// ISessionDBService is the storage of admin sessions, one per login on a
+// device. An expired session is treated like a revoked one
+type ISessionDBService interface {
+	CreateSession(session *Admin.AdminSession, ctx context.Context) error
+	// FetchSession return ErrSessionNotFound for a session revoked or expired
+	FetchSession(_id string, ctx context.Context) (*Admin.AdminSession, error)
+	// FetchSessions return the open sessions of an admin, last seen first
+	FetchSessions(adminID string, ctx context.Context) ([]Admin.AdminSession, error)
+	// TouchSession record activity on an open session of adminID
+	TouchSession(_id string, adminID string, now time.Time, ctx context.Context) error
+	// RotateSession fail with ErrRefreshTokenMismatch when the stored hash is not rotation.OldHash
+	RotateSession(_id string, rotation *Admin.SessionRotation, ctx context.Context) error
+	DeleteSession(_id string, adminID string, ctx context.Context) error
+	// DeleteSessions revoke every session of an admin
+	DeleteSessions(adminID string, ctx context.Context) error
+}
+
+// IRevocationDBService is the list of revoked token keys, a key is kept until
+// the tokens it refuse are expired and then removed on its own
+type IRevocationDBService interface {
+	// Revoke add key until expiresAt, revoking it again keep the later expiry
+	Revoke(key string, expiresAt time.Time, ctx context.Context) error
+	// AnyRevoked tell if one of keys is revoked at now
+	AnyRevoked(keys []string, now time.Time, ctx context.Context) (bool, error)
+}
+
+// IAttemptDBService count failed logins, a failure older than the window of
+// the next one start the count again
+type IAttemptDBService interface {
+	// RecordFailure count a failure of key only if its attempt is still seen,
+	// nil for none, and return false when another failure was counted first.
+	// The check of the count and the failure are then one atomic step
+	RecordFailure(key string, seen *Admin.LoginAttempt, now time.Time, window time.Duration, ctx context.Context) (bool, error)
+	// ForgetFailure take back the failure counted at now over previous, the
+	// attempt is put back to previous when no failure was counted since
+	ForgetFailure(key string, previous *Admin.LoginAttempt, now time.Time, ctx context.Context) error
+	// FetchAttempt return nil when key has no failure
+	FetchAttempt(key string, ctx context.Context) (*Admin.LoginAttempt, error)
+	ClearAttempts(key string, ctx context.Context) error
+}
+
+// ICollegeDBService is the storage of colleges, CollegeDBManager is backed by
+// MongoDB and CollegeMemoryManager keep everything in memory.
+// Deleting a college only mark it as deleted, purging remove it for good
+type ICollegeDBService interface {
+	// AddCollege insert validated colleges with distinct unique names and return
+	// the names that already existed. In AllOrNothing mode nothing is inserted
+	// when one exists and ErrCollegeDuplicate is returned, in Partial mode only
+	// the existing ones are skipped
+	AddCollege(colleges []Admin.CollegeData, mode Admin.ImportMode, ctx context.Context) ([]string, error)
+	// UpdateCollage set the fields present in update on a college that is not deleted
+	UpdateCollage(uniqueName string, update *Admin.CollegeUpdate, ctx context.Context) (*Admin.CollegeData, error)
+	DeleteCollage(data *Admin.CollegeNameData, ctx context.Context) error
+	RestoreCollage(data *Admin.CollegeNameData, ctx context.Context) error
+	// PurgeCollage remove a college already marked as deleted
+	PurgeCollage(data *Admin.CollegeNameData, ctx context.Context) error
+	// FetchCollege return a page of colleges sorted by filter.Sort then unique name,
+	// Listing.ErrInvalidSort and Listing.ErrInvalidCursor report a bad filter
+	FetchCollege(filter *Admin.CollegeFilter, ctx context.Context) (*Listing.Page[Admin.CollegeData], error)
+	FetchCollegeByName(uniqueName string, ctx context.Context) (*Admin.CollegeData, error)
+	// FetchExistingNames return the unique names of the list already taken, deleted colleges included
+	FetchExistingNames(uniqueNames []string, ctx context.Context) ([]string, error)
+}
+
+// collegeListing is a college filter read into a sort, the cursor to continue
+// after and the page bounds, a cursor replace the skip of the page
+type collegeListing struct {
+	sort   Listing.Sort
+	cursor *Listing.Cursor
+	limit  int64
+	skip   int64
+}
+
+func parseCollegeListing(filter *Admin.CollegeFilter) (*collegeListing, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 100)
+	sort, err := Listing.ParseSort(filter.Sort, Admin.CollegeSortFields...)
+	if err != nil {
+		return nil, err
+	}
+	listing := &collegeListing{sort: sort, limit: limit, skip: (page - 1) * limit}
+	if filter.Cursor != "" {
+		if listing.cursor, err = Listing.DecodeCursor(filter.Cursor, sort); err != nil {
+			return nil, err
+		}
+		if sort.Field == "collage_strength" {
+			if _, err = strconv.ParseInt(listing.cursor.Value, 10, 64); err != nil {
+				return nil, Listing.ErrInvalidCursor
+			}
+		}
+		listing.skip = 0
+	}
+	return listing, nil
+}
+
+func collegeSortValue(college *Admin.CollegeData, field string) string {
+	switch field {
+	case "collage_name":
+		return college.CollageName
+	case "collage_address":
+		return college.CollageAddress
+	case "pin_code":
+		return college.PinCode
+	case "collage_strength":
+		return strconv.FormatInt(college.CollageStrength, 10)
+	}
+	return college.CollageUniqueName
+}
+
+// nextCursor point after the last college of a full page, a shorter page is the last one
+func (l *collegeListing) nextCursor(colleges []Admin.CollegeData) string {
+	if len(colleges) == 0 || int64(len(colleges)) < l.limit {
+		return ""
+	}
+	last := &colleges[len(colleges)-1]
+	return Listing.EncodeCursor(Listing.Cursor{
+		Sort:  l.sort.String(),
+		Value: collegeSortValue(last, l.sort.Field),
+		Key:   last.CollageUniqueName,
+	})
+}
+
+// dummyPasswordHash is compared with the password of an unknown username so
+// it take as long to reject as a wrong password
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a password of anyone"), bcrypt.DefaultCost)
+	return hash
+})
@@ -0,0 +1,427 @@
+package Allocation
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+const (
+	activeBedIndex     = "active_bed"
+	activeStudentIndex = "active_student"
+)
+
+// AllocationDBManager rely on partial unique indexes instead of transactions so
+// it also works on a standalone MongoDB: the insert of an active allocation is
+// the single atomic step that claims a bed. Only a bulk allocation and a
+// transfer use a transaction, with a compensating step on a standalone MongoDB
+type AllocationDBManager struct {
+	client               *mongo.Client
+	allocationCollection *mongo.Collection
+	waitlistCollection   *mongo.Collection
+}
+
+func NewAllocationDBManager(client *mongo.Client) *AllocationDBManager {
+	slog.Info(LogHelper.LogServiceStarting("AllocationDBManager"))
+	instance := &AllocationDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("AllocationDBManager"))
+	return instance
+}
+
+func (m *AllocationDBManager) init() {
+	m.allocationCollection = m.client.Database("hosteldb").Collection("allocations")
+	m.waitlistCollection = m.client.Database("hosteldb").Collection("waitlist")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *AllocationDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	activeOnly := bson.M{"status": Allocation.Active}
+	allocationIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "room_id", Value: 1}, {Key: "bed_number", Value: 1}},
+			Options: options.Index().SetName(activeBedIndex).SetUnique(true).SetPartialFilterExpression(activeOnly),
+		},
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}},
+			Options: options.Index().SetName(activeStudentIndex).SetUnique(true).SetPartialFilterExpression(activeOnly),
+		},
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "allocated_at", Value: -1}},
+		},
+	}
+	if _, err := m.allocationCollection.Indexes().CreateMany(ctx, allocationIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for allocationDB error: %v", err)))
+	}
+
+	waitlistIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hostel_id", Value: 1}, {Key: "student_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": Allocation.Waiting}),
+		},
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	}
+	if _, err := m.waitlistCollection.Indexes().CreateMany(ctx, waitlistIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for waitlistDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *AllocationDBManager) CreateAllocation(allocation *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error) {
+	allocation.ID = primitive.NewObjectID().Hex()
+	allocation.Status = Allocation.Active
+	allocation.AllocatedAt = time.Now()
+	allocation.EndedAt = nil
+	if _, err := m.allocationCollection.InsertOne(ctx, allocation); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, duplicateConflict(err)
+		}
+		return nil, fmt.Errorf("failed to insert allocation: %v", err)
+	}
+	return allocation, nil
+}
+
+// illegalOperation is the server error of a transaction on a standalone mongod
+const illegalOperation = 20
+
+// CreateAllocations insert a batch of active allocations in one transaction,
+// when one of them conflicts nothing is committed
+func (m *AllocationDBManager) CreateAllocations(allocations []Allocation.AllocationData, ctx context.Context) ([]Allocation.AllocationData, error) {
+	now := time.Now()
+	documents := make([]interface{}, 0, len(allocations))
+	created := make([]Allocation.AllocationData, 0, len(allocations))
+	for _, allocation := range allocations {
+		allocation.ID = primitive.NewObjectID().Hex()
+		allocation.Status = Allocation.Active
+		allocation.AllocatedAt = now
+		allocation.EndedAt = nil
+		documents = append(documents, allocation)
+		created = append(created, allocation)
+	}
+	if len(documents) == 0 {
+		return created, nil
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return m.allocationCollection.InsertMany(sc, documents)
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(illegalOperation) {
+		slog.Warn("mongo does not support transactions, inserting allocations with a compensating delete")
+		if err = m.insertWithoutTransaction(created, documents, ctx); err != nil {
+			return nil, err
+		}
+		return created, nil
+	}
+	if err != nil {
+		return nil, allocationInsertError(err)
+	}
+	return created, nil
+}
+
+// insertWithoutTransaction insert in order and delete the inserted allocations
+// again when one fail, for deployments without replica set. A failed delete is
+// returned with the insert error as the batch is then partly committed
+func (m *AllocationDBManager) insertWithoutTransaction(created []Allocation.AllocationData, documents []interface{}, ctx context.Context) error {
+	_, err := m.allocationCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(true))
+	if err == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(created))
+	for _, allocation := range created {
+		ids = append(ids, allocation.ID)
+	}
+	if _, deleteErr := m.allocationCollection.DeleteMany(context.WithoutCancel(ctx), bson.M{"_id": bson.M{"$in": ids}}); deleteErr != nil {
+		return fmt.Errorf("failed to insert allocations: %v, then failed to roll back the inserted ones: %v", err, deleteErr)
+	}
+	return allocationInsertError(err)
+}
+
+func allocationInsertError(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return duplicateConflict(err)
+	}
+	return fmt.Errorf("failed to insert allocations: %v", err)
+}
+
+// duplicateKey is the server error of a unique index violation
+const duplicateKey = 11000
+
+// duplicateConflict tell which of the active_bed and active_student indexes a
+// duplicate key error violated. The write errors carry the key pattern of the
+// violated index and student_id is only part of active_student
+func duplicateConflict(err error) error {
+	var writeErrors []mongo.WriteError
+	var writeException mongo.WriteException
+	var bulkException mongo.BulkWriteException
+	if errors.As(err, &writeException) {
+		writeErrors = writeException.WriteErrors
+	} else if errors.As(err, &bulkException) {
+		for _, bulkErr := range bulkException.WriteErrors {
+			writeErrors = append(writeErrors, bulkErr.WriteError)
+		}
+	}
+	for _, writeErr := range writeErrors {
+		if writeErr.Code != duplicateKey {
+			continue
+		}
+		keyPattern, ok := writeErr.Raw.Lookup("keyPattern").DocumentOK()
+		if !ok {
+			continue
+		}
+		if _, lookupErr := keyPattern.LookupErr("student_id"); lookupErr == nil {
+			return ErrStudentAllocated
+		}
+	}
+	// the bed is the only other unique key of an active allocation
+	return ErrBedOccupied
+}
+
+func (m *AllocationDBManager) EndAllocation(_id string, status Allocation.AllocationStatus, reason string, ctx context.Context) (*Allocation.AllocationData, error) {
+	update := bson.M{"$set": bson.M{"status": status, "ended_at": time.Now(), "end_reason": reason}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var allocation Allocation.AllocationData
+	// only an active allocation can be ended, two concurrent vacate can not both succeed
+	err := m.allocationCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": Allocation.Active}, update, opts).Decode(&allocation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchAllocationByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrAllocationNotActive
+		}
+		return nil, err
+	}
+	return &allocation, nil
+}
+
+func (m *AllocationDBManager) RestoreAllocation(_id string, ctx context.Context) error {
+	update := bson.M{
+		"$set":   bson.M{"status": Allocation.Active},
+		"$unset": bson.M{"ended_at": "", "end_reason": ""},
+	}
+	result, err := m.allocationCollection.UpdateOne(ctx, bson.M{"_id": _id, "status": bson.M{"$ne": Allocation.Active}}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return duplicateConflict(err)
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAllocationNotFound
+	}
+	return nil
+}
+
+// TransferAllocation end the allocation and insert next in one transaction
+func (m *AllocationDBManager) TransferAllocation(_id string, reason string, next *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error) {
+	next.ID = primitive.NewObjectID().Hex()
+	next.Status = Allocation.Active
+	next.AllocatedAt = time.Now()
+	next.EndedAt = nil
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, endErr := m.EndAllocation(_id, Allocation.Transferred, reason, sc); endErr != nil {
+			return nil, endErr
+		}
+		return m.allocationCollection.InsertOne(sc, next)
+	})
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(illegalOperation) {
+		slog.Warn("mongo does not support transactions, transferring the allocation with a compensating restore")
+		return m.transferWithoutTransaction(_id, reason, next, ctx)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, allocationInsertError(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// transferWithoutTransaction end the allocation then insert next, the old
+// allocation is restored when the insert fail, for deployments without replica set
+func (m *AllocationDBManager) transferWithoutTransaction(_id string, reason string, next *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error) {
+	if _, err := m.EndAllocation(_id, Allocation.Transferred, reason, ctx); err != nil {
+		return nil, err
+	}
+	if _, err := m.allocationCollection.InsertOne(ctx, next); err != nil {
+		err = allocationInsertError(err)
+		if restoreErr := m.RestoreAllocation(_id, context.WithoutCancel(ctx)); restoreErr != nil {
+			return nil, fmt.Errorf("%w: allocation %s: transfer error: %v, restore error: %v", ErrTransferNotRestored, _id, err, restoreErr)
+		}
+		return nil, err
+	}
+	return next, nil
+}
+
+func (m *AllocationDBManager) FetchAllocationByID(_id string, ctx context.Context) (*Allocation.AllocationData, error) {
+	var allocation Allocation.AllocationData
+	err := m.allocationCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&allocation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrAllocationNotFound
+		}
+		return nil, err
+	}
+	return &allocation, nil
+}
+
+func (m *AllocationDBManager) FetchActiveAllocations(hostelID string, roomID string, ctx context.Context) ([]Allocation.AllocationData, error) {
+	query := bson.M{"status": Allocation.Active}
+	if hostelID != "" {
+		query["hostel_id"] = hostelID
+	}
+	if roomID != "" {
+		query["room_id"] = roomID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "room_id", Value: 1}, {Key: "bed_number", Value: 1}})
+	cursor, err := m.allocationCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var allocations []Allocation.AllocationData
+	if err = cursor.All(ctx, &allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+func (m *AllocationDBManager) FetchStudentsActiveAllocations(studentIDs []string, ctx context.Context) ([]Allocation.AllocationData, error) {
+	if len(studentIDs) == 0 {
+		return nil, nil
+	}
+	cursor, err := m.allocationCollection.Find(ctx, bson.M{"status": Allocation.Active, "student_id": bson.M{"$in": studentIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var allocations []Allocation.AllocationData
+	if err = cursor.All(ctx, &allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+func (m *AllocationDBManager) FetchAllocations(filter *Allocation.AllocationFilter, ctx context.Context) ([]Allocation.AllocationData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.HostelID != "" {
+		query["hostel_id"] = filter.HostelID
+	}
+	if filter.RoomID != "" {
+		query["room_id"] = filter.RoomID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "allocated_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.allocationCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var allocations []Allocation.AllocationData
+	if err = cursor.All(ctx, &allocations); err != nil {
+		return nil, err
+	}
+	return allocations, nil
+}
+
+func (m *AllocationDBManager) AddWaitlistEntry(entry *Allocation.WaitlistEntry, ctx context.Context) (*Allocation.WaitlistEntry, error) {
+	now := time.Now()
+	entry.ID = primitive.NewObjectID().Hex()
+	entry.Status = Allocation.Waiting
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	if _, err := m.waitlistCollection.InsertOne(ctx, entry); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrAlreadyWaitlisted
+		}
+		return nil, fmt.Errorf("failed to insert waitlist entry: %v", err)
+	}
+	return entry, nil
+}
+
+func (m *AllocationDBManager) FetchWaitlist(hostelID string, status Allocation.WaitlistStatus, ctx context.Context) ([]Allocation.WaitlistEntry, error) {
+	query := bson.M{"hostel_id": hostelID}
+	if status != "" {
+		query["status"] = status
+	}
+	// first come first served
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := m.waitlistCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var entries []Allocation.WaitlistEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (m *AllocationDBManager) UpdateWaitlistStatus(_id string, status Allocation.WaitlistStatus, allocationID string, ctx context.Context) (*Allocation.WaitlistEntry, error) {
+	set := bson.M{"status": status, "updated_at": time.Now()}
+	if allocationID != "" {
+		set["allocation_id"] = allocationID
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var entry Allocation.WaitlistEntry
+	err := m.waitlistCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": Allocation.Waiting}, bson.M{"$set": set}, opts).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			count, countErr := m.waitlistCollection.CountDocuments(ctx, bson.M{"_id": _id})
+			if countErr == nil && count == 0 {
+				return nil, ErrWaitlistEntryNotFound
+			}
+			return nil, ErrWaitlistEntryNotWaiting
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
@@ -0,0 +1,38 @@
+package Allocation
+
+import (
+	"HostelApp/internal/testutil"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"testing"
+)
+
+// TestAllocationDBManager run the checks on the active_bed and active_student partial unique indexes
+func TestAllocationDBManager(t *testing.T) {
+	testAllocationManager(t, NewAllocationDBManager(testutil.MongoClient(t)))
+}
+
+func TestDuplicateConflict(t *testing.T) {
+	writeError := func(keyPattern bson.D) mongo.WriteError {
+		raw, _ := bson.Marshal(bson.D{{Key: "code", Value: duplicateKey}, {Key: "keyPattern", Value: keyPattern}})
+		return mongo.WriteError{Code: duplicateKey, Raw: raw}
+	}
+	student := writeError(bson.D{{Key: "student_id", Value: 1}})
+	bed := writeError(bson.D{{Key: "room_id", Value: 1}, {Key: "bed_number", Value: 1}})
+	cases := []struct {
+		err      error
+		expected error
+	}{
+		{mongo.WriteException{WriteErrors: []mongo.WriteError{student}}, ErrStudentAllocated},
+		{mongo.WriteException{WriteErrors: []mongo.WriteError{bed}}, ErrBedOccupied},
+		{mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: student}}}, ErrStudentAllocated},
+		{fmt.Errorf("insert: %w", mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: bed}}}), ErrBedOccupied},
+	}
+	for i, c := range cases {
+		if err := duplicateConflict(c.err); !errors.Is(err, c.expected) {
+			t.Fatalf("case %d: expected %v; got %v", i, c.expected, err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package Allocation
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AllocationMemoryManager is the in-memory IAllocationDBService, every
+// operation hold the lock so the active bed and student rules are atomic
+type AllocationMemoryManager struct {
+	mu          sync.RWMutex
+	allocations map[string]*Allocation.AllocationData // key is _id
+	waitlist    map[string]*Allocation.WaitlistEntry  // key is _id
+}
+
+func NewAllocationMemoryManager() *AllocationMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("AllocationMemoryManager"))
+	instance := &AllocationMemoryManager{
+		allocations: make(map[string]*Allocation.AllocationData),
+		waitlist:    make(map[string]*Allocation.WaitlistEntry),
+	}
+	slog.Info(LogHelper.LogServiceStarted("AllocationMemoryManager"))
+	return instance
+}
+
+// activeConflict emulate the active_bed and active_student partial unique indexes
+func (m *AllocationMemoryManager) activeConflict(allocation *Allocation.AllocationData) error {
+	for _id, stored := range m.allocations {
+		if _id == allocation.ID || stored.Status != Allocation.Active {
+			continue
+		}
+		if stored.RoomID == allocation.RoomID && stored.BedNumber == allocation.BedNumber {
+			return ErrBedOccupied
+		}
+		if stored.StudentID == allocation.StudentID {
+			return ErrStudentAllocated
+		}
+	}
+	return nil
+}
+
+func (m *AllocationMemoryManager) CreateAllocation(allocation *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	allocation.ID = primitive.NewObjectID().Hex()
+	allocation.Status = Allocation.Active
+	allocation.AllocatedAt = time.Now()
+	allocation.EndedAt = nil
+	if err := m.activeConflict(allocation); err != nil {
+		return nil, err
+	}
+	stored := *allocation
+	m.allocations[stored.ID] = &stored
+	return allocation, nil
+}
+
+func (m *AllocationMemoryManager) CreateAllocations(allocations []Allocation.AllocationData, ctx context.Context) ([]Allocation.AllocationData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	created := make([]Allocation.AllocationData, 0, len(allocations))
+	staged := make(map[string]*Allocation.AllocationData, len(allocations))
+	for _, allocation := range allocations {
+		allocation.ID = primitive.NewObjectID().Hex()
+		allocation.Status = Allocation.Active
+		allocation.AllocatedAt = now
+		allocation.EndedAt = nil
+		if err := m.activeConflict(&allocation); err != nil {
+			return nil, err
+		}
+		for _, other := range staged {
+			if other.StudentID == allocation.StudentID {
+				return nil, ErrStudentAllocated
+			}
+			if other.RoomID == allocation.RoomID && other.BedNumber == allocation.BedNumber {
+				return nil, ErrBedOccupied
+			}
+		}
+		stored := allocation
+		staged[stored.ID] = &stored
+		created = append(created, allocation)
+	}
+	for _id, stored := range staged {
+		m.allocations[_id] = stored
+	}
+	return created, nil
+}
+
+func (m *AllocationMemoryManager) EndAllocation(_id string, status Allocation.AllocationStatus, reason string, ctx context.Context) (*Allocation.AllocationData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.allocations[_id]
+	if !ok {
+		return nil, ErrAllocationNotFound
+	}
+	if stored.Status != Allocation.Active {
+		return nil, ErrAllocationNotActive
+	}
+	now := time.Now()
+	stored.Status = status
+	stored.EndedAt = &now
+	stored.EndReason = reason
+	allocation := *stored
+	return &allocation, nil
+}
+
+func (m *AllocationMemoryManager) RestoreAllocation(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.allocations[_id]
+	if !ok || stored.Status == Allocation.Active {
+		return ErrAllocationNotFound
+	}
+	restored := *stored
+	restored.Status = Allocation.Active
+	if err := m.activeConflict(&restored); err != nil {
+		return err
+	}
+	stored.Status = Allocation.Active
+	stored.EndedAt = nil
+	stored.EndReason = ""
+	return nil
+}
+
+func (m *AllocationMemoryManager) TransferAllocation(_id string, reason string, next *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.allocations[_id]
+	if !ok {
+		return nil, ErrAllocationNotFound
+	}
+	if stored.Status != Allocation.Active {
+		return nil, ErrAllocationNotActive
+	}
+	now := time.Now()
+	next.ID = primitive.NewObjectID().Hex()
+	next.Status = Allocation.Active
+	next.AllocatedAt = now
+	next.EndedAt = nil
+	// the old allocation no longer hold its bed and student for the check
+	stored.Status = Allocation.Transferred
+	if err := m.activeConflict(next); err != nil {
+		stored.Status = Allocation.Active
+		return nil, err
+	}
+	stored.EndedAt = &now
+	stored.EndReason = reason
+	created := *next
+	m.allocations[created.ID] = &created
+	return next, nil
+}
+
+func (m *AllocationMemoryManager) FetchAllocationByID(_id string, ctx context.Context) (*Allocation.AllocationData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.allocations[_id]
+	if !ok {
+		return nil, ErrAllocationNotFound
+	}
+	allocation := *stored
+	return &allocation, nil
+}
+
+func (m *AllocationMemoryManager) FetchActiveAllocations(hostelID string, roomID string, ctx context.Context) ([]Allocation.AllocationData, error) {
+	m.mu.RLock()
+	var allocations []Allocation.AllocationData
+	for _, stored := range m.allocations {
+		if stored.Status != Allocation.Active {
+			continue
+		}
+		if hostelID != "" && stored.HostelID != hostelID {
+			continue
+		}
+		if roomID != "" && stored.RoomID != roomID {
+			continue
+		}
+		allocations = append(allocations, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].RoomID != allocations[j].RoomID {
+			return allocations[i].RoomID < allocations[j].RoomID
+		}
+		return allocations[i].BedNumber < allocations[j].BedNumber
+	})
+	return allocations, nil
+}
+
+func (m *AllocationMemoryManager) FetchStudentsActiveAllocations(studentIDs []string, ctx context.Context) ([]Allocation.AllocationData, error) {
+	wanted := make(map[string]bool, len(studentIDs))
+	for _, studentID := range studentIDs {
+		wanted[studentID] = true
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var allocations []Allocation.AllocationData
+	for _, stored := range m.allocations {
+		if stored.Status == Allocation.Active && wanted[stored.StudentID] {
+			allocations = append(allocations, *stored)
+		}
+	}
+	return allocations, nil
+}
+
+func (m *AllocationMemoryManager) FetchAllocations(filter *Allocation.AllocationFilter, ctx context.Context) ([]Allocation.AllocationData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Allocation.AllocationData
+	for _, stored := range m.allocations {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.HostelID != "" && stored.HostelID != filter.HostelID {
+			continue
+		}
+		if filter.RoomID != "" && stored.RoomID != filter.RoomID {
+			continue
+		}
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].AllocatedAt.Equal(matched[j].AllocatedAt) {
+			return matched[i].AllocatedAt.After(matched[j].AllocatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *AllocationMemoryManager) AddWaitlistEntry(entry *Allocation.WaitlistEntry, ctx context.Context) (*Allocation.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, stored := range m.waitlist {
+		if stored.Status == Allocation.Waiting && stored.HostelID == entry.HostelID && stored.StudentID == entry.StudentID {
+			return nil, ErrAlreadyWaitlisted
+		}
+	}
+	now := time.Now()
+	entry.ID = primitive.NewObjectID().Hex()
+	entry.Status = Allocation.Waiting
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	stored := *entry
+	m.waitlist[stored.ID] = &stored
+	return entry, nil
+}
+
+func (m *AllocationMemoryManager) FetchWaitlist(hostelID string, status Allocation.WaitlistStatus, ctx context.Context) ([]Allocation.WaitlistEntry, error) {
+	m.mu.RLock()
+	var entries []Allocation.WaitlistEntry
+	for _, stored := range m.waitlist {
+		if stored.HostelID != hostelID {
+			continue
+		}
+		if status != "" && stored.Status != status {
+			continue
+		}
+		entries = append(entries, *stored)
+	}
+	m.mu.RUnlock()
+
+	// first come first served
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+func (m *AllocationMemoryManager) UpdateWaitlistStatus(_id string, status Allocation.WaitlistStatus, allocationID string, ctx context.Context) (*Allocation.WaitlistEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.waitlist[_id]
+	if !ok {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	if stored.Status != Allocation.Waiting {
+		return nil, ErrWaitlistEntryNotWaiting
+	}
+	stored.Status = status
+	stored.UpdatedAt = time.Now()
+	if allocationID != "" {
+		stored.AllocationID = allocationID
+	}
+	entry := *stored
+	return &entry, nil
+}
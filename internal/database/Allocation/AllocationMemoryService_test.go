@@ -0,0 +1,114 @@
+package Allocation
+
+import (
+	"HostelApp/internal/storageData/Allocation"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAllocationMemoryManager(t *testing.T) {
+	testAllocationManager(t, NewAllocationMemoryManager())
+}
+
+// testAllocationManager check an empty IAllocationDBService keep one active
+// allocation per bed and per student
+func testAllocationManager(t *testing.T, m IAllocationDBService) {
+	ctx := context.Background()
+	bed := func(studentID string, roomID string, number int) *Allocation.AllocationData {
+		return &Allocation.AllocationData{StudentID: studentID, CollageUniqueName: "college-a", HostelID: "h1", RoomID: roomID, BedNumber: number}
+	}
+	first, err := m.CreateAllocation(bed("s1", "r1", 1), ctx)
+	if err != nil || first.Status != Allocation.Active {
+		t.Fatalf("CreateAllocation failed %+v. Err: %v", first, err)
+	}
+	if _, err = m.CreateAllocation(bed("s2", "r1", 1), ctx); !errors.Is(err, ErrBedOccupied) {
+		t.Fatalf("expected ErrBedOccupied for an occupied bed; got %v", err)
+	}
+	if _, err = m.CreateAllocation(bed("s1", "r2", 1), ctx); !errors.Is(err, ErrStudentAllocated) {
+		t.Fatalf("expected ErrStudentAllocated for a student already placed; got %v", err)
+	}
+
+	if _, err = m.CreateAllocations([]Allocation.AllocationData{*bed("s2", "r2", 1), *bed("s3", "r1", 1)}, ctx); !errors.Is(err, ErrBedOccupied) {
+		t.Fatalf("expected the batch rejected on an occupied bed; got %v", err)
+	}
+	if _, err = m.CreateAllocations([]Allocation.AllocationData{*bed("s2", "r2", 1), *bed("s2", "r2", 2)}, ctx); !errors.Is(err, ErrStudentAllocated) {
+		t.Fatalf("expected the batch rejected on a student placed twice; got %v", err)
+	}
+	if active, _ := m.FetchActiveAllocations("", "r2", ctx); len(active) != 0 {
+		t.Fatalf("expected nothing stored from a rejected batch; got %+v", active)
+	}
+	created, err := m.CreateAllocations([]Allocation.AllocationData{*bed("s2", "r2", 1), *bed("s3", "r2", 2)}, ctx)
+	if err != nil || len(created) != 2 {
+		t.Fatalf("CreateAllocations failed %+v. Err: %v", created, err)
+	}
+
+	// the partial indexes only cover active allocations, an ended one free the bed and the student
+	ended, err := m.EndAllocation(first.ID, Allocation.Vacated, "graduated", ctx)
+	if err != nil || ended.Status != Allocation.Vacated || ended.EndedAt == nil {
+		t.Fatalf("EndAllocation failed %+v. Err: %v", ended, err)
+	}
+	if _, err = m.EndAllocation(first.ID, Allocation.Vacated, "", ctx); !errors.Is(err, ErrAllocationNotActive) {
+		t.Fatalf("expected ErrAllocationNotActive ending twice; got %v", err)
+	}
+	next, err := m.CreateAllocation(bed("s4", "r1", 1), ctx)
+	if err != nil {
+		t.Fatalf("expected the vacated bed to be free. Err: %v", err)
+	}
+	if err = m.RestoreAllocation(first.ID, ctx); err == nil {
+		t.Fatal("expected the restore to fail while the bed is taken again")
+	}
+	if _, err = m.EndAllocation(next.ID, Allocation.Vacated, "", ctx); err != nil {
+		t.Fatalf("EndAllocation failed. Err: %v", err)
+	}
+	if err = m.RestoreAllocation(first.ID, ctx); err != nil {
+		t.Fatalf("RestoreAllocation failed. Err: %v", err)
+	}
+	if active, _ := m.FetchActiveAllocations("h1", "", ctx); len(active) != 3 {
+		t.Fatalf("expected 3 active allocations; got %+v", active)
+	}
+	history, _ := m.FetchAllocations(&Allocation.AllocationFilter{Page: 1, Limit: 10, RoomID: "r1"}, ctx)
+	if len(history) != 2 {
+		t.Fatalf("expected the history of the bed; got %+v", history)
+	}
+
+	active, err := m.FetchStudentsActiveAllocations([]string{"s1", "s4", "s3", "s9"}, ctx)
+	if err != nil || len(active) != 2 {
+		t.Fatalf("expected the active allocations of s1 and s3 only; got %+v. Err: %v", active, err)
+	}
+
+	// a transfer to an occupied bed leave the allocation active
+	if _, err = m.TransferAllocation(first.ID, "swap", bed("s1", "r2", 1), ctx); !errors.Is(err, ErrBedOccupied) {
+		t.Fatalf("expected ErrBedOccupied transferring to an occupied bed; got %v", err)
+	}
+	if stored, err := m.FetchAllocationByID(first.ID, ctx); err != nil || stored.Status != Allocation.Active {
+		t.Fatalf("expected the allocation to stay active after a failed transfer; got %+v. Err: %v", stored, err)
+	}
+	moved, err := m.TransferAllocation(first.ID, "upgrade", bed("s1", "r3", 1), ctx)
+	if err != nil || moved.Status != Allocation.Active || moved.ID == first.ID {
+		t.Fatalf("TransferAllocation failed %+v. Err: %v", moved, err)
+	}
+	if stored, err := m.FetchAllocationByID(first.ID, ctx); err != nil || stored.Status != Allocation.Transferred || stored.EndReason != "upgrade" {
+		t.Fatalf("expected the old allocation transferred; got %+v. Err: %v", stored, err)
+	}
+	if _, err = m.TransferAllocation(first.ID, "", bed("s1", "r3", 2), ctx); !errors.Is(err, ErrAllocationNotActive) {
+		t.Fatalf("expected ErrAllocationNotActive transferring twice; got %v", err)
+	}
+
+	entry, err := m.AddWaitlistEntry(&Allocation.WaitlistEntry{HostelID: "h1", StudentID: "s5"}, ctx)
+	if err != nil || entry.Status != Allocation.Waiting {
+		t.Fatalf("AddWaitlistEntry failed %+v. Err: %v", entry, err)
+	}
+	if _, err = m.AddWaitlistEntry(&Allocation.WaitlistEntry{HostelID: "h1", StudentID: "s5"}, ctx); !errors.Is(err, ErrAlreadyWaitlisted) {
+		t.Fatalf("expected ErrAlreadyWaitlisted; got %v", err)
+	}
+	if _, err = m.UpdateWaitlistStatus(entry.ID, Allocation.Cancelled, "", ctx); err != nil {
+		t.Fatalf("UpdateWaitlistStatus failed. Err: %v", err)
+	}
+	if _, err = m.UpdateWaitlistStatus(entry.ID, Allocation.Promoted, "a1", ctx); !errors.Is(err, ErrWaitlistEntryNotWaiting) {
+		t.Fatalf("expected ErrWaitlistEntryNotWaiting; got %v", err)
+	}
+	if waiting, _ := m.FetchWaitlist("h1", Allocation.Waiting, ctx); len(waiting) != 0 {
+		t.Fatalf("expected no waiting entry; got %+v", waiting)
+	}
+}
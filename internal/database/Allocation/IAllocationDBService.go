@@ -0,0 +1,41 @@
+package Allocation
+
+import (
+	"HostelApp/internal/storageData/Allocation"
+	"context"
+	"errors"
+)
+
+var (
+	ErrAllocationNotFound      = errors.New("allocation not found")
+	ErrAllocationNotActive     = errors.New("allocation is not active")
+	ErrBedOccupied             = errors.New("bed is already occupied")
+	ErrStudentAllocated        = errors.New("student already has an active allocation")
+	ErrWaitlistEntryNotFound   = errors.New("waitlist entry not found")
+	ErrWaitlistEntryNotWaiting = errors.New("waitlist entry is not waiting")
+	ErrAlreadyWaitlisted       = errors.New("student is already waiting for this hostel")
+	// ErrTransferNotRestored is a failed transfer whose old allocation could not
+	// be restored, the student is left without an active allocation
+	ErrTransferNotRestored = errors.New("transfer failed and the previous allocation could not be restored")
+)
+
+// IAllocationDBService is the storage of bed allocations and hostel waitlists.
+// A bed and a student can only be part of one active allocation, implementations
+// must enforce it atomically so concurrent requests can not over-book a bed
+type IAllocationDBService interface {
+	CreateAllocation(allocation *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error)
+	CreateAllocations(allocations []Allocation.AllocationData, ctx context.Context) ([]Allocation.AllocationData, error) // all or nothing
+	EndAllocation(_id string, status Allocation.AllocationStatus, reason string, ctx context.Context) (*Allocation.AllocationData, error)
+	RestoreAllocation(_id string, ctx context.Context) error
+	// TransferAllocation end an active allocation as transferred and create next
+	// in one step, when next conflicts the old allocation stay active
+	TransferAllocation(_id string, reason string, next *Allocation.AllocationData, ctx context.Context) (*Allocation.AllocationData, error)
+	FetchAllocationByID(_id string, ctx context.Context) (*Allocation.AllocationData, error)
+	FetchActiveAllocations(hostelID string, roomID string, ctx context.Context) ([]Allocation.AllocationData, error)
+	// FetchStudentsActiveAllocations return the active allocations of the students in any hostel
+	FetchStudentsActiveAllocations(studentIDs []string, ctx context.Context) ([]Allocation.AllocationData, error)
+	FetchAllocations(filter *Allocation.AllocationFilter, ctx context.Context) ([]Allocation.AllocationData, error)
+	AddWaitlistEntry(entry *Allocation.WaitlistEntry, ctx context.Context) (*Allocation.WaitlistEntry, error)
+	FetchWaitlist(hostelID string, status Allocation.WaitlistStatus, ctx context.Context) ([]Allocation.WaitlistEntry, error)
+	UpdateWaitlistStatus(_id string, status Allocation.WaitlistStatus, allocationID string, ctx context.Context) (*Allocation.WaitlistEntry, error)
+}
@@ -0,0 +1,156 @@
+package Attendance
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Attendance"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type AttendanceDBManager struct {
+	client           *mongo.Client
+	recordCollection *mongo.Collection
+}
+
+func NewAttendanceDBManager(client *mongo.Client) *AttendanceDBManager {
+	slog.Info(LogHelper.LogServiceStarting("AttendanceDBManager"))
+	instance := &AttendanceDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("AttendanceDBManager"))
+	return instance
+}
+
+func (m *AttendanceDBManager) init() {
+	m.recordCollection = m.client.Database("hosteldb").Collection("attendance")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *AttendanceDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "date", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "date", Value: 1}},
+		},
+	}
+	if _, err := m.recordCollection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for attendanceDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *AttendanceDBManager) UpsertRecords(records []Attendance.AttendanceRecord, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(records))
+	for _, record := range records {
+		set := bson.M{
+			"collage_unique_name": record.CollageUniqueName,
+			"hostel_id":           record.HostelID,
+			"room_id":             record.RoomID,
+			"mark":                record.Mark,
+			"marked_by":           record.MarkedBy,
+			"updated_at":          now,
+		}
+		update := bson.M{
+			"$set":         set,
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID().Hex(), "created_at": now},
+		}
+		if record.ApprovalRef != "" {
+			set["approval_ref"] = record.ApprovalRef
+		} else {
+			update["$unset"] = bson.M{"approval_ref": ""}
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"student_id": record.StudentID, "date": record.Date}).
+			SetUpdate(update).
+			SetUpsert(true))
+	}
+	if _, err := m.recordCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return nil, fmt.Errorf("failed to write attendance: %v", err)
+	}
+
+	// read back to return the ids and creation time of records that already existed
+	keys := make(bson.A, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, bson.M{"student_id": record.StudentID, "date": record.Date})
+	}
+	cursor, err := m.recordCollection.Find(ctx, bson.M{"$or": keys}, options.Find().SetSort(bson.D{{Key: "student_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var stored []Attendance.AttendanceRecord
+	if err = cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (m *AttendanceDBManager) FetchRecords(filter *Attendance.AttendanceFilter, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.HostelID != "" {
+		query["hostel_id"] = filter.HostelID
+	}
+	if filter.RoomID != "" {
+		query["room_id"] = filter.RoomID
+	}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.Date != "" {
+		query["date"] = filter.Date
+	}
+	if filter.Mark != "" {
+		query["mark"] = filter.Mark
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "date", Value: -1}, {Key: "student_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.recordCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var records []Attendance.AttendanceRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (m *AttendanceDBManager) FetchRange(hostelID string, from string, to string, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	query := bson.M{"hostel_id": hostelID, "date": bson.M{"$gte": from, "$lte": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "student_id", Value: 1}, {Key: "date", Value: 1}})
+	cursor, err := m.recordCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var records []Attendance.AttendanceRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,11 @@
+package Attendance
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestAttendanceDBManager run the checks on the student and date unique upsert
+func TestAttendanceDBManager(t *testing.T) {
+	testAttendanceManager(t, NewAttendanceDBManager(testutil.MongoClient(t)))
+}
@@ -0,0 +1,118 @@
+package Attendance
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Attendance"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AttendanceMemoryManager is the in-memory IAttendanceDBService
+type AttendanceMemoryManager struct {
+	mu      sync.RWMutex
+	records map[string]*Attendance.AttendanceRecord // key is student_id|date
+}
+
+func NewAttendanceMemoryManager() *AttendanceMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("AttendanceMemoryManager"))
+	instance := &AttendanceMemoryManager{
+		records: make(map[string]*Attendance.AttendanceRecord),
+	}
+	slog.Info(LogHelper.LogServiceStarted("AttendanceMemoryManager"))
+	return instance
+}
+
+func recordKey(studentID string, date string) string {
+	return studentID + "|" + date
+}
+
+func (m *AttendanceMemoryManager) UpsertRecords(records []Attendance.AttendanceRecord, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	stored := make([]Attendance.AttendanceRecord, 0, len(records))
+	for _, record := range records {
+		key := recordKey(record.StudentID, record.Date)
+		if existing, ok := m.records[key]; ok {
+			record.ID = existing.ID
+			record.CreatedAt = existing.CreatedAt
+		} else {
+			record.ID = primitive.NewObjectID().Hex()
+			record.CreatedAt = now
+		}
+		record.UpdatedAt = now
+		saved := record
+		m.records[key] = &saved
+		stored = append(stored, record)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].StudentID < stored[j].StudentID })
+	return stored, nil
+}
+
+func (m *AttendanceMemoryManager) FetchRecords(filter *Attendance.AttendanceFilter, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Attendance.AttendanceRecord
+	for _, stored := range m.records {
+		if filter.HostelID != "" && stored.HostelID != filter.HostelID {
+			continue
+		}
+		if filter.RoomID != "" && stored.RoomID != filter.RoomID {
+			continue
+		}
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.Date != "" && stored.Date != filter.Date {
+			continue
+		}
+		if filter.Mark != "" && stored.Mark != filter.Mark {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	// same order as the mongo query, latest roll-call first
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Date != matched[j].Date {
+			return matched[i].Date > matched[j].Date
+		}
+		return matched[i].StudentID < matched[j].StudentID
+	})
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *AttendanceMemoryManager) FetchRange(hostelID string, from string, to string, ctx context.Context) ([]Attendance.AttendanceRecord, error) {
+	m.mu.RLock()
+	var matched []Attendance.AttendanceRecord
+	for _, stored := range m.records {
+		// dates are YYYY-MM-DD so the string order is the calendar order
+		if stored.HostelID == hostelID && stored.Date >= from && stored.Date <= to {
+			matched = append(matched, *stored)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].StudentID != matched[j].StudentID {
+			return matched[i].StudentID < matched[j].StudentID
+		}
+		return matched[i].Date < matched[j].Date
+	})
+	return matched, nil
+}
@@ -0,0 +1,54 @@
+package Attendance
+
+import (
+	"HostelApp/internal/storageData/Attendance"
+	"context"
+	"testing"
+)
+
+func TestAttendanceMemoryManager(t *testing.T) {
+	testAttendanceManager(t, NewAttendanceMemoryManager())
+}
+
+// testAttendanceManager check an empty IAttendanceDBService keep one record per
+// student and date and return a hostel range by student then date
+func testAttendanceManager(t *testing.T, m IAttendanceDBService) {
+	ctx := context.Background()
+	record := func(studentID string, date string, mark Attendance.Mark) Attendance.AttendanceRecord {
+		return Attendance.AttendanceRecord{StudentID: studentID, CollageUniqueName: "college-a", HostelID: "h1", RoomID: "r1", Date: date, Mark: mark, MarkedBy: "u1"}
+	}
+	first, err := m.UpsertRecords([]Attendance.AttendanceRecord{
+		record("s2", "2026-03-02", Attendance.Present),
+		record("s1", "2026-03-02", Attendance.Absent),
+		record("s1", "2026-03-01", Attendance.Present),
+	}, ctx)
+	if err != nil || len(first) != 3 {
+		t.Fatalf("UpsertRecords failed %+v. Err: %v", first, err)
+	}
+	again, err := m.UpsertRecords([]Attendance.AttendanceRecord{record("s1", "2026-03-02", Attendance.Present)}, ctx)
+	if err != nil || len(again) != 1 {
+		t.Fatalf("UpsertRecords failed %+v. Err: %v", again, err)
+	}
+	for _, stored := range first {
+		if stored.StudentID == "s1" && stored.Date == "2026-03-02" && stored.ID != again[0].ID {
+			t.Fatalf("expected marking again to keep the record id %s; got %s", stored.ID, again[0].ID)
+		}
+	}
+
+	records, err := m.FetchRange("h1", "2026-03-01", "2026-03-02", ctx)
+	if err != nil || len(records) != 3 {
+		t.Fatalf("expected 3 records in range; got %+v %v", records, err)
+	}
+	order := []string{"s1 2026-03-01 present", "s1 2026-03-02 present", "s2 2026-03-02 present"}
+	for i, stored := range records {
+		if got := stored.StudentID + " " + stored.Date + " " + string(stored.Mark); got != order[i] {
+			t.Fatalf("record %d: expected %s; got %s", i, order[i], got)
+		}
+	}
+	if records, err = m.FetchRange("h1", "2026-03-02", "2026-03-02", ctx); err != nil || len(records) != 2 {
+		t.Fatalf("expected 2 records on 2026-03-02; got %+v %v", records, err)
+	}
+	if absent, err := m.FetchRecords(&Attendance.AttendanceFilter{Page: 1, Limit: 10, Mark: Attendance.Absent}, ctx); err != nil || len(absent) != 0 {
+		t.Fatalf("expected the absent mark overwritten; got %+v %v", absent, err)
+	}
+}
@@ -0,0 +1,16 @@
+package Attendance
+
+import (
+	"HostelApp/internal/storageData/Attendance"
+	"context"
+)
+
+// IAttendanceDBService is the storage of roll-call marks, a student has at most
+// one record per date and writing it again replace the mark
+type IAttendanceDBService interface {
+	// UpsertRecords insert or overwrite the records keyed on (student_id, date)
+	UpsertRecords(records []Attendance.AttendanceRecord, ctx context.Context) ([]Attendance.AttendanceRecord, error)
+	FetchRecords(filter *Attendance.AttendanceFilter, ctx context.Context) ([]Attendance.AttendanceRecord, error)
+	// FetchRange return every record of a hostel between from and to included, by student then date
+	FetchRange(hostelID string, from string, to string, ctx context.Context) ([]Attendance.AttendanceRecord, error)
+}
@@ -0,0 +1,116 @@
+package Audit
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Audit"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type AuditDBManager struct {
+	client          *mongo.Client
+	auditCollection *mongo.Collection
+}
+
+func NewAuditDBManager(client *mongo.Client) *AuditDBManager {
+	slog.Info(LogHelper.LogServiceStarting("AuditDBManager"))
+	instance := &AuditDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("AuditDBManager"))
+	return instance
+}
+
+func (m *AuditDBManager) init() {
+	// changes hold free form values, decode their documents as maps so they render as json objects
+	opts := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	m.auditCollection = m.client.Database("hosteldb").Collection("audit_log", opts)
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *AuditDBManager) createIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := m.auditCollection.Indexes().CreateMany(ctx, indexModels); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for auditDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *AuditDBManager) Append(entry *Audit.AuditEntry, ctx context.Context) error {
+	entry.ID = primitive.NewObjectID().Hex()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	_, err := m.auditCollection.InsertOne(ctx, entry)
+	return err
+}
+
+func (m *AuditDBManager) FetchEntries(filter *Audit.AuditFilter, ctx context.Context) (*Listing.Page[Audit.AuditEntry], error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 100)
+	query := bson.M{}
+	if filter.ActorID != "" {
+		query["actor_id"] = filter.ActorID
+	}
+	if filter.Entity != "" {
+		query["entity"] = filter.Entity
+	}
+	if filter.EntityID != "" {
+		query["entity_id"] = filter.EntityID
+	}
+	from, to := dayRange(filter)
+	createdAt := bson.M{}
+	if !from.IsZero() {
+		createdAt["$gte"] = from
+	}
+	if !to.IsZero() {
+		createdAt["$lt"] = to
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+	total, err := m.auditCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.auditCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	entries := []Audit.AuditEntry{}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return &Listing.Page[Audit.AuditEntry]{Items: entries, Total: total}, nil
+}
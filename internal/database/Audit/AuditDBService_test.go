@@ -0,0 +1,10 @@
+package Audit
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+func TestAuditDBManager(t *testing.T) {
+	testAuditManager(t, NewAuditDBManager(testutil.MongoClient(t)))
+}
@@ -0,0 +1,73 @@
+package Audit
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Audit"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AuditMemoryManager is the in-memory IAuditDBService, entries are kept in
+// the order they were appended
+type AuditMemoryManager struct {
+	mu      sync.RWMutex
+	entries []Audit.AuditEntry
+}
+
+func NewAuditMemoryManager() *AuditMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("AuditMemoryManager"))
+	instance := &AuditMemoryManager{}
+	slog.Info(LogHelper.LogServiceStarted("AuditMemoryManager"))
+	return instance
+}
+
+func (m *AuditMemoryManager) Append(entry *Audit.AuditEntry, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = primitive.NewObjectID().Hex()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *AuditMemoryManager) FetchEntries(filter *Audit.AuditFilter, ctx context.Context) (*Listing.Page[Audit.AuditEntry], error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 100)
+	from, to := dayRange(filter)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := []Audit.AuditEntry{}
+	var total int64
+	skip := (page - 1) * limit
+	// latest first like the mongo query, appends are already in time order
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := &m.entries[i]
+		if filter.ActorID != "" && entry.ActorID != filter.ActorID {
+			continue
+		}
+		if filter.Entity != "" && entry.Entity != filter.Entity {
+			continue
+		}
+		if filter.EntityID != "" && entry.EntityID != filter.EntityID {
+			continue
+		}
+		if (!from.IsZero() && entry.CreatedAt.Before(from)) || (!to.IsZero() && !entry.CreatedAt.Before(to)) {
+			continue
+		}
+		total++
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if int64(len(entries)) < limit {
+			entries = append(entries, *entry)
+		}
+	}
+	return &Listing.Page[Audit.AuditEntry]{Items: entries, Total: total}, nil
+}
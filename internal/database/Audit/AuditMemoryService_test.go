@@ -0,0 +1,51 @@
+package Audit
+
+import (
+	"HostelApp/internal/storageData/Audit"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAuditMemoryManager(t *testing.T) {
+	testAuditManager(t, NewAuditMemoryManager())
+}
+
+// testAuditManager check an empty IAuditDBService return entries latest first
+// and filter them by actor, entity and day
+func testAuditManager(t *testing.T, m IAuditDBService) {
+	ctx := context.Background()
+	day := func(date int, hour int) time.Time {
+		return time.Date(2026, 3, date, hour, 0, 0, 0, time.UTC)
+	}
+	entries := []Audit.AuditEntry{
+		{ActorID: "u1", Action: "POST /admin/college", Entity: "college", EntityID: "college-a", Status: 200, CreatedAt: day(1, 10)},
+		{ActorID: "u2", Action: "PATCH /admin/college/:unique_name", Entity: "college", EntityID: "college-a", Status: 200, CreatedAt: day(2, 23),
+			Changes: []Audit.FieldChange{{Field: "pin_code", Before: "560001", After: "110001"}}},
+		{ActorID: "u1", Action: "POST /admin/User", Entity: "user", EntityID: "writer", Status: 201, CreatedAt: day(3, 0)},
+	}
+	for i := range entries {
+		if err := m.Append(&entries[i], ctx); err != nil || entries[i].ID == "" {
+			t.Fatalf("Append failed %+v. Err: %v", entries[i], err)
+		}
+	}
+
+	page, err := m.FetchEntries(&Audit.AuditFilter{Page: 1, Limit: 2}, ctx)
+	if err != nil || page.Total != 3 || len(page.Items) != 2 || page.Items[0].EntityID != "writer" {
+		t.Fatalf("expected the first 2 of 3 entries latest first; got %+v %v", page, err)
+	}
+	if page, err = m.FetchEntries(&Audit.AuditFilter{Page: 2, Limit: 2}, ctx); err != nil || len(page.Items) != 1 || page.Items[0].Action != "POST /admin/college" {
+		t.Fatalf("expected the oldest entry on the second page; got %+v %v", page, err)
+	}
+	page, err = m.FetchEntries(&Audit.AuditFilter{Page: 1, Limit: 10, Entity: "college", ActorID: "u2"}, ctx)
+	if err != nil || page.Total != 1 || len(page.Items[0].Changes) != 1 || page.Items[0].Changes[0].After != "110001" {
+		t.Fatalf("expected the update of u2 with its change; got %+v %v", page, err)
+	}
+	// to is included up to the end of its day
+	if page, err = m.FetchEntries(&Audit.AuditFilter{Page: 1, Limit: 10, From: "2026-03-02", To: "2026-03-02"}, ctx); err != nil || page.Total != 1 {
+		t.Fatalf("expected only the entry of 2026-03-02; got %+v %v", page, err)
+	}
+	if page, err = m.FetchEntries(&Audit.AuditFilter{Page: 1, Limit: 10, From: "2026-03-04"}, ctx); err != nil || page.Total != 0 || page.Items == nil {
+		t.Fatalf("expected an empty list from 2026-03-04; got %+v %v", page, err)
+	}
+}
@@ -0,0 +1,31 @@
+package Audit
+
+import (
+	"HostelApp/internal/storageData/Audit"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"time"
+)
+
+// IAuditDBService is the append-only storage of the audit log, there is
+// deliberately no way to update or delete an entry
+type IAuditDBService interface {
+	Append(entry *Audit.AuditEntry, ctx context.Context) error
+	// FetchEntries return a page of entries, latest first
+	FetchEntries(filter *Audit.AuditFilter, ctx context.Context) (*Listing.Page[Audit.AuditEntry], error)
+}
+
+// dayRange turn the from and to days of a filter into [from, to) instants in
+// UTC, a zero time is an open bound
+func dayRange(filter *Audit.AuditFilter) (time.Time, time.Time) {
+	var from, to time.Time
+	if filter.From != "" {
+		from, _ = time.Parse(Audit.DateLayout, filter.From)
+	}
+	if filter.To != "" {
+		if day, err := time.Parse(Audit.DateLayout, filter.To); err == nil {
+			to = day.AddDate(0, 0, 1)
+		}
+	}
+	return from, to
+}
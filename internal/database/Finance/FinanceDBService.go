@@ -0,0 +1,329 @@
+package Finance
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type FinanceDBManager struct {
+	client                 *mongo.Client
+	feeStructureCollection *mongo.Collection
+	invoiceCollection      *mongo.Collection
+	ledgerCollection       *mongo.Collection
+}
+
+func NewFinanceDBManager(client *mongo.Client) *FinanceDBManager {
+	slog.Info(LogHelper.LogServiceStarting("FinanceDBManager"))
+	instance := &FinanceDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("FinanceDBManager"))
+	return instance
+}
+
+func (m *FinanceDBManager) init() {
+	m.feeStructureCollection = m.client.Database("hosteldb").Collection("fee_structures")
+	m.invoiceCollection = m.client.Database("hosteldb").Collection("invoices")
+	m.ledgerCollection = m.client.Database("hosteldb").Collection("ledger")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *FinanceDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	feeStructureIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "effective_from", Value: -1}},
+		},
+	}
+	if _, err := m.feeStructureCollection.Indexes().CreateMany(ctx, feeStructureIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for feeStructureDB error: %v", err)))
+	}
+
+	invoiceIndexes := []mongo.IndexModel{
+		{
+			// one issued invoice per student, hostel and period, a void one can be issued again
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "hostel_id", Value: 1}, {Key: "period", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": Finance.Issued}),
+		},
+		{
+			Keys: bson.D{{Key: "collage_unique_name", Value: 1}, {Key: "period", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "allocation_id", Value: 1}},
+		},
+	}
+	if _, err := m.invoiceCollection.Indexes().CreateMany(ctx, invoiceIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for invoiceDB error: %v", err)))
+	}
+
+	ledgerIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "reference", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"reference": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "invoice_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "postings.account", Value: 1}},
+		},
+	}
+	if _, err := m.ledgerCollection.Indexes().CreateMany(ctx, ledgerIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for ledgerDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *FinanceDBManager) AddFeeStructure(structure *Finance.FeeStructure, ctx context.Context) (*Finance.FeeStructure, error) {
+	structure.ID = primitive.NewObjectID().Hex()
+	structure.CreatedAt = time.Now()
+	if _, err := m.feeStructureCollection.InsertOne(ctx, structure); err != nil {
+		return nil, fmt.Errorf("failed to insert fee structure: %v", err)
+	}
+	return structure, nil
+}
+
+func (m *FinanceDBManager) FetchFeeStructures(hostelID string, ctx context.Context) ([]Finance.FeeStructure, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "effective_from", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := m.feeStructureCollection.Find(ctx, bson.M{"hostel_id": hostelID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var structures []Finance.FeeStructure
+	if err = cursor.All(ctx, &structures); err != nil {
+		return nil, err
+	}
+	return structures, nil
+}
+
+func (m *FinanceDBManager) CreateInvoice(invoice *Finance.Invoice, ctx context.Context) (*Finance.Invoice, error) {
+	invoice.ID = primitive.NewObjectID().Hex()
+	invoice.Status = Finance.Issued
+	invoice.IssuedAt = time.Now()
+	if _, err := m.invoiceCollection.InsertOne(ctx, invoice); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrInvoiceDuplicate
+		}
+		return nil, fmt.Errorf("failed to insert invoice: %v", err)
+	}
+	return invoice, nil
+}
+
+func (m *FinanceDBManager) FetchInvoiceByID(_id string, ctx context.Context) (*Finance.Invoice, error) {
+	var invoice Finance.Invoice
+	err := m.invoiceCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&invoice)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (m *FinanceDBManager) FetchInvoices(filter *Finance.InvoiceFilter, ctx context.Context) ([]Finance.Invoice, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.HostelID != "" {
+		query["hostel_id"] = filter.HostelID
+	}
+	if filter.AllocationID != "" {
+		query["allocation_id"] = filter.AllocationID
+	}
+	if filter.CollageUniqueName != "" {
+		query["collage_unique_name"] = filter.CollageUniqueName
+	}
+	if filter.Period != "" {
+		query["period"] = filter.Period
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "period", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.invoiceCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var invoices []Finance.Invoice
+	if err = cursor.All(ctx, &invoices); err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (m *FinanceDBManager) VoidInvoice(_id string, ctx context.Context) (*Finance.Invoice, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var invoice Finance.Invoice
+	err := m.invoiceCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": Finance.Issued}, bson.M{"$set": bson.M{"status": Finance.Void}}, opts).Decode(&invoice)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchInvoiceByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrInvoiceNotIssued
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (m *FinanceDBManager) PostTransaction(transaction *Finance.LedgerTransaction, ctx context.Context) (*Finance.LedgerTransaction, error) {
+	if err := checkBalanced(transaction); err != nil {
+		return nil, err
+	}
+	transaction.ID = primitive.NewObjectID().Hex()
+	transaction.CreatedAt = time.Now()
+	if _, err := m.ledgerCollection.InsertOne(ctx, transaction); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrDuplicateReference
+		}
+		return nil, fmt.Errorf("failed to insert ledger transaction: %v", err)
+	}
+	return transaction, nil
+}
+
+func (m *FinanceDBManager) FetchTransactions(filter *Finance.LedgerFilter, ctx context.Context) ([]Finance.LedgerTransaction, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.InvoiceID != "" {
+		query["invoice_id"] = filter.InvoiceID
+	}
+	if filter.Kind != "" {
+		query["kind"] = filter.Kind
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.ledgerCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var transactions []Finance.LedgerTransaction
+	if err = cursor.All(ctx, &transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// sumInt64 run an aggregation ending with a single {total} document
+func (m *FinanceDBManager) sumInt64(pipeline mongo.Pipeline, ctx context.Context) (int64, error) {
+	cursor, err := m.ledgerCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	var results []struct {
+		Total int64 `bson:"total"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].Total, nil
+}
+
+func (m *FinanceDBManager) AccountBalance(account string, ctx context.Context) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"postings.account": account}}},
+		{{Key: "$unwind", Value: "$postings"}},
+		{{Key: "$match", Value: bson.M{"postings.account": account}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"total": bson.M{"$sum": bson.M{"$subtract": bson.A{"$postings.debit", "$postings.credit"}}},
+		}}},
+	}
+	return m.sumInt64(pipeline, ctx)
+}
+
+func (m *FinanceDBManager) InvoicePaid(invoiceID string, ctx context.Context) (int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"invoice_id": invoiceID, "kind": bson.M{"$in": bson.A{Finance.Payment, Finance.Refund}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"total": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$kind", Finance.Payment}}, "$amount", bson.M{"$multiply": bson.A{"$amount", -1}},
+			}}},
+		}}},
+	}
+	return m.sumInt64(pipeline, ctx)
+}
+
+func (m *FinanceDBManager) OutstandingBalances(filter *Finance.OutstandingFilter, ctx context.Context) ([]Finance.StudentBalance, error) {
+	match := bson.M{}
+	if filter.CollageUniqueName != "" {
+		match["collage_unique_name"] = filter.CollageUniqueName
+	}
+	receivable := bson.M{"postings.account": bson.M{"$regex": "^" + Finance.ReceivableAccount}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$postings"}},
+		{{Key: "$match", Value: receivable}},
+		{{Key: "$group", Value: bson.M{
+			"_id":                 "$student_id",
+			"collage_unique_name": bson.M{"$first": "$collage_unique_name"},
+			"balance":             bson.M{"$sum": bson.M{"$subtract": bson.A{"$postings.debit", "$postings.credit"}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"balance": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "balance", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := m.ledgerCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var results []struct {
+		StudentID         string `bson:"_id"`
+		CollageUniqueName string `bson:"collage_unique_name"`
+		Balance           int64  `bson:"balance"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	balances := make([]Finance.StudentBalance, 0, len(results))
+	for _, result := range results {
+		balances = append(balances, Finance.StudentBalance{
+			StudentID:         result.StudentID,
+			CollageUniqueName: result.CollageUniqueName,
+			Balance:           result.Balance,
+		})
+	}
+	return balances, nil
+}
@@ -0,0 +1,11 @@
+package Finance
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestFinanceDBManager run the checks on the issued invoice and reference partial unique indexes
+func TestFinanceDBManager(t *testing.T) {
+	testFinanceManager(t, NewFinanceDBManager(testutil.MongoClient(t)))
+}
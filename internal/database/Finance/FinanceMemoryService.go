@@ -0,0 +1,265 @@
+package Finance
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FinanceMemoryManager is the in-memory IFinanceDBService
+type FinanceMemoryManager struct {
+	mu           sync.RWMutex
+	structures   map[string]*Finance.FeeStructure // key is _id
+	invoices     map[string]*Finance.Invoice      // key is _id
+	transactions []Finance.LedgerTransaction      // append only, in posting order
+}
+
+func NewFinanceMemoryManager() *FinanceMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("FinanceMemoryManager"))
+	instance := &FinanceMemoryManager{
+		structures: make(map[string]*Finance.FeeStructure),
+		invoices:   make(map[string]*Finance.Invoice),
+	}
+	slog.Info(LogHelper.LogServiceStarted("FinanceMemoryManager"))
+	return instance
+}
+
+func (m *FinanceMemoryManager) AddFeeStructure(structure *Finance.FeeStructure, ctx context.Context) (*Finance.FeeStructure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	structure.ID = primitive.NewObjectID().Hex()
+	structure.CreatedAt = time.Now()
+	stored := *structure
+	m.structures[stored.ID] = &stored
+	return structure, nil
+}
+
+func (m *FinanceMemoryManager) FetchFeeStructures(hostelID string, ctx context.Context) ([]Finance.FeeStructure, error) {
+	m.mu.RLock()
+	var structures []Finance.FeeStructure
+	for _, stored := range m.structures {
+		if stored.HostelID == hostelID {
+			structures = append(structures, *stored)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(structures, func(i, j int) bool {
+		if !structures[i].EffectiveFrom.Equal(structures[j].EffectiveFrom) {
+			return structures[i].EffectiveFrom.After(structures[j].EffectiveFrom)
+		}
+		return structures[i].ID > structures[j].ID
+	})
+	return structures, nil
+}
+
+func (m *FinanceMemoryManager) CreateInvoice(invoice *Finance.Invoice, ctx context.Context) (*Finance.Invoice, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// emulate the issued_period partial unique index
+	for _, stored := range m.invoices {
+		if stored.Status == Finance.Issued && stored.StudentID == invoice.StudentID && stored.HostelID == invoice.HostelID && stored.Period == invoice.Period {
+			return nil, ErrInvoiceDuplicate
+		}
+	}
+	invoice.ID = primitive.NewObjectID().Hex()
+	invoice.Status = Finance.Issued
+	invoice.IssuedAt = time.Now()
+	stored := *invoice
+	m.invoices[stored.ID] = &stored
+	return invoice, nil
+}
+
+func (m *FinanceMemoryManager) FetchInvoiceByID(_id string, ctx context.Context) (*Finance.Invoice, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.invoices[_id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	invoice := *stored
+	return &invoice, nil
+}
+
+func (m *FinanceMemoryManager) FetchInvoices(filter *Finance.InvoiceFilter, ctx context.Context) ([]Finance.Invoice, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Finance.Invoice
+	for _, stored := range m.invoices {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.HostelID != "" && stored.HostelID != filter.HostelID {
+			continue
+		}
+		if filter.AllocationID != "" && stored.AllocationID != filter.AllocationID {
+			continue
+		}
+		if filter.CollageUniqueName != "" && stored.CollageUniqueName != filter.CollageUniqueName {
+			continue
+		}
+		if filter.Period != "" && stored.Period != filter.Period {
+			continue
+		}
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Period != matched[j].Period {
+			return matched[i].Period > matched[j].Period
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *FinanceMemoryManager) VoidInvoice(_id string, ctx context.Context) (*Finance.Invoice, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.invoices[_id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	if stored.Status != Finance.Issued {
+		return nil, ErrInvoiceNotIssued
+	}
+	stored.Status = Finance.Void
+	invoice := *stored
+	return &invoice, nil
+}
+
+func (m *FinanceMemoryManager) PostTransaction(transaction *Finance.LedgerTransaction, ctx context.Context) (*Finance.LedgerTransaction, error) {
+	if err := checkBalanced(transaction); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if transaction.Reference != "" {
+		for _, stored := range m.transactions {
+			if stored.Reference == transaction.Reference {
+				return nil, ErrDuplicateReference
+			}
+		}
+	}
+	transaction.ID = primitive.NewObjectID().Hex()
+	transaction.CreatedAt = time.Now()
+	stored := *transaction
+	stored.Postings = append([]Finance.Posting(nil), transaction.Postings...)
+	m.transactions = append(m.transactions, stored)
+	return transaction, nil
+}
+
+func (m *FinanceMemoryManager) FetchTransactions(filter *Finance.LedgerFilter, ctx context.Context) ([]Finance.LedgerTransaction, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Finance.LedgerTransaction
+	// newest first
+	for i := len(m.transactions) - 1; i >= 0; i-- {
+		stored := m.transactions[i]
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.InvoiceID != "" && stored.InvoiceID != filter.InvoiceID {
+			continue
+		}
+		if filter.Kind != "" && stored.Kind != filter.Kind {
+			continue
+		}
+		matched = append(matched, stored)
+	}
+	m.mu.RUnlock()
+
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *FinanceMemoryManager) AccountBalance(account string, ctx context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var balance int64
+	for _, transaction := range m.transactions {
+		for _, posting := range transaction.Postings {
+			if posting.Account == account {
+				balance += posting.Debit - posting.Credit
+			}
+		}
+	}
+	return balance, nil
+}
+
+func (m *FinanceMemoryManager) InvoicePaid(invoiceID string, ctx context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var paid int64
+	for i := range m.transactions {
+		if m.transactions[i].InvoiceID == invoiceID {
+			paid += paidDelta(&m.transactions[i])
+		}
+	}
+	return paid, nil
+}
+
+func (m *FinanceMemoryManager) OutstandingBalances(filter *Finance.OutstandingFilter, ctx context.Context) ([]Finance.StudentBalance, error) {
+	m.mu.RLock()
+	balances := map[string]*Finance.StudentBalance{}
+	for _, transaction := range m.transactions {
+		if filter.CollageUniqueName != "" && transaction.CollageUniqueName != filter.CollageUniqueName {
+			continue
+		}
+		for _, posting := range transaction.Postings {
+			if !strings.HasPrefix(posting.Account, Finance.ReceivableAccount) {
+				continue
+			}
+			studentID := strings.TrimPrefix(posting.Account, Finance.ReceivableAccount)
+			balance, ok := balances[studentID]
+			if !ok {
+				balance = &Finance.StudentBalance{StudentID: studentID, CollageUniqueName: transaction.CollageUniqueName}
+				balances[studentID] = balance
+			}
+			balance.Balance += posting.Debit - posting.Credit
+		}
+	}
+	m.mu.RUnlock()
+
+	var outstanding []Finance.StudentBalance
+	for _, balance := range balances {
+		if balance.Balance > 0 {
+			outstanding = append(outstanding, *balance)
+		}
+	}
+	sort.Slice(outstanding, func(i, j int) bool {
+		if outstanding[i].Balance != outstanding[j].Balance {
+			return outstanding[i].Balance > outstanding[j].Balance
+		}
+		return outstanding[i].StudentID < outstanding[j].StudentID
+	})
+	return outstanding, nil
+}
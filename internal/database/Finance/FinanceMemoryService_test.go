@@ -0,0 +1,97 @@
+package Finance
+
+import (
+	"HostelApp/internal/storageData/Finance"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFinanceMemoryManager(t *testing.T) {
+	testFinanceManager(t, NewFinanceMemoryManager())
+}
+
+// testFinanceManager check an empty IFinanceDBService keep one issued invoice
+// per period and only store balanced ledger transactions
+func testFinanceManager(t *testing.T, m IFinanceDBService) {
+	ctx := context.Background()
+	january := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lines := []Finance.FeeLine{{Kind: Finance.Rent, Description: "Rent", Amount: 500000}}
+	for _, from := range []time.Time{january, january.AddDate(0, 6, 0)} {
+		if _, err := m.AddFeeStructure(&Finance.FeeStructure{HostelID: "h1", Currency: Finance.DefaultCurrency, Lines: lines, EffectiveFrom: from}, ctx); err != nil {
+			t.Fatalf("AddFeeStructure failed. Err: %v", err)
+		}
+	}
+	structures, err := m.FetchFeeStructures("h1", ctx)
+	if err != nil || len(structures) != 2 || !structures[0].EffectiveFrom.After(structures[1].EffectiveFrom) {
+		t.Fatalf("expected 2 fee structures newest first; got %+v %v", structures, err)
+	}
+
+	invoice := func(studentID string, period string) *Finance.Invoice {
+		return &Finance.Invoice{StudentID: studentID, CollageUniqueName: "college-a", HostelID: "h1", Period: period, Currency: Finance.DefaultCurrency, Lines: lines, Total: 500000}
+	}
+	first, err := m.CreateInvoice(invoice("s1", "2025-01"), ctx)
+	if err != nil || first.Status != Finance.Issued {
+		t.Fatalf("CreateInvoice failed %+v. Err: %v", first, err)
+	}
+	if _, err = m.CreateInvoice(invoice("s1", "2025-01"), ctx); !errors.Is(err, ErrInvoiceDuplicate) {
+		t.Fatalf("expected ErrInvoiceDuplicate for a second invoice of the period; got %v", err)
+	}
+	if voided, err := m.VoidInvoice(first.ID, ctx); err != nil || voided.Status != Finance.Void {
+		t.Fatalf("VoidInvoice failed %+v. Err: %v", voided, err)
+	}
+	if _, err = m.VoidInvoice(first.ID, ctx); !errors.Is(err, ErrInvoiceNotIssued) {
+		t.Fatalf("expected ErrInvoiceNotIssued voiding twice; got %v", err)
+	}
+	second, err := m.CreateInvoice(invoice("s1", "2025-01"), ctx)
+	if err != nil {
+		t.Fatalf("expected a new invoice once the first is void. Err: %v", err)
+	}
+	if _, err = m.CreateInvoice(invoice("s2", "2025-02"), ctx); err != nil {
+		t.Fatalf("CreateInvoice failed. Err: %v", err)
+	}
+	if _, err = m.FetchInvoiceByID("missing", ctx); !errors.Is(err, ErrInvoiceNotFound) {
+		t.Fatalf("expected ErrInvoiceNotFound; got %v", err)
+	}
+	issued, err := m.FetchInvoices(&Finance.InvoiceFilter{Page: 1, Limit: 10, Status: Finance.Issued}, ctx)
+	if err != nil || len(issued) != 2 || issued[0].Period != "2025-02" {
+		t.Fatalf("expected 2 issued invoices newest period first; got %+v %v", issued, err)
+	}
+
+	charge := func(studentID string, invoiceID string, amount int64) *Finance.LedgerTransaction {
+		return &Finance.LedgerTransaction{Kind: Finance.InvoiceCharge, StudentID: studentID, CollageUniqueName: "college-a", InvoiceID: invoiceID, Currency: Finance.DefaultCurrency, Amount: amount,
+			Postings: []Finance.Posting{{Account: Finance.StudentAccount(studentID), Debit: amount}, {Account: Finance.RevenueAccount + "rent", Credit: amount}}}
+	}
+	payment := func(studentID string, invoiceID string, amount int64, reference string) *Finance.LedgerTransaction {
+		return &Finance.LedgerTransaction{Kind: Finance.Payment, StudentID: studentID, CollageUniqueName: "college-a", InvoiceID: invoiceID, Currency: Finance.DefaultCurrency, Amount: amount, Reference: reference,
+			Postings: []Finance.Posting{{Account: Finance.CashAccount, Debit: amount}, {Account: Finance.StudentAccount(studentID), Credit: amount}}}
+	}
+	unbalanced := charge("s1", second.ID, 500000)
+	unbalanced.Postings[1].Credit = 1
+	if _, err = m.PostTransaction(unbalanced, ctx); !errors.Is(err, ErrUnbalancedTransaction) {
+		t.Fatalf("expected ErrUnbalancedTransaction; got %v", err)
+	}
+	for _, transaction := range []*Finance.LedgerTransaction{charge("s1", second.ID, 500000), charge("s2", "", 400000), payment("s1", second.ID, 200000, "receipt-1")} {
+		if _, err = m.PostTransaction(transaction, ctx); err != nil {
+			t.Fatalf("PostTransaction failed. Err: %v", err)
+		}
+	}
+	if _, err = m.PostTransaction(payment("s1", second.ID, 100, "receipt-1"), ctx); !errors.Is(err, ErrDuplicateReference) {
+		t.Fatalf("expected ErrDuplicateReference; got %v", err)
+	}
+
+	if cash, err := m.AccountBalance(Finance.CashAccount, ctx); err != nil || cash != 200000 {
+		t.Fatalf("expected a cash balance of 200000; got %d %v", cash, err)
+	}
+	if paid, err := m.InvoicePaid(second.ID, ctx); err != nil || paid != 200000 {
+		t.Fatalf("expected 200000 paid on the invoice; got %d %v", paid, err)
+	}
+	if history, err := m.FetchTransactions(&Finance.LedgerFilter{Page: 1, Limit: 10, StudentID: "s1"}, ctx); err != nil || len(history) != 2 || history[0].Kind != Finance.Payment {
+		t.Fatalf("expected 2 transactions of s1 newest first; got %+v %v", history, err)
+	}
+	outstanding, err := m.OutstandingBalances(&Finance.OutstandingFilter{CollageUniqueName: "college-a"}, ctx)
+	if err != nil || len(outstanding) != 2 || outstanding[0].StudentID != "s2" || outstanding[0].Balance != 400000 || outstanding[1].Balance != 300000 {
+		t.Fatalf("expected s2 owing 400000 then s1 owing 300000; got %+v %v", outstanding, err)
+	}
+}
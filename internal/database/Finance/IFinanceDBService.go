@@ -0,0 +1,61 @@
+package Finance
+
+import (
+	"HostelApp/internal/storageData/Finance"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrFeeStructureNotFound  = errors.New("no fee structure is effective for this hostel")
+	ErrInvoiceNotFound       = errors.New("invoice not found")
+	ErrInvoiceDuplicate      = errors.New("student already has an invoice for this hostel and period")
+	ErrInvoiceNotIssued      = errors.New("invoice is not issued")
+	ErrUnbalancedTransaction = errors.New("ledger transaction postings do not balance")
+	ErrDuplicateReference    = errors.New("a ledger transaction with this reference already exists")
+)
+
+// IFinanceDBService is the storage of fee structures, invoices and the ledger.
+// Ledger transactions are append only, a mistake is corrected by a new transaction
+type IFinanceDBService interface {
+	AddFeeStructure(structure *Finance.FeeStructure, ctx context.Context) (*Finance.FeeStructure, error)
+	FetchFeeStructures(hostelID string, ctx context.Context) ([]Finance.FeeStructure, error) // newest effective first
+	CreateInvoice(invoice *Finance.Invoice, ctx context.Context) (*Finance.Invoice, error)
+	FetchInvoiceByID(_id string, ctx context.Context) (*Finance.Invoice, error)
+	FetchInvoices(filter *Finance.InvoiceFilter, ctx context.Context) ([]Finance.Invoice, error)
+	VoidInvoice(_id string, ctx context.Context) (*Finance.Invoice, error)
+	PostTransaction(transaction *Finance.LedgerTransaction, ctx context.Context) (*Finance.LedgerTransaction, error)
+	FetchTransactions(filter *Finance.LedgerFilter, ctx context.Context) ([]Finance.LedgerTransaction, error)
+	AccountBalance(account string, ctx context.Context) (int64, error)
+	InvoicePaid(invoiceID string, ctx context.Context) (int64, error)
+	OutstandingBalances(filter *Finance.OutstandingFilter, ctx context.Context) ([]Finance.StudentBalance, error)
+}
+
+// checkBalanced reject a transaction whose debits and credits differ or that move nothing
+func checkBalanced(transaction *Finance.LedgerTransaction) error {
+	var debit, credit int64
+	for _, posting := range transaction.Postings {
+		if posting.Debit < 0 || posting.Credit < 0 {
+			return fmt.Errorf("%w: negative posting on %s", ErrUnbalancedTransaction, posting.Account)
+		}
+		debit += posting.Debit
+		credit += posting.Credit
+	}
+	if debit != credit || debit == 0 {
+		return fmt.Errorf("%w: debit %d credit %d", ErrUnbalancedTransaction, debit, credit)
+	}
+	return nil
+}
+
+// paidDelta is what a transaction add to the paid amount of its invoice
+func paidDelta(transaction *Finance.LedgerTransaction) int64 {
+	switch transaction.Kind {
+	case Finance.Payment:
+		return transaction.Amount
+	case Finance.Refund:
+		return -transaction.Amount
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,254 @@
+package Gate
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Gate"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type GateDBManager struct {
+	client             *mongo.Client
+	visitorCollection  *mongo.Collection
+	gatePassCollection *mongo.Collection
+}
+
+func NewGateDBManager(client *mongo.Client) *GateDBManager {
+	slog.Info(LogHelper.LogServiceStarting("GateDBManager"))
+	instance := &GateDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("GateDBManager"))
+	return instance
+}
+
+func (m *GateDBManager) init() {
+	m.visitorCollection = m.client.Database("hosteldb").Collection("visitors")
+	m.gatePassCollection = m.client.Database("hosteldb").Collection("gate_passes")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *GateDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	visitorIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "check_in_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"check_out_at": bson.M{"$exists": false}}),
+		},
+	}
+	if _, err := m.visitorCollection.Indexes().CreateMany(ctx, visitorIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for gateDB error: %v", err)))
+	}
+	gatePassIndexes := []mongo.IndexModel{
+		{
+			// one pending, approved or out pass per student
+			Keys:    bson.D{{Key: "student_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true}),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "return_by", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "collage_unique_name", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	}
+	if _, err := m.gatePassCollection.Indexes().CreateMany(ctx, gatePassIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for gateDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *GateDBManager) AddVisitor(visitor *Gate.VisitorData, ctx context.Context) (*Gate.VisitorData, error) {
+	visitor.ID = primitive.NewObjectID().Hex()
+	visitor.CheckOutAt = nil
+	if _, err := m.visitorCollection.InsertOne(ctx, visitor); err != nil {
+		return nil, fmt.Errorf("failed to insert visitor: %v", err)
+	}
+	return visitor, nil
+}
+
+func (m *GateDBManager) FetchVisitorByID(_id string, ctx context.Context) (*Gate.VisitorData, error) {
+	var visitor Gate.VisitorData
+	err := m.visitorCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&visitor)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrVisitorNotFound
+		}
+		return nil, err
+	}
+	return &visitor, nil
+}
+
+func (m *GateDBManager) FetchVisitors(filter *Gate.VisitorFilter, ctx context.Context) ([]Gate.VisitorData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.Inside {
+		query["check_out_at"] = bson.M{"$exists": false}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.visitorCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var visitors []Gate.VisitorData
+	if err = cursor.All(ctx, &visitors); err != nil {
+		return nil, err
+	}
+	return visitors, nil
+}
+
+func (m *GateDBManager) CheckOutVisitor(_id string, at time.Time, ctx context.Context) (*Gate.VisitorData, error) {
+	query := bson.M{"_id": _id, "check_out_at": bson.M{"$exists": false}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var visitor Gate.VisitorData
+	err := m.visitorCollection.FindOneAndUpdate(ctx, query, bson.M{"$set": bson.M{"check_out_at": at}}, opts).Decode(&visitor)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchVisitorByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrVisitorCheckedOut
+		}
+		return nil, err
+	}
+	return &visitor, nil
+}
+
+func (m *GateDBManager) CreateGatePass(pass *Gate.GatePassData, ctx context.Context) (*Gate.GatePassData, error) {
+	now := time.Now()
+	pass.ID = primitive.NewObjectID().Hex()
+	pass.Status = Gate.Pending
+	pass.Active = true
+	pass.CreatedAt = now
+	pass.UpdatedAt = now
+	if _, err := m.gatePassCollection.InsertOne(ctx, pass); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrActiveGatePass
+		}
+		return nil, fmt.Errorf("failed to insert gate pass: %v", err)
+	}
+	return pass, nil
+}
+
+func (m *GateDBManager) FetchGatePassByID(_id string, ctx context.Context) (*Gate.GatePassData, error) {
+	var pass Gate.GatePassData
+	err := m.gatePassCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&pass)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGatePassNotFound
+		}
+		return nil, err
+	}
+	return &pass, nil
+}
+
+func (m *GateDBManager) FetchGatePasses(filter *Gate.GatePassFilter, ctx context.Context) ([]Gate.GatePassData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.CollageUniqueName != "" {
+		query["collage_unique_name"] = filter.CollageUniqueName
+	}
+	if filter.Kind != "" {
+		query["kind"] = filter.Kind
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.gatePassCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var passes []Gate.GatePassData
+	if err = cursor.All(ctx, &passes); err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
+
+func (m *GateDBManager) TransitionGatePass(_id string, from Gate.PassStatus, change *Gate.GatePassChange, ctx context.Context) (*Gate.GatePassData, error) {
+	set := bson.M{"status": change.To, "active": IsActive(change.To), "updated_at": time.Now()}
+	if change.DecidedBy != "" {
+		set["decided_by"] = change.DecidedBy
+		set["decision_note"] = change.DecisionNote
+	}
+	if change.DecidedAt != nil {
+		set["decided_at"] = *change.DecidedAt
+	}
+	if change.CheckedOutAt != nil {
+		set["checked_out_at"] = *change.CheckedOutAt
+	}
+	if change.ReturnBy != nil {
+		set["return_by"] = *change.ReturnBy
+	}
+	if change.ReturnedAt != nil {
+		set["returned_at"] = *change.ReturnedAt
+		set["returned_late"] = change.ReturnedLate
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var pass Gate.GatePassData
+	err := m.gatePassCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": from}, bson.M{"$set": set}, opts).Decode(&pass)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchGatePassByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrGatePassStatusChanged
+		}
+		return nil, err
+	}
+	return &pass, nil
+}
+
+func (m *GateDBManager) FetchPastCurfew(now time.Time, collageUniqueName string, ctx context.Context) ([]Gate.GatePassData, error) {
+	query := bson.M{"status": Gate.Out, "return_by": bson.M{"$lt": now}}
+	if collageUniqueName != "" {
+		query["collage_unique_name"] = collageUniqueName
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "return_by", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := m.gatePassCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var passes []Gate.GatePassData
+	if err = cursor.All(ctx, &passes); err != nil {
+		return nil, err
+	}
+	return passes, nil
+}
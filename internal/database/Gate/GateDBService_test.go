@@ -0,0 +1,11 @@
+package Gate
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestGateDBManager run the checks on the active pass partial unique index
+func TestGateDBManager(t *testing.T) {
+	testGateManager(t, NewGateDBManager(testutil.MongoClient(t)))
+}
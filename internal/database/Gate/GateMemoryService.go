@@ -0,0 +1,211 @@
+package Gate
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Gate"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// GateMemoryManager is the in-memory IGateDBService
+type GateMemoryManager struct {
+	mu       sync.RWMutex
+	visitors map[string]*Gate.VisitorData  // key is _id
+	passes   map[string]*Gate.GatePassData // key is _id
+}
+
+func NewGateMemoryManager() *GateMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("GateMemoryManager"))
+	instance := &GateMemoryManager{
+		visitors: make(map[string]*Gate.VisitorData),
+		passes:   make(map[string]*Gate.GatePassData),
+	}
+	slog.Info(LogHelper.LogServiceStarted("GateMemoryManager"))
+	return instance
+}
+
+func (m *GateMemoryManager) AddVisitor(visitor *Gate.VisitorData, ctx context.Context) (*Gate.VisitorData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	visitor.ID = primitive.NewObjectID().Hex()
+	visitor.CheckOutAt = nil
+	stored := *visitor
+	m.visitors[stored.ID] = &stored
+	return visitor, nil
+}
+
+func (m *GateMemoryManager) FetchVisitorByID(_id string, ctx context.Context) (*Gate.VisitorData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.visitors[_id]
+	if !ok {
+		return nil, ErrVisitorNotFound
+	}
+	visitor := *stored
+	return &visitor, nil
+}
+
+func (m *GateMemoryManager) FetchVisitors(filter *Gate.VisitorFilter, ctx context.Context) ([]Gate.VisitorData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Gate.VisitorData
+	for _, stored := range m.visitors {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.Inside && stored.CheckOutAt != nil {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return pageSlice(matched, page, limit), nil
+}
+
+func (m *GateMemoryManager) CheckOutVisitor(_id string, at time.Time, ctx context.Context) (*Gate.VisitorData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.visitors[_id]
+	if !ok {
+		return nil, ErrVisitorNotFound
+	}
+	if stored.CheckOutAt != nil {
+		return nil, ErrVisitorCheckedOut
+	}
+	stored.CheckOutAt = &at
+	visitor := *stored
+	return &visitor, nil
+}
+
+func (m *GateMemoryManager) CreateGatePass(pass *Gate.GatePassData, ctx context.Context) (*Gate.GatePassData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, stored := range m.passes {
+		if stored.StudentID == pass.StudentID && stored.Active {
+			return nil, ErrActiveGatePass
+		}
+	}
+	now := time.Now()
+	pass.ID = primitive.NewObjectID().Hex()
+	pass.Status = Gate.Pending
+	pass.Active = true
+	pass.CreatedAt = now
+	pass.UpdatedAt = now
+	stored := *pass
+	m.passes[stored.ID] = &stored
+	return pass, nil
+}
+
+func (m *GateMemoryManager) FetchGatePassByID(_id string, ctx context.Context) (*Gate.GatePassData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.passes[_id]
+	if !ok {
+		return nil, ErrGatePassNotFound
+	}
+	pass := *stored
+	return &pass, nil
+}
+
+func (m *GateMemoryManager) FetchGatePasses(filter *Gate.GatePassFilter, ctx context.Context) ([]Gate.GatePassData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Gate.GatePassData
+	for _, stored := range m.passes {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.CollageUniqueName != "" && stored.CollageUniqueName != filter.CollageUniqueName {
+			continue
+		}
+		if filter.Kind != "" && stored.Kind != filter.Kind {
+			continue
+		}
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return pageSlice(matched, page, limit), nil
+}
+
+func (m *GateMemoryManager) TransitionGatePass(_id string, from Gate.PassStatus, change *Gate.GatePassChange, ctx context.Context) (*Gate.GatePassData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.passes[_id]
+	if !ok {
+		return nil, ErrGatePassNotFound
+	}
+	if stored.Status != from {
+		return nil, ErrGatePassStatusChanged
+	}
+	stored.Status = change.To
+	stored.Active = IsActive(change.To)
+	if change.DecidedBy != "" {
+		stored.DecidedBy = change.DecidedBy
+		stored.DecisionNote = change.DecisionNote
+	}
+	if change.DecidedAt != nil {
+		stored.DecidedAt = change.DecidedAt
+	}
+	if change.CheckedOutAt != nil {
+		stored.CheckedOutAt = change.CheckedOutAt
+	}
+	if change.ReturnBy != nil {
+		stored.ReturnBy = change.ReturnBy
+	}
+	if change.ReturnedAt != nil {
+		stored.ReturnedAt = change.ReturnedAt
+		stored.ReturnedLate = change.ReturnedLate
+	}
+	stored.UpdatedAt = time.Now()
+	pass := *stored
+	return &pass, nil
+}
+
+func (m *GateMemoryManager) FetchPastCurfew(now time.Time, collageUniqueName string, ctx context.Context) ([]Gate.GatePassData, error) {
+	m.mu.RLock()
+	var matched []Gate.GatePassData
+	for _, stored := range m.passes {
+		if stored.Status != Gate.Out || stored.ReturnBy == nil || !stored.ReturnBy.Before(now) {
+			continue
+		}
+		if collageUniqueName != "" && stored.CollageUniqueName != collageUniqueName {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].ReturnBy.Equal(*matched[j].ReturnBy) {
+			return matched[i].ReturnBy.Before(*matched[j].ReturnBy)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched, nil
+}
+
+func pageSlice[T any](items []T, page int64, limit int64) []T {
+	skip := (page - 1) * limit
+	if skip >= int64(len(items)) {
+		return nil
+	}
+	end := skip + limit
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+	return items[skip:end]
+}
@@ -0,0 +1,84 @@
+package Gate
+
+import (
+	"HostelApp/internal/storageData/Gate"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGateMemoryManager(t *testing.T) {
+	testGateManager(t, NewGateMemoryManager())
+}
+
+// testGateManager check an empty IGateDBService check a visitor out once, keep
+// one active gate pass per student and list the students out past their deadline
+func testGateManager(t *testing.T, m IGateDBService) {
+	ctx := context.Background()
+	now := time.Now()
+	visitor, err := m.AddVisitor(&Gate.VisitorData{VisitorName: "Parent", VisitorPhone: "+919876543210", StudentID: "s1", CollageUniqueName: "college-a", CheckInAt: now}, ctx)
+	if err != nil {
+		t.Fatalf("AddVisitor failed. Err: %v", err)
+	}
+	if inside, err := m.FetchVisitors(&Gate.VisitorFilter{Page: 1, Limit: 10, Inside: true}, ctx); err != nil || len(inside) != 1 {
+		t.Fatalf("expected 1 visitor inside; got %+v %v", inside, err)
+	}
+	if out, err := m.CheckOutVisitor(visitor.ID, now, ctx); err != nil || out.CheckOutAt == nil {
+		t.Fatalf("CheckOutVisitor failed %+v. Err: %v", out, err)
+	}
+	if _, err = m.CheckOutVisitor(visitor.ID, now, ctx); !errors.Is(err, ErrVisitorCheckedOut) {
+		t.Fatalf("expected ErrVisitorCheckedOut; got %v", err)
+	}
+	if _, err = m.CheckOutVisitor("missing", now, ctx); !errors.Is(err, ErrVisitorNotFound) {
+		t.Fatalf("expected ErrVisitorNotFound; got %v", err)
+	}
+	if inside, err := m.FetchVisitors(&Gate.VisitorFilter{Page: 1, Limit: 10, Inside: true}, ctx); err != nil || len(inside) != 0 {
+		t.Fatalf("expected nobody inside; got %+v %v", inside, err)
+	}
+
+	pass := func(studentID string) *Gate.GatePassData {
+		return &Gate.GatePassData{StudentID: studentID, CollageUniqueName: "college-a", Kind: Gate.Outing, Reason: "Market", LeaveAt: now, ExpectedReturnAt: now.Add(time.Hour)}
+	}
+	first, err := m.CreateGatePass(pass("s1"), ctx)
+	if err != nil {
+		t.Fatalf("CreateGatePass failed. Err: %v", err)
+	}
+	if _, err = m.CreateGatePass(pass("s1"), ctx); !errors.Is(err, ErrActiveGatePass) {
+		t.Fatalf("expected ErrActiveGatePass for a second active pass; got %v", err)
+	}
+	second, err := m.CreateGatePass(pass("s2"), ctx)
+	if err != nil {
+		t.Fatalf("CreateGatePass failed. Err: %v", err)
+	}
+
+	// s1 went out and is late, s2 went out and still have time
+	goOut := func(_id string, returnBy time.Time) {
+		if _, err := m.TransitionGatePass(_id, Gate.Pending, &Gate.GatePassChange{To: Gate.Approved, DecidedBy: "u1", DecidedAt: &now}, ctx); err != nil {
+			t.Fatalf("approve failed. Err: %v", err)
+		}
+		if _, err := m.TransitionGatePass(_id, Gate.Approved, &Gate.GatePassChange{To: Gate.Out, CheckedOutAt: &now, ReturnBy: &returnBy}, ctx); err != nil {
+			t.Fatalf("check out failed. Err: %v", err)
+		}
+	}
+	goOut(first.ID, now.Add(-time.Minute))
+	goOut(second.ID, now.Add(time.Hour))
+	if _, err = m.TransitionGatePass(first.ID, Gate.Approved, &Gate.GatePassChange{To: Gate.Out}, ctx); !errors.Is(err, ErrGatePassStatusChanged) {
+		t.Fatalf("expected ErrGatePassStatusChanged from a stale status; got %v", err)
+	}
+	late, err := m.FetchPastCurfew(now, "college-a", ctx)
+	if err != nil || len(late) != 1 || late[0].ID != first.ID {
+		t.Fatalf("expected only s1 past curfew; got %+v %v", late, err)
+	}
+
+	returned, err := m.TransitionGatePass(first.ID, Gate.Out, &Gate.GatePassChange{To: Gate.Returned, ReturnedAt: &now, ReturnedLate: true}, ctx)
+	if err != nil || !returned.ReturnedLate {
+		t.Fatalf("return failed %+v. Err: %v", returned, err)
+	}
+	if _, err = m.CreateGatePass(pass("s1"), ctx); err != nil {
+		t.Fatalf("expected a new pass once the first is returned. Err: %v", err)
+	}
+	if late, err = m.FetchPastCurfew(now, "", ctx); err != nil || len(late) != 0 {
+		t.Fatalf("expected nobody past curfew; got %+v %v", late, err)
+	}
+}
@@ -0,0 +1,37 @@
+package Gate
+
+import (
+	"HostelApp/internal/storageData/Gate"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrVisitorNotFound       = errors.New("visitor not found")
+	ErrVisitorCheckedOut     = errors.New("visitor already checked out")
+	ErrGatePassNotFound      = errors.New("gate pass not found")
+	ErrActiveGatePass        = errors.New("student already has a pending, approved or open gate pass")
+	ErrGatePassStatusChanged = errors.New("gate pass is no longer in the expected status")
+)
+
+// IGateDBService is the storage of the visitor log and of student gate passes,
+// a student hold at most one active gate pass
+type IGateDBService interface {
+	AddVisitor(visitor *Gate.VisitorData, ctx context.Context) (*Gate.VisitorData, error)
+	FetchVisitorByID(_id string, ctx context.Context) (*Gate.VisitorData, error)
+	FetchVisitors(filter *Gate.VisitorFilter, ctx context.Context) ([]Gate.VisitorData, error)
+	CheckOutVisitor(_id string, at time.Time, ctx context.Context) (*Gate.VisitorData, error)
+	CreateGatePass(pass *Gate.GatePassData, ctx context.Context) (*Gate.GatePassData, error)
+	FetchGatePassByID(_id string, ctx context.Context) (*Gate.GatePassData, error)
+	FetchGatePasses(filter *Gate.GatePassFilter, ctx context.Context) ([]Gate.GatePassData, error)
+	// TransitionGatePass apply the change only when the pass is still in status from
+	TransitionGatePass(_id string, from Gate.PassStatus, change *Gate.GatePassChange, ctx context.Context) (*Gate.GatePassData, error)
+	// FetchPastCurfew return the passes of students still out after their return deadline, most late first
+	FetchPastCurfew(now time.Time, collageUniqueName string, ctx context.Context) ([]Gate.GatePassData, error)
+}
+
+// IsActive tell if a pass in this status block the student from requesting another one
+func IsActive(status Gate.PassStatus) bool {
+	return status == Gate.Pending || status == Gate.Approved || status == Gate.Out
+}
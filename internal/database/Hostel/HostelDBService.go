@@ -0,0 +1,259 @@
+package Hostel
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type HostelDBManager struct {
+	client           *mongo.Client
+	hostelCollection *mongo.Collection
+	roomCollection   *mongo.Collection
+}
+
+func NewHostelDBManager(client *mongo.Client) *HostelDBManager {
+	slog.Info(LogHelper.LogServiceStarting("HostelDBManager"))
+	instance := &HostelDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("HostelDBManager"))
+	return instance
+}
+
+func (m *HostelDBManager) init() {
+	m.hostelCollection = m.client.Database("hosteldb").Collection("hostels")
+	m.roomCollection = m.client.Database("hosteldb").Collection("rooms")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *HostelDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hostelIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "collage_unique_name", Value: 1}},
+		},
+	}
+	if _, err := m.hostelCollection.Indexes().CreateMany(ctx, hostelIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for hostelDB error: %v", err)))
+	}
+
+	roomIndexes := []mongo.IndexModel{
+		{
+			// room number is unique inside a hostel block
+			Keys:    bson.D{{Key: "hostel_id", Value: 1}, {Key: "block", Value: 1}, {Key: "room_number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := m.roomCollection.Indexes().CreateMany(ctx, roomIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for roomDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *HostelDBManager) AddHostel(hostel *Hostel.HostelData, ctx context.Context) (*Hostel.HostelData, error) {
+	hostel.ID = primitive.NewObjectID().Hex()
+	hostel.CreatedAt = time.Now()
+	hostel.MarkAsDeleted = false
+	if _, err := m.hostelCollection.InsertOne(ctx, hostel); err != nil {
+		return nil, fmt.Errorf("failed to insert hostel '%s': %v", hostel.Name, err)
+	}
+	return hostel, nil
+}
+
+func (m *HostelDBManager) FetchHostelByID(_id string, ctx context.Context) (*Hostel.HostelData, error) {
+	var hostel Hostel.HostelData
+	err := m.hostelCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&hostel)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrHostelNotFound
+		}
+		return nil, err
+	}
+	return &hostel, nil
+}
+
+func (m *HostelDBManager) FetchHostels(filter *Hostel.HostelFilter, ctx context.Context) ([]Hostel.HostelData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 20)
+	query := bson.M{"mark_as_deleted": filter.MarkAsDeleted}
+	if filter.CollageUniqueName != "" {
+		query["collage_unique_name"] = filter.CollageUniqueName
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.hostelCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var hostels []Hostel.HostelData
+	if err = cursor.All(ctx, &hostels); err != nil {
+		return nil, err
+	}
+	return hostels, nil
+}
+
+func (m *HostelDBManager) DeleteHostel(_id string, ctx context.Context) error {
+	result, err := m.hostelCollection.UpdateOne(ctx, bson.M{"_id": _id}, bson.M{"$set": bson.M{"mark_as_deleted": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrHostelNotFound
+	}
+	return nil
+}
+
+func (m *HostelDBManager) AddRooms(hostelID string, rooms *[]Hostel.RoomData, ctx context.Context) ([]Hostel.RoomData, error) {
+	var addedRooms []Hostel.RoomData
+	for _, room := range *rooms {
+		room.ID = primitive.NewObjectID().Hex()
+		room.HostelID = hostelID
+		room.MarkAsDeleted = false
+		if _, err := m.roomCollection.InsertOne(ctx, room); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return addedRooms, fmt.Errorf("%w: %s/%s", ErrRoomDuplicate, room.Block, room.RoomNumber)
+			}
+			return addedRooms, fmt.Errorf("failed to insert room '%s': %v", room.RoomNumber, err)
+		}
+		addedRooms = append(addedRooms, room)
+	}
+	return addedRooms, nil
+}
+
+func (m *HostelDBManager) FetchRoomByID(_id string, ctx context.Context) (*Hostel.RoomData, error) {
+	var room Hostel.RoomData
+	err := m.roomCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&room)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (m *HostelDBManager) FetchRooms(filter *Hostel.RoomFilter, ctx context.Context) ([]Hostel.RoomData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{"hostel_id": filter.HostelID, "mark_as_deleted": filter.MarkAsDeleted}
+	if filter.Block != "" {
+		query["block"] = filter.Block
+	}
+	if filter.Floor != nil {
+		query["floor"] = *filter.Floor
+	}
+	if filter.RoomType != "" {
+		query["room_type"] = filter.RoomType
+	}
+	if filter.Gender != "" {
+		query["gender"] = filter.Gender
+	}
+	if filter.MaintenanceStatus != "" {
+		query["maintenance_status"] = filter.MaintenanceStatus
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "block", Value: 1}, {Key: "room_number", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.roomCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var rooms []Hostel.RoomData
+	if err = cursor.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (m *HostelDBManager) UpdateRoom(_id string, update *Hostel.RoomUpdate, ctx context.Context) (*Hostel.RoomData, error) {
+	set := bson.M{}
+	if update.RoomType != nil {
+		set["room_type"] = *update.RoomType
+	}
+	if update.Capacity != nil {
+		set["capacity"] = *update.Capacity
+	}
+	if update.Gender != nil {
+		set["gender"] = *update.Gender
+	}
+	if update.Amenities != nil {
+		set["amenities"] = *update.Amenities
+	}
+	if update.MaintenanceStatus != nil {
+		set["maintenance_status"] = *update.MaintenanceStatus
+	}
+	if len(set) == 0 {
+		return m.FetchRoomByID(_id, ctx)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var room Hostel.RoomData
+	err := m.roomCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "mark_as_deleted": false}, bson.M{"$set": set}, opts).Decode(&room)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (m *HostelDBManager) DeleteRoom(_id string, ctx context.Context) error {
+	result, err := m.roomCollection.UpdateOne(ctx, bson.M{"_id": _id}, bson.M{"$set": bson.M{"mark_as_deleted": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRoomNotFound
+	}
+	return nil
+}
+
+// FetchHostelRooms return every not deleted room of a hostel sorted by block and room number
+func (m *HostelDBManager) FetchHostelRooms(hostelID string, ctx context.Context) ([]Hostel.RoomData, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "block", Value: 1}, {Key: "room_number", Value: 1}})
+	cursor, err := m.roomCollection.Find(ctx, bson.M{"hostel_id": hostelID, "mark_as_deleted": false}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var rooms []Hostel.RoomData
+	if err = cursor.All(ctx, &rooms); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (m *HostelDBManager) OccupancySummary(hostelID string, occupied map[string]int, ctx context.Context) (*Hostel.OccupancySummary, error) {
+	if _, err := m.FetchHostelByID(hostelID, ctx); err != nil {
+		return nil, err
+	}
+	rooms, err := m.FetchHostelRooms(hostelID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(hostelID, rooms, occupied), nil
+}
@@ -0,0 +1,10 @@
+package Hostel
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+func TestHostelDBManager(t *testing.T) {
+	testHostelManager(t, NewHostelDBManager(testutil.MongoClient(t)))
+}
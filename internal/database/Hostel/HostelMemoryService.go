@@ -0,0 +1,240 @@
+package Hostel
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Listing"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HostelMemoryManager is the in-memory IHostelDBService
+type HostelMemoryManager struct {
+	mu      sync.RWMutex
+	hostels map[string]*Hostel.HostelData // key is _id
+	rooms   map[string]*Hostel.RoomData   // key is _id
+}
+
+func NewHostelMemoryManager() *HostelMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("HostelMemoryManager"))
+	instance := &HostelMemoryManager{
+		hostels: make(map[string]*Hostel.HostelData),
+		rooms:   make(map[string]*Hostel.RoomData),
+	}
+	slog.Info(LogHelper.LogServiceStarted("HostelMemoryManager"))
+	return instance
+}
+
+func (m *HostelMemoryManager) AddHostel(hostel *Hostel.HostelData, ctx context.Context) (*Hostel.HostelData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hostel.ID = primitive.NewObjectID().Hex()
+	hostel.CreatedAt = time.Now()
+	hostel.MarkAsDeleted = false
+	stored := *hostel
+	m.hostels[stored.ID] = &stored
+	return hostel, nil
+}
+
+func (m *HostelMemoryManager) FetchHostelByID(_id string, ctx context.Context) (*Hostel.HostelData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.hostels[_id]
+	if !ok {
+		return nil, ErrHostelNotFound
+	}
+	hostel := *stored
+	return &hostel, nil
+}
+
+func (m *HostelMemoryManager) FetchHostels(filter *Hostel.HostelFilter, ctx context.Context) ([]Hostel.HostelData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 20)
+
+	m.mu.RLock()
+	var matched []Hostel.HostelData
+	for _, hostel := range m.hostels {
+		if hostel.MarkAsDeleted != filter.MarkAsDeleted {
+			continue
+		}
+		if filter.CollageUniqueName != "" && hostel.CollageUniqueName != filter.CollageUniqueName {
+			continue
+		}
+		matched = append(matched, *hostel)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	return pageSlice(matched, page, limit), nil
+}
+
+func (m *HostelMemoryManager) DeleteHostel(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.hostels[_id]
+	if !ok {
+		return ErrHostelNotFound
+	}
+	stored.MarkAsDeleted = true
+	return nil
+}
+
+// roomNumberTaken emulate the unique (hostel_id, block, room_number) index
+func (m *HostelMemoryManager) roomNumberTaken(room *Hostel.RoomData) bool {
+	for _, stored := range m.rooms {
+		if stored.HostelID == room.HostelID && stored.Block == room.Block && stored.RoomNumber == room.RoomNumber {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *HostelMemoryManager) AddRooms(hostelID string, rooms *[]Hostel.RoomData, ctx context.Context) ([]Hostel.RoomData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var addedRooms []Hostel.RoomData
+	for _, room := range *rooms {
+		room.ID = primitive.NewObjectID().Hex()
+		room.HostelID = hostelID
+		room.MarkAsDeleted = false
+		if m.roomNumberTaken(&room) {
+			return addedRooms, fmt.Errorf("%w: %s/%s", ErrRoomDuplicate, room.Block, room.RoomNumber)
+		}
+		stored := room
+		m.rooms[stored.ID] = &stored
+		addedRooms = append(addedRooms, room)
+	}
+	return addedRooms, nil
+}
+
+func (m *HostelMemoryManager) FetchRoomByID(_id string, ctx context.Context) (*Hostel.RoomData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.rooms[_id]
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	room := *stored
+	return &room, nil
+}
+
+func (m *HostelMemoryManager) FetchRooms(filter *Hostel.RoomFilter, ctx context.Context) ([]Hostel.RoomData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Hostel.RoomData
+	for _, room := range m.rooms {
+		if room.HostelID != filter.HostelID || room.MarkAsDeleted != filter.MarkAsDeleted {
+			continue
+		}
+		if filter.Block != "" && room.Block != filter.Block {
+			continue
+		}
+		if filter.Floor != nil && room.Floor != *filter.Floor {
+			continue
+		}
+		if filter.RoomType != "" && room.RoomType != filter.RoomType {
+			continue
+		}
+		if filter.Gender != "" && room.Gender != filter.Gender {
+			continue
+		}
+		if filter.MaintenanceStatus != "" && room.MaintenanceStatus != filter.MaintenanceStatus {
+			continue
+		}
+		matched = append(matched, *room)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Block != matched[j].Block {
+			return matched[i].Block < matched[j].Block
+		}
+		return matched[i].RoomNumber < matched[j].RoomNumber
+	})
+	return pageSlice(matched, page, limit), nil
+}
+
+func (m *HostelMemoryManager) UpdateRoom(_id string, update *Hostel.RoomUpdate, ctx context.Context) (*Hostel.RoomData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.rooms[_id]
+	if !ok || stored.MarkAsDeleted {
+		return nil, ErrRoomNotFound
+	}
+	if update.RoomType != nil {
+		stored.RoomType = *update.RoomType
+	}
+	if update.Capacity != nil {
+		stored.Capacity = *update.Capacity
+	}
+	if update.Gender != nil {
+		stored.Gender = *update.Gender
+	}
+	if update.Amenities != nil {
+		stored.Amenities = *update.Amenities
+	}
+	if update.MaintenanceStatus != nil {
+		stored.MaintenanceStatus = *update.MaintenanceStatus
+	}
+	room := *stored
+	return &room, nil
+}
+
+func (m *HostelMemoryManager) DeleteRoom(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.rooms[_id]
+	if !ok {
+		return ErrRoomNotFound
+	}
+	stored.MarkAsDeleted = true
+	return nil
+}
+
+// FetchHostelRooms return every not deleted room of a hostel sorted by block and room number
+func (m *HostelMemoryManager) FetchHostelRooms(hostelID string, ctx context.Context) ([]Hostel.RoomData, error) {
+	m.mu.RLock()
+	var rooms []Hostel.RoomData
+	for _, room := range m.rooms {
+		if room.HostelID == hostelID && !room.MarkAsDeleted {
+			rooms = append(rooms, *room)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(rooms, func(i, j int) bool {
+		if rooms[i].Block != rooms[j].Block {
+			return rooms[i].Block < rooms[j].Block
+		}
+		return rooms[i].RoomNumber < rooms[j].RoomNumber
+	})
+	return rooms, nil
+}
+
+func (m *HostelMemoryManager) OccupancySummary(hostelID string, occupied map[string]int, ctx context.Context) (*Hostel.OccupancySummary, error) {
+	if _, err := m.FetchHostelByID(hostelID, ctx); err != nil {
+		return nil, err
+	}
+	rooms, err := m.FetchHostelRooms(hostelID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(hostelID, rooms, occupied), nil
+}
+
+func pageSlice[T any](items []T, page int64, limit int64) []T {
+	skip := (page - 1) * limit
+	if skip >= int64(len(items)) {
+		return nil
+	}
+	end := skip + limit
+	if end > int64(len(items)) {
+		end = int64(len(items))
+	}
+	return items[skip:end]
+}
@@ -0,0 +1,87 @@
+package Hostel
+
+import (
+	"HostelApp/internal/storageData/Hostel"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHostelMemoryManager(t *testing.T) {
+	testHostelManager(t, NewHostelMemoryManager())
+}
+
+// testHostelManager check an empty IHostelDBService
+func testHostelManager(t *testing.T, m IHostelDBService) {
+	ctx := context.Background()
+	hostel, err := m.AddHostel(&Hostel.HostelData{Name: "North", CollageUniqueName: "college-a", Gender: Hostel.Mixed,
+		Address: "North campus", Blocks: []Hostel.BlockData{{Name: "A", Floors: 2}}}, ctx)
+	if err != nil {
+		t.Fatalf("AddHostel failed. Err: %v", err)
+	}
+	rooms, err := m.AddRooms(hostel.ID, &[]Hostel.RoomData{
+		{Block: "A", Floor: 1, RoomNumber: "A-102", RoomType: Hostel.Double, Capacity: 2, Gender: Hostel.FemaleOnly, MaintenanceStatus: Hostel.Available},
+		{Block: "A", Floor: 1, RoomNumber: "A-101", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.UnderMaintenance},
+		{Block: "A", Floor: 0, RoomNumber: "A-001", RoomType: Hostel.Dorm, Capacity: 6, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+	}, ctx)
+	if err != nil || len(rooms) != 3 || rooms[0].HostelID != hostel.ID {
+		t.Fatalf("AddRooms failed %+v. Err: %v", rooms, err)
+	}
+	if _, err = m.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-101", RoomType: Hostel.Single, Capacity: 1,
+		Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx); !errors.Is(err, ErrRoomDuplicate) {
+		t.Fatalf("expected ErrRoomDuplicate for a room number taken in the block; got %v", err)
+	}
+
+	floor := 1
+	filtered, err := m.FetchRooms(&Hostel.RoomFilter{Page: 1, Limit: 10, HostelID: hostel.ID, Floor: &floor}, ctx)
+	if err != nil || len(filtered) != 2 || filtered[0].RoomNumber != "A-101" || filtered[1].RoomNumber != "A-102" {
+		t.Fatalf("expected the first floor rooms by room number; got %+v. Err: %v", filtered, err)
+	}
+
+	capacity, status := 3, Hostel.Available
+	updated, err := m.UpdateRoom(rooms[1].ID, &Hostel.RoomUpdate{Capacity: &capacity, MaintenanceStatus: &status}, ctx)
+	if err != nil || updated.Capacity != 3 || updated.RoomType != Hostel.Single || updated.MaintenanceStatus != Hostel.Available {
+		t.Fatalf("unexpected partial update %+v. Err: %v", updated, err)
+	}
+	if err = m.DeleteRoom(rooms[2].ID, ctx); err != nil {
+		t.Fatalf("DeleteRoom failed. Err: %v", err)
+	}
+	if _, err = m.UpdateRoom(rooms[2].ID, &Hostel.RoomUpdate{Capacity: &capacity}, ctx); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("expected ErrRoomNotFound updating a deleted room; got %v", err)
+	}
+	live, _ := m.FetchHostelRooms(hostel.ID, ctx)
+	if len(live) != 2 {
+		t.Fatalf("expected the deleted room left out; got %+v", live)
+	}
+
+	summary, err := m.OccupancySummary(hostel.ID, map[string]int{rooms[1].ID: 1}, ctx)
+	if err != nil || summary.TotalRooms != 2 || summary.TotalCapacity != 5 || summary.UsableCapacity != 5 || summary.OccupiedBeds != 1 || summary.FreeBeds != 4 ||
+		summary.ByRoomType[Hostel.Double].Capacity != 2 || summary.ByGender[Hostel.AnyGender].Rooms != 1 {
+		t.Fatalf("unexpected occupancy summary %+v. Err: %v", summary, err)
+	}
+
+	if err = m.DeleteHostel(hostel.ID, ctx); err != nil {
+		t.Fatalf("DeleteHostel failed. Err: %v", err)
+	}
+	hostels, _ := m.FetchHostels(&Hostel.HostelFilter{Page: 1, Limit: 10, CollageUniqueName: "college-a"}, ctx)
+	if len(hostels) != 0 {
+		t.Fatalf("expected the deleted hostel left out; got %+v", hostels)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	// a student still live in the room out of service, its bed is occupied but none is free
+	summary := summarize("h1", []Hostel.RoomData{
+		{ID: "r1", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.MaleOnly, MaintenanceStatus: Hostel.OutOfService},
+		{ID: "r2", RoomType: Hostel.Dorm, Capacity: 4, Gender: Hostel.MaleOnly, MaintenanceStatus: Hostel.Available},
+	}, map[string]int{"r1": 1, "r2": 3})
+	if summary.TotalRooms != 2 || summary.TotalCapacity != 5 || summary.UsableCapacity != 4 || summary.OccupiedBeds != 4 || summary.FreeBeds != 1 {
+		t.Fatalf("unexpected totals %+v", summary)
+	}
+	if male := summary.ByGender[Hostel.MaleOnly]; male.Rooms != 2 || male.Capacity != 5 || male.Occupied != 4 || male.Free != 1 {
+		t.Fatalf("unexpected gender summary %+v", summary.ByGender)
+	}
+	if out := summary.ByMaintenance[Hostel.OutOfService]; out.Rooms != 1 || out.Capacity != 1 || out.Occupied != 1 || out.Free != 0 {
+		t.Fatalf("unexpected maintenance summary %+v", summary.ByMaintenance)
+	}
+}
@@ -0,0 +1,65 @@
+package Hostel
+
+import (
+	"HostelApp/internal/storageData/Hostel"
+	"context"
+	"errors"
+)
+
+var (
+	ErrHostelNotFound = errors.New("hostel not found")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrRoomDuplicate  = errors.New("room with this number already exists in the block")
+)
+
+// IHostelDBService is the storage of hostels and their rooms,
+// room numbers are unique per hostel block
+type IHostelDBService interface {
+	AddHostel(hostel *Hostel.HostelData, ctx context.Context) (*Hostel.HostelData, error)
+	FetchHostelByID(_id string, ctx context.Context) (*Hostel.HostelData, error)
+	FetchHostels(filter *Hostel.HostelFilter, ctx context.Context) ([]Hostel.HostelData, error)
+	DeleteHostel(_id string, ctx context.Context) error
+	AddRooms(hostelID string, rooms *[]Hostel.RoomData, ctx context.Context) ([]Hostel.RoomData, error)
+	FetchRoomByID(_id string, ctx context.Context) (*Hostel.RoomData, error)
+	FetchRooms(filter *Hostel.RoomFilter, ctx context.Context) ([]Hostel.RoomData, error)
+	FetchHostelRooms(hostelID string, ctx context.Context) ([]Hostel.RoomData, error)
+	UpdateRoom(_id string, update *Hostel.RoomUpdate, ctx context.Context) (*Hostel.RoomData, error)
+	DeleteRoom(_id string, ctx context.Context) error
+	// OccupancySummary count the beds of the rooms of a hostel, occupied is the
+	// number of beds taken in each room by id
+	OccupancySummary(hostelID string, occupied map[string]int, ctx context.Context) (*Hostel.OccupancySummary, error)
+}
+
+// summarize build the OccupancySummary of the rooms of one hostel, occupied is
+// the number of beds taken in each room by id
+func summarize(hostelID string, rooms []Hostel.RoomData, occupied map[string]int) *Hostel.OccupancySummary {
+	summary := &Hostel.OccupancySummary{
+		HostelID:      hostelID,
+		ByRoomType:    map[Hostel.RoomType]Hostel.CapacitySummary{},
+		ByGender:      map[Hostel.GenderRestriction]Hostel.CapacitySummary{},
+		ByMaintenance: map[Hostel.MaintenanceStatus]Hostel.CapacitySummary{},
+	}
+	for _, room := range rooms {
+		taken, free := occupied[room.ID], 0
+		summary.TotalRooms++
+		summary.TotalCapacity += room.Capacity
+		if room.MaintenanceStatus == Hostel.Available {
+			summary.UsableCapacity += room.Capacity
+			free = max(room.Capacity-taken, 0)
+		}
+		summary.OccupiedBeds += taken
+		summary.FreeBeds += free
+		summary.ByRoomType[room.RoomType] = addCapacity(summary.ByRoomType[room.RoomType], room.Capacity, taken, free)
+		summary.ByGender[room.Gender] = addCapacity(summary.ByGender[room.Gender], room.Capacity, taken, free)
+		summary.ByMaintenance[room.MaintenanceStatus] = addCapacity(summary.ByMaintenance[room.MaintenanceStatus], room.Capacity, taken, free)
+	}
+	return summary
+}
+
+func addCapacity(summary Hostel.CapacitySummary, capacity int, occupied int, free int) Hostel.CapacitySummary {
+	summary.Rooms++
+	summary.Capacity += capacity
+	summary.Occupied += occupied
+	summary.Free += free
+	return summary
+}
@@ -0,0 +1,27 @@
+package Mess
+
+import (
+	"HostelApp/internal/storageData/Mess"
+	"context"
+	"errors"
+)
+
+var (
+	ErrMenuNotFound   = errors.New("menu not found")
+	ErrOptOutNotFound = errors.New("opt-out not found")
+)
+
+// IMessDBService is the storage of weekly mess menus and meal opt-outs, a hostel
+// has one menu per week and a student one opt-out per meal
+type IMessDBService interface {
+	// UpsertMenu insert or replace the menu keyed on (hostel_id, week_start)
+	UpsertMenu(menu *Mess.MenuData, ctx context.Context) (*Mess.MenuData, error)
+	FetchMenu(hostelID string, weekStart string, ctx context.Context) (*Mess.MenuData, error)
+	// AddOptOuts insert the opt-outs the student does not have yet and return only those
+	AddOptOuts(optOuts []Mess.OptOutData, ctx context.Context) ([]Mess.OptOutData, error)
+	FetchOptOutByID(_id string, ctx context.Context) (*Mess.OptOutData, error)
+	FetchOptOuts(filter *Mess.OptOutFilter, ctx context.Context) ([]Mess.OptOutData, error)
+	// FetchOptOutRange return every opt-out of a hostel between from and to included, by student then date
+	FetchOptOutRange(hostelID string, from string, to string, ctx context.Context) ([]Mess.OptOutData, error)
+	DeleteOptOut(_id string, ctx context.Context) error
+}
@@ -0,0 +1,208 @@
+package Mess
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Mess"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type MessDBManager struct {
+	client           *mongo.Client
+	menuCollection   *mongo.Collection
+	optOutCollection *mongo.Collection
+}
+
+func NewMessDBManager(client *mongo.Client) *MessDBManager {
+	slog.Info(LogHelper.LogServiceStarting("MessDBManager"))
+	instance := &MessDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("MessDBManager"))
+	return instance
+}
+
+func (m *MessDBManager) init() {
+	m.menuCollection = m.client.Database("hosteldb").Collection("mess_menus")
+	m.optOutCollection = m.client.Database("hosteldb").Collection("mess_opt_outs")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *MessDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	menuIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hostel_id", Value: 1}, {Key: "week_start", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	if _, err := m.menuCollection.Indexes().CreateMany(ctx, menuIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for messDB error: %v", err)))
+	}
+	optOutIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "student_id", Value: 1}, {Key: "date", Value: 1}, {Key: "meal", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "date", Value: 1}},
+		},
+	}
+	if _, err := m.optOutCollection.Indexes().CreateMany(ctx, optOutIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for messDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *MessDBManager) UpsertMenu(menu *Mess.MenuData, ctx context.Context) (*Mess.MenuData, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"days":       menu.Days,
+			"updated_by": menu.UpdatedBy,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID().Hex(), "created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var saved Mess.MenuData
+	err := m.menuCollection.FindOneAndUpdate(ctx, bson.M{"hostel_id": menu.HostelID, "week_start": menu.WeekStart}, update, opts).Decode(&saved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save menu: %v", err)
+	}
+	return &saved, nil
+}
+
+func (m *MessDBManager) FetchMenu(hostelID string, weekStart string, ctx context.Context) (*Mess.MenuData, error) {
+	var menu Mess.MenuData
+	err := m.menuCollection.FindOne(ctx, bson.M{"hostel_id": hostelID, "week_start": weekStart}).Decode(&menu)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+	return &menu, nil
+}
+
+func (m *MessDBManager) AddOptOuts(optOuts []Mess.OptOutData, ctx context.Context) ([]Mess.OptOutData, error) {
+	if len(optOuts) == 0 {
+		return []Mess.OptOutData{}, nil
+	}
+	now := time.Now()
+	documents := make([]interface{}, 0, len(optOuts))
+	for i := range optOuts {
+		optOuts[i].ID = primitive.NewObjectID().Hex()
+		optOuts[i].CreatedAt = now
+		documents = append(documents, optOuts[i])
+	}
+
+	// unordered so one meal already opted out does not stop the others
+	duplicates := map[int]bool{}
+	_, err := m.optOutCollection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return nil, fmt.Errorf("failed to add opt-outs: %v", err)
+			}
+			duplicates[writeErr.Index] = true
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to add opt-outs: %v", err)
+	}
+
+	created := make([]Mess.OptOutData, 0, len(optOuts)-len(duplicates))
+	for i, optOut := range optOuts {
+		if !duplicates[i] {
+			created = append(created, optOut)
+		}
+	}
+	return created, nil
+}
+
+func (m *MessDBManager) FetchOptOutByID(_id string, ctx context.Context) (*Mess.OptOutData, error) {
+	var optOut Mess.OptOutData
+	err := m.optOutCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&optOut)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOptOutNotFound
+		}
+		return nil, err
+	}
+	return &optOut, nil
+}
+
+func (m *MessDBManager) FetchOptOuts(filter *Mess.OptOutFilter, ctx context.Context) ([]Mess.OptOutData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.HostelID != "" {
+		query["hostel_id"] = filter.HostelID
+	}
+	if filter.Date != "" {
+		query["date"] = filter.Date
+	}
+	if filter.Meal != "" {
+		query["meal"] = filter.Meal
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "date", Value: -1}, {Key: "student_id", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.optOutCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var optOuts []Mess.OptOutData
+	if err = cursor.All(ctx, &optOuts); err != nil {
+		return nil, err
+	}
+	return optOuts, nil
+}
+
+func (m *MessDBManager) FetchOptOutRange(hostelID string, from string, to string, ctx context.Context) ([]Mess.OptOutData, error) {
+	query := bson.M{"hostel_id": hostelID, "date": bson.M{"$gte": from, "$lte": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "student_id", Value: 1}, {Key: "date", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := m.optOutCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var optOuts []Mess.OptOutData
+	if err = cursor.All(ctx, &optOuts); err != nil {
+		return nil, err
+	}
+	return optOuts, nil
+}
+
+func (m *MessDBManager) DeleteOptOut(_id string, ctx context.Context) error {
+	result, err := m.optOutCollection.DeleteOne(ctx, bson.M{"_id": _id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrOptOutNotFound
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package Mess
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestMessDBManager run the checks on the menu week and opt-out meal unique indexes
+func TestMessDBManager(t *testing.T) {
+	testMessManager(t, NewMessDBManager(testutil.MongoClient(t)))
+}
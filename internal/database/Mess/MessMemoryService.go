@@ -0,0 +1,175 @@
+package Mess
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Mess"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MessMemoryManager is the in-memory IMessDBService
+type MessMemoryManager struct {
+	mu      sync.RWMutex
+	menus   map[string]*Mess.MenuData   // key is hostel_id|week_start
+	optOuts map[string]*Mess.OptOutData // key is _id
+	keys    map[string]string           // student_id|date|meal to opt-out _id
+}
+
+func NewMessMemoryManager() *MessMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("MessMemoryManager"))
+	instance := &MessMemoryManager{
+		menus:   make(map[string]*Mess.MenuData),
+		optOuts: make(map[string]*Mess.OptOutData),
+		keys:    make(map[string]string),
+	}
+	slog.Info(LogHelper.LogServiceStarted("MessMemoryManager"))
+	return instance
+}
+
+func optOutKey(optOut *Mess.OptOutData) string {
+	return optOut.StudentID + "|" + optOut.Date + "|" + string(optOut.Meal)
+}
+
+func (m *MessMemoryManager) UpsertMenu(menu *Mess.MenuData, ctx context.Context) (*Mess.MenuData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	key := menu.HostelID + "|" + menu.WeekStart
+	saved := *menu
+	if existing, ok := m.menus[key]; ok {
+		saved.ID = existing.ID
+		saved.CreatedAt = existing.CreatedAt
+	} else {
+		saved.ID = primitive.NewObjectID().Hex()
+		saved.CreatedAt = now
+	}
+	saved.UpdatedAt = now
+	m.menus[key] = &saved
+	result := saved
+	return &result, nil
+}
+
+func (m *MessMemoryManager) FetchMenu(hostelID string, weekStart string, ctx context.Context) (*Mess.MenuData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.menus[hostelID+"|"+weekStart]
+	if !ok {
+		return nil, ErrMenuNotFound
+	}
+	menu := *stored
+	return &menu, nil
+}
+
+func (m *MessMemoryManager) AddOptOuts(optOuts []Mess.OptOutData, ctx context.Context) ([]Mess.OptOutData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	created := make([]Mess.OptOutData, 0, len(optOuts))
+	for _, optOut := range optOuts {
+		key := optOutKey(&optOut)
+		if _, ok := m.keys[key]; ok {
+			continue
+		}
+		optOut.ID = primitive.NewObjectID().Hex()
+		optOut.CreatedAt = now
+		saved := optOut
+		m.optOuts[optOut.ID] = &saved
+		m.keys[key] = optOut.ID
+		created = append(created, optOut)
+	}
+	return created, nil
+}
+
+func (m *MessMemoryManager) FetchOptOutByID(_id string, ctx context.Context) (*Mess.OptOutData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.optOuts[_id]
+	if !ok {
+		return nil, ErrOptOutNotFound
+	}
+	optOut := *stored
+	return &optOut, nil
+}
+
+func (m *MessMemoryManager) FetchOptOuts(filter *Mess.OptOutFilter, ctx context.Context) ([]Mess.OptOutData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Mess.OptOutData
+	for _, stored := range m.optOuts {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.HostelID != "" && stored.HostelID != filter.HostelID {
+			continue
+		}
+		if filter.Date != "" && stored.Date != filter.Date {
+			continue
+		}
+		if filter.Meal != "" && stored.Meal != filter.Meal {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	// same order as the mongo query, latest date first
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Date != matched[j].Date {
+			return matched[i].Date > matched[j].Date
+		}
+		if matched[i].StudentID != matched[j].StudentID {
+			return matched[i].StudentID < matched[j].StudentID
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *MessMemoryManager) FetchOptOutRange(hostelID string, from string, to string, ctx context.Context) ([]Mess.OptOutData, error) {
+	m.mu.RLock()
+	var matched []Mess.OptOutData
+	for _, stored := range m.optOuts {
+		// dates are YYYY-MM-DD so the string order is the calendar order
+		if stored.HostelID == hostelID && stored.Date >= from && stored.Date <= to {
+			matched = append(matched, *stored)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].StudentID != matched[j].StudentID {
+			return matched[i].StudentID < matched[j].StudentID
+		}
+		if matched[i].Date != matched[j].Date {
+			return matched[i].Date < matched[j].Date
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched, nil
+}
+
+func (m *MessMemoryManager) DeleteOptOut(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.optOuts[_id]
+	if !ok {
+		return ErrOptOutNotFound
+	}
+	delete(m.keys, optOutKey(stored))
+	delete(m.optOuts, _id)
+	return nil
+}
@@ -0,0 +1,71 @@
+package Mess
+
+import (
+	"HostelApp/internal/storageData/Mess"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMessMemoryManager(t *testing.T) {
+	testMessManager(t, NewMessMemoryManager())
+}
+
+// testMessManager check an empty IMessDBService keep one menu per hostel and
+// week and one opt-out per student and meal
+func testMessManager(t *testing.T, m IMessDBService) {
+	ctx := context.Background()
+	menu := func(item string) *Mess.MenuData {
+		return &Mess.MenuData{HostelID: "h1", WeekStart: "2026-03-02", UpdatedBy: "u1",
+			Days: []Mess.DayMenu{{Weekday: time.Monday, Meals: []Mess.MealMenu{{Meal: Mess.Lunch, Items: []string{item}}}}}}
+	}
+	first, err := m.UpsertMenu(menu("Rice"), ctx)
+	if err != nil {
+		t.Fatalf("UpsertMenu failed. Err: %v", err)
+	}
+	if _, err = m.UpsertMenu(menu("Roti"), ctx); err != nil {
+		t.Fatalf("UpsertMenu failed. Err: %v", err)
+	}
+	stored, err := m.FetchMenu("h1", "2026-03-02", ctx)
+	if err != nil || stored.ID != first.ID || len(stored.Days) != 1 || stored.Days[0].Meals[0].Items[0] != "Roti" {
+		t.Fatalf("expected the menu of the week replaced in place; got %+v %v", stored, err)
+	}
+	if _, err = m.FetchMenu("h1", "2026-03-09", ctx); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("expected ErrMenuNotFound; got %v", err)
+	}
+
+	optOut := func(studentID string, date string, meal Mess.Meal) Mess.OptOutData {
+		return Mess.OptOutData{StudentID: studentID, CollageUniqueName: "college-a", HostelID: "h1", Date: date, Meal: meal, CreatedBy: "u1"}
+	}
+	created, err := m.AddOptOuts([]Mess.OptOutData{optOut("s1", "2026-03-03", Mess.Lunch), optOut("s1", "2026-03-03", Mess.Dinner)}, ctx)
+	if err != nil || len(created) != 2 {
+		t.Fatalf("AddOptOuts failed %+v. Err: %v", created, err)
+	}
+	// the lunch is already skipped, only the new ones are returned
+	created, err = m.AddOptOuts([]Mess.OptOutData{optOut("s1", "2026-03-03", Mess.Lunch), optOut("s2", "2026-03-02", Mess.Lunch)}, ctx)
+	if err != nil || len(created) != 1 || created[0].StudentID != "s2" {
+		t.Fatalf("expected only the opt-out of s2 created; got %+v %v", created, err)
+	}
+
+	ranged, err := m.FetchOptOutRange("h1", "2026-03-02", "2026-03-03", ctx)
+	if err != nil || len(ranged) != 3 || ranged[0].StudentID != "s1" || ranged[2].StudentID != "s2" {
+		t.Fatalf("expected 3 opt-outs by student; got %+v %v", ranged, err)
+	}
+	if ranged, err = m.FetchOptOutRange("h1", "2026-03-03", "2026-03-03", ctx); err != nil || len(ranged) != 2 {
+		t.Fatalf("expected 2 opt-outs on 2026-03-03; got %+v %v", ranged, err)
+	}
+
+	if err = m.DeleteOptOut(created[0].ID, ctx); err != nil {
+		t.Fatalf("DeleteOptOut failed. Err: %v", err)
+	}
+	if err = m.DeleteOptOut(created[0].ID, ctx); !errors.Is(err, ErrOptOutNotFound) {
+		t.Fatalf("expected ErrOptOutNotFound deleting twice; got %v", err)
+	}
+	if _, err = m.FetchOptOutByID(created[0].ID, ctx); !errors.Is(err, ErrOptOutNotFound) {
+		t.Fatalf("expected ErrOptOutNotFound; got %v", err)
+	}
+	if remaining, err := m.FetchOptOuts(&Mess.OptOutFilter{Page: 1, Limit: 10, HostelID: "h1"}, ctx); err != nil || len(remaining) != 2 {
+		t.Fatalf("expected 2 opt-outs left; got %+v %v", remaining, err)
+	}
+}
@@ -0,0 +1,22 @@
+package Payment
+
+import (
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+)
+
+var (
+	ErrOrderNotFound      = errors.New("payment order not found")
+	ErrOrderStatusChanged = errors.New("payment order is no longer in the expected status")
+)
+
+// IPaymentDBService is the storage of online payment orders, a provider order id is unique per provider
+type IPaymentDBService interface {
+	CreateOrder(order *Payment.PaymentOrder, ctx context.Context) (*Payment.PaymentOrder, error)
+	FetchOrderByID(_id string, ctx context.Context) (*Payment.PaymentOrder, error)
+	FetchOrderByProviderID(provider string, providerOrderID string, ctx context.Context) (*Payment.PaymentOrder, error)
+	FetchOrders(filter *Payment.OrderFilter, ctx context.Context) ([]Payment.PaymentOrder, error)
+	// SettleOrder move an order out of the created status, only one caller can win
+	SettleOrder(_id string, status Payment.OrderStatus, providerPaymentID string, ledgerTransactionID string, ctx context.Context) (*Payment.PaymentOrder, error)
+}
@@ -0,0 +1,147 @@
+package Payment
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type PaymentDBManager struct {
+	client          *mongo.Client
+	orderCollection *mongo.Collection
+}
+
+func NewPaymentDBManager(client *mongo.Client) *PaymentDBManager {
+	slog.Info(LogHelper.LogServiceStarting("PaymentDBManager"))
+	instance := &PaymentDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("PaymentDBManager"))
+	return instance
+}
+
+func (m *PaymentDBManager) init() {
+	m.orderCollection = m.client.Database("hosteldb").Collection("payment_orders")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *PaymentDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "provider_order_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "invoice_id", Value: 1}},
+		},
+	}
+	if _, err := m.orderCollection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for paymentDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *PaymentDBManager) CreateOrder(order *Payment.PaymentOrder, ctx context.Context) (*Payment.PaymentOrder, error) {
+	now := time.Now()
+	order.ID = primitive.NewObjectID().Hex()
+	order.Status = Payment.Created
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	if _, err := m.orderCollection.InsertOne(ctx, order); err != nil {
+		return nil, fmt.Errorf("failed to insert payment order: %v", err)
+	}
+	return order, nil
+}
+
+func (m *PaymentDBManager) fetchOne(query bson.M, ctx context.Context) (*Payment.PaymentOrder, error) {
+	var order Payment.PaymentOrder
+	err := m.orderCollection.FindOne(ctx, query).Decode(&order)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (m *PaymentDBManager) FetchOrderByID(_id string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	return m.fetchOne(bson.M{"_id": _id}, ctx)
+}
+
+func (m *PaymentDBManager) FetchOrderByProviderID(provider string, providerOrderID string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	return m.fetchOne(bson.M{"provider": provider, "provider_order_id": providerOrderID}, ctx)
+}
+
+func (m *PaymentDBManager) FetchOrders(filter *Payment.OrderFilter, ctx context.Context) ([]Payment.PaymentOrder, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.StudentID != "" {
+		query["student_id"] = filter.StudentID
+	}
+	if filter.InvoiceID != "" {
+		query["invoice_id"] = filter.InvoiceID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.orderCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var orders []Payment.PaymentOrder
+	if err = cursor.All(ctx, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (m *PaymentDBManager) SettleOrder(_id string, status Payment.OrderStatus, providerPaymentID string, ledgerTransactionID string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	set := bson.M{"status": status, "updated_at": time.Now()}
+	if providerPaymentID != "" {
+		set["provider_payment_id"] = providerPaymentID
+	}
+	if ledgerTransactionID != "" {
+		set["ledger_transaction_id"] = ledgerTransactionID
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var order Payment.PaymentOrder
+	err := m.orderCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": Payment.Created}, bson.M{"$set": set}, opts).Decode(&order)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchOrderByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrOrderStatusChanged
+		}
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,11 @@
+package Payment
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestPaymentDBManager run the checks on the provider order unique index
+func TestPaymentDBManager(t *testing.T) {
+	testPaymentManager(t, NewPaymentDBManager(testutil.MongoClient(t)))
+}
@@ -0,0 +1,119 @@
+package Payment
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PaymentMemoryManager is the in-memory IPaymentDBService
+type PaymentMemoryManager struct {
+	mu     sync.RWMutex
+	orders map[string]*Payment.PaymentOrder // key is _id
+}
+
+func NewPaymentMemoryManager() *PaymentMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("PaymentMemoryManager"))
+	instance := &PaymentMemoryManager{
+		orders: make(map[string]*Payment.PaymentOrder),
+	}
+	slog.Info(LogHelper.LogServiceStarted("PaymentMemoryManager"))
+	return instance
+}
+
+func (m *PaymentMemoryManager) CreateOrder(order *Payment.PaymentOrder, ctx context.Context) (*Payment.PaymentOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, stored := range m.orders {
+		if stored.Provider == order.Provider && stored.ProviderOrderID == order.ProviderOrderID {
+			return nil, fmt.Errorf("failed to insert payment order: duplicate provider order %s", order.ProviderOrderID)
+		}
+	}
+	now := time.Now()
+	order.ID = primitive.NewObjectID().Hex()
+	order.Status = Payment.Created
+	order.CreatedAt = now
+	order.UpdatedAt = now
+	stored := *order
+	m.orders[stored.ID] = &stored
+	return order, nil
+}
+
+func (m *PaymentMemoryManager) FetchOrderByID(_id string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.orders[_id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	order := *stored
+	return &order, nil
+}
+
+func (m *PaymentMemoryManager) FetchOrderByProviderID(provider string, providerOrderID string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, stored := range m.orders {
+		if stored.Provider == provider && stored.ProviderOrderID == providerOrderID {
+			order := *stored
+			return &order, nil
+		}
+	}
+	return nil, ErrOrderNotFound
+}
+
+func (m *PaymentMemoryManager) FetchOrders(filter *Payment.OrderFilter, ctx context.Context) ([]Payment.PaymentOrder, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+
+	m.mu.RLock()
+	var matched []Payment.PaymentOrder
+	for _, stored := range m.orders {
+		if filter.StudentID != "" && stored.StudentID != filter.StudentID {
+			continue
+		}
+		if filter.InvoiceID != "" && stored.InvoiceID != filter.InvoiceID {
+			continue
+		}
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, *stored)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *PaymentMemoryManager) SettleOrder(_id string, status Payment.OrderStatus, providerPaymentID string, ledgerTransactionID string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.orders[_id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	if stored.Status != Payment.Created {
+		return nil, ErrOrderStatusChanged
+	}
+	stored.Status = status
+	stored.ProviderPaymentID = providerPaymentID
+	stored.LedgerTransactionID = ledgerTransactionID
+	stored.UpdatedAt = time.Now()
+	order := *stored
+	return &order, nil
+}
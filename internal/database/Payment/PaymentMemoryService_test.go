@@ -0,0 +1,69 @@
+package Payment
+
+import (
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPaymentMemoryManager(t *testing.T) {
+	testPaymentManager(t, NewPaymentMemoryManager())
+}
+
+// testPaymentManager check an empty IPaymentDBService keep provider order ids
+// unique and settle an order only once
+func testPaymentManager(t *testing.T, m IPaymentDBService) {
+	ctx := context.Background()
+	order := func(studentID string, providerOrderID string) *Payment.PaymentOrder {
+		return &Payment.PaymentOrder{StudentID: studentID, CollageUniqueName: "college-a", InvoiceID: "i1", Amount: 500000, Currency: "INR", Provider: "fake", ProviderOrderID: providerOrderID}
+	}
+	created, err := m.CreateOrder(order("s1", "order-1"), ctx)
+	if err != nil || created.Status != Payment.Created {
+		t.Fatalf("CreateOrder failed %+v. Err: %v", created, err)
+	}
+	if _, err = m.CreateOrder(order("s2", "order-1"), ctx); err == nil {
+		t.Fatalf("expected an error for a duplicate provider order id")
+	}
+	if _, err = m.CreateOrder(order("s2", "order-2"), ctx); err != nil {
+		t.Fatalf("CreateOrder failed. Err: %v", err)
+	}
+	if fetched, err := m.FetchOrderByProviderID("fake", "order-1", ctx); err != nil || fetched.ID != created.ID {
+		t.Fatalf("expected order-1 by provider id; got %+v %v", fetched, err)
+	}
+	if _, err = m.FetchOrderByProviderID("other", "order-1", ctx); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound for another provider; got %v", err)
+	}
+
+	// concurrent webhook deliveries, only one settle the order
+	var wg sync.WaitGroup
+	var settled atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.SettleOrder(created.ID, Payment.Paid, "pay-1", "ledger-1", ctx); err == nil {
+				settled.Add(1)
+			} else if !errors.Is(err, ErrOrderStatusChanged) {
+				t.Errorf("expected ErrOrderStatusChanged; got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if settled.Load() != 1 {
+		t.Fatalf("expected the order settled once; got %d", settled.Load())
+	}
+	if _, err = m.SettleOrder("missing", Payment.Paid, "", "", ctx); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound; got %v", err)
+	}
+	fetched, err := m.FetchOrderByID(created.ID, ctx)
+	if err != nil || fetched.Status != Payment.Paid || fetched.LedgerTransactionID != "ledger-1" {
+		t.Fatalf("expected a paid order linked to its ledger transaction; got %+v %v", fetched, err)
+	}
+	paid, err := m.FetchOrders(&Payment.OrderFilter{Page: 1, Limit: 10, Status: Payment.Paid}, ctx)
+	if err != nil || len(paid) != 1 || paid[0].ID != created.ID {
+		t.Fatalf("expected only order-1 paid; got %+v %v", paid, err)
+	}
+}
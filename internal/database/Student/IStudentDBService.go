@@ -0,0 +1,23 @@
+package Student
+
+import (
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+)
+
+var (
+	ErrStudentNotFound  = errors.New("student not found")
+	ErrStudentDuplicate = errors.New("student with this roll number already exists in the college")
+)
+
+// IStudentDBService is the storage of students, roll numbers are unique per college
+type IStudentDBService interface {
+	AddStudent(student *Student.StudentData, ctx context.Context) (*Student.StudentData, error)
+	FetchStudentByID(_id string, ctx context.Context) (*Student.StudentData, error)
+	// FetchStudentsByID return the students of ids that exist, deleted ones included
+	FetchStudentsByID(ids []string, ctx context.Context) ([]Student.StudentData, error)
+	UpdateStudent(_id string, update *Student.StudentUpdate, ctx context.Context) (*Student.StudentData, error)
+	DeleteStudent(_id string, ctx context.Context) error
+	FetchStudents(filter *Student.StudentFilter, ctx context.Context) ([]Student.StudentData, error)
+}
@@ -0,0 +1,213 @@
+package Student
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+type StudentDBManager struct {
+	client            *mongo.Client
+	studentCollection *mongo.Collection
+}
+
+func NewStudentDBManager(client *mongo.Client) *StudentDBManager {
+	slog.Info(LogHelper.LogServiceStarting("StudentDBManager"))
+	instance := &StudentDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("StudentDBManager"))
+	return instance
+}
+
+func (m *StudentDBManager) init() {
+	m.studentCollection = m.client.Database("hosteldb").Collection("students")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *StudentDBManager) createIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			// roll number is unique inside a college
+			Keys:    bson.D{{Key: "collage_unique_name", Value: 1}, {Key: "roll_number", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "mark_as_deleted", Value: 1}, {Key: "year", Value: 1}},
+		},
+	}
+
+	// Create all indexes
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := m.studentCollection.Indexes().CreateMany(ctx, indexModels)
+	if err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for studentDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *StudentDBManager) AddStudent(student *Student.StudentData, ctx context.Context) (*Student.StudentData, error) {
+	now := time.Now()
+	student.ID = primitive.NewObjectID().Hex()
+	student.CreatedAt = now
+	student.UpdatedAt = now
+	student.MarkAsDeleted = false
+
+	if _, err := m.studentCollection.InsertOne(ctx, student); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrStudentDuplicate
+		}
+		return nil, fmt.Errorf("failed to insert student '%s': %v", student.RollNumber, err)
+	}
+	return student, nil
+}
+
+func (m *StudentDBManager) FetchStudentByID(_id string, ctx context.Context) (*Student.StudentData, error) {
+	var student Student.StudentData
+	err := m.studentCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&student)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrStudentNotFound
+		}
+		return nil, err
+	}
+	return &student, nil
+}
+
+func (m *StudentDBManager) FetchStudentsByID(ids []string, ctx context.Context) ([]Student.StudentData, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	cursor, err := m.studentCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var students []Student.StudentData
+	if err = cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+	return students, nil
+}
+
+func (m *StudentDBManager) UpdateStudent(_id string, update *Student.StudentUpdate, ctx context.Context) (*Student.StudentData, error) {
+	set := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.RollNumber != nil {
+		set["roll_number"] = *update.RollNumber
+	}
+	if update.CollageUniqueName != nil {
+		set["collage_unique_name"] = *update.CollageUniqueName
+	}
+	if update.Gender != nil {
+		set["gender"] = *update.Gender
+	}
+	if update.Year != nil {
+		set["year"] = *update.Year
+	}
+	if update.Phone != nil {
+		set["phone"] = *update.Phone
+	}
+	if update.Guardians != nil {
+		set["guardians"] = *update.Guardians
+	}
+	if update.IDProofReference != nil {
+		set["id_proof_reference"] = *update.IDProofReference
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var student Student.StudentData
+	err := m.studentCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "mark_as_deleted": false}, bson.M{"$set": set}, opts).Decode(&student)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrStudentNotFound
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrStudentDuplicate
+		}
+		return nil, err
+	}
+	return &student, nil
+}
+
+func (m *StudentDBManager) DeleteStudent(_id string, ctx context.Context) error {
+	update := bson.M{"$set": bson.M{"mark_as_deleted": true, "updated_at": time.Now()}}
+	result, err := m.studentCollection.UpdateOne(ctx, bson.M{"_id": _id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrStudentNotFound
+	}
+	return nil
+}
+
+func (m *StudentDBManager) FetchStudents(filter *Student.StudentFilter, ctx context.Context) ([]Student.StudentData, error) {
+	page, limit := studentPage(filter)
+	skip := (page - 1) * limit
+
+	// Build MongoDB filter
+	query := bson.M{"mark_as_deleted": filter.MarkAsDeleted}
+	if filter.CollageUniqueName != "" {
+		query["collage_unique_name"] = filter.CollageUniqueName
+	}
+	if filter.Gender != "" {
+		query["gender"] = filter.Gender
+	}
+	if filter.Year != 0 {
+		query["year"] = filter.Year
+	}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = []bson.M{
+			{"name": pattern},
+			{"roll_number": pattern},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "collage_unique_name", Value: 1}, {Key: "roll_number", Value: 1}}).
+		SetLimit(limit).
+		SetSkip(skip)
+	cursor, err := m.studentCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var students []Student.StudentData
+	if err = cursor.All(ctx, &students); err != nil {
+		return nil, err
+	}
+	return students, nil
+}
+
+// studentPage clamp page and limit the same way FetchCollege does
+func studentPage(filter *Student.StudentFilter) (int64, int64) {
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := filter.Limit
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+	return page, limit
+}
@@ -0,0 +1,10 @@
+package Student
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+func TestStudentDBManager(t *testing.T) {
+	testStudentManager(t, NewStudentDBManager(testutil.MongoClient(t)))
+}
@@ -0,0 +1,176 @@
+package Student
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StudentMemoryManager is the in-memory IStudentDBService
+type StudentMemoryManager struct {
+	mu       sync.RWMutex
+	students map[string]*Student.StudentData // key is _id
+}
+
+func NewStudentMemoryManager() *StudentMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("StudentMemoryManager"))
+	instance := &StudentMemoryManager{
+		students: make(map[string]*Student.StudentData),
+	}
+	slog.Info(LogHelper.LogServiceStarted("StudentMemoryManager"))
+	return instance
+}
+
+// rollNumberTaken emulate the unique (collage_unique_name, roll_number) index
+func (m *StudentMemoryManager) rollNumberTaken(collageUniqueName string, rollNumber string, exceptID string) bool {
+	for _id, student := range m.students {
+		if _id != exceptID && student.CollageUniqueName == collageUniqueName && student.RollNumber == rollNumber {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *StudentMemoryManager) AddStudent(student *Student.StudentData, ctx context.Context) (*Student.StudentData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rollNumberTaken(student.CollageUniqueName, student.RollNumber, "") {
+		return nil, ErrStudentDuplicate
+	}
+	now := time.Now()
+	student.ID = primitive.NewObjectID().Hex()
+	student.CreatedAt = now
+	student.UpdatedAt = now
+	student.MarkAsDeleted = false
+
+	stored := *student
+	m.students[stored.ID] = &stored
+	return student, nil
+}
+
+func (m *StudentMemoryManager) FetchStudentByID(_id string, ctx context.Context) (*Student.StudentData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.students[_id]
+	if !ok {
+		return nil, ErrStudentNotFound
+	}
+	student := *stored
+	return &student, nil
+}
+
+func (m *StudentMemoryManager) FetchStudentsByID(ids []string, ctx context.Context) ([]Student.StudentData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var students []Student.StudentData
+	for _, _id := range ids {
+		if stored, ok := m.students[_id]; ok {
+			students = append(students, *stored)
+		}
+	}
+	return students, nil
+}
+
+func (m *StudentMemoryManager) UpdateStudent(_id string, update *Student.StudentUpdate, ctx context.Context) (*Student.StudentData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.students[_id]
+	if !ok || stored.MarkAsDeleted {
+		return nil, ErrStudentNotFound
+	}
+
+	updated := *stored
+	if update.Name != nil {
+		updated.Name = *update.Name
+	}
+	if update.RollNumber != nil {
+		updated.RollNumber = *update.RollNumber
+	}
+	if update.CollageUniqueName != nil {
+		updated.CollageUniqueName = *update.CollageUniqueName
+	}
+	if update.Gender != nil {
+		updated.Gender = *update.Gender
+	}
+	if update.Year != nil {
+		updated.Year = *update.Year
+	}
+	if update.Phone != nil {
+		updated.Phone = *update.Phone
+	}
+	if update.Guardians != nil {
+		updated.Guardians = *update.Guardians
+	}
+	if update.IDProofReference != nil {
+		updated.IDProofReference = *update.IDProofReference
+	}
+	if m.rollNumberTaken(updated.CollageUniqueName, updated.RollNumber, _id) {
+		return nil, ErrStudentDuplicate
+	}
+	updated.UpdatedAt = time.Now()
+	*stored = updated
+	return &updated, nil
+}
+
+func (m *StudentMemoryManager) DeleteStudent(_id string, ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.students[_id]
+	if !ok {
+		return ErrStudentNotFound
+	}
+	stored.MarkAsDeleted = true
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (m *StudentMemoryManager) FetchStudents(filter *Student.StudentFilter, ctx context.Context) ([]Student.StudentData, error) {
+	page, limit := studentPage(filter)
+	skip := (page - 1) * limit
+	search := strings.ToLower(filter.Search)
+
+	m.mu.RLock()
+	var matched []Student.StudentData
+	for _, student := range m.students {
+		if student.MarkAsDeleted != filter.MarkAsDeleted {
+			continue
+		}
+		if filter.CollageUniqueName != "" && student.CollageUniqueName != filter.CollageUniqueName {
+			continue
+		}
+		if filter.Gender != "" && student.Gender != filter.Gender {
+			continue
+		}
+		if filter.Year != 0 && student.Year != filter.Year {
+			continue
+		}
+		if search != "" &&
+			!strings.Contains(strings.ToLower(student.Name), search) &&
+			!strings.Contains(strings.ToLower(student.RollNumber), search) {
+			continue
+		}
+		matched = append(matched, *student)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CollageUniqueName != matched[j].CollageUniqueName {
+			return matched[i].CollageUniqueName < matched[j].CollageUniqueName
+		}
+		return matched[i].RollNumber < matched[j].RollNumber
+	})
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
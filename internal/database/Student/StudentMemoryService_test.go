@@ -0,0 +1,64 @@
+package Student
+
+import (
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStudentMemoryManager(t *testing.T) {
+	testStudentManager(t, NewStudentMemoryManager())
+}
+
+// testStudentManager check an empty IStudentDBService
+func testStudentManager(t *testing.T, m IStudentDBService) {
+	ctx := context.Background()
+	var ids []string
+	for _, roll := range []string{"R-2", "R-1", "R-3"} {
+		student, err := m.AddStudent(&Student.StudentData{Name: "Student " + roll, RollNumber: roll, CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+		if err != nil {
+			t.Fatalf("AddStudent failed. Err: %v", err)
+		}
+		ids = append(ids, student.ID)
+	}
+	if _, err := m.AddStudent(&Student.StudentData{Name: "Again", RollNumber: "R-1", CollageUniqueName: "college-a"}, ctx); !errors.Is(err, ErrStudentDuplicate) {
+		t.Fatalf("expected ErrStudentDuplicate for a roll number taken in the college; got %v", err)
+	}
+	if _, err := m.AddStudent(&Student.StudentData{Name: "Other college", RollNumber: "R-1", CollageUniqueName: "college-b"}, ctx); err != nil {
+		t.Fatalf("expected the roll number to be free in another college. Err: %v", err)
+	}
+
+	taken := "R-2"
+	if _, err := m.UpdateStudent(ids[2], &Student.StudentUpdate{RollNumber: &taken}, ctx); !errors.Is(err, ErrStudentDuplicate) {
+		t.Fatalf("expected ErrStudentDuplicate renaming to a taken roll number; got %v", err)
+	}
+	year := 2
+	updated, err := m.UpdateStudent(ids[0], &Student.StudentUpdate{Year: &year}, ctx)
+	if err != nil || updated.Year != 2 || updated.RollNumber != "R-2" {
+		t.Fatalf("unexpected partial update %+v. Err: %v", updated, err)
+	}
+
+	if err = m.DeleteStudent(ids[1], ctx); err != nil {
+		t.Fatalf("DeleteStudent failed. Err: %v", err)
+	}
+	if _, err = m.UpdateStudent(ids[1], &Student.StudentUpdate{Year: &year}, ctx); !errors.Is(err, ErrStudentNotFound) {
+		t.Fatalf("expected ErrStudentNotFound updating a deleted student; got %v", err)
+	}
+	students, err := m.FetchStudents(&Student.StudentFilter{Page: 1, Limit: 10, CollageUniqueName: "college-a"}, ctx)
+	if err != nil || len(students) != 2 || students[0].RollNumber != "R-2" || students[1].RollNumber != "R-3" {
+		t.Fatalf("expected the live students of college-a by roll number; got %+v. Err: %v", students, err)
+	}
+	students, _ = m.FetchStudents(&Student.StudentFilter{Page: 1, Limit: 10, Search: "r-3"}, ctx)
+	if len(students) != 1 || students[0].ID != ids[2] {
+		t.Fatalf("expected the search to match the roll number; got %+v", students)
+	}
+	students, _ = m.FetchStudents(&Student.StudentFilter{Page: 1, Limit: 10, MarkAsDeleted: true}, ctx)
+	if len(students) != 1 || students[0].ID != ids[1] {
+		t.Fatalf("expected only the deleted student; got %+v", students)
+	}
+	students, err = m.FetchStudentsByID([]string{ids[1], "missing", ids[2]}, ctx)
+	if err != nil || len(students) != 2 {
+		t.Fatalf("expected the deleted and the live student by id; got %+v. Err: %v", students, err)
+	}
+}
@@ -0,0 +1,40 @@
+package Ticket
+
+import (
+	"HostelApp/internal/storageData/Ticket"
+	"context"
+	"errors"
+)
+
+var (
+	ErrTicketNotFound      = errors.New("ticket not found")
+	ErrTicketStatusChanged = errors.New("ticket is no longer in the expected status")
+	ErrTooManyAttachments  = errors.New("ticket already has the maximum number of attachments")
+)
+
+const MaxAttachments = 10
+
+// activeStatuses are the statuses where the SLA clock is running
+var activeStatuses = []Ticket.TicketStatus{Ticket.Open, Ticket.Assigned, Ticket.InProgress, Ticket.Reopened}
+
+// ITicketDBService is the storage of complaint tickets and their comment threads
+type ITicketDBService interface {
+	CreateTicket(ticket *Ticket.TicketData, ctx context.Context) (*Ticket.TicketData, error)
+	FetchTicketByID(_id string, ctx context.Context) (*Ticket.TicketData, error)
+	FetchTickets(filter *Ticket.TicketFilter, ctx context.Context) ([]Ticket.TicketData, error)
+	// TransitionTicket apply the change only when the ticket is still in status from
+	TransitionTicket(_id string, from Ticket.TicketStatus, change *Ticket.TicketChange, ctx context.Context) (*Ticket.TicketData, error)
+	AddAttachment(_id string, attachment *Ticket.Attachment, ctx context.Context) (*Ticket.TicketData, error)
+	AddComment(comment *Ticket.CommentData, ctx context.Context) (*Ticket.CommentData, error)
+	FetchComments(ticketID string, page int64, limit int64, ctx context.Context) ([]Ticket.CommentData, error)
+}
+
+// IsActive tell if the SLA clock of a ticket in this status is running
+func IsActive(status Ticket.TicketStatus) bool {
+	for _, active := range activeStatuses {
+		if status == active {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package Ticket
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Ticket"
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"log/slog"
+	"time"
+)
+
+type TicketDBManager struct {
+	client            *mongo.Client
+	ticketCollection  *mongo.Collection
+	commentCollection *mongo.Collection
+}
+
+func NewTicketDBManager(client *mongo.Client) *TicketDBManager {
+	slog.Info(LogHelper.LogServiceStarting("TicketDBManager"))
+	instance := &TicketDBManager{
+		client: client,
+	}
+	instance.init()
+	slog.Info(LogHelper.LogServiceStarted("TicketDBManager"))
+	return instance
+}
+
+func (m *TicketDBManager) init() {
+	m.ticketCollection = m.client.Database("hosteldb").Collection("tickets")
+	m.commentCollection = m.client.Database("hosteldb").Collection("ticket_comments")
+	err := m.createIndexes()
+	if err != nil {
+		log.Panicf("!!!panic %v", err)
+	}
+}
+
+func (m *TicketDBManager) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ticketIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "due_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "hostel_id", Value: 1}, {Key: "due_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "assigned_to", Value: 1}, {Key: "due_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "room_id", Value: 1}},
+		},
+	}
+	if _, err := m.ticketCollection.Indexes().CreateMany(ctx, ticketIndexes); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for ticketDB error: %v", err)))
+	}
+	commentIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "ticket_id", Value: 1}, {Key: "_id", Value: 1}},
+	}
+	if _, err := m.commentCollection.Indexes().CreateOne(ctx, commentIndex); err != nil {
+		return fmt.Errorf("%s", LogColor.Red(fmt.Sprintf("failed to create indexes for ticketDB error: %v", err)))
+	}
+	return nil
+}
+
+func (m *TicketDBManager) CreateTicket(ticket *Ticket.TicketData, ctx context.Context) (*Ticket.TicketData, error) {
+	now := time.Now()
+	ticket.ID = primitive.NewObjectID().Hex()
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	if ticket.Attachments == nil {
+		ticket.Attachments = []Ticket.Attachment{}
+	}
+	if _, err := m.ticketCollection.InsertOne(ctx, ticket); err != nil {
+		return nil, fmt.Errorf("failed to insert ticket: %v", err)
+	}
+	return ticket, nil
+}
+
+func (m *TicketDBManager) FetchTicketByID(_id string, ctx context.Context) (*Ticket.TicketData, error) {
+	var ticket Ticket.TicketData
+	err := m.ticketCollection.FindOne(ctx, bson.M{"_id": _id}).Decode(&ticket)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (m *TicketDBManager) FetchTickets(filter *Ticket.TicketFilter, ctx context.Context) ([]Ticket.TicketData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+	if filter.Priority != "" {
+		query["priority"] = filter.Priority
+	}
+	if filter.HostelID != "" {
+		query["hostel_id"] = filter.HostelID
+	}
+	if filter.RoomID != "" {
+		query["room_id"] = filter.RoomID
+	}
+	if filter.AssignedTo != "" {
+		query["assigned_to"] = filter.AssignedTo
+	}
+	if filter.Overdue {
+		query["due_at"] = bson.M{"$lt": time.Now()}
+		if filter.Status == "" {
+			query["status"] = bson.M{"$in": activeStatuses}
+		} else if !IsActive(filter.Status) {
+			return nil, nil
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "due_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.ticketCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var tickets []Ticket.TicketData
+	if err = cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (m *TicketDBManager) TransitionTicket(_id string, from Ticket.TicketStatus, change *Ticket.TicketChange, ctx context.Context) (*Ticket.TicketData, error) {
+	set := bson.M{"status": change.To, "updated_at": time.Now()}
+	if change.AssignedTo != nil {
+		set["assigned_to"] = *change.AssignedTo
+	}
+	if change.DueAt != nil {
+		set["due_at"] = *change.DueAt
+	}
+	if change.ResolvedAt != nil {
+		set["resolved_at"] = *change.ResolvedAt
+	}
+	if change.ClosedAt != nil {
+		set["closed_at"] = *change.ClosedAt
+	}
+	update := bson.M{"$set": set, "$push": bson.M{"history": change.Entry}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var ticket Ticket.TicketData
+	err := m.ticketCollection.FindOneAndUpdate(ctx, bson.M{"_id": _id, "status": from}, update, opts).Decode(&ticket)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchTicketByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrTicketStatusChanged
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (m *TicketDBManager) AddAttachment(_id string, attachment *Ticket.Attachment, ctx context.Context) (*Ticket.TicketData, error) {
+	// the position check keep the limit without reading the ticket first
+	query := bson.M{"_id": _id, fmt.Sprintf("attachments.%d", MaxAttachments-1): bson.M{"$exists": false}}
+	update := bson.M{"$push": bson.M{"attachments": attachment}, "$set": bson.M{"updated_at": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var ticket Ticket.TicketData
+	err := m.ticketCollection.FindOneAndUpdate(ctx, query, update, opts).Decode(&ticket)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, fetchErr := m.FetchTicketByID(_id, ctx); fetchErr != nil {
+				return nil, fetchErr
+			}
+			return nil, ErrTooManyAttachments
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (m *TicketDBManager) AddComment(comment *Ticket.CommentData, ctx context.Context) (*Ticket.CommentData, error) {
+	if _, err := m.FetchTicketByID(comment.TicketID, ctx); err != nil {
+		return nil, err
+	}
+	comment.ID = primitive.NewObjectID().Hex()
+	comment.CreatedAt = time.Now()
+	if _, err := m.commentCollection.InsertOne(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to insert comment: %v", err)
+	}
+	return comment, nil
+}
+
+func (m *TicketDBManager) FetchComments(ticketID string, page int64, limit int64, ctx context.Context) ([]Ticket.CommentData, error) {
+	page, limit = Listing.PageOf(page, limit, 50)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetSkip((page - 1) * limit)
+	cursor, err := m.commentCollection.Find(ctx, bson.M{"ticket_id": ticketID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var comments []Ticket.CommentData
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
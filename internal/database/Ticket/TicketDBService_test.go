@@ -0,0 +1,11 @@
+package Ticket
+
+import (
+	"HostelApp/internal/testutil"
+	"testing"
+)
+
+// TestTicketDBManager run the checks on the conditional status update and attachment cap
+func TestTicketDBManager(t *testing.T) {
+	testTicketManager(t, NewTicketDBManager(testutil.MongoClient(t)))
+}
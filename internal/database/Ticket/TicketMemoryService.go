@@ -0,0 +1,183 @@
+package Ticket
+
+import (
+	"HostelApp/LogHelper"
+	"HostelApp/internal/storageData/Listing"
+	"HostelApp/internal/storageData/Ticket"
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TicketMemoryManager is the in-memory ITicketDBService
+type TicketMemoryManager struct {
+	mu       sync.RWMutex
+	tickets  map[string]*Ticket.TicketData   // key is _id
+	comments map[string][]Ticket.CommentData // key is ticket _id, oldest first
+}
+
+func NewTicketMemoryManager() *TicketMemoryManager {
+	slog.Info(LogHelper.LogServiceStarting("TicketMemoryManager"))
+	instance := &TicketMemoryManager{
+		tickets:  make(map[string]*Ticket.TicketData),
+		comments: make(map[string][]Ticket.CommentData),
+	}
+	slog.Info(LogHelper.LogServiceStarted("TicketMemoryManager"))
+	return instance
+}
+
+// copyTicket detach the slices so callers can not modify the stored ticket
+func copyTicket(stored *Ticket.TicketData) *Ticket.TicketData {
+	ticket := *stored
+	ticket.Attachments = append([]Ticket.Attachment{}, stored.Attachments...)
+	ticket.History = append([]Ticket.StatusChange{}, stored.History...)
+	return &ticket
+}
+
+func (m *TicketMemoryManager) CreateTicket(ticket *Ticket.TicketData, ctx context.Context) (*Ticket.TicketData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	ticket.ID = primitive.NewObjectID().Hex()
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+	if ticket.Attachments == nil {
+		ticket.Attachments = []Ticket.Attachment{}
+	}
+	m.tickets[ticket.ID] = copyTicket(ticket)
+	return ticket, nil
+}
+
+func (m *TicketMemoryManager) FetchTicketByID(_id string, ctx context.Context) (*Ticket.TicketData, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.tickets[_id]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	return copyTicket(stored), nil
+}
+
+func (m *TicketMemoryManager) FetchTickets(filter *Ticket.TicketFilter, ctx context.Context) ([]Ticket.TicketData, error) {
+	page, limit := Listing.PageOf(filter.Page, filter.Limit, 50)
+	now := time.Now()
+
+	m.mu.RLock()
+	var matched []Ticket.TicketData
+	for _, stored := range m.tickets {
+		if filter.Status != "" && stored.Status != filter.Status {
+			continue
+		}
+		if filter.Category != "" && stored.Category != filter.Category {
+			continue
+		}
+		if filter.Priority != "" && stored.Priority != filter.Priority {
+			continue
+		}
+		if filter.HostelID != "" && stored.HostelID != filter.HostelID {
+			continue
+		}
+		if filter.RoomID != "" && stored.RoomID != filter.RoomID {
+			continue
+		}
+		if filter.AssignedTo != "" && stored.AssignedTo != filter.AssignedTo {
+			continue
+		}
+		if filter.Overdue && (!IsActive(stored.Status) || !stored.DueAt.Before(now)) {
+			continue
+		}
+		matched = append(matched, *copyTicket(stored))
+	}
+	m.mu.RUnlock()
+
+	// same order as the mongo query, closest deadline first
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].DueAt.Equal(matched[j].DueAt) {
+			return matched[i].DueAt.Before(matched[j].DueAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	skip := (page - 1) * limit
+	if skip >= int64(len(matched)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+	return matched[skip:end], nil
+}
+
+func (m *TicketMemoryManager) TransitionTicket(_id string, from Ticket.TicketStatus, change *Ticket.TicketChange, ctx context.Context) (*Ticket.TicketData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.tickets[_id]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	if stored.Status != from {
+		return nil, ErrTicketStatusChanged
+	}
+	stored.Status = change.To
+	if change.AssignedTo != nil {
+		stored.AssignedTo = *change.AssignedTo
+	}
+	if change.DueAt != nil {
+		stored.DueAt = *change.DueAt
+	}
+	if change.ResolvedAt != nil {
+		stored.ResolvedAt = change.ResolvedAt
+	}
+	if change.ClosedAt != nil {
+		stored.ClosedAt = change.ClosedAt
+	}
+	stored.History = append(stored.History, change.Entry)
+	stored.UpdatedAt = time.Now()
+	return copyTicket(stored), nil
+}
+
+func (m *TicketMemoryManager) AddAttachment(_id string, attachment *Ticket.Attachment, ctx context.Context) (*Ticket.TicketData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.tickets[_id]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	if len(stored.Attachments) >= MaxAttachments {
+		return nil, ErrTooManyAttachments
+	}
+	stored.Attachments = append(stored.Attachments, *attachment)
+	stored.UpdatedAt = time.Now()
+	return copyTicket(stored), nil
+}
+
+func (m *TicketMemoryManager) AddComment(comment *Ticket.CommentData, ctx context.Context) (*Ticket.CommentData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tickets[comment.TicketID]; !ok {
+		return nil, ErrTicketNotFound
+	}
+	comment.ID = primitive.NewObjectID().Hex()
+	comment.CreatedAt = time.Now()
+	m.comments[comment.TicketID] = append(m.comments[comment.TicketID], *comment)
+	return comment, nil
+}
+
+func (m *TicketMemoryManager) FetchComments(ticketID string, page int64, limit int64, ctx context.Context) ([]Ticket.CommentData, error) {
+	page, limit = Listing.PageOf(page, limit, 50)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	comments := m.comments[ticketID]
+	skip := (page - 1) * limit
+	if skip >= int64(len(comments)) {
+		return nil, nil
+	}
+	end := skip + limit
+	if end > int64(len(comments)) {
+		end = int64(len(comments))
+	}
+	return append([]Ticket.CommentData{}, comments[skip:end]...), nil
+}
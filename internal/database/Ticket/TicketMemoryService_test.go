@@ -0,0 +1,73 @@
+package Ticket
+
+import (
+	"HostelApp/internal/storageData/Ticket"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTicketMemoryManager(t *testing.T) {
+	testTicketManager(t, NewTicketMemoryManager())
+}
+
+// testTicketManager check an empty ITicketDBService apply a transition only
+// from the expected status and cap the attachments
+func testTicketManager(t *testing.T, m ITicketDBService) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	opened := []Ticket.StatusChange{{To: Ticket.Open, By: "u0", At: past}}
+	ticket, err := m.CreateTicket(&Ticket.TicketData{Title: "Leak", Category: Ticket.Plumbing, Priority: Ticket.Urgent, Status: Ticket.Open, HostelID: "h1", RoomID: "r1", History: opened, DueAt: past}, ctx)
+	if err != nil || ticket.ID == "" {
+		t.Fatalf("CreateTicket failed %+v. Err: %v", ticket, err)
+	}
+	if _, err = m.CreateTicket(&Ticket.TicketData{Title: "Fan", Category: Ticket.Electrical, Priority: Ticket.Low, Status: Ticket.Open, HostelID: "h1", RoomID: "r2", History: opened, DueAt: time.Now().Add(time.Hour)}, ctx); err != nil {
+		t.Fatalf("CreateTicket failed. Err: %v", err)
+	}
+	if _, err = m.FetchTicketByID("missing", ctx); !errors.Is(err, ErrTicketNotFound) {
+		t.Fatalf("expected ErrTicketNotFound; got %v", err)
+	}
+	if overdue, err := m.FetchTickets(&Ticket.TicketFilter{Page: 1, Limit: 10, Overdue: true}, ctx); err != nil || len(overdue) != 1 || overdue[0].ID != ticket.ID {
+		t.Fatalf("expected only the late ticket overdue; got %+v %v", overdue, err)
+	}
+
+	assignee := "u1"
+	change := &Ticket.TicketChange{To: Ticket.Assigned, AssignedTo: &assignee, Entry: Ticket.StatusChange{From: Ticket.Open, To: Ticket.Assigned, By: "u0", AssignedTo: assignee}}
+	assigned, err := m.TransitionTicket(ticket.ID, Ticket.Open, change, ctx)
+	if err != nil || assigned.Status != Ticket.Assigned || assigned.AssignedTo != assignee || len(assigned.History) != 2 {
+		t.Fatalf("TransitionTicket failed %+v. Err: %v", assigned, err)
+	}
+	if _, err = m.TransitionTicket(ticket.ID, Ticket.Open, change, ctx); !errors.Is(err, ErrTicketStatusChanged) {
+		t.Fatalf("expected ErrTicketStatusChanged from a stale status; got %v", err)
+	}
+	resolvedAt := time.Now()
+	resolved, err := m.TransitionTicket(ticket.ID, Ticket.Assigned, &Ticket.TicketChange{To: Ticket.Resolved, ResolvedAt: &resolvedAt, Entry: Ticket.StatusChange{From: Ticket.Assigned, To: Ticket.Resolved, By: "u1"}}, ctx)
+	if err != nil || resolved.ResolvedAt == nil || len(resolved.History) != 3 {
+		t.Fatalf("TransitionTicket failed %+v. Err: %v", resolved, err)
+	}
+	if overdue, err := m.FetchTickets(&Ticket.TicketFilter{Page: 1, Limit: 10, Overdue: true}, ctx); err != nil || len(overdue) != 0 {
+		t.Fatalf("expected a resolved ticket not overdue; got %+v %v", overdue, err)
+	}
+
+	for i := 0; i < MaxAttachments; i++ {
+		if _, err = m.AddAttachment(ticket.ID, &Ticket.Attachment{Name: "photo.jpg", URL: "https://files.example.com/photo.jpg"}, ctx); err != nil {
+			t.Fatalf("AddAttachment %d failed. Err: %v", i, err)
+		}
+	}
+	if _, err = m.AddAttachment(ticket.ID, &Ticket.Attachment{Name: "extra.jpg", URL: "https://files.example.com/extra.jpg"}, ctx); !errors.Is(err, ErrTooManyAttachments) {
+		t.Fatalf("expected ErrTooManyAttachments; got %v", err)
+	}
+
+	if _, err = m.AddComment(&Ticket.CommentData{TicketID: "missing", Author: "u1", Body: "lost"}, ctx); !errors.Is(err, ErrTicketNotFound) {
+		t.Fatalf("expected ErrTicketNotFound commenting an unknown ticket; got %v", err)
+	}
+	for _, body := range []string{"first", "second"} {
+		if _, err = m.AddComment(&Ticket.CommentData{TicketID: ticket.ID, Author: "u1", Body: body}, ctx); err != nil {
+			t.Fatalf("AddComment failed. Err: %v", err)
+		}
+	}
+	if comments, err := m.FetchComments(ticket.ID, 1, 10, ctx); err != nil || len(comments) != 2 || comments[0].Body != "first" {
+		t.Fatalf("expected 2 comments oldest first; got %+v %v", comments, err)
+	}
+}
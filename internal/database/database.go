@@ -4,6 +4,16 @@ import (
 	"HostelApp/LogColor"
 	"HostelApp/LogHelper"
 	"HostelApp/internal/database/Admin"
+	"HostelApp/internal/database/Allocation"
+	"HostelApp/internal/database/Attendance"
+	"HostelApp/internal/database/Audit"
+	"HostelApp/internal/database/Finance"
+	"HostelApp/internal/database/Gate"
+	"HostelApp/internal/database/Hostel"
+	"HostelApp/internal/database/Mess"
+	"HostelApp/internal/database/Payment"
+	"HostelApp/internal/database/Student"
+	"HostelApp/internal/database/Ticket"
 	"context"
 	"fmt"
 	"log"
@@ -18,8 +28,18 @@ import (
 )
 
 type DBService struct {
-	db      *mongo.Client
-	AdminDB *Admin.DbManager
+	db           *mongo.Client
+	AdminDB      *Admin.DbManager
+	StudentDB    Student.IStudentDBService
+	HostelDB     Hostel.IHostelDBService
+	AllocationDB Allocation.IAllocationDBService
+	FinanceDB    Finance.IFinanceDBService
+	PaymentDB    Payment.IPaymentDBService
+	TicketDB     Ticket.ITicketDBService
+	GateDB       Gate.IGateDBService
+	AttendanceDB Attendance.IAttendanceDBService
+	MessDB       Mess.IMessDBService
+	AuditDB      Audit.IAuditDBService
 }
 
 var (
@@ -27,6 +47,7 @@ var (
 	password = os.Getenv("BLUEPRINT_DB_ROOT_PASSWORD")
 	host     = os.Getenv("BLUEPRINT_DB_HOST")
 	port     = os.Getenv("BLUEPRINT_DB_PORT")
+	driver   = os.Getenv("BLUEPRINT_DB_DRIVER") // "memory" run without MongoDB
 )
 
 func IsRunningInDocker() bool {
@@ -44,6 +65,9 @@ func IsRunningInDocker() bool {
 }
 
 func NewDBService() *DBService {
+	if driver == "memory" {
+		return NewMemoryDBService()
+	}
 	slog.Info(LogHelper.LogServiceStarted("Database"))
 	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
 	defer cancel()
@@ -55,25 +79,55 @@ func NewDBService() *DBService {
 	slog.Info(LogColor.Yellow("Connecting to MongoDB url:" + uri + "\n"))
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
 	if err != nil {
-		log.Panicf(LogHelper.LogPanic("fail to connect to mongo" + err.Error()))
+		log.Panic(LogHelper.LogPanic("fail to connect to mongo" + err.Error()))
 	}
 	if errPing := client.Ping(ctx, nil); errPing != nil {
 		newClient, errFallback := fallBack(ctx)
 		if errFallback != nil {
-			log.Panicf(LogColor.Red("!!Panic!! fail to ping MongoDB error: " + errFallback.Error()))
+			log.Panic(LogColor.Red("!!Panic!! fail to ping MongoDB error: " + errFallback.Error()))
 			return nil
 		} else {
 			slog.Info(LogHelper.LogServiceStarted("Database fall back"))
-			return &DBService{
-				db:      newClient,
-				AdminDB: Admin.NewService(client),
-			}
+			return newMongoDBService(newClient)
 		}
 	}
 	slog.Info(LogHelper.LogServiceStarted("Database"))
+	return newMongoDBService(client)
+}
+
+// newMongoDBService build every MongoDB manager on the connected client
+func newMongoDBService(client *mongo.Client) *DBService {
 	return &DBService{
-		db:      client,
-		AdminDB: Admin.NewService(client),
+		db:           client,
+		AdminDB:      Admin.NewService(client),
+		StudentDB:    Student.NewStudentDBManager(client),
+		HostelDB:     Hostel.NewHostelDBManager(client),
+		AllocationDB: Allocation.NewAllocationDBManager(client),
+		FinanceDB:    Finance.NewFinanceDBManager(client),
+		PaymentDB:    Payment.NewPaymentDBManager(client),
+		TicketDB:     Ticket.NewTicketDBManager(client),
+		GateDB:       Gate.NewGateDBManager(client),
+		AttendanceDB: Attendance.NewAttendanceDBManager(client),
+		MessDB:       Mess.NewMessDBManager(client),
+		AuditDB:      Audit.NewAuditDBManager(client),
+	}
+}
+
+// NewMemoryDBService keep all data in process memory, nothing survive a restart
+func NewMemoryDBService() *DBService {
+	slog.Info(LogColor.Blue("Running with in-memory database ..."))
+	return &DBService{
+		AdminDB:      Admin.NewMemoryService(),
+		StudentDB:    Student.NewStudentMemoryManager(),
+		HostelDB:     Hostel.NewHostelMemoryManager(),
+		AllocationDB: Allocation.NewAllocationMemoryManager(),
+		FinanceDB:    Finance.NewFinanceMemoryManager(),
+		PaymentDB:    Payment.NewPaymentMemoryManager(),
+		TicketDB:     Ticket.NewTicketMemoryManager(),
+		GateDB:       Gate.NewGateMemoryManager(),
+		AttendanceDB: Attendance.NewAttendanceMemoryManager(),
+		MessDB:       Mess.NewMessMemoryManager(),
+		AuditDB:      Audit.NewAuditMemoryManager(),
 	}
 }
 
@@ -82,7 +136,7 @@ func fallBack(ctx context.Context) (*mongo.Client, error) {
 	slog.Info(LogColor.Yellow("Connecting to MongoDB url:" + uri + "\n"))
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
 	if err != nil {
-		log.Panicf(LogHelper.LogPanic("fail to connect to mongo" + err.Error()))
+		log.Panic(LogHelper.LogPanic("fail to connect to mongo" + err.Error()))
 		return nil, err
 	}
 	if errPing := client.Ping(ctx, nil); errPing != nil {
@@ -92,6 +146,11 @@ func fallBack(ctx context.Context) (*mongo.Client, error) {
 }
 
 func (s *DBService) Health() map[string]string {
+	if s.db == nil {
+		return map[string]string{
+			"message": "It's healthy",
+		}
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
@@ -6,26 +6,34 @@ import (
 	"HostelApp/internal/database/Admin"
 	"HostelApp/internal/server/Admin/AuthenticationSystem"
 	"HostelApp/internal/server/Admin/CollegeSystem"
+	"HostelApp/internal/server/Admin/UserSystem"
 )
 
 type AdminManager struct {
 	auth       *AuthenticationSystem.AuthenticationManager
 	collageMng *CollegeSystem.CollegeManager
+	userMng    *UserSystem.UserManager
 }
 
 func (a AdminManager) GetFiberRoutes() *[]internal.APIRoute {
 	authRoutes := a.auth.GetFiberRoutes()
 	collegeRoutes := a.collageMng.GetFiberRoutes()
+	userRoutes := a.userMng.GetFiberRoutes()
 
-	// Combine both slices into a new one
+	// Combine the slices into a new one
 	allRoutes := append(*authRoutes, *collegeRoutes...)
+	allRoutes = append(allRoutes, *userRoutes...)
 	return &allRoutes
 }
 
-func NewAdminManager(adminDb *Admin.DbManager) *AdminManager {
-	jwtManager := JWTManager.NewJWTManager("", 30, 30)
+func NewAdminManager(adminDb *Admin.DbManager, jwtManager *JWTManager.JWTManager, loginPolicy AuthenticationSystem.LoginPolicy) *AdminManager {
+	auth := AuthenticationSystem.NewAuthenticationManager(adminDb.LoginDB, adminDb.AttemptDB, adminDb.SessionDB, jwtManager, loginPolicy)
+	// access tokens of a revoked session are refused at once
+	jwtManager.UseSessionChecker(auth.CheckSession)
+	jwtManager.UseRevocationList(adminDb.RevocationDB)
 	return &AdminManager{
-		auth:       AuthenticationSystem.NewAuthenticationManager(adminDb.LoginDB, jwtManager),
+		auth:       auth,
 		collageMng: CollegeSystem.NewCollegeManager(adminDb.CollegeDB, jwtManager),
+		userMng:    UserSystem.NewUserManager(adminDb.LoginDB, adminDb.AttemptDB, adminDb.SessionDB, jwtManager),
 	}
 }
@@ -5,39 +5,56 @@ import (
 	"HostelApp/internal/JWTManager"
 	"HostelApp/internal/ValidatorSystem"
 	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/server/Audit"
 	"HostelApp/internal/storageData/Admin"
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
 )
 
 type AuthenticationManager struct {
-	dbManager  *AdminDB.LoginDBManager
+	dbManager  AdminDB.ILoginDBService
+	sessions   AdminDB.ISessionDBService
 	jwtManager *JWTManager.JWTManager
+	throttle   *loginThrottle
 }
 
 func (m *AuthenticationManager) GetFiberRoutes() *[]internal.APIRoute {
-	return &[]internal.APIRoute{
-		{"/admin/login", internal.POST, m.login},
-		{"/admin/User", internal.POST, m.createUser},
-		{"/admin/logout", internal.POST, m.logout},
+	routes := []internal.APIRoute{
+		{Path: "/admin/login", Method: internal.POST, Handler: m.login},
+		{Path: "/admin/token/refresh", Method: internal.POST, Handler: m.refreshToken},
+		{Path: "/admin/User", Method: internal.POST, Handler: m.createUser, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/logout", Method: internal.POST, Handler: m.logout, Protected: true},
 	}
+	routes = append(routes, m.twoFactorRoutes()...)
+	routes = append(routes, m.sessionRoutes()...)
+	return &routes
 }
 
-func NewAuthenticationManager(dbManager *AdminDB.LoginDBManager, jwtManager *JWTManager.JWTManager) *AuthenticationManager {
+func NewAuthenticationManager(dbManager AdminDB.ILoginDBService, attemptDB AdminDB.IAttemptDBService, sessions AdminDB.ISessionDBService, jwtManager *JWTManager.JWTManager, policy LoginPolicy) *AuthenticationManager {
 	instance := &AuthenticationManager{
 		dbManager:  dbManager,
+		sessions:   sessions,
 		jwtManager: jwtManager,
+		throttle:   &loginThrottle{dbManager: attemptDB, policy: policy},
 	}
 	return instance
 }
 
 // @Summary Admin login
-// @Description Authenticate admin user
+// @Description Authenticate admin user. Repeated failures for a username or from an IP are slowed down then locked for a while
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param credentials body Admin.AdminLogin true "Admin credentials"
-// @Success 200 {object} map[string]interface{} "Returns JWT token"
+// @Success 200 {object} map[string]interface{} "Returns JWT token and refresh token, or a challengeToken for /admin/login/2fa, or an enrollmentToken for /admin/login/2fa/enroll"
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{} "Too many failures, Retry-After give the seconds to wait"
 // @Failure 500 {object} map[string]interface{}
 // @Router /admin/login [post]
 func (s *AuthenticationManager) login(c *fiber.Ctx) error {
@@ -57,18 +74,126 @@ func (s *AuthenticationManager) login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
-	//checking Credentials in DB
+	//refusing early while the username or the IP is backing off or locked,
+	//else the try is counted as a failure until the password is checked
+	try, wait, throttleErr := s.throttle.begin(user.Username, c.IP(), c.Context())
+	if throttleErr != nil {
+		resp := fiber.Map{
+			"message": "failed to fetch login attempts from DB",
+			"error":   throttleErr.Error(),
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(resp)
+	}
+	if wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
+	//checking Credentials in DB, an unknown user, a wrong password and a
+	//disabled user get the same answer
 	_id, validErr := s.dbManager.IsValidCredentials(&user, c.Context())
 	if validErr != nil {
+		if !errors.Is(validErr, AdminDB.ErrAdminNotFound) && !errors.Is(validErr, AdminDB.ErrPasswordMismatch) && !errors.Is(validErr, AdminDB.ErrAdminDisabled) {
+			s.passedTry(try, c)
+			resp := fiber.Map{
+				"message": "failed to validate credentials in DB",
+				"error":   validErr.Error(),
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(resp)
+		}
 		resp := fiber.Map{
-			"message": "failed to validate credentials in DB",
-			"error":   validErr.Error(),
+			"message": "failed to validate credentials",
+			"error":   "invalid username or password",
 		}
-		return c.Status(fiber.StatusBadRequest).JSON(resp)
+		return c.Status(fiber.StatusUnauthorized).JSON(resp)
+	}
+
+	//an admin with 2FA only get a challenge for the second step, failures of
+	//the username are forgotten once it is passed
+	s.passedTry(try, c)
+	admin, adminErr := s.dbManager.FetchAdmin(*_id, c.Context())
+	if adminErr != nil {
+		resp := fiber.Map{
+			"message": "failed to fetch admin from DB",
+			"error":   adminErr.Error(),
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(resp)
 	}
+	if admin.TwoFactor {
+		challenge, challengeErr := s.jwtManager.GenerateChallengeToken(*_id)
+		if challengeErr != nil {
+			resp := fiber.Map{
+				"message": "failed to generate challenge JWT",
+				"error":   challengeErr.Error(),
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(resp)
+		}
+		return c.JSON(fiber.Map{
+			"message":        "two factor code required",
+			"challengeToken": challenge,
+		})
+	}
+	if s.throttle.policy.RequireFullTwoFactor && admin.ExcessLevel == Admin.Full {
+		enrollment, enrollmentErr := s.jwtManager.GenerateEnrollmentToken(*_id)
+		if enrollmentErr != nil {
+			resp := fiber.Map{
+				"message": "failed to generate enrollment JWT",
+				"error":   enrollmentErr.Error(),
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(resp)
+		}
+		return c.JSON(fiber.Map{
+			"message":         "two factor enrollment required",
+			"enrollmentToken": enrollment,
+		})
+	}
+
+	if err := s.throttle.succeeded(user.Username, c.Context()); err != nil {
+		slog.Error(fmt.Sprintf("failed to clear failed logins: %v", err))
+	}
+	return s.startSession(c, *_id, nil)
+}
 
-	//generating new Refresh token
-	refreshToken, refreshJwtErr := s.jwtManager.GenerateRefreshToken("")
+// passedTry take back the try of a login step that was not a wrong guess
+func (s *AuthenticationManager) passedTry(try *loginTry, c *fiber.Ctx) {
+	if err := s.throttle.passed(try, c.Context()); err != nil {
+		slog.Error(fmt.Sprintf("failed to take back a login attempt: %v", err))
+	}
+}
+
+// tooManyAttempts refuse a login step while its username or IP is backing off or locked
+func tooManyAttempts(c *fiber.Ctx, wait time.Duration) error {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	resp := fiber.Map{
+		"message": "too many failed login attempts",
+		"error":   fmt.Sprintf("try again in %d seconds", seconds),
+	}
+	return c.Status(fiber.StatusTooManyRequests).JSON(resp)
+}
+
+// startSession end a successful login, it start a new refresh token family and
+// answer with the JWT, the refresh token and the extra fields
+func (s *AuthenticationManager) startSession(c *fiber.Ctx, _id string, extra fiber.Map) error {
+	//access level is embedded in the JWT for permission checks
+	excessLevel, levelErr := s.dbManager.FetchExcessLevel(_id, c.Context())
+	if levelErr != nil {
+		resp := fiber.Map{
+			"message": "failed to fetch access level from DB",
+			"error":   levelErr.Error(),
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(resp)
+	}
+
+	//generating new Refresh token which start a new session, one per device
+	family, familyErr := JWTManager.NewTokenID()
+	if familyErr != nil {
+		resp := fiber.Map{
+			"message": "failed to generate refresh JWT",
+			"error":   familyErr.Error(),
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(resp)
+	}
+	refreshToken, refreshJwtErr := s.jwtManager.GenerateRefreshToken(_id, family)
 	if refreshJwtErr != nil {
 		resp := fiber.Map{
 			"message": "failed to generate refresh JWT",
@@ -77,34 +202,169 @@ func (s *AuthenticationManager) login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(resp)
 	}
 
-	//Updating new Refresh token to DB
-	dBJwtWriteErr := s.dbManager.UpdateRefreshToken(*_id, refreshToken, c.Context())
-	if dBJwtWriteErr != nil {
+	//storing the session with only a hash of its refresh token
+	now := time.Now()
+	session := &Admin.AdminSession{
+		ID:          family,
+		AdminID:     _id,
+		Created:     now,
+		LastSeen:    now,
+		IP:          c.IP(),
+		UserAgent:   userAgent(c),
+		RefreshHash: JWTManager.HashToken(refreshToken),
+		ExpiresAt:   s.jwtManager.RefreshTokenExpiry(now),
+	}
+	if sessionErr := s.sessions.CreateSession(session, c.Context()); sessionErr != nil {
 		resp := fiber.Map{
-			"message": "failed to update UpdateRefreshToken in DB ",
-			"error":   dBJwtWriteErr.Error(),
+			"message": "failed to create session in DB",
+			"error":   sessionErr.Error(),
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(resp)
 	}
 
 	//generating new JWT token
-	if token, jwtErr := s.jwtManager.GenerateToken(*_id); jwtErr != nil {
+	token, jwtErr := s.jwtManager.GenerateToken(_id, family, []string{JWTManager.RoleAdmin}, excessLevel)
+	if jwtErr != nil {
 		resp := fiber.Map{
 			"message": "failed to generate JWT",
 			"error":   jwtErr.Error(),
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(resp)
-	} else {
-		resp := fiber.Map{
-			"message":  "successfully login",
-			"jwtToken": token,
+	}
+	resp := fiber.Map{
+		"message":      "successfully login",
+		"jwtToken":     token,
+		"refreshToken": refreshToken,
+	}
+	for key, value := range extra {
+		resp[key] = value
+	}
+	return c.JSON(resp)
+}
+
+// @Summary Refresh admin token
+// @Description Exchange a refresh token for a new JWT and refresh token, each refresh token can be used only once and reusing an old one revokes the session
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param refresh body Admin.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} map[string]interface{} "Returns JWT token and refresh token"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/token/refresh [post]
+func (s *AuthenticationManager) refreshToken(c *fiber.Ctx) error {
+	var request Admin.RefreshTokenRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate refresh token in validator",
+			"error":   err.Error(),
+		})
+	}
+
+	claims, jwtErr := s.jwtManager.VerifyRefreshToken(request.RefreshToken, c.Context())
+	if jwtErr != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to validate refresh token at jwt",
+			"error":   jwtErr.Error(),
+		})
+	}
+	_id := claims.Subject
+	family := claims.SessionID
+	if family == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to validate refresh token at jwt",
+			"error":   "refresh token is missing session id",
+		})
+	}
+
+	session, dbErr := s.sessions.FetchSession(family, c.Context())
+	if dbErr != nil && !errors.Is(dbErr, AdminDB.ErrSessionNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch session from DB",
+			"error":   dbErr.Error(),
+		})
+	}
+	if session == nil || session.AdminID != _id {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "refresh token is no longer valid",
+			"error":   "session expired or revoked",
+		})
+	}
+	if session.RefreshHash != JWTManager.HashToken(request.RefreshToken) {
+		return s.revokeRefreshFamily(c, _id, family)
+	}
+
+	newRefreshToken, refreshJwtErr := s.jwtManager.GenerateRefreshToken(_id, family)
+	if refreshJwtErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to generate refresh JWT",
+			"error":   refreshJwtErr.Error(),
+		})
+	}
+	now := time.Now()
+	rotation := &Admin.SessionRotation{
+		OldHash:   session.RefreshHash,
+		NewHash:   JWTManager.HashToken(newRefreshToken),
+		IP:        c.IP(),
+		Now:       now,
+		ExpiresAt: s.jwtManager.RefreshTokenExpiry(now),
+	}
+	if err := s.sessions.RotateSession(family, rotation, c.Context()); err != nil {
+		if errors.Is(err, AdminDB.ErrRefreshTokenMismatch) {
+			// token was rotated by a concurrent request in between, treat it as reuse
+			return s.revokeRefreshFamily(c, _id, family)
 		}
-		return c.JSON(resp)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to rotate refresh token in DB",
+			"error":   err.Error(),
+		})
+	}
+
+	excessLevel, levelErr := s.dbManager.FetchExcessLevel(_id, c.Context())
+	if levelErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch access level from DB",
+			"error":   levelErr.Error(),
+		})
 	}
+	token, jwtErr := s.jwtManager.GenerateToken(_id, family, []string{JWTManager.RoleAdmin}, excessLevel)
+	if jwtErr != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to generate JWT",
+			"error":   jwtErr.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message":      "token refreshed",
+		"jwtToken":     token,
+		"refreshToken": newRefreshToken,
+	})
+}
+
+// revokeRefreshFamily is called when an already rotated refresh token is presented again,
+// the whole session is dropped so both the attacker and the user have to login again
+// on that device
+func (s *AuthenticationManager) revokeRefreshFamily(c *fiber.Ctx, _id string, family string) error {
+	if err := s.closeSession(family, _id, c.Context()); err != nil && !errors.Is(err, AdminDB.ErrSessionNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to revoke refresh session in DB",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"message": "refresh token reuse detected",
+		"error":   "session revoked, login again",
+	})
 }
 
 // @Summary Create admin user
-// @Description Create a new admin user (requires authentication)
+// @Description Create a new admin user (requires Full access level)
 // @Tags admin
 // @Security ApiKeyAuth
 // @Accept json
@@ -114,17 +374,10 @@ func (s *AuthenticationManager) login(c *fiber.Ctx) error {
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /admin/User [post]
 func (s *AuthenticationManager) createUser(c *fiber.Ctx) error {
 	var user Admin.AdminUserDetail
-	authHeader := c.Get("Authorization")
-	if _, jwtErr := s.jwtManager.IsValid(authHeader); jwtErr != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
-			"error":   jwtErr.Error(),
-		})
-	}
-
 	if err := c.BodyParser(&user); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "cannot parse JSON",
@@ -144,6 +397,7 @@ func (s *AuthenticationManager) createUser(c *fiber.Ctx) error {
 		})
 	}
 
+	Audit.Record(c, user.Username, nil, &user)
 	resp := fiber.Map{
 		"message": "user create",
 	}
@@ -152,7 +406,7 @@ func (s *AuthenticationManager) createUser(c *fiber.Ctx) error {
 }
 
 // @Summary Logout admin user
-// @Description this is for logout (requires authentication)
+// @Description Close the session of the token, other devices stay logged in (requires authentication)
 // @Tags admin
 // @Security ApiKeyAuth
 // @Accept json
@@ -163,22 +417,17 @@ func (s *AuthenticationManager) createUser(c *fiber.Ctx) error {
 // @Failure 401 {object} map[string]interface{}
 // @Router /admin/logout [post]
 func (s *AuthenticationManager) logout(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	_id := ""
-	if jwtMap, jwtErr := s.jwtManager.IsValid(authHeader); jwtErr != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
-			"error":   jwtErr.Error(),
-		})
-	} else {
-		_id = jwtMap["_id"].(string)
-	}
+	claims := JWTManager.GetClaims(c)
 
-	//Updating new Refresh token to DB
-	if dBJwtWriteErr := s.dbManager.UpdateRefreshToken(_id, "", c.Context()); dBJwtWriteErr != nil {
+	//revoking the token and its session so its access and refresh tokens stop working
+	dbErr := s.jwtManager.RevokeToken(claims, c.Context())
+	if dbErr == nil {
+		dbErr = s.closeSession(claims.SessionID, claims.Subject, c.Context())
+	}
+	if dbErr != nil && !errors.Is(dbErr, AdminDB.ErrSessionNotFound) {
 		resp := fiber.Map{
-			"message": "failed to update UpdateRefreshToken in DB ",
-			"error":   dBJwtWriteErr.Error(),
+			"message": "failed to delete session in DB",
+			"error":   dbErr.Error(),
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(resp)
 	}
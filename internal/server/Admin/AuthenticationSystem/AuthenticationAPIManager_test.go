@@ -0,0 +1,110 @@
+package AuthenticationSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAdminFlowInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, refreshToken := testutil.Login(t, s, "admin", "password@123")
+
+	// refresh rotate the token, reusing the old one revoke the session family
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/token/refresh", "", map[string]string{"refresh_token": refreshToken})
+	if status != http.StatusOK {
+		t.Fatalf("refresh: expected status OK; got %d %v", status, body)
+	}
+	rotated := testutil.Path[string](t, body, "refreshToken")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/token/refresh", "", map[string]string{"refresh_token": refreshToken}); status != http.StatusUnauthorized {
+		t.Fatalf("refresh reuse: expected status 401; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/token/refresh", "", map[string]string{"refresh_token": rotated}); status != http.StatusUnauthorized {
+		t.Fatalf("refresh after revoke: expected status 401; got %d", status)
+	}
+	// the access token of the revoked session stop working at once
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/me", token, nil); status != http.StatusUnauthorized {
+		t.Fatalf("access token of a revoked session: expected status 401; got %d", status)
+	}
+	token, _ = testutil.Login(t, s, "admin", "password@123")
+
+	readOnly := map[string]interface{}{"username": "viewer", "email": "viewer@admin.com", "password": "password@123", "excess_level": 2}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/User", token, readOnly); status != http.StatusCreated {
+		t.Fatalf("create user: expected status 201; got %d %v", status, body)
+	}
+	viewerToken, _ := testutil.Login(t, s, "viewer", "password@123")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/User", viewerToken, readOnly); status != http.StatusForbidden {
+		t.Fatalf("create user as read only: expected status 403; got %d", status)
+	}
+
+	colleges := []map[string]interface{}{testutil.CollegeRow("college-a")}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/college", viewerToken, colleges); status != http.StatusForbidden {
+		t.Fatalf("add college as read only: expected status 403; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/college", token, colleges); status != http.StatusOK {
+		t.Fatalf("add college: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/college?page=1&limit=10", viewerToken, nil); status != http.StatusOK {
+		t.Fatalf("get college as read only: expected status OK; got %d", status)
+	}
+
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/logout", viewerToken, nil); status != http.StatusOK {
+		t.Fatalf("logout: expected status OK; got %d %v", status, body)
+	}
+	// the logged out token is on the revocation list until it expire
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/me", viewerToken, nil); status != http.StatusUnauthorized || body["error"] != "token has been revoked" {
+		t.Fatalf("token after logout: expected a revoked 401; got %d %v", status, body)
+	}
+}
+
+func TestLoginLockoutInMemory(t *testing.T) {
+	// no backoff so only the lockout refuse a login
+	t.Setenv("LOGIN_BACKOFF", "0s")
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	writer := map[string]interface{}{"username": "writer", "email": "writer@admin.com", "password": "password@123", "excess_level": 3}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/User", token, writer); status != http.StatusCreated {
+		t.Fatalf("create user: expected status 201; got %d %v", status, body)
+	}
+
+	// an unknown username and a wrong password can not be told apart
+	_, unknown := testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "nobody", "password": "wrong@12345"})
+	for i := 0; i < 5; i++ {
+		status, body := testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "writer", "password": "wrong@12345"})
+		if status != http.StatusUnauthorized || fmt.Sprint(body) != fmt.Sprint(unknown) {
+			t.Fatalf("failure %d: expected the unknown user 401 %v; got %d %v", i+1, unknown, status, body)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/admin/login", bytes.NewReader([]byte(`{"username":"writer","password":"password@123"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "900" {
+		t.Fatalf("locked login: expected status 429 with Retry-After 900; got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	// the lockout is per username, other admins still log in from the same IP
+	token, _ = testutil.Login(t, s, "admin", "password@123")
+
+	status, body := testutil.DoJSON(t, s, "GET", "/admin/users?limit=10", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("list admins: expected status OK; got %d %v", status, body)
+	}
+	var writerID string
+	for _, item := range testutil.Path[[]interface{}](t, body, "items") {
+		if admin := testutil.Path[map[string]interface{}](t, item); admin["username"] == "writer" {
+			writerID = testutil.Path[string](t, admin, "id")
+		}
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/users/"+writerID+"/unlock", token, nil); status != http.StatusOK {
+		t.Fatalf("unlock: expected status OK; got %d %v", status, body)
+	}
+	testutil.Login(t, s, "writer", "password@123")
+}
@@ -0,0 +1,139 @@
+package AuthenticationSystem
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/internal"
+	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxFailures  = 5
+	DefaultLoginBackoff = "1s"
+	DefaultLoginLockout = "15m"
+)
+
+// LoginPolicy slow down password guessing. From the second failure in a row a
+// username or an IP wait Backoff, doubled on every further failure, and at
+// MaxFailures it is locked for Lockout. An IP is shared by many admins so it
+// is only locked after MaxIPFailures. Wrong 2FA codes count as failures too.
+// With RequireFullTwoFactor a Full admin must enroll in 2FA to login
+type LoginPolicy struct {
+	MaxFailures          int
+	MaxIPFailures        int
+	Backoff              time.Duration
+	Lockout              time.Duration // also how long a failure is remembered
+	RequireFullTwoFactor bool
+}
+
+// LoginPolicyFromEnv read LOGIN_MAX_FAILURES, LOGIN_BACKOFF, LOGIN_LOCKOUT and
+// ADMIN_REQUIRE_2FA_FULL, the defaults are used for the ones not set
+func LoginPolicyFromEnv() LoginPolicy {
+	policy := LoginPolicy{MaxFailures: DefaultMaxFailures}
+	if value := os.Getenv("LOGIN_MAX_FAILURES"); value != "" {
+		failures, err := strconv.Atoi(value)
+		if err == nil && failures < 2 {
+			err = fmt.Errorf("at least 2 failures are needed")
+		}
+		if err != nil {
+			log.Panic(LogColor.Red("!!Panic!! invalid LOGIN_MAX_FAILURES " + err.Error()))
+		}
+		policy.MaxFailures = failures
+	}
+	policy.MaxIPFailures = 4 * policy.MaxFailures
+	policy.Backoff = internal.DurationFromEnv("LOGIN_BACKOFF", DefaultLoginBackoff)
+	policy.Lockout = internal.DurationFromEnv("LOGIN_LOCKOUT", DefaultLoginLockout)
+	if policy.Lockout > AdminDB.AttemptRetention {
+		log.Panic(LogColor.Red("!!Panic!! invalid LOGIN_LOCKOUT failures are only kept " + AdminDB.AttemptRetention.String()))
+	}
+	if value := os.Getenv("ADMIN_REQUIRE_2FA_FULL"); value != "" {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Panic(LogColor.Red("!!Panic!! invalid ADMIN_REQUIRE_2FA_FULL " + err.Error()))
+		}
+		policy.RequireFullTwoFactor = required
+	}
+	return policy
+}
+
+// wait is how long the key of attempt must wait before its next try
+func (p LoginPolicy) wait(attempt *Admin.LoginAttempt, maxFailures int, now time.Time) time.Duration {
+	if attempt == nil || attempt.Failures < 2 {
+		return 0
+	}
+	delay := p.Lockout
+	// the doubling is only done while it stay under Lockout so a large shift can not overflow
+	if shift := attempt.Failures - 2; attempt.Failures < maxFailures && p.Backoff <= p.Lockout>>shift {
+		delay = p.Backoff << shift
+	}
+	return max(attempt.LastFailure.Add(delay).Sub(now), 0)
+}
+
+// loginThrottle apply a LoginPolicy to the failures of a username and an IP.
+// Unknown usernames are counted too so a lockout does not tell they do not exist
+type loginThrottle struct {
+	dbManager AdminDB.IAttemptDBService
+	policy    LoginPolicy
+}
+
+// loginTry is a try counted by begin, passed take it back
+type loginTry struct {
+	now      time.Time
+	keys     []string
+	previous []*Admin.LoginAttempt
+}
+
+// begin count the try of username from ip as a failure before it is checked,
+// so concurrent tries can not all be checked against the same count. When
+// the username or the IP must wait nothing is counted and the wait is returned
+func (t *loginThrottle) begin(username string, ip string, ctx context.Context) (*loginTry, time.Duration, error) {
+	try := &loginTry{now: time.Now()}
+	limits := []struct {
+		key         string
+		maxFailures int
+	}{
+		{Admin.UserAttemptKey(username), t.policy.MaxFailures},
+		{Admin.IPAttemptKey(ip), t.policy.MaxIPFailures},
+	}
+	for _, limit := range limits {
+		for counted := false; !counted; {
+			seen, err := t.dbManager.FetchAttempt(limit.key, ctx)
+			if err != nil {
+				return nil, 0, errors.Join(err, t.passed(try, ctx))
+			}
+			if wait := t.policy.wait(seen, limit.maxFailures, try.now); wait > 0 {
+				return nil, wait, t.passed(try, ctx)
+			}
+			if counted, err = t.dbManager.RecordFailure(limit.key, seen, try.now, t.policy.Lockout, ctx); err != nil {
+				return nil, 0, errors.Join(err, t.passed(try, ctx))
+			}
+			if counted {
+				try.keys = append(try.keys, limit.key)
+				try.previous = append(try.previous, seen)
+			}
+		}
+	}
+	return try, 0, nil
+}
+
+// passed take back a try that was not a wrong password or code
+func (t *loginThrottle) passed(try *loginTry, ctx context.Context) error {
+	var errs []error
+	for i, key := range try.keys {
+		errs = append(errs, t.dbManager.ForgetFailure(key, try.previous[i], try.now, ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// succeeded forget the failures of the username, the ones of the IP expire on
+// their own so one valid account can not be used to keep guessing others
+func (t *loginThrottle) succeeded(username string, ctx context.Context) error {
+	return t.dbManager.ClearAttempts(Admin.UserAttemptKey(username), ctx)
+}
@@ -0,0 +1,29 @@
+package AuthenticationSystem
+
+import (
+	"HostelApp/internal/storageData/Admin"
+	"testing"
+	"time"
+)
+
+func TestLoginPolicyWait(t *testing.T) {
+	policy := LoginPolicy{MaxFailures: 20, MaxIPFailures: 80, Backoff: time.Second, Lockout: 15 * time.Minute}
+	now := time.Now()
+	for _, tt := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{2, time.Second},
+		{5, 8 * time.Second},
+		{15, 15 * time.Minute},
+		// a shift far past the size of a duration still wait, it does not overflow to 0
+		{79, 15 * time.Minute},
+		{80, 15 * time.Minute},
+	} {
+		attempt := &Admin.LoginAttempt{Failures: tt.failures, LastFailure: now}
+		if got := policy.wait(attempt, policy.MaxIPFailures, now); got != tt.want {
+			t.Errorf("%d failures: expected %v; got %v", tt.failures, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package AuthenticationSystem
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	AdminDB "HostelApp/internal/database/Admin"
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+// maxUserAgent bound the user agent kept with a session
+const maxUserAgent = 256
+
+// touchInterval is how stale last_seen may get before a request write it
+// again, so every authenticated request is not also a database write
+const touchInterval = time.Minute
+
+func userAgent(c *fiber.Ctx) string {
+	agent := c.Get(fiber.HeaderUserAgent)
+	if len(agent) > maxUserAgent {
+		agent = agent[:maxUserAgent]
+	}
+	return agent
+}
+
+func sessionErrorStatus(err error) int {
+	if errors.Is(err, AdminDB.ErrSessionNotFound) {
+		return fiber.StatusNotFound
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (m *AuthenticationManager) sessionRoutes() []internal.APIRoute {
+	return []internal.APIRoute{
+		{Path: "/admin/me/sessions", Method: internal.GET, Handler: m.getSessions, Protected: true},
+		{Path: "/admin/me/sessions", Method: internal.DELETE, Handler: m.revokeSessions, Protected: true},
+		{Path: "/admin/me/sessions/:id", Method: internal.DELETE, Handler: m.revokeSession, Protected: true},
+	}
+}
+
+// closeSession put the tokens of a session on the revocation list then drop it
+func (m *AuthenticationManager) closeSession(sessionID string, adminID string, ctx context.Context) error {
+	if err := m.jwtManager.RevokeSession(sessionID, ctx); err != nil {
+		return err
+	}
+	return m.sessions.DeleteSession(sessionID, adminID, ctx)
+}
+
+// CheckSession is the JWTManager.SessionChecker of admin access tokens, it
+// also record the activity of the session once last_seen is touchInterval old
+func (m *AuthenticationManager) CheckSession(subject string, sessionID string, ctx context.Context) error {
+	if sessionID == "" {
+		return errors.New("access token is missing session id")
+	}
+	session, err := m.sessions.FetchSession(sessionID, ctx)
+	if err != nil {
+		if errors.Is(err, AdminDB.ErrSessionNotFound) {
+			return errors.New("session expired or revoked")
+		}
+		return err
+	}
+	if session.AdminID != subject {
+		return errors.New("session expired or revoked")
+	}
+	now := time.Now()
+	if now.Sub(session.LastSeen) < touchInterval {
+		return nil
+	}
+	if err = m.sessions.TouchSession(sessionID, subject, now, ctx); err != nil {
+		if errors.Is(err, AdminDB.ErrSessionNotFound) {
+			return errors.New("session expired or revoked")
+		}
+		return err
+	}
+	return nil
+}
+
+// @Summary List my sessions
+// @Description List the open sessions of the logged in admin, one per login on a device, the one of the request is marked current
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {array} Admin.AdminSession
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/me/sessions [get]
+func (s *AuthenticationManager) getSessions(c *fiber.Ctx) error {
+	claims := JWTManager.GetClaims(c)
+	sessions, err := s.sessions.FetchSessions(claims.Subject, c.Context())
+	if err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch sessions",
+			"error":   err.Error(),
+		})
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims.SessionID
+	}
+	return c.JSON(sessions)
+}
+
+// @Summary Revoke a session
+// @Description Log out one device of the logged in admin, its access and refresh tokens stop working at once
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Session id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/me/sessions/{id} [delete]
+func (s *AuthenticationManager) revokeSession(c *fiber.Ctx) error {
+	subject := JWTManager.GetClaims(c).Subject
+	session, err := s.sessions.FetchSession(c.Params("id"), c.Context())
+	if err == nil && session.AdminID != subject {
+		err = AdminDB.ErrSessionNotFound
+	}
+	if err == nil {
+		err = s.closeSession(session.ID, subject, c.Context())
+	}
+	if err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to revoke session",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "session revoked",
+	})
+}
+
+// @Summary Revoke all my sessions
+// @Description Log out every device of the logged in admin, the current one included
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/me/sessions [delete]
+func (s *AuthenticationManager) revokeSessions(c *fiber.Ctx) error {
+	subject := JWTManager.GetClaims(c).Subject
+	sessions, err := s.sessions.FetchSessions(subject, c.Context())
+	for i := 0; err == nil && i < len(sessions); i++ {
+		err = s.closeSession(sessions[i].ID, subject, c.Context())
+		if errors.Is(err, AdminDB.ErrSessionNotFound) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return c.Status(sessionErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to revoke sessions",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "all sessions revoked",
+	})
+}
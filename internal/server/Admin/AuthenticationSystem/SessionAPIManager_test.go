@@ -0,0 +1,65 @@
+package AuthenticationSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestAdminSessionsInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	// a second device does not log out the first
+	laptop, laptopRefresh := testutil.Login(t, s, "admin", "password@123")
+	phone, _ := testutil.Login(t, s, "admin", "password@123")
+	if status, _ := testutil.DoJSON(t, s, "GET", "/admin/me", laptop, nil); status != http.StatusOK {
+		t.Fatalf("first device after a second login: expected status OK; got %d", status)
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/me/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+phone)
+	resp, err := s.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	var sessions []map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&sessions)
+	if resp.StatusCode != http.StatusOK || len(sessions) != 2 {
+		t.Fatalf("list sessions: expected 2 sessions; got %d %v", resp.StatusCode, sessions)
+	}
+	var laptopID string
+	for _, session := range sessions {
+		if _, ok := session["refresh_hash"]; ok {
+			t.Fatalf("list sessions: refresh hash leaked in %v", session)
+		}
+		if session["current"] != true {
+			laptopID = testutil.Path[string](t, session, "id")
+		}
+	}
+
+	if status, _ := testutil.DoJSON(t, s, "DELETE", "/admin/me/sessions/"+laptopID, phone, nil); status != http.StatusOK {
+		t.Fatalf("revoke laptop session: expected status OK; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "GET", "/admin/me", laptop, nil); status != http.StatusUnauthorized {
+		t.Fatalf("revoked session: expected status 401; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/token/refresh", "", map[string]string{"refresh_token": laptopRefresh}); status != http.StatusUnauthorized {
+		t.Fatalf("refresh of a revoked session: expected status 401; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "GET", "/admin/me", phone, nil); status != http.StatusOK {
+		t.Fatalf("other session: expected status OK; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "DELETE", "/admin/me/sessions/"+laptopID, phone, nil); status != http.StatusNotFound {
+		t.Fatalf("revoke a revoked session: expected status 404; got %d", status)
+	}
+
+	if status, _ := testutil.DoJSON(t, s, "DELETE", "/admin/me/sessions", phone, nil); status != http.StatusOK {
+		t.Fatalf("revoke all sessions: expected status OK; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "GET", "/admin/me", phone, nil); status != http.StatusUnauthorized {
+		t.Fatalf("after revoking all sessions: expected status 401; got %d", status)
+	}
+}
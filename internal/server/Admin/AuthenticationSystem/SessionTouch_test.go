@@ -0,0 +1,45 @@
+package AuthenticationSystem
+
+import (
+	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckSessionTouch(t *testing.T) {
+	sessions := AdminDB.NewSessionMemoryManager()
+	m := &AuthenticationManager{sessions: sessions}
+	ctx := context.Background()
+	now := time.Now()
+	for _, tt := range []struct {
+		id      string
+		seen    time.Time
+		touched bool
+	}{
+		{"recent", now.Add(-30 * time.Second), false},
+		{"stale", now.Add(-2 * time.Minute), true},
+	} {
+		session := &Admin.AdminSession{ID: tt.id, AdminID: "a1", Created: tt.seen, LastSeen: tt.seen, ExpiresAt: now.Add(time.Hour)}
+		if err := sessions.CreateSession(session, ctx); err != nil {
+			t.Fatalf("CreateSession failed. Err: %v", err)
+		}
+		if err := m.CheckSession("a1", tt.id, ctx); err != nil {
+			t.Fatalf("%s: CheckSession failed. Err: %v", tt.id, err)
+		}
+		stored, err := sessions.FetchSession(tt.id, ctx)
+		if err != nil {
+			t.Fatalf("FetchSession failed. Err: %v", err)
+		}
+		if touched := !stored.LastSeen.Equal(tt.seen); touched != tt.touched {
+			t.Errorf("%s: expected touched %v; got last_seen %v", tt.id, tt.touched, stored.LastSeen)
+		}
+	}
+	if err := m.CheckSession("a2", "recent", ctx); err == nil {
+		t.Errorf("expected the session of another admin to be refused")
+	}
+	if err := m.CheckSession("a1", "missing", ctx); err == nil {
+		t.Errorf("expected a missing session to be refused")
+	}
+}
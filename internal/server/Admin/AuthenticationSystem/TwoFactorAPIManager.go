@@ -0,0 +1,462 @@
+package AuthenticationSystem
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/TwoFactor"
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"time"
+)
+
+// totpIssuer is the account issuer shown by authenticator apps
+const totpIssuer = "HostelApp"
+
+var (
+	ErrInvalidSecondFactor = errors.New("invalid two factor code")
+	ErrTwoFactorRequired   = errors.New("two factor authentication is required for Full admins")
+	ErrCodeRequired        = errors.New("code is required")
+)
+
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, AdminDB.ErrAdminNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, AdminDB.ErrTwoFactorEnabled), errors.Is(err, AdminDB.ErrTwoFactorNotEnabled),
+		errors.Is(err, AdminDB.ErrTwoFactorNotPending), errors.Is(err, ErrTwoFactorRequired):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrInvalidSecondFactor), errors.Is(err, AdminDB.ErrPasswordMismatch):
+		return fiber.StatusUnauthorized
+	}
+	return fiber.StatusInternalServerError
+}
+
+func (m *AuthenticationManager) twoFactorRoutes() []internal.APIRoute {
+	return []internal.APIRoute{
+		{Path: "/admin/login/2fa", Method: internal.POST, Handler: m.loginTwoFactor},
+		{Path: "/admin/login/2fa/enroll", Method: internal.POST, Handler: m.enrollAtLogin},
+		{Path: "/admin/login/2fa/enroll/confirm", Method: internal.POST, Handler: m.confirmEnrollAtLogin},
+		{Path: "/admin/me/2fa", Method: internal.POST, Handler: m.enroll, Protected: true},
+		{Path: "/admin/me/2fa/confirm", Method: internal.POST, Handler: m.confirmEnroll, Protected: true},
+		{Path: "/admin/me/2fa/recovery-codes", Method: internal.POST, Handler: m.regenerateRecoveryCodes, Protected: true},
+		{Path: "/admin/me/2fa/disable", Method: internal.POST, Handler: m.disableTwoFactor, Protected: true},
+	}
+}
+
+// checkSecondFactor accept a TOTP code of the admin or one of its recovery
+// codes, each only once. ErrInvalidSecondFactor is returned for anything else
+func (s *AuthenticationManager) checkSecondFactor(_id string, code string, c *fiber.Ctx) error {
+	state, err := s.dbManager.FetchTwoFactor(_id, c.Context())
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return AdminDB.ErrTwoFactorNotEnabled
+	}
+	if step, ok := TwoFactor.Verify(state.Secret, code, time.Now()); ok {
+		err = s.dbManager.UseTOTPStep(_id, step, c.Context())
+	} else if len(code) == TwoFactor.Digits {
+		return ErrInvalidSecondFactor
+	} else {
+		err = s.dbManager.UseRecoveryCode(_id, TwoFactor.HashRecoveryCode(code), c.Context())
+	}
+	if errors.Is(err, AdminDB.ErrTOTPCodeUsed) || errors.Is(err, AdminDB.ErrRecoveryCodeNotFound) {
+		return ErrInvalidSecondFactor
+	}
+	return err
+}
+
+// passSecondFactor run checkSecondFactor under the login throttle of the
+// admin, when it return false the error response is already written
+func (s *AuthenticationManager) passSecondFactor(c *fiber.Ctx, admin *Admin.AdminUser, code string) (bool, error) {
+	return s.passThrottled(c, admin, func() error {
+		return s.checkSecondFactor(admin.ID, code, c)
+	})
+}
+
+// passThrottled run check under the login throttle of the admin, a wrong
+// password or code it return count as a failed login. When it return false
+// the error response is already written
+func (s *AuthenticationManager) passThrottled(c *fiber.Ctx, admin *Admin.AdminUser, check func() error) (bool, error) {
+	try, wait, err := s.throttle.begin(admin.Username, c.IP(), c.Context())
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch login attempts from DB",
+			"error":   err.Error(),
+		})
+	}
+	if wait > 0 {
+		return false, tooManyAttempts(c, wait)
+	}
+	if err = check(); err != nil {
+		if !errors.Is(err, ErrInvalidSecondFactor) && !errors.Is(err, AdminDB.ErrPasswordMismatch) {
+			s.passedTry(try, c)
+		}
+		return false, c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to validate two factor code",
+			"error":   err.Error(),
+		})
+	}
+	s.passedTry(try, c)
+	return true, nil
+}
+
+// loginAdmin is the admin of a challenge or enrollment token checked by verify,
+// a disabled admin is refused like an expired token
+func (s *AuthenticationManager) loginAdmin(c *fiber.Ctx, verify func(string, context.Context) (*JWTManager.Claims, error), token string) (*Admin.AdminUser, error) {
+	claims, err := verify(token, c.Context())
+	if err != nil {
+		return nil, err
+	}
+	admin, err := s.dbManager.FetchAdmin(claims.Subject, c.Context())
+	if err != nil {
+		return nil, err
+	}
+	if admin.Disabled {
+		return nil, AdminDB.ErrAdminDisabled
+	}
+	return admin, nil
+}
+
+// @Summary Admin login second factor
+// @Description Finish a login with the challenge token of /admin/login and a TOTP code or a recovery code, each code work once
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code body Admin.TwoFactorLogin true "Challenge token and code"
+// @Success 200 {object} map[string]interface{} "Returns JWT token and refresh token"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/login/2fa [post]
+func (s *AuthenticationManager) loginTwoFactor(c *fiber.Ctx) error {
+	var request Admin.TwoFactorLogin
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate two factor login in validator",
+			"error":   err.Error(),
+		})
+	}
+	admin, err := s.loginAdmin(c, s.jwtManager.VerifyChallengeToken, request.ChallengeToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to validate challenge token",
+			"error":   err.Error(),
+		})
+	}
+	if ok, respErr := s.passSecondFactor(c, admin, request.Code); !ok {
+		return respErr
+	}
+	if err = s.throttle.succeeded(admin.Username, c.Context()); err != nil {
+		slog.Error(fmt.Sprintf("failed to clear failed logins: %v", err))
+	}
+	return s.startSession(c, admin.ID, nil)
+}
+
+// startEnrollment give the admin a new secret waiting for its first code
+func (s *AuthenticationManager) startEnrollment(c *fiber.Ctx, admin *Admin.AdminUser) error {
+	secret, err := TwoFactor.NewSecret()
+	if err == nil {
+		err = s.dbManager.StartTwoFactor(admin.ID, secret, c.Context())
+	}
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to start two factor enrollment",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "scan the uri then confirm with a code",
+		"secret":  secret,
+		"uri":     TwoFactor.URI(totpIssuer, admin.Username, secret),
+	})
+}
+
+// confirmEnrollment turn on the pending secret when code match it and return
+// the recovery codes, they are only ever shown here
+func (s *AuthenticationManager) confirmEnrollment(c *fiber.Ctx, _id string, code string) ([]string, error) {
+	state, err := s.dbManager.FetchTwoFactor(_id, c.Context())
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, AdminDB.ErrTwoFactorEnabled
+	}
+	if state.PendingSecret == "" {
+		return nil, AdminDB.ErrTwoFactorNotPending
+	}
+	step, ok := TwoFactor.Verify(state.PendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidSecondFactor
+	}
+	codes, hashes, err := TwoFactor.NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.dbManager.EnableTwoFactor(_id, state.PendingSecret, step, hashes, c.Context()); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// @Summary Start 2FA enrollment at login
+// @Description Start the TOTP enrollment of a Full admin that must use 2FA, with the enrollment token of /admin/login
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param enrollment body Admin.TwoFactorEnrollment true "Enrollment token"
+// @Success 200 {object} map[string]interface{} "Returns the secret and its otpauth uri"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/login/2fa/enroll [post]
+func (s *AuthenticationManager) enrollAtLogin(c *fiber.Ctx) error {
+	var request Admin.TwoFactorEnrollment
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate enrollment in validator",
+			"error":   err.Error(),
+		})
+	}
+	admin, err := s.loginAdmin(c, s.jwtManager.VerifyEnrollmentToken, request.EnrollmentToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to validate enrollment token",
+			"error":   err.Error(),
+		})
+	}
+	return s.startEnrollment(c, admin)
+}
+
+// @Summary Confirm 2FA enrollment at login
+// @Description Confirm the enrollment started at /admin/login/2fa/enroll with a TOTP code, it finish the login and return the recovery codes once
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param enrollment body Admin.TwoFactorEnrollment true "Enrollment token and code"
+// @Success 200 {object} map[string]interface{} "Returns JWT token, refresh token and recovery codes"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/login/2fa/enroll/confirm [post]
+func (s *AuthenticationManager) confirmEnrollAtLogin(c *fiber.Ctx) error {
+	var request Admin.TwoFactorEnrollment
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil || request.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate enrollment in validator",
+			"error":   errors.Join(err, ErrCodeRequired).Error(),
+		})
+	}
+	admin, err := s.loginAdmin(c, s.jwtManager.VerifyEnrollmentToken, request.EnrollmentToken)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "failed to validate enrollment token",
+			"error":   err.Error(),
+		})
+	}
+	codes, err := s.confirmEnrollment(c, admin.ID, request.Code)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to confirm two factor enrollment",
+			"error":   err.Error(),
+		})
+	}
+	if err = s.throttle.succeeded(admin.Username, c.Context()); err != nil {
+		slog.Error(fmt.Sprintf("failed to clear failed logins: %v", err))
+	}
+	return s.startSession(c, admin.ID, fiber.Map{"recoveryCodes": codes})
+}
+
+// @Summary Start 2FA enrollment
+// @Description Generate a TOTP secret for the logged in admin, 2FA is on once confirmed with a code
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} map[string]interface{} "Returns the secret and its otpauth uri"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/me/2fa [post]
+func (s *AuthenticationManager) enroll(c *fiber.Ctx) error {
+	admin, err := s.dbManager.FetchAdmin(JWTManager.GetClaims(c).Subject, c.Context())
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch admin",
+			"error":   err.Error(),
+		})
+	}
+	return s.startEnrollment(c, admin)
+}
+
+// @Summary Confirm 2FA enrollment
+// @Description Turn on 2FA with a code of the new secret, the recovery codes are returned only this once
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code body Admin.TOTPConfirm true "TOTP code"
+// @Success 200 {object} map[string]interface{} "Returns the recovery codes"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/me/2fa/confirm [post]
+func (s *AuthenticationManager) confirmEnroll(c *fiber.Ctx) error {
+	var request Admin.TOTPConfirm
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate code in validator",
+			"error":   err.Error(),
+		})
+	}
+	codes, err := s.confirmEnrollment(c, JWTManager.GetClaims(c).Subject, request.Code)
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to confirm two factor enrollment",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message":       "two factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code of the logged in admin, a TOTP code or a recovery code is needed
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code body Admin.TwoFactorCode true "TOTP code or recovery code"
+// @Success 200 {object} map[string]interface{} "Returns the new recovery codes"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /admin/me/2fa/recovery-codes [post]
+func (s *AuthenticationManager) regenerateRecoveryCodes(c *fiber.Ctx) error {
+	var request Admin.TwoFactorCode
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate code in validator",
+			"error":   err.Error(),
+		})
+	}
+	admin, err := s.dbManager.FetchAdmin(JWTManager.GetClaims(c).Subject, c.Context())
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch admin",
+			"error":   err.Error(),
+		})
+	}
+	if ok, respErr := s.passSecondFactor(c, admin, request.Code); !ok {
+		return respErr
+	}
+	codes, hashes, err := TwoFactor.NewRecoveryCodes()
+	if err == nil {
+		err = s.dbManager.SetRecoveryCodes(admin.ID, hashes, c.Context())
+	}
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to replace recovery codes",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message":       "recovery codes replaced",
+		"recoveryCodes": codes,
+	})
+}
+
+// @Summary Disable 2FA
+// @Description Turn off 2FA of the logged in admin with its password and a code, refused for Full admins when 2FA is required for them
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param disable body Admin.TwoFactorDisable true "Password and TOTP code or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /admin/me/2fa/disable [post]
+func (s *AuthenticationManager) disableTwoFactor(c *fiber.Ctx) error {
+	var request Admin.TwoFactorDisable
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "cannot parse JSON",
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate request in validator",
+			"error":   err.Error(),
+		})
+	}
+	admin, err := s.dbManager.FetchAdmin(JWTManager.GetClaims(c).Subject, c.Context())
+	if err == nil && s.throttle.policy.RequireFullTwoFactor && admin.ExcessLevel == Admin.Full {
+		err = ErrTwoFactorRequired
+	}
+	if err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to disable two factor authentication",
+			"error":   err.Error(),
+		})
+	}
+	//the password is guessed under the same throttle as the code
+	ok, respErr := s.passThrottled(c, admin, func() error {
+		credentials := &Admin.AdminLogin{Username: admin.Username, Password: request.Password}
+		if _, err := s.dbManager.IsValidCredentials(credentials, c.Context()); err != nil {
+			return err
+		}
+		return s.checkSecondFactor(admin.ID, request.Code, c)
+	})
+	if !ok {
+		return respErr
+	}
+	if err = s.dbManager.DisableTwoFactor(admin.ID, c.Context()); err != nil {
+		return c.Status(twoFactorErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to disable two factor authentication",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "two factor authentication disabled",
+	})
+}
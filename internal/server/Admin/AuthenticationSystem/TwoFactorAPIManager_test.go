@@ -0,0 +1,129 @@
+package AuthenticationSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/TwoFactor"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTwoFactorInMemory(t *testing.T) {
+	// the failed codes below must not slow down the next logins from the same IP
+	t.Setenv("LOGIN_BACKOFF", "0s")
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/me/2fa", token, nil)
+	if status != http.StatusOK || !strings.HasPrefix(testutil.Path[string](t, body, "uri"), "otpauth://totp/HostelApp:admin?") {
+		t.Fatalf("start 2fa: expected an otpauth uri; got %d %v", status, body)
+	}
+	secret := testutil.Path[string](t, body, "secret")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/me/2fa/confirm", token, map[string]string{"code": "000000"}); status != http.StatusUnauthorized {
+		t.Fatalf("confirm with a wrong code: expected status 401; got %d", status)
+	}
+	now := TwoFactor.Step(time.Now())
+	code, _ := TwoFactor.Code(secret, now)
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/me/2fa/confirm", token, map[string]string{"code": code})
+	if status != http.StatusOK || len(testutil.Path[[]interface{}](t, body, "recoveryCodes")) != TwoFactor.RecoveryCodeCount {
+		t.Fatalf("confirm 2fa: expected the recovery codes; got %d %v", status, body)
+	}
+	recovery := testutil.Path[string](t, body, "recoveryCodes", 0)
+
+	// the password alone now only give a challenge
+	challenge := func() string {
+		t.Helper()
+		status, body := testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "admin", "password": "password@123"})
+		if status != http.StatusOK || body["jwtToken"] != nil || body["challengeToken"] == nil {
+			t.Fatalf("login with 2fa: expected a challenge only; got %d %v", status, body)
+		}
+		return testutil.Path[string](t, body, "challengeToken")
+	}
+	challengeToken := challenge()
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/me", challengeToken, nil); status != http.StatusUnauthorized {
+		t.Fatalf("challenge token as access token: expected status 401; got %d", status)
+	}
+	// the code that confirmed the enrollment can not be replayed
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/login/2fa", "", map[string]string{"challenge_token": challengeToken, "code": code}); status != http.StatusUnauthorized {
+		t.Fatalf("replayed code: expected status 401; got %d", status)
+	}
+	next, _ := TwoFactor.Code(secret, now+1)
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/login/2fa", "", map[string]string{"challenge_token": challengeToken, "code": next}); status != http.StatusOK || body["jwtToken"] == nil {
+		t.Fatalf("login with a code: expected a JWT; got %d %v", status, body)
+	}
+	token = testutil.Path[string](t, body, "jwtToken")
+	recoveryLogin := map[string]string{"challenge_token": challenge(), "code": strings.ToUpper(recovery)}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/login/2fa", "", recoveryLogin); status != http.StatusOK {
+		t.Fatalf("login with a recovery code: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/login/2fa", "", recoveryLogin); status != http.StatusUnauthorized {
+		t.Fatalf("reused recovery code: expected status 401; got %d", status)
+	}
+
+	_, me := testutil.DoJSON(t, s, "GET", "/admin/me", token, nil)
+	if me["two_factor_enabled"] != true {
+		t.Fatalf("profile: expected two_factor_enabled; got %v", me)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/users/"+testutil.Path[string](t, me, "id")+"/2fa/reset", token, nil); status != http.StatusOK {
+		t.Fatalf("reset 2fa: expected status OK; got %d", status)
+	}
+	testutil.Login(t, s, "admin", "password@123")
+}
+
+func TestTwoFactorDisableLockoutInMemory(t *testing.T) {
+	t.Setenv("LOGIN_BACKOFF", "0s")
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	_, body := testutil.DoJSON(t, s, "POST", "/admin/me/2fa", token, nil)
+	secret := testutil.Path[string](t, body, "secret")
+	now := TwoFactor.Step(time.Now())
+	code, _ := TwoFactor.Code(secret, now)
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/me/2fa/confirm", token, map[string]string{"code": code}); status != http.StatusOK {
+		t.Fatalf("confirm 2fa: expected status OK; got %d %v", status, body)
+	}
+
+	// guessing the password through disable lock the username like a login
+	next, _ := TwoFactor.Code(secret, now+1)
+	for i := 0; i < 5; i++ {
+		wrong := map[string]string{"password": "wrong@12345", "code": next}
+		if status, _ := testutil.DoJSON(t, s, "POST", "/admin/me/2fa/disable", token, wrong); status != http.StatusUnauthorized {
+			t.Fatalf("disable with a wrong password %d: expected status 401; got %d", i+1, status)
+		}
+	}
+	disable := map[string]string{"password": "password@123", "code": next}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/me/2fa/disable", token, disable); status != http.StatusTooManyRequests {
+		t.Fatalf("disable while locked: expected status 429; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "admin", "password": "password@123"}); status != http.StatusTooManyRequests {
+		t.Fatalf("login while locked: expected status 429; got %d", status)
+	}
+}
+
+func TestTwoFactorRequiredInMemory(t *testing.T) {
+	t.Setenv("ADMIN_REQUIRE_2FA_FULL", "true")
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "admin", "password": "password@123"})
+	if status != http.StatusOK || body["jwtToken"] != nil || body["enrollmentToken"] == nil {
+		t.Fatalf("login of a Full admin without 2fa: expected an enrollment token only; got %d %v", status, body)
+	}
+	enrollment := testutil.Path[string](t, body, "enrollmentToken")
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/login/2fa/enroll", "", map[string]string{"enrollment_token": enrollment}); status != http.StatusOK {
+		t.Fatalf("enroll at login: expected status OK; got %d %v", status, body)
+	}
+	code, _ := TwoFactor.Code(testutil.Path[string](t, body, "secret"), TwoFactor.Step(time.Now()))
+	confirm := map[string]string{"enrollment_token": enrollment, "code": code}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/login/2fa/enroll/confirm", "", confirm)
+	if status != http.StatusOK || body["jwtToken"] == nil || len(testutil.Path[[]interface{}](t, body, "recoveryCodes")) != TwoFactor.RecoveryCodeCount {
+		t.Fatalf("confirm at login: expected a JWT and recovery codes; got %d %v", status, body)
+	}
+	token := testutil.Path[string](t, body, "jwtToken")
+	recovery := testutil.Path[string](t, body, "recoveryCodes", 0)
+	disable := map[string]string{"password": "password@123", "code": recovery}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/me/2fa/disable", token, disable); status != http.StatusConflict {
+		t.Fatalf("disable 2fa of a Full admin: expected status 409; got %d", status)
+	}
+}
@@ -3,19 +3,31 @@ package CollegeSystem
 import (
 	"HostelApp/internal"
 	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/Spreadsheet"
 	"HostelApp/internal/ValidatorSystem"
 	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/server/Audit"
 	"HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Listing"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"io"
+	"log/slog"
 	"strconv"
+	"time"
 )
 
 type CollegeManager struct {
-	dbManager  *AdminDB.CollegeDBManager
+	dbManager  AdminDB.ICollegeDBService
 	jwtManager *JWTManager.JWTManager
 }
 
-func NewCollegeManager(dbManager *AdminDB.CollegeDBManager, jwtManager *JWTManager.JWTManager) *CollegeManager {
+func NewCollegeManager(dbManager AdminDB.ICollegeDBService, jwtManager *JWTManager.JWTManager) *CollegeManager {
 	instance := &CollegeManager{
 		dbManager:  dbManager,
 		jwtManager: jwtManager,
@@ -25,61 +37,107 @@ func NewCollegeManager(dbManager *AdminDB.CollegeDBManager, jwtManager *JWTManag
 
 func (m *CollegeManager) GetFiberRoutes() *[]internal.APIRoute {
 	return &[]internal.APIRoute{
-		{"/admin/college", internal.GET, m.GetCollege},
-		{"/admin/college", internal.POST, m.AddCollege},
-		{"/admin/college", internal.PATCH, m.AddCollege},
+		{Path: "/admin/college", Method: internal.GET, Handler: m.GetCollege, Permission: internal.ReadPermission},
+		{Path: "/admin/college", Method: internal.POST, Handler: m.AddCollege, Permission: internal.WritePermission},
+		{Path: "/admin/college/import/csv", Method: internal.POST, Handler: m.ImportCSV, Permission: internal.WritePermission},
+		{Path: "/admin/college/import/xlsx", Method: internal.POST, Handler: m.ImportXLSX, Permission: internal.WritePermission},
+		{Path: "/admin/college/export/csv", Method: internal.GET, Handler: m.ExportCSV, Permission: internal.ReadPermission},
+		{Path: "/admin/college/export/xlsx", Method: internal.GET, Handler: m.ExportXLSX, Permission: internal.ReadPermission},
+		{Path: "/admin/college/:unique_name", Method: internal.PATCH, Handler: m.UpdateCollege, Permission: internal.WritePermission},
+		{Path: "/admin/college/:unique_name", Method: internal.DELETE, Handler: m.DeleteCollege, Permission: internal.WritePermission},
+		{Path: "/admin/college/:unique_name/restore", Method: internal.POST, Handler: m.RestoreCollege, Permission: internal.WritePermission},
+		// purging can not be undone so it is kept to Full admins
+		{Path: "/admin/college/:unique_name/purge", Method: internal.DELETE, Handler: m.PurgeCollege, Permission: internal.ManageAdminPermission},
 	}
 }
 
+func collegeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, AdminDB.ErrCollegeNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, AdminDB.ErrCollegeDeleted), errors.Is(err, AdminDB.ErrCollegeNotDeleted):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrTooManyRows), errors.Is(err, Listing.ErrInvalidCursor), errors.Is(err, Listing.ErrInvalidSort):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrImportRejected):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// ErrInvalidStrengthRange reject a min_strength above max_strength
+var ErrInvalidStrengthRange = errors.New("min_strength must not be greater than max_strength")
+
+// listFilter read the query of a college list, shared by GetCollege and the exports
+func listFilter(c *fiber.Ctx) (*Admin.CollegeFilter, error) {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	markAsDeleted, err := strconv.ParseBool(c.Query("mark_as_deleted", "false"))
+	if err != nil {
+		markAsDeleted = false
+	}
+	filter := &Admin.CollegeFilter{
+		Page:          page,
+		Limit:         limit,
+		PinCode:       c.Query("pin_code", ""),
+		MarkAsDeleted: markAsDeleted,
+		Search:        c.Query("search", ""),
+		Sort:          c.Query("sort", ""),
+		Cursor:        c.Query("cursor", ""),
+	}
+	for name, bound := range map[string]**int64{"min_strength": &filter.MinStrength, "max_strength": &filter.MaxStrength} {
+		if value := c.Query(name, ""); value != "" {
+			strength, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q is not a whole number", name, value)
+			}
+			*bound = &strength
+		}
+	}
+	if err = ValidatorSystem.GetValidator().IsValid(filter); err != nil {
+		return nil, err
+	}
+	if filter.MinStrength != nil && filter.MaxStrength != nil && *filter.MinStrength > *filter.MaxStrength {
+		return nil, ErrInvalidStrengthRange
+	}
+	if _, err = Listing.ParseSort(filter.Sort, Admin.CollegeSortFields...); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
 // @Summary Get college list
-// @Description Fetch filtered list of colleges
+// @Description Fetch filtered list of colleges. Search match words of the name or address, sort is one of collage_unique_name (default), collage_name, collage_address, pin_code or collage_strength, prefixed by - for descending. Pass the next_cursor of a page as cursor to get the next one
 // @Tags admin
 // @Accept json
 // @Produce json
-// @Param page query string true "Page number"
-// @Param limit query string true "Items per page"
+// @Param page query string true "Page number, ignored with a cursor"
+// @Param limit query string true "Items per page, at most 100"
 // @Param pin_code query string false "Pin code"
-// @Param mark_as_deleted query boolean false "Include deleted items"
+// @Param mark_as_deleted query boolean false "Only deleted colleges instead of the live ones"
+// @Param search query string false "Words of the name or address"
+// @Param min_strength query integer false "Smallest collage_strength"
+// @Param max_strength query integer false "Largest collage_strength"
+// @Param sort query string false "Sort field, -field for descending"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Param Authorization header string true "Bearer JWT token"
-// @Success 200 {object} []Admin.CollegeData
+// @Success 200 {object} Listing.Page[Admin.CollegeData]
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /admin/college [get]
 func (m *CollegeManager) GetCollege(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if _, jwtErr := m.jwtManager.IsValid(authHeader); jwtErr != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
-			"error":   jwtErr.Error(),
-		})
-	}
-	pageStr := c.Query("page", "1")
-	limitStr := c.Query("limit", "10")
-	page, _ := strconv.ParseInt(pageStr, 10, 64)
-	limit, _ := strconv.ParseInt(limitStr, 10, 64)
-	collFilter := Admin.CollegeFilter{
-		Page:    page,
-		Limit:   limit,
-		PinCode: c.Query("pin_code", ""),
-	}
-
-	markAsDeleted, err := strconv.ParseBool(c.Query("mark_as_deleted", "false"))
+	collFilter, err := listFilter(c)
 	if err != nil {
-		markAsDeleted = false
-	}
-	collFilter.MarkAsDeleted = markAsDeleted
-
-	// Optional: Validate the filter struct
-	if err := ValidatorSystem.GetValidator().IsValid(&collFilter); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": "failed to validate filter",
 			"error":   err.Error(),
 		})
 	}
 
-	colleges, err := m.dbManager.FetchCollege(&collFilter, c.Context())
+	colleges, err := m.dbManager.FetchCollege(collFilter, c.Context())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(collegeErrorStatus(err)).JSON(fiber.Map{
 			"message": "failed to fetch colleges",
 			"error":   err.Error(),
 		})
@@ -89,22 +147,24 @@ func (m *CollegeManager) GetCollege(c *fiber.Ctx) error {
 }
 
 // @Summary Add college
-// @Description Add new list of colleges
+// @Description Import a list of colleges. Every row is validated first, in all_or_nothing mode one invalid or existing college reject the whole list, in partial mode the valid rows are inserted. The report give the outcome of every row
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer JWT token"
+// @Param mode query string false "all_or_nothing (default) or partial"
 // @Param user body []Admin.CollegeData true "College to be added"
-// @Success 200 {object} []Admin.CollegeNameData
+// @Success 200 {object} Admin.CollegeImportReport
 // @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /admin/college [post]
 func (m *CollegeManager) AddCollege(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if _, jwtErr := m.jwtManager.IsValid(authHeader); jwtErr != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
-			"error":   jwtErr.Error(),
+	mode, err := parseImportMode(c.Query("mode", ""))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse import mode",
+			"error":   err.Error(),
 		})
 	}
 	var colleges []Admin.CollegeData
@@ -114,49 +174,342 @@ func (m *CollegeManager) AddCollege(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	if addedColleges, err := m.dbManager.AddCollege(&colleges, c.Context()); err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
+	rows := make([]importRow, 0, len(colleges))
+	for i, college := range colleges {
+		rows = append(rows, importRow{Line: i + 1, College: college})
+	}
+	return m.respondImport(c, rows, mode)
+}
+
+func (m *CollegeManager) respondImport(c *fiber.Ctx, rows []importRow, mode Admin.ImportMode) error {
+	report, err := m.importColleges(rows, mode, c.Context())
+	if err != nil {
+		body := fiber.Map{
+			"message": "failed to import colleges",
+			"error":   err.Error(),
+		}
+		if report != nil {
+			body["report"] = report
+		}
+		return c.Status(collegeErrorStatus(err)).JSON(body)
+	}
+	inserted := make([]string, 0, report.Inserted)
+	for _, row := range report.Rows {
+		if row.Status == Admin.RowInserted {
+			inserted = append(inserted, row.CollageUniqueName)
+		}
+	}
+	Audit.Record(c, "", nil, fiber.Map{"inserted": inserted})
+	return c.JSON(report)
+}
+
+// uploadedSheet is the file field of a multipart upload or else the raw body
+func uploadedSheet(c *fiber.Ctx) ([]byte, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Body(), nil
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// importSheet read the records of an uploaded sheet then import them like AddCollege
+func (m *CollegeManager) importSheet(c *fiber.Ctx, read func(data []byte) ([]Spreadsheet.Row, error)) error {
+	rows, mode, err := m.readSheet(c, read)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to read colleges sheet",
+			"error":   err.Error(),
+		})
+	}
+	return m.respondImport(c, rows, mode)
+}
+
+func (m *CollegeManager) readSheet(c *fiber.Ctx, read func(data []byte) ([]Spreadsheet.Row, error)) ([]importRow, Admin.ImportMode, error) {
+	mode, err := parseImportMode(c.Query("mode", ""))
+	if err != nil {
+		return nil, "", err
+	}
+	mapping, err := parseMapping(c.Query("mapping", c.FormValue("mapping")))
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := uploadedSheet(c)
+	if err != nil {
+		return nil, "", err
+	}
+	records, err := read(data)
+	if err != nil {
+		return nil, "", err
+	}
+	rows, err := sheetRows(records, mapping)
+	return rows, mode, err
+}
+
+// @Summary Import colleges from CSV
+// @Description Import colleges from a text/csv body or a multipart file field. The first line is the header, columns are matched to college fields by name or by the mapping. Same modes and report as Add college, rows are counted from the header line
+// @Tags admin
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param mode query string false "all_or_nothing (default) or partial"
+// @Param mapping query string false "Header=field pairs separated by commas, e.g. Name=collage_name,Code=collage_unique_name"
+// @Success 200 {object} Admin.CollegeImportReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/college/import/csv [post]
+func (m *CollegeManager) ImportCSV(c *fiber.Ctx) error {
+	return m.importSheet(c, readCSV)
+}
+
+// @Summary Import colleges from Excel
+// @Description Import colleges from the first sheet of an .xlsx workbook sent as body or multipart file field. Same header rules as the CSV import
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param mode query string false "all_or_nothing (default) or partial"
+// @Param mapping query string false "Header=field pairs separated by commas, e.g. Name=collage_name,Code=collage_unique_name"
+// @Success 200 {object} Admin.CollegeImportReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/college/import/xlsx [post]
+func (m *CollegeManager) ImportXLSX(c *fiber.Ctx) error {
+	return m.importSheet(c, func(data []byte) ([]Spreadsheet.Row, error) {
+		return Spreadsheet.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	})
+}
+
+// exportFilter read the same filter as GetCollege, the pages are walked by the export
+func exportFilter(c *fiber.Ctx) (*Admin.CollegeFilter, *headerMapping, error) {
+	filter, err := listFilter(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapping, err := parseMapping(c.Query("mapping", ""))
+	if err != nil {
+		return nil, nil, err
+	}
+	filter.Page, filter.Limit, filter.Cursor = 1, exportPageSize, ""
+	return filter, mapping, nil
+}
+
+// exportPageSize is the largest page FetchCollege serve
+const exportPageSize = 100
+
+// streamColleges write the header then every college matching the filter page
+// by page, the response is already sent so a storage error can only end it early
+func (m *CollegeManager) streamColleges(c *fiber.Ctx, contentType string, filename string, open func(w io.Writer) (func([]string) error, func() error, error)) error {
+	filter, mapping, err := exportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse export filter",
 			"error":   err.Error(),
 		})
-	} else {
-		return c.JSON(addedColleges)
 	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		writeRow, finish, err := open(w)
+		if err == nil {
+			err = writeRow(exportHeader(mapping))
+		}
+		for err == nil {
+			var page *Listing.Page[Admin.CollegeData]
+			if page, err = m.dbManager.FetchCollege(filter, ctx); err != nil {
+				break
+			}
+			for i := 0; i < len(page.Items) && err == nil; i++ {
+				err = writeRow(collegeRecord(&page.Items[i]))
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+		if err == nil {
+			err = finish()
+		}
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to export colleges: %v", err))
+		}
+	})
+	return nil
 }
 
-// @Summary update college list
-// @Description to update existing collages make sure the unique college name should be present
+// @Summary Export colleges as CSV
+// @Description Stream every college matching the filter as CSV, the header use the field names or the mapping. A cell starting with =, +, - or @ is prefixed with ' so it is not run as a formula, the import remove it
+// @Tags admin
+// @Produce text/csv
+// @Param Authorization header string true "Bearer JWT token"
+// @Param pin_code query string false "Pin code"
+// @Param mark_as_deleted query boolean false "Only deleted colleges instead of the live ones"
+// @Param search query string false "Words of the name or address"
+// @Param min_strength query integer false "Smallest collage_strength"
+// @Param max_strength query integer false "Largest collage_strength"
+// @Param sort query string false "Sort field, -field for descending"
+// @Param mapping query string false "Header=field pairs separated by commas"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/college/export/csv [get]
+func (m *CollegeManager) ExportCSV(c *fiber.Ctx) error {
+	return m.streamColleges(c, "text/csv; charset=utf-8", "colleges.csv", func(w io.Writer) (func([]string) error, func() error, error) {
+		writer := csv.NewWriter(w)
+		finish := func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		return writer.Write, finish, nil
+	})
+}
+
+// @Summary Export colleges as Excel
+// @Description Stream every college matching the filter as a single sheet .xlsx workbook
+// @Tags admin
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param Authorization header string true "Bearer JWT token"
+// @Param pin_code query string false "Pin code"
+// @Param mark_as_deleted query boolean false "Only deleted colleges instead of the live ones"
+// @Param search query string false "Words of the name or address"
+// @Param min_strength query integer false "Smallest collage_strength"
+// @Param max_strength query integer false "Largest collage_strength"
+// @Param sort query string false "Sort field, -field for descending"
+// @Param mapping query string false "Header=field pairs separated by commas"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/college/export/xlsx [get]
+func (m *CollegeManager) ExportXLSX(c *fiber.Ctx) error {
+	return m.streamColleges(c, Spreadsheet.ContentTypeXLSX, "colleges.xlsx", func(w io.Writer) (func([]string) error, func() error, error) {
+		writer, err := Spreadsheet.NewXLSXWriter(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		return writer.WriteRow, writer.Close, nil
+	})
+}
+
+// @Summary Update college
+// @Description Set only the fields present in the body, the unique name can not be changed and deleted colleges must be restored first
 // @Tags admin
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer JWT token"
-// @Param user body Admin.CollegeData true "College to be added"
-// @Success 200 {object} map[string]interface{}
+// @Param unique_name path string true "College unique name"
+// @Param college body Admin.CollegeUpdate true "Fields to update"
+// @Success 200 {object} Admin.CollegeData
 // @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
-// @Router /admin/college [patch]
+// @Router /admin/college/{unique_name} [patch]
 func (m *CollegeManager) UpdateCollege(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if _, jwtErr := m.jwtManager.IsValid(authHeader); jwtErr != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "failed to validate credentials at jwt",
-			"error":   jwtErr.Error(),
+	var update Admin.CollegeUpdate
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse college",
+			"error":   err.Error(),
 		})
 	}
-	var college *Admin.CollegeData
-	if err := c.BodyParser(college); err != nil {
+	if err := ValidatorSystem.GetValidator().IsValid(&update); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "failed to parse colleges",
+			"message": "failed to validate college",
 			"error":   err.Error(),
 		})
 	}
-	if err := m.dbManager.UpdateCollage(college, c.Context()); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "failed to update colleges in database",
+	if update.IsEmpty() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate college",
+			"error":   "no field to update",
+		})
+	}
+
+	before, _ := m.dbManager.FetchCollegeByName(c.Params("unique_name"), c.Context())
+	college, err := m.dbManager.UpdateCollage(c.Params("unique_name"), &update, c.Context())
+	if err != nil {
+		return c.Status(collegeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update college in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, college.CollageUniqueName, before, college)
+	return c.JSON(college)
+}
+
+// @Summary Delete college
+// @Description Mark a college as deleted, it can be restored later
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param unique_name path string true "College unique name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/college/{unique_name} [delete]
+func (m *CollegeManager) DeleteCollege(c *fiber.Ctx) error {
+	if err := m.dbManager.DeleteCollage(&Admin.CollegeNameData{CollageUniqueName: c.Params("unique_name")}, c.Context()); err != nil {
+		return c.Status(collegeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete college",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("unique_name"), fiber.Map{"mark_as_deleted": false}, fiber.Map{"mark_as_deleted": true})
+	return c.JSON(fiber.Map{
+		"message": "college deleted",
+	})
+}
+
+// @Summary Restore college
+// @Description Clear the deleted mark of a college
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param unique_name path string true "College unique name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/college/{unique_name}/restore [post]
+func (m *CollegeManager) RestoreCollege(c *fiber.Ctx) error {
+	if err := m.dbManager.RestoreCollage(&Admin.CollegeNameData{CollageUniqueName: c.Params("unique_name")}, c.Context()); err != nil {
+		return c.Status(collegeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to restore college",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("unique_name"), fiber.Map{"mark_as_deleted": true}, fiber.Map{"mark_as_deleted": false})
+	return c.JSON(fiber.Map{
+		"message": "college restored",
+	})
+}
+
+// @Summary Purge college
+// @Description Remove a deleted college for good, only for Full admins
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param unique_name path string true "College unique name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/college/{unique_name}/purge [delete]
+func (m *CollegeManager) PurgeCollege(c *fiber.Ctx) error {
+	before, _ := m.dbManager.FetchCollegeByName(c.Params("unique_name"), c.Context())
+	if err := m.dbManager.PurgeCollage(&Admin.CollegeNameData{CollageUniqueName: c.Params("unique_name")}, c.Context()); err != nil {
+		return c.Status(collegeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to purge college",
 			"error":   err.Error(),
 		})
 	}
+	Audit.Record(c, c.Params("unique_name"), before, nil)
 	return c.JSON(fiber.Map{
-		"message": "college updated",
+		"message": "college purged",
 	})
 }
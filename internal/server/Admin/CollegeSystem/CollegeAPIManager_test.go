@@ -0,0 +1,193 @@
+package CollegeSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/Spreadsheet"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	AdminData "HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/testutil"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCollegeFlowInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	writer := map[string]interface{}{"username": "writer", "email": "writer@admin.com", "password": "password@123", "excess_level": 3}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/User", token, writer); status != http.StatusCreated {
+		t.Fatalf("create user: expected status 201; got %d %v", status, body)
+	}
+	writerToken, _ := testutil.Login(t, s, "writer", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	status, body := testutil.DoJSON(t, s, "PATCH", "/admin/college/college-a", writerToken, map[string]interface{}{"pin_code": "110001"})
+	if status != http.StatusOK || body["pin_code"] != "110001" || body["collage_name"] != "College" {
+		t.Fatalf("partial update: expected only the pin code changed; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/college/college-a", writerToken, map[string]interface{}{"collage_name": "X"}); status != http.StatusBadRequest {
+		t.Fatalf("invalid update: expected status 400; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/college/college-a", writerToken, map[string]interface{}{}); status != http.StatusBadRequest {
+		t.Fatalf("empty update: expected status 400; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/college/college-z", writerToken, map[string]interface{}{"pin_code": "110001"}); status != http.StatusNotFound {
+		t.Fatalf("update unknown college: expected status 404; got %d", status)
+	}
+
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a/purge", token, nil); status != http.StatusConflict {
+		t.Fatalf("purge active college: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a", writerToken, nil); status != http.StatusOK {
+		t.Fatalf("delete college: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/college/college-a", writerToken, map[string]interface{}{"pin_code": "560001"}); status != http.StatusConflict {
+		t.Fatalf("update deleted college: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/college/college-a/restore", writerToken, nil); status != http.StatusOK {
+		t.Fatalf("restore college: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a", writerToken, nil); status != http.StatusOK {
+		t.Fatalf("delete college again: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a/purge", writerToken, nil); status != http.StatusForbidden {
+		t.Fatalf("purge as read and write: expected status 403; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a/purge", token, nil); status != http.StatusOK {
+		t.Fatalf("purge college: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/college/college-a/restore", token, nil); status != http.StatusNotFound {
+		t.Fatalf("restore purged college: expected status 404; got %d", status)
+	}
+}
+
+func TestCollegeSheetInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	sheet := "\ufeffName,Code,collage_address,PIN_CODE,collage_icon,collage_strength,Notes\n" +
+		"College B,college-b,MG Road,560001,icon.png,10,first\n" +
+		",,,,,,\n" +
+		"College C,college-c,MG Road,560001,icon.png,ten,typo\n" +
+		"College A,college-a,MG Road,560001,icon.png,10,again\n"
+	mapping := url.QueryEscape("Name=collage_name,Code=collage_unique_name")
+	status, raw := testutil.DoRaw(t, s, "POST", "/admin/college/import/csv?mode=partial&mapping="+mapping, token, "text/csv", []byte(sheet))
+	var report AdminData.CollegeImportReport
+	_ = json.Unmarshal(raw, &report)
+	if status != http.StatusOK || report.Inserted != 1 || len(report.Rows) != 3 || report.Rows[1].Row != 4 ||
+		report.Rows[1].Status != AdminData.RowInvalid || report.Rows[2].Status != AdminData.RowDuplicate {
+		t.Fatalf("csv import: expected college-b inserted and line 4 invalid; got %d %s", status, raw)
+	}
+	if status, _ = testutil.DoRaw(t, s, "POST", "/admin/college/import/csv?mapping=Name%3Dcollage_nam", token, "text/csv", []byte(sheet)); status != http.StatusBadRequest {
+		t.Fatalf("unknown mapping field: expected status 400; got %d", status)
+	}
+
+	status, raw = testutil.DoRaw(t, s, "GET", "/admin/college/export/csv?mapping="+mapping, token, "", nil)
+	records, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if status != http.StatusOK || err != nil || len(records) != 3 || cell(records, 0, 0) != "Name" || cell(records, 2, 1) != "college-b" {
+		t.Fatalf("csv export: expected a header and 2 colleges; got %d %q %v", status, records, err)
+	}
+
+	status, raw = testutil.DoRaw(t, s, "GET", "/admin/college/export/xlsx", token, "", nil)
+	rows, err := Spreadsheet.ReadXLSX(bytes.NewReader(raw), int64(len(raw)))
+	if status != http.StatusOK || err != nil || len(rows) != 3 || rows[2].Line != 3 || cell([][]string{rows[0].Values}, 0, 1) != "collage_unique_name" {
+		t.Fatalf("xlsx export: expected a header and 2 colleges; got %d %q %v", status, rows, err)
+	}
+	// a workbook exported from one server import into another unchanged
+	other := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	otherToken, _ := testutil.Login(t, other, "admin", "password@123")
+	status, body := testutil.DoRaw(t, other, "POST", "/admin/college/import/xlsx", otherToken, Spreadsheet.ContentTypeXLSX, raw)
+	if status != http.StatusOK {
+		t.Fatalf("xlsx import: expected status OK; got %d %s", status, body)
+	}
+	if status, _ = testutil.DoRaw(t, other, "POST", "/admin/college/import/xlsx", otherToken, Spreadsheet.ContentTypeXLSX, []byte(sheet)); status != http.StatusBadRequest {
+		t.Fatalf("xlsx import of a csv: expected status 400; got %d", status)
+	}
+
+	// mark_as_deleted select the deleted colleges instead of the live ones, the same for the list and the exports
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a", token, nil); status != http.StatusOK {
+		t.Fatalf("delete college: expected status OK; got %d", status)
+	}
+	status, listed := testutil.DoJSON(t, s, "GET", "/admin/college?mark_as_deleted=true", token, nil)
+	if status != http.StatusOK || len(testutil.Path[[]interface{}](t, listed, "items")) != 1 {
+		t.Fatalf("deleted list: expected only college-a; got %d %v", status, listed)
+	}
+	status, raw = testutil.DoRaw(t, s, "GET", "/admin/college/export/csv?mark_as_deleted=true", token, "", nil)
+	records, err = csv.NewReader(bytes.NewReader(raw)).ReadAll()
+	if status != http.StatusOK || err != nil || len(records) != 2 || cell(records, 1, 1) != "college-a" {
+		t.Fatalf("deleted csv export: expected only college-a; got %d %q %v", status, records, err)
+	}
+}
+
+// cell return a cell of a sheet, or an empty string when it is out of range
+func cell(rows [][]string, row int, column int) string {
+	if row >= len(rows) || column >= len(rows[row]) {
+		return ""
+	}
+	return rows[row][column]
+}
+
+func TestCollegeListingInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	var batch []map[string]interface{}
+	for i, address := range []string{"MG Road", "Park Street", "MG Road", "Lake View", "Park Street"} {
+		row := testutil.CollegeRow(fmt.Sprintf("college-%d", i))
+		row["collage_address"] = address
+		row["collage_strength"] = (i%3 + 1) * 5
+		batch = append(batch, row)
+	}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/college", token, batch); status != http.StatusOK {
+		t.Fatalf("add colleges: expected status OK; got %d %v", status, body)
+	}
+	uniqueNames := func(body map[string]interface{}) []string {
+		var names []string
+		for _, item := range testutil.Path[[]interface{}](t, body, "items") {
+			names = append(names, testutil.Path[string](t, item, "collage_unique_name"))
+		}
+		return names
+	}
+
+	status, body := testutil.DoJSON(t, s, "GET", "/admin/college?search=park&limit=10", token, nil)
+	if status != http.StatusOK || fmt.Sprint(uniqueNames(body)) != "[college-1 college-4]" || testutil.Path[float64](t, body, "total") != 2 {
+		t.Fatalf("search: expected the Park Street colleges; got %d %v", status, body)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/college?min_strength=10&max_strength=15&sort=-collage_strength", token, nil)
+	if status != http.StatusOK || fmt.Sprint(uniqueNames(body)) != "[college-2 college-4 college-1]" {
+		t.Fatalf("strength range: expected 3 colleges by strength descending; got %d %v", status, body)
+	}
+
+	// walk every college two at a time, ties on the strength are ordered by unique name
+	var walked []string
+	path := "/admin/college?limit=2&sort=collage_strength"
+	for pages := 0; ; pages++ {
+		if status, body = testutil.DoJSON(t, s, "GET", path, token, nil); status != http.StatusOK || pages > 3 {
+			t.Fatalf("cursor page: expected status OK; got %d %v", status, body)
+		}
+		walked = append(walked, uniqueNames(body)...)
+		next, _ := body["next_cursor"].(string)
+		if next == "" {
+			break
+		}
+		path = "/admin/college?limit=2&sort=collage_strength&cursor=" + url.QueryEscape(next)
+	}
+	if fmt.Sprint(walked) != "[college-0 college-3 college-1 college-4 college-2]" {
+		t.Fatalf("cursor pages: expected every college once in order; got %v", walked)
+	}
+
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/college?sort=-collage_name&cursor="+url.QueryEscape(walked[0]), token, nil); status != http.StatusBadRequest {
+		t.Fatalf("bad cursor: expected status 400; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/college?sort=collage_icon", token, nil); status != http.StatusBadRequest {
+		t.Fatalf("unknown sort field: expected status 400; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/college?min_strength=15&max_strength=5", token, nil); status != http.StatusBadRequest {
+		t.Fatalf("inverted strength range: expected status 400; got %d", status)
+	}
+}
@@ -0,0 +1,117 @@
+package CollegeSystem
+
+import (
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+)
+
+// MaxImportRows is the largest batch of colleges accepted by one import
+const MaxImportRows = 500
+
+var (
+	ErrInvalidImportMode = errors.New("mode must be all_or_nothing or partial")
+	ErrTooManyRows       = errors.New("import is limited to 500 colleges")
+	ErrImportRejected    = errors.New("import has invalid or duplicate rows, nothing was inserted")
+)
+
+func parseImportMode(value string) (Admin.ImportMode, error) {
+	switch mode := Admin.ImportMode(value); mode {
+	case "":
+		return Admin.AllOrNothing, nil
+	case Admin.AllOrNothing, Admin.Partial:
+		return mode, nil
+	default:
+		return "", ErrInvalidImportMode
+	}
+}
+
+// importRow is one college read from a request, Line is the row number shown
+// in the report and ParseError a value that could not be read into the college
+type importRow struct {
+	Line       int
+	College    Admin.CollegeData
+	ParseError error
+}
+
+// importColleges validate every row up front then insert the valid ones. In
+// AllOrNothing mode a single invalid or duplicate row reject the whole batch
+// with ErrImportRejected and the report tell which rows are at fault
+func (m *CollegeManager) importColleges(rows []importRow, mode Admin.ImportMode, ctx context.Context) (*Admin.CollegeImportReport, error) {
+	if len(rows) > MaxImportRows {
+		return nil, ErrTooManyRows
+	}
+	report := &Admin.CollegeImportReport{Mode: mode, Rows: make([]Admin.CollegeImportRow, len(rows))}
+	valid := make([]Admin.CollegeData, 0, len(rows))
+	rowOf := map[string]int{}
+	for i := range rows {
+		row := &report.Rows[i]
+		row.Row = rows[i].Line
+		row.CollageUniqueName = rows[i].College.CollageUniqueName
+		err := rows[i].ParseError
+		if err == nil {
+			err = ValidatorSystem.GetValidator().IsValid(&rows[i].College)
+		}
+		if err != nil {
+			row.Status, row.Reason = Admin.RowInvalid, err.Error()
+			continue
+		}
+		if _, seen := rowOf[row.CollageUniqueName]; seen {
+			row.Status, row.Reason = Admin.RowDuplicate, "unique name is listed more than once in the import"
+			continue
+		}
+		rowOf[row.CollageUniqueName] = i
+		row.Status = Admin.RowInserted
+		valid = append(valid, rows[i].College)
+	}
+
+	names := make([]string, 0, len(valid))
+	for _, college := range valid {
+		names = append(names, college.CollageUniqueName)
+	}
+	existing, err := m.dbManager.FetchExistingNames(names, ctx)
+	if err != nil {
+		return nil, err
+	}
+	markExisting(report, rowOf, existing)
+	rejected := len(valid) < len(rows) || len(existing) > 0
+	if !rejected || mode == Admin.Partial {
+		// the storage check again in case another request added one of them meanwhile
+		duplicates, err := m.dbManager.AddCollege(valid, mode, ctx)
+		if err != nil && !errors.Is(err, AdminDB.ErrCollegeDuplicate) {
+			return nil, err
+		}
+		markExisting(report, rowOf, duplicates)
+		rejected = rejected || err != nil
+	}
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if rejected && mode == Admin.AllOrNothing && row.Status == Admin.RowInserted {
+			row.Status = Admin.RowSkipped
+		}
+		switch row.Status {
+		case Admin.RowInserted:
+			report.Inserted++
+		case Admin.RowDuplicate:
+			report.Duplicate++
+		case Admin.RowInvalid:
+			report.Invalid++
+		case Admin.RowSkipped:
+			report.Skipped++
+		}
+	}
+	if rejected && mode == Admin.AllOrNothing {
+		return report, ErrImportRejected
+	}
+	return report, nil
+}
+
+func markExisting(report *Admin.CollegeImportReport, rowOf map[string]int, names []string) {
+	for _, name := range names {
+		row := &report.Rows[rowOf[name]]
+		row.Status, row.Reason = Admin.RowDuplicate, "college with the same unique name already exists"
+	}
+}
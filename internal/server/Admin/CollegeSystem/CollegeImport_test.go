@@ -0,0 +1,51 @@
+package CollegeSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	AdminData "HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/testutil"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCollegeImportInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	invalid := testutil.CollegeRow("college-x")
+	delete(invalid, "collage_address")
+	batch := []map[string]interface{}{testutil.CollegeRow("college-b"), testutil.CollegeRow("college-a"), invalid, testutil.CollegeRow("college-c"), testutil.CollegeRow("college-b")}
+	rowStatus := func(body map[string]interface{}) []string {
+		var statuses []string
+		for _, row := range testutil.Path[[]interface{}](t, body, "rows") {
+			statuses = append(statuses, testutil.Path[string](t, row, "status"))
+		}
+		return statuses
+	}
+
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/college", token, batch)
+	report := testutil.Path[map[string]interface{}](t, body, "report")
+	if status != http.StatusUnprocessableEntity || fmt.Sprint(rowStatus(report)) != "[skipped duplicate invalid skipped duplicate]" {
+		t.Fatalf("all or nothing import: expected the batch rejected; got %d %v", status, body)
+	}
+	if colleges, err := db.AdminDB.CollegeDB.FetchCollege(&AdminData.CollegeFilter{Page: 1, Limit: 10}, context.Background()); err != nil || len(colleges.Items) != 1 {
+		t.Fatalf("rejected import: expected nothing inserted; got %v %v", colleges, err)
+	}
+
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/college?mode=partial", token, batch)
+	if status != http.StatusOK || fmt.Sprint(rowStatus(body)) != "[inserted duplicate invalid inserted duplicate]" || testutil.Path[float64](t, body, "inserted") != 2 {
+		t.Fatalf("partial import: expected the valid rows inserted; got %d %v", status, body)
+	}
+	if colleges, err := db.AdminDB.CollegeDB.FetchCollege(&AdminData.CollegeFilter{Page: 1, Limit: 10}, context.Background()); err != nil || len(colleges.Items) != 3 {
+		t.Fatalf("partial import: expected 3 colleges; got %v %v", colleges, err)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/college?mode=maybe", token, batch); status != http.StatusBadRequest {
+		t.Fatalf("unknown import mode: expected status 400; got %d", status)
+	}
+}
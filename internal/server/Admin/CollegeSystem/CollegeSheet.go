@@ -0,0 +1,204 @@
+package CollegeSystem
+
+import (
+	"HostelApp/internal/Spreadsheet"
+	"HostelApp/internal/storageData/Admin"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidMapping = errors.New("mapping must be a comma separated list of header=field with known college fields")
+	ErrNoKnownColumn  = errors.New("header row has no known college column")
+)
+
+// collegeColumns are the spreadsheet fields of a college, named like its json
+// fields. mark_as_deleted is only exported, an import never create deleted colleges
+var collegeColumns = []string{"collage_name", "collage_unique_name", "collage_address", "pin_code", "collage_icon", "collage_strength", "mark_as_deleted"}
+
+// headerMapping rename spreadsheet columns to college fields, headers are
+// compared without case and surrounding spaces
+type headerMapping struct {
+	fields  map[string]string // normalized header to field
+	headers map[string]string // field to header as written in the mapping
+}
+
+// parseMapping read "Header=field,Other Header=field", columns not listed are
+// matched by their field name
+func parseMapping(value string) (*headerMapping, error) {
+	mapping := &headerMapping{fields: map[string]string{}, headers: map[string]string{}}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		header, field, ok := strings.Cut(pair, "=")
+		header, field = strings.TrimSpace(header), strings.TrimSpace(field)
+		if !ok || header == "" || !isImportColumn(field) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMapping, pair)
+		}
+		mapping.fields[normalizeHeader(header)] = field
+		mapping.headers[field] = header
+	}
+	return mapping, nil
+}
+
+func normalizeHeader(header string) string {
+	// spreadsheet applications often start a csv export with a byte order mark
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+}
+
+func isImportColumn(field string) bool {
+	for _, column := range collegeColumns[:len(collegeColumns)-1] {
+		if field == column {
+			return true
+		}
+	}
+	return false
+}
+
+// readCSV read the records of a csv sheet with the line each one start on, a
+// quoted value can span several lines and empty lines are skipped
+func readCSV(data []byte) ([]Spreadsheet.Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	var rows []Spreadsheet.Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, Spreadsheet.Row{Line: line, Values: record})
+	}
+}
+
+// sheetRows turn a header row and the data rows under it into import rows, an
+// import row keep the line of its row in the sheet
+func sheetRows(records []Spreadsheet.Row, mapping *headerMapping) ([]importRow, error) {
+	if len(records) == 0 {
+		return nil, ErrNoKnownColumn
+	}
+	fields := make([]string, len(records[0].Values))
+	known := false
+	for i, header := range records[0].Values {
+		header = normalizeHeader(header)
+		if field, ok := mapping.fields[header]; ok {
+			fields[i] = field
+		} else if isImportColumn(header) {
+			fields[i] = header
+		}
+		known = known || fields[i] != ""
+	}
+	if !known {
+		return nil, ErrNoKnownColumn
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if isBlankRecord(record.Values) {
+			continue
+		}
+		row := importRow{Line: record.Line}
+		for column, value := range record.Values {
+			if column >= len(fields) || fields[column] == "" {
+				continue
+			}
+			if err := setCollegeField(&row.College, fields[column], unescapeFormula(strings.TrimSpace(value))); err != nil && row.ParseError == nil {
+				row.ParseError = err
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func setCollegeField(college *Admin.CollegeData, field string, value string) error {
+	switch field {
+	case "collage_name":
+		college.CollageName = value
+	case "collage_unique_name":
+		college.CollageUniqueName = value
+	case "collage_address":
+		college.CollageAddress = value
+	case "pin_code":
+		college.PinCode = value
+	case "collage_icon":
+		college.CollageIcon = value
+	case "collage_strength":
+		if value == "" {
+			return nil
+		}
+		strength, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("collage_strength %q is not a whole number", value)
+		}
+		college.CollageStrength = strength
+	}
+	return nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// exportHeader is the header row of an export, renamed by the same mapping an
+// import would use to read it back
+func exportHeader(mapping *headerMapping) []string {
+	header := make([]string, 0, len(collegeColumns))
+	for _, column := range collegeColumns {
+		if name, ok := mapping.headers[column]; ok {
+			column = name
+		}
+		header = append(header, column)
+	}
+	return header
+}
+
+// formulaPrefixes start a formula when a spreadsheet application open a csv
+const formulaPrefixes = "=+-@"
+
+// escapeFormula quote a value a spreadsheet would run as a formula, the
+// leading ' make it show as text
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula undo escapeFormula so an export import back unchanged
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+func collegeRecord(college *Admin.CollegeData) []string {
+	record := []string{
+		college.CollageName,
+		college.CollageUniqueName,
+		college.CollageAddress,
+		college.PinCode,
+		college.CollageIcon,
+		strconv.FormatInt(college.CollageStrength, 10),
+		strconv.FormatBool(college.MarkAsDeleted),
+	}
+	for i := range record {
+		record[i] = escapeFormula(record[i])
+	}
+	return record
+}
@@ -0,0 +1,82 @@
+package CollegeSystem
+
+import (
+	"HostelApp/internal/Spreadsheet"
+	"HostelApp/internal/storageData/Admin"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := parseMapping(" College Name = collage_name ,PIN=pin_code")
+	if err != nil {
+		t.Fatalf("parseMapping failed. Err: %v", err)
+	}
+	if mapping.fields["college name"] != "collage_name" || mapping.headers["pin_code"] != "PIN" {
+		t.Fatalf("unexpected mapping %+v", mapping)
+	}
+	for _, value := range []string{"Name", "=collage_name", "Name=collage_nam", "Deleted=mark_as_deleted"} {
+		if _, err = parseMapping(value); !errors.Is(err, ErrInvalidMapping) {
+			t.Fatalf("%q: expected ErrInvalidMapping; got %v", value, err)
+		}
+	}
+	header := exportHeader(mapping)
+	if fmt.Sprint(header) != "[College Name collage_unique_name collage_address PIN collage_icon collage_strength mark_as_deleted]" {
+		t.Fatalf("expected the export header renamed by the mapping; got %v", header)
+	}
+}
+
+func TestSheetRows(t *testing.T) {
+	mapping, err := parseMapping("Name=collage_name")
+	if err != nil {
+		t.Fatalf("parseMapping failed. Err: %v", err)
+	}
+	rows, err := sheetRows([]Spreadsheet.Row{
+		{Line: 1, Values: []string{"\ufeffNAME", "Collage_Unique_Name", "notes", "collage_strength"}},
+		{Line: 2, Values: []string{"College A", "college-a", "ignored", "10"}},
+		{Line: 3, Values: []string{"", " ", "", ""}},
+		{Line: 5, Values: []string{"'=College B", "college-b", "", "ten"}},
+	}, mapping)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected 2 rows, the blank one skipped; got %+v %v", rows, err)
+	}
+	first, second := rows[0], rows[1]
+	if first.Line != 2 || first.College.CollageName != "College A" || first.College.CollageUniqueName != "college-a" || first.College.CollageStrength != 10 || first.ParseError != nil {
+		t.Fatalf("unexpected first row %+v", first)
+	}
+	if second.Line != 5 || second.College.CollageName != "=College B" || second.ParseError == nil {
+		t.Fatalf("expected line 5 unescaped with a strength parse error; got %+v", second)
+	}
+	if _, err = sheetRows([]Spreadsheet.Row{{Line: 1, Values: []string{"a", "b"}}, {Line: 2, Values: []string{"1", "2"}}}, mapping); !errors.Is(err, ErrNoKnownColumn) {
+		t.Fatalf("expected ErrNoKnownColumn; got %v", err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	rows, err := readCSV([]byte("name,address\n\nCollege A,\"MG Road\nBangalore\"\nCollege B,Delhi\n"))
+	if err != nil {
+		t.Fatalf("readCSV failed. Err: %v", err)
+	}
+	lines := make([]int, 0, len(rows))
+	for _, row := range rows {
+		lines = append(lines, row.Line)
+	}
+	if fmt.Sprint(lines) != "[1 3 5]" || rows[1].Values[1] != "MG Road\nBangalore" {
+		t.Fatalf("expected the lines each record start on; got %v %q", lines, rows)
+	}
+}
+
+func TestCollegeRecordEscapeFormula(t *testing.T) {
+	college := &Admin.CollegeData{CollageName: "=HYPERLINK(\"x\")", CollageUniqueName: "college-a", CollageAddress: "+91 road",
+		PinCode: "-1", CollageIcon: "@icon", CollageStrength: 10}
+	record := collegeRecord(college)
+	if fmt.Sprintf("%q", record) != `["'=HYPERLINK(\"x\")" "college-a" "'+91 road" "'-1" "'@icon" "10" "false"]` {
+		t.Fatalf("expected the formula cells escaped; got %q", record)
+	}
+	for i, value := range []string{college.CollageName, college.CollageUniqueName, college.CollageAddress, college.PinCode, college.CollageIcon} {
+		if got := unescapeFormula(record[i]); got != value {
+			t.Fatalf("expected %q back; got %q", value, got)
+		}
+	}
+}
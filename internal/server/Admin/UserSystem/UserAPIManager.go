@@ -0,0 +1,443 @@
+package UserSystem
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Admin"
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+// UserManager manage admin accounts, creating one stay in AuthenticationManager
+type UserManager struct {
+	dbManager  AdminDB.ILoginDBService
+	attemptDB  AdminDB.IAttemptDBService
+	sessions   AdminDB.ISessionDBService
+	jwtManager *JWTManager.JWTManager
+}
+
+func NewUserManager(dbManager AdminDB.ILoginDBService, attemptDB AdminDB.IAttemptDBService, sessions AdminDB.ISessionDBService, jwtManager *JWTManager.JWTManager) *UserManager {
+	instance := &UserManager{
+		dbManager:  dbManager,
+		attemptDB:  attemptDB,
+		sessions:   sessions,
+		jwtManager: jwtManager,
+	}
+	return instance
+}
+
+// revokeSessions log an admin out of every device except the session keep,
+// an empty keep revoke them all. The tokens of each session are put on the
+// revocation list before the session is dropped
+func (m *UserManager) revokeSessions(_id string, keep string, ctx context.Context) error {
+	sessions, err := m.sessions.FetchSessions(_id, ctx)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == keep {
+			continue
+		}
+		if err = m.jwtManager.RevokeSession(session.ID, ctx); err != nil {
+			return err
+		}
+		if err = m.sessions.DeleteSession(session.ID, _id, ctx); err != nil && !errors.Is(err, AdminDB.ErrSessionNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func revokeFailed(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "saved but failed to revoke the sessions of the admin",
+		"error":   err.Error(),
+	})
+}
+
+func (m *UserManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/me", Method: internal.GET, Handler: m.GetProfile, Protected: true},
+		{Path: "/admin/me", Method: internal.PATCH, Handler: m.UpdateProfile, Protected: true},
+		{Path: "/admin/me/password", Method: internal.POST, Handler: m.ChangePassword, Protected: true},
+		{Path: "/admin/users", Method: internal.GET, Handler: m.GetAdmins, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id", Method: internal.GET, Handler: m.GetAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id", Method: internal.PATCH, Handler: m.UpdateAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id", Method: internal.DELETE, Handler: m.DeleteAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id/disable", Method: internal.POST, Handler: m.DisableAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id/enable", Method: internal.POST, Handler: m.EnableAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id/password", Method: internal.POST, Handler: m.ResetPassword, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id/unlock", Method: internal.POST, Handler: m.UnlockAdmin, Permission: internal.ManageAdminPermission},
+		{Path: "/admin/users/:id/2fa/reset", Method: internal.POST, Handler: m.ResetTwoFactor, Permission: internal.ManageAdminPermission},
+	}
+}
+
+func actor(c *fiber.Ctx) string {
+	if claims := JWTManager.GetClaims(c); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, AdminDB.ErrAdminNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, AdminDB.ErrAdminDuplicate), errors.Is(err, AdminDB.ErrLastFullAdmin):
+		return fiber.StatusConflict
+	case errors.Is(err, AdminDB.ErrPasswordMismatch):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// @Summary Get own profile
+// @Description Fetch the admin of the JWT
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/me [get]
+func (m *UserManager) GetProfile(c *fiber.Ctx) error {
+	admin, err := m.dbManager.FetchAdmin(actor(c), c.Context())
+	if err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch profile",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(admin)
+}
+
+// @Summary Update own profile
+// @Description Change the email of the admin of the JWT, the access level can only be changed by a Full admin
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param profile body Admin.AdminProfileUpdate true "Profile"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/me [patch]
+func (m *UserManager) UpdateProfile(c *fiber.Ctx) error {
+	var profile Admin.AdminProfileUpdate
+	if err := c.BodyParser(&profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse profile",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate profile",
+			"error":   err.Error(),
+		})
+	}
+	return m.applyUpdate(c, actor(c), &Admin.AdminUserUpdate{Email: &profile.Email})
+}
+
+// @Summary Change own password
+// @Description Replace the password of the admin of the JWT after checking the old one, the refresh session is dropped so other devices have to login again
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param password body Admin.PasswordChange true "Old and new password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/me/password [post]
+func (m *UserManager) ChangePassword(c *fiber.Ctx) error {
+	var change Admin.PasswordChange
+	if err := c.BodyParser(&change); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse password",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&change); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate password",
+			"error":   err.Error(),
+		})
+	}
+	if err := m.dbManager.ChangePassword(actor(c), change.OldPassword, change.NewPassword, c.Context()); err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to change password",
+			"error":   err.Error(),
+		})
+	}
+	// other devices logged in with the old password are logged out
+	if err := m.revokeSessions(actor(c), JWTManager.GetClaims(c).SessionID, c.Context()); err != nil {
+		return revokeFailed(c, err)
+	}
+	Audit.Record(c, actor(c), nil, fiber.Map{"password": true})
+	return c.JSON(fiber.Map{
+		"message": "password changed",
+	})
+}
+
+// @Summary Get admin list
+// @Description Fetch admins sorted by username (requires Full access level)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string false "Page number"
+// @Param limit query string false "Items per page, at most 50"
+// @Param excess_level query integer false "1 Full, 2 ReadOnly or 3 ReadAndWrite"
+// @Success 200 {object} Listing.Page[Admin.AdminUser]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/users [get]
+func (m *UserManager) GetAdmins(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	level, _ := strconv.Atoi(c.Query("excess_level", "0"))
+	filter := Admin.AdminUserFilter{Page: page, Limit: limit, ExcessLevel: Admin.ExcessType(level)}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+	admins, err := m.dbManager.FetchAdmins(&filter, c.Context())
+	if err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch admins",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(admins)
+}
+
+// @Summary Get admin
+// @Description Fetch an admin by id (requires Full access level)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{id} [get]
+func (m *UserManager) GetAdmin(c *fiber.Ctx) error {
+	admin, err := m.dbManager.FetchAdmin(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch admin",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(admin)
+}
+
+// @Summary Update admin
+// @Description Change the email, access level or disabled flag of an admin (requires Full access level). The last enabled Full admin can not be demoted or disabled
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Param user body Admin.AdminUserUpdate true "Fields to update"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/users/{id} [patch]
+func (m *UserManager) UpdateAdmin(c *fiber.Ctx) error {
+	var update Admin.AdminUserUpdate
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse admin",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate admin",
+			"error":   err.Error(),
+		})
+	}
+	if update.IsEmpty() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate admin",
+			"error":   "no field to update",
+		})
+	}
+	return m.applyUpdate(c, c.Params("id"), &update)
+}
+
+// @Summary Disable admin
+// @Description Block the login of an admin and drop their refresh session (requires Full access level)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/users/{id}/disable [post]
+func (m *UserManager) DisableAdmin(c *fiber.Ctx) error {
+	disabled := true
+	return m.applyUpdate(c, c.Params("id"), &Admin.AdminUserUpdate{Disabled: &disabled})
+}
+
+// @Summary Enable admin
+// @Description Allow a disabled admin to login again (requires Full access level)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} Admin.AdminUser
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{id}/enable [post]
+func (m *UserManager) EnableAdmin(c *fiber.Ctx) error {
+	disabled := false
+	return m.applyUpdate(c, c.Params("id"), &Admin.AdminUserUpdate{Disabled: &disabled})
+}
+
+func (m *UserManager) applyUpdate(c *fiber.Ctx, _id string, update *Admin.AdminUserUpdate) error {
+	before, _ := m.dbManager.FetchAdmin(_id, c.Context())
+	admin, err := m.dbManager.UpdateAdmin(_id, update, c.Context())
+	if err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update admin in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, admin.ID, before, admin)
+	// the access level is in the JWT, a demoted admin must not keep it until expiry
+	if admin.Disabled || before == nil || before.ExcessLevel != admin.ExcessLevel {
+		if err = m.revokeSessions(admin.ID, "", c.Context()); err != nil {
+			return revokeFailed(c, err)
+		}
+	}
+	return c.JSON(admin)
+}
+
+// @Summary Delete admin
+// @Description Remove an admin for good (requires Full access level), the last enabled Full admin can not be deleted
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/users/{id} [delete]
+func (m *UserManager) DeleteAdmin(c *fiber.Ctx) error {
+	before, _ := m.dbManager.FetchAdmin(c.Params("id"), c.Context())
+	if err := m.dbManager.DeleteAdmin(c.Params("id"), c.Context()); err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete admin",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), before, nil)
+	if err := m.revokeSessions(c.Params("id"), "", c.Context()); err != nil {
+		return revokeFailed(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"message": "admin deleted",
+	})
+}
+
+// @Summary Reset admin password
+// @Description Set a new password for an admin without the old one (requires Full access level), the admin has to login again
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Param password body Admin.PasswordReset true "New password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{id}/password [post]
+func (m *UserManager) ResetPassword(c *fiber.Ctx) error {
+	var reset Admin.PasswordReset
+	if err := c.BodyParser(&reset); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse password",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&reset); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate password",
+			"error":   err.Error(),
+		})
+	}
+	if err := m.dbManager.SetPassword(c.Params("id"), reset.NewPassword, c.Context()); err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to reset password",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), nil, fiber.Map{"password": true})
+	if err := m.revokeSessions(c.Params("id"), "", c.Context()); err != nil {
+		return revokeFailed(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"message": "password reset",
+	})
+}
+
+// @Summary Unlock admin
+// @Description Forget the failed logins of an admin so a lockout end now (requires Full access level), failures counted for an IP are kept
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/unlock [post]
+func (m *UserManager) UnlockAdmin(c *fiber.Ctx) error {
+	admin, err := m.dbManager.FetchAdmin(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch admin",
+			"error":   err.Error(),
+		})
+	}
+	if err = m.attemptDB.ClearAttempts(Admin.UserAttemptKey(admin.Username), c.Context()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to clear failed logins",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "admin unlocked",
+	})
+}
+
+// @Summary Reset admin 2FA
+// @Description Turn off 2FA of an admin that lost its device and recovery codes (requires Full access level), the admin can enroll again
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Admin id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/2fa/reset [post]
+func (m *UserManager) ResetTwoFactor(c *fiber.Ctx) error {
+	if err := m.dbManager.DisableTwoFactor(c.Params("id"), c.Context()); err != nil {
+		return c.Status(userErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to reset two factor authentication",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "two factor authentication reset",
+	})
+}
@@ -0,0 +1,94 @@
+package UserSystem_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"net/http"
+	"testing"
+)
+
+func TestAdminUserManagementInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	for _, user := range []map[string]interface{}{
+		{"username": "root2", "email": "root2@admin.com", "password": "password@123", "excess_level": 1},
+		{"username": "writer", "email": "writer@admin.com", "password": "password@123", "excess_level": 3},
+	} {
+		if status, body := testutil.DoJSON(t, s, "POST", "/admin/User", token, user); status != http.StatusCreated {
+			t.Fatalf("create user: expected status 201; got %d %v", status, body)
+		}
+	}
+
+	status, body := testutil.DoJSON(t, s, "GET", "/admin/users?limit=10", token, nil)
+	if status != http.StatusOK || testutil.Path[float64](t, body, "total") != 3 {
+		t.Fatalf("list admins: expected 3 admins; got %d %v", status, body)
+	}
+	ids := map[string]string{}
+	for _, item := range testutil.Path[[]interface{}](t, body, "items") {
+		admin := testutil.Path[map[string]interface{}](t, item)
+		if _, ok := admin["password"]; ok {
+			t.Fatalf("list admins: password leaked in %v", admin)
+		}
+		ids[testutil.Path[string](t, admin, "username")] = testutil.Path[string](t, admin, "id")
+	}
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/me", token, nil); status != http.StatusOK || body["id"] != ids["admin"] || len(body) != 7 {
+		t.Fatalf("profile: expected the admin without secrets; got %d %v", status, body)
+	}
+
+	// with root2 disabled the admin is the last enabled Full admin
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/users/"+ids["root2"]+"/disable", token, nil); status != http.StatusOK || body["disabled"] != true {
+		t.Fatalf("disable root2: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/login", "", map[string]string{"username": "root2", "password": "password@123"}); status == http.StatusOK {
+		t.Fatal("login of a disabled admin: expected a failure")
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/users/"+ids["admin"], token, map[string]interface{}{"excess_level": 2}); status != http.StatusConflict {
+		t.Fatalf("demote the last Full admin: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/users/"+ids["admin"], token, nil); status != http.StatusConflict {
+		t.Fatalf("delete the last Full admin: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/users/"+ids["root2"]+"/enable", token, nil); status != http.StatusOK {
+		t.Fatalf("enable root2: expected status OK; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/users/"+ids["admin"], token, map[string]interface{}{"excess_level": 2}); status != http.StatusOK || testutil.Path[float64](t, body, "excess_level") != 2 {
+		t.Fatalf("demote admin: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/users", token, nil); status != http.StatusUnauthorized {
+		t.Fatalf("token issued before the demotion: expected status 401; got %d", status)
+	}
+
+	rootToken, _ := testutil.Login(t, s, "root2", "password@123")
+	writerToken, _ := testutil.Login(t, s, "writer", "password@123")
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/users", writerToken, nil); status != http.StatusForbidden {
+		t.Fatalf("list admins as read and write: expected status 403; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/me", writerToken, map[string]string{"email": "root2@admin.com"}); status != http.StatusConflict {
+		t.Fatalf("profile with a taken email: expected status 409; got %d %v", status, body)
+	}
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/me", writerToken, map[string]string{"email": "writer@hostel.com"}); status != http.StatusOK || body["email"] != "writer@hostel.com" {
+		t.Fatalf("update profile: expected the new email; got %d %v", status, body)
+	}
+	change := map[string]string{"old_password": "wrong@12345", "new_password": "changed@123"}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/me/password", writerToken, change); status != http.StatusBadRequest {
+		t.Fatalf("change password with a wrong old one: expected status 400; got %d", status)
+	}
+	change["old_password"] = "password@123"
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/me/password", writerToken, change); status != http.StatusOK {
+		t.Fatalf("change password: expected status OK; got %d", status)
+	}
+	testutil.Login(t, s, "writer", "changed@123")
+
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/users/"+ids["writer"]+"/password", rootToken, map[string]string{"new_password": "reset@12345"}); status != http.StatusOK {
+		t.Fatalf("reset password: expected status OK; got %d", status)
+	}
+	testutil.Login(t, s, "writer", "reset@12345")
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/users/"+ids["writer"], rootToken, nil); status != http.StatusOK {
+		t.Fatalf("delete writer: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/users/"+ids["writer"], rootToken, nil); status != http.StatusNotFound {
+		t.Fatalf("get deleted writer: expected status 404; got %d", status)
+	}
+}
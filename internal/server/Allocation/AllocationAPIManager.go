@@ -0,0 +1,419 @@
+package Allocation
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/ValidatorSystem"
+	AllocationDB "HostelApp/internal/database/Allocation"
+	HostelDB "HostelApp/internal/database/Hostel"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Allocation"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type AllocationManager struct {
+	engine    *AllocationEngine
+	dbManager AllocationDB.IAllocationDBService
+}
+
+func NewAllocationManager(engine *AllocationEngine, dbManager AllocationDB.IAllocationDBService) *AllocationManager {
+	instance := &AllocationManager{
+		engine:    engine,
+		dbManager: dbManager,
+	}
+	return instance
+}
+
+func (m *AllocationManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/allocation", Method: internal.GET, Handler: m.GetAllocations, Permission: internal.ReadPermission},
+		{Path: "/admin/allocation", Method: internal.POST, Handler: m.Allocate, Permission: internal.WritePermission},
+		{Path: "/admin/allocation/bulk", Method: internal.POST, Handler: m.BulkAllocate, Permission: internal.WritePermission},
+		{Path: "/admin/allocation/:id", Method: internal.GET, Handler: m.GetAllocation, Permission: internal.ReadPermission},
+		{Path: "/admin/allocation/:id/transfer", Method: internal.POST, Handler: m.Transfer, Permission: internal.WritePermission},
+		{Path: "/admin/allocation/:id/vacate", Method: internal.POST, Handler: m.Vacate, Permission: internal.WritePermission},
+		{Path: "/admin/student/:id/allocation", Method: internal.GET, Handler: m.GetStudentHistory, Permission: internal.ReadPermission},
+		{Path: "/admin/room/:room_id/bed", Method: internal.GET, Handler: m.GetRoomBeds, Permission: internal.ReadPermission},
+		{Path: "/admin/waitlist", Method: internal.GET, Handler: m.GetWaitlist, Permission: internal.ReadPermission},
+		{Path: "/admin/waitlist", Method: internal.POST, Handler: m.JoinWaitlist, Permission: internal.WritePermission},
+		{Path: "/admin/waitlist/:id", Method: internal.DELETE, Handler: m.CancelWaitlist, Permission: internal.WritePermission},
+	}
+}
+
+func allocationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, AllocationDB.ErrAllocationNotFound), errors.Is(err, AllocationDB.ErrWaitlistEntryNotFound),
+		errors.Is(err, StudentDB.ErrStudentNotFound), errors.Is(err, HostelDB.ErrHostelNotFound), errors.Is(err, HostelDB.ErrRoomNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, AllocationDB.ErrBedOccupied), errors.Is(err, AllocationDB.ErrStudentAllocated),
+		errors.Is(err, AllocationDB.ErrAllocationNotActive), errors.Is(err, AllocationDB.ErrAlreadyWaitlisted),
+		errors.Is(err, AllocationDB.ErrWaitlistEntryNotWaiting), errors.Is(err, ErrNoFreeBed):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrStudentUnavailable), errors.Is(err, ErrRoomUnavailable), errors.Is(err, ErrBedOutOfRange),
+		errors.Is(err, ErrGenderMismatch), errors.Is(err, ErrCollegeMismatch):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// @Summary Allocate bed
+// @Description Allocate a bed of a room to a student, bed_number 0 pick the first free bed
+// @Tags allocation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param allocation body Allocation.AllocateRequest true "Student and bed"
+// @Success 201 {object} Allocation.AllocationData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/allocation [post]
+func (m *AllocationManager) Allocate(c *fiber.Ctx) error {
+	var request Allocation.AllocateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse allocation",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate allocation",
+			"error":   err.Error(),
+		})
+	}
+
+	allocation, err := m.engine.Allocate(request.StudentID, request.RoomID, request.BedNumber, c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to allocate bed",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, allocation.ID, nil, allocation)
+	return c.Status(fiber.StatusCreated).JSON(allocation)
+}
+
+// @Summary Bulk allocate beds
+// @Description Allocate the beds of a college to many students from their preferences. With dry_run the plan and its conflicts are only returned, otherwise every assignment is stored at once or none is
+// @Tags allocation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param request body Allocation.BulkAllocationRequest true "College, students and preferences"
+// @Success 200 {object} Allocation.BulkAllocationPlan
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/allocation/bulk [post]
+func (m *AllocationManager) BulkAllocate(c *fiber.Ctx) error {
+	var request Allocation.BulkAllocationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse bulk allocation",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate bulk allocation",
+			"error":   err.Error(),
+		})
+	}
+
+	plan, err := m.engine.BulkAllocate(&request, c.Context())
+	if err != nil {
+		status := allocationErrorStatus(err)
+		if plan != nil {
+			// a bed was taken between planning and commit, nothing was stored
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "failed to commit bulk allocation",
+			"error":   err.Error(),
+			"plan":    plan,
+		})
+	}
+	if plan.Committed {
+		Audit.Record(c, plan.CollageUniqueName, nil, fiber.Map{"assignments": plan.Assignments})
+	}
+	return c.JSON(plan)
+}
+
+func (m *AllocationManager) fetchAllocations(c *fiber.Ctx, filter Allocation.AllocationFilter) error {
+	filter.Page, _ = strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	filter.Limit, _ = strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter.Status = Allocation.AllocationStatus(c.Query("status", ""))
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	allocations, err := m.dbManager.FetchAllocations(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch allocations",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(allocations)
+}
+
+// @Summary Get allocation list
+// @Description Fetch filtered list of allocations, newest first
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param hostel_id query string false "Hostel id"
+// @Param room_id query string false "Room id"
+// @Param status query string false "active, transferred or vacated"
+// @Success 200 {object} []Allocation.AllocationData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/allocation [get]
+func (m *AllocationManager) GetAllocations(c *fiber.Ctx) error {
+	return m.fetchAllocations(c, Allocation.AllocationFilter{
+		StudentID: c.Query("student_id", ""),
+		HostelID:  c.Query("hostel_id", ""),
+		RoomID:    c.Query("room_id", ""),
+	})
+}
+
+// @Summary Get student allocation history
+// @Description Fetch every allocation of a student, newest first
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Student id"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param status query string false "active, transferred or vacated"
+// @Success 200 {object} []Allocation.AllocationData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/student/{id}/allocation [get]
+func (m *AllocationManager) GetStudentHistory(c *fiber.Ctx) error {
+	return m.fetchAllocations(c, Allocation.AllocationFilter{StudentID: c.Params("id")})
+}
+
+// @Summary Get allocation
+// @Description Fetch an allocation by id
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Allocation id"
+// @Success 200 {object} Allocation.AllocationData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/allocation/{id} [get]
+func (m *AllocationManager) GetAllocation(c *fiber.Ctx) error {
+	allocation, err := m.dbManager.FetchAllocationByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch allocation",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(allocation)
+}
+
+// @Summary Transfer allocation
+// @Description Move a student to another bed, the old allocation stay in the history as transferred
+// @Tags allocation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Allocation id"
+// @Param transfer body Allocation.TransferRequest true "Target bed"
+// @Success 201 {object} Allocation.AllocationData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/allocation/{id}/transfer [post]
+func (m *AllocationManager) Transfer(c *fiber.Ctx) error {
+	var request Allocation.TransferRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse transfer",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate transfer",
+			"error":   err.Error(),
+		})
+	}
+
+	before, _ := m.dbManager.FetchAllocationByID(c.Params("id"), c.Context())
+	allocation, err := m.engine.Transfer(c.Params("id"), &request, c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to transfer allocation",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), before, allocation)
+	return c.Status(fiber.StatusCreated).JSON(allocation)
+}
+
+// @Summary Vacate bed
+// @Description End an active allocation, the freed bed is given to the hostel waitlist
+// @Tags allocation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Allocation id"
+// @Param vacate body Allocation.VacateRequest false "Reason"
+// @Success 200 {object} Allocation.AllocationData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/allocation/{id}/vacate [post]
+func (m *AllocationManager) Vacate(c *fiber.Ctx) error {
+	var request Allocation.VacateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "failed to parse vacate",
+				"error":   err.Error(),
+			})
+		}
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate vacate",
+			"error":   err.Error(),
+		})
+	}
+
+	before, _ := m.dbManager.FetchAllocationByID(c.Params("id"), c.Context())
+	allocation, err := m.engine.Vacate(c.Params("id"), request.Reason, c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to vacate allocation",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, allocation.ID, before, allocation)
+	return c.JSON(allocation)
+}
+
+// @Summary Get room beds
+// @Description Fetch the state of every bed of a room
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param room_id path string true "Room id"
+// @Success 200 {object} []Allocation.BedStatus
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/room/{room_id}/bed [get]
+func (m *AllocationManager) GetRoomBeds(c *fiber.Ctx) error {
+	beds, err := m.engine.RoomBeds(c.Params("room_id"), c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch beds",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(beds)
+}
+
+// @Summary Join waitlist
+// @Description Allocate a matching bed of the hostel right away or queue the student until one is freed
+// @Tags allocation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param entry body Allocation.WaitlistEntry true "Hostel, student and optional room type"
+// @Success 201 {object} Allocation.AllocationData
+// @Success 202 {object} Allocation.WaitlistEntry
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/waitlist [post]
+func (m *AllocationManager) JoinWaitlist(c *fiber.Ctx) error {
+	var entry Allocation.WaitlistEntry
+	if err := c.BodyParser(&entry); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse waitlist entry",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&entry); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate waitlist entry",
+			"error":   err.Error(),
+		})
+	}
+
+	allocation, queued, err := m.engine.Waitlist(&entry, c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to join waitlist",
+			"error":   err.Error(),
+		})
+	}
+	if allocation != nil {
+		Audit.Record(c, allocation.ID, nil, allocation)
+		return c.Status(fiber.StatusCreated).JSON(allocation)
+	}
+	Audit.Record(c, queued.ID, nil, queued)
+	return c.Status(fiber.StatusAccepted).JSON(queued)
+}
+
+// @Summary Get waitlist
+// @Description Fetch the waitlist of a hostel in queue order
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param status query string false "waiting, promoted or cancelled"
+// @Success 200 {object} []Allocation.WaitlistEntry
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/waitlist [get]
+func (m *AllocationManager) GetWaitlist(c *fiber.Ctx) error {
+	hostelID := c.Query("hostel_id", "")
+	if hostelID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   "hostel_id is required",
+		})
+	}
+	entries, err := m.dbManager.FetchWaitlist(hostelID, Allocation.WaitlistStatus(c.Query("status", "")), c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch waitlist",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(entries)
+}
+
+// @Summary Cancel waitlist entry
+// @Description Remove a waiting student from a hostel waitlist
+// @Tags allocation
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Waitlist entry id"
+// @Success 200 {object} Allocation.WaitlistEntry
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/waitlist/{id} [delete]
+func (m *AllocationManager) CancelWaitlist(c *fiber.Ctx) error {
+	entry, err := m.dbManager.UpdateWaitlistStatus(c.Params("id"), Allocation.Cancelled, "", c.Context())
+	if err != nil {
+		return c.Status(allocationErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to cancel waitlist entry",
+			"error":   err.Error(),
+		})
+	}
+	// only a waiting entry can be cancelled
+	Audit.Record(c, entry.ID, fiber.Map{"status": Allocation.Waiting}, fiber.Map{"status": entry.Status})
+	return c.JSON(entry)
+}
@@ -0,0 +1,108 @@
+package Allocation_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/testutil"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAllocationFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	hostel := map[string]interface{}{
+		"name": "North Hostel", "collage_unique_name": "college-a", "gender": "mixed", "address": "North campus",
+		"blocks": []map[string]interface{}{{"name": "A", "floors": 1}},
+	}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/hostel", token, hostel)
+	if status != http.StatusCreated {
+		t.Fatalf("add hostel: expected status 201; got %d %v", status, body)
+	}
+	hostelID := testutil.Path[string](t, body, "id")
+	rooms := []map[string]interface{}{
+		{"block": "A", "floor": 0, "room_number": "A-001", "room_type": "double", "capacity": 2, "gender": "female", "maintenance_status": "available"},
+		{"block": "A", "floor": 0, "room_number": "A-002", "room_type": "single", "capacity": 1, "gender": "female", "maintenance_status": "available"},
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/hostel/"+hostelID+"/room", token, rooms); status != http.StatusCreated {
+		t.Fatalf("add rooms: expected status 201; got %d %v", status, body)
+	}
+	added, _ := db.HostelDB.FetchHostelRooms(hostelID, context.Background())
+	if len(added) != 2 {
+		t.Fatalf("expected the 2 rooms stored; got %+v", added)
+	}
+	doubleID, singleID := added[0].ID, added[1].ID
+
+	addStudent := func(roll string, gender string) string {
+		student := map[string]interface{}{
+			"name": "Student " + roll, "roll_number": roll, "collage_unique_name": "college-a", "gender": gender, "year": 1,
+			"guardians":          []map[string]string{{"name": "Guardian", "relation": "mother", "phone": "+919876543210"}},
+			"id_proof_reference": "aadhaar/" + roll,
+		}
+		status, body := testutil.DoJSON(t, s, "POST", "/admin/student", token, student)
+		if status != http.StatusCreated {
+			t.Fatalf("add student %s: expected status 201; got %d %v", roll, status, body)
+		}
+		return testutil.Path[string](t, body, "id")
+	}
+	first, second, third, male := addStudent("CS-001", "female"), addStudent("CS-002", "female"), addStudent("CS-003", "female"), addStudent("CS-004", "male")
+
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": first, "room_id": doubleID, "bed_number": 1})
+	if status != http.StatusCreated {
+		t.Fatalf("allocate: expected status 201; got %d %v", status, body)
+	}
+	firstAllocation := testutil.Path[string](t, body, "id")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": second, "room_id": doubleID, "bed_number": 1}); status != http.StatusConflict {
+		t.Fatalf("allocate occupied bed: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": first, "room_id": singleID}); status != http.StatusConflict {
+		t.Fatalf("allocate student twice: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": male, "room_id": doubleID}); status != http.StatusUnprocessableEntity {
+		t.Fatalf("allocate male student in female room: expected status 422; got %d", status)
+	}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": second, "room_id": doubleID})
+	if status != http.StatusCreated || testutil.Path[float64](t, body, "bed_number") != 2 {
+		t.Fatalf("allocate first free bed: expected bed 2; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/allocation", token, map[string]interface{}{"student_id": third, "room_id": doubleID}); status != http.StatusConflict {
+		t.Fatalf("allocate full room: expected status 409; got %d", status)
+	}
+
+	// first move to the single room, the freed bed go to the next waitlist request
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation/"+firstAllocation+"/transfer", token, map[string]interface{}{"room_id": singleID, "reason": "asked for single"})
+	if status != http.StatusCreated || body["previous_id"] != firstAllocation {
+		t.Fatalf("transfer: expected status 201; got %d %v", status, body)
+	}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/waitlist", token, map[string]interface{}{"student_id": third, "hostel_id": hostelID, "room_type": "single"})
+	if status != http.StatusAccepted || body["status"] != "waiting" {
+		t.Fatalf("join waitlist: expected status 202; got %d %v", status, body)
+	}
+
+	active, _ := db.AllocationDB.FetchActiveAllocations("", singleID, context.Background())
+	if len(active) != 1 || active[0].StudentID != first {
+		t.Fatalf("single room: expected first student; got %v", active)
+	}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation/"+active[0].ID+"/vacate", token, map[string]string{"reason": "graduated"})
+	if status != http.StatusOK || body["status"] != "vacated" {
+		t.Fatalf("vacate: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/allocation/"+active[0].ID+"/vacate", token, nil); status != http.StatusConflict {
+		t.Fatalf("vacate twice: expected status 409; got %d", status)
+	}
+	active, _ = db.AllocationDB.FetchActiveAllocations("", singleID, context.Background())
+	if len(active) != 1 || active[0].StudentID != third {
+		t.Fatalf("waitlist promotion: expected third student in single room; got %v", active)
+	}
+
+	history, _ := db.AllocationDB.FetchAllocations(&Allocation.AllocationFilter{Page: 1, Limit: 10, StudentID: first}, context.Background())
+	if len(history) != 2 || history[0].Status != Allocation.Vacated || history[1].Status != Allocation.Transferred {
+		t.Fatalf("history: expected vacated then transferred; got %v", history)
+	}
+}
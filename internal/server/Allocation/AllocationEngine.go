@@ -0,0 +1,341 @@
+package Allocation
+
+import (
+	AllocationDB "HostelApp/internal/database/Allocation"
+	HostelDB "HostelApp/internal/database/Hostel"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+var (
+	ErrStudentUnavailable = errors.New("student is deleted")
+	ErrRoomUnavailable    = errors.New("room is not available for allocation")
+	ErrBedOutOfRange      = errors.New("bed number is outside the room capacity")
+	ErrGenderMismatch     = errors.New("room gender restriction does not match the student")
+	ErrCollegeMismatch    = errors.New("hostel does not belong to the student college")
+	ErrNoFreeBed          = errors.New("no free bed matches the request")
+)
+
+// AllocationEngine hold the allocation rules, the storage only guarantee that
+// a bed or a student is never part of two active allocations
+type AllocationEngine struct {
+	allocationDB AllocationDB.IAllocationDBService
+	studentDB    StudentDB.IStudentDBService
+	hostelDB     HostelDB.IHostelDBService
+}
+
+func NewAllocationEngine(allocationDB AllocationDB.IAllocationDBService, studentDB StudentDB.IStudentDBService, hostelDB HostelDB.IHostelDBService) *AllocationEngine {
+	return &AllocationEngine{
+		allocationDB: allocationDB,
+		studentDB:    studentDB,
+		hostelDB:     hostelDB,
+	}
+}
+
+// GenderFits check a student against a room gender restriction
+func GenderFits(room Hostel.GenderRestriction, student Student.Gender) bool {
+	return room == Hostel.AnyGender || string(room) == string(student)
+}
+
+// FreeBeds return the bed numbers of a room not used by the active allocations
+func FreeBeds(room *Hostel.RoomData, active []Allocation.AllocationData) []int {
+	taken := map[int]bool{}
+	for _, allocation := range active {
+		if allocation.RoomID == room.ID {
+			taken[allocation.BedNumber] = true
+		}
+	}
+	var free []int
+	for bed := 1; bed <= room.Capacity; bed++ {
+		if !taken[bed] {
+			free = append(free, bed)
+		}
+	}
+	return free
+}
+
+// CheckPlacement validate that a student can sleep in a room of a hostel
+func CheckPlacement(student *Student.StudentData, room *Hostel.RoomData, hostel *Hostel.HostelData) error {
+	if student.MarkAsDeleted {
+		return ErrStudentUnavailable
+	}
+	if room.MarkAsDeleted || hostel.MarkAsDeleted || room.MaintenanceStatus != Hostel.Available {
+		return ErrRoomUnavailable
+	}
+	if hostel.CollageUniqueName != student.CollageUniqueName {
+		return ErrCollegeMismatch
+	}
+	if !GenderFits(room.Gender, student.Gender) {
+		return ErrGenderMismatch
+	}
+	return nil
+}
+
+func (e *AllocationEngine) loadRoom(roomID string, ctx context.Context) (*Hostel.RoomData, *Hostel.HostelData, error) {
+	room, err := e.hostelDB.FetchRoomByID(roomID, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostel, err := e.hostelDB.FetchHostelByID(room.HostelID, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return room, hostel, nil
+}
+
+// claimBed store the active allocation with create, with bedNumber 0 every free bed
+// of the room is tried in order so a concurrent claim on one bed fall through to the next
+func (e *AllocationEngine) claimBed(student *Student.StudentData, room *Hostel.RoomData, bedNumber int,
+	create func(allocation *Allocation.AllocationData) (*Allocation.AllocationData, error), ctx context.Context) (*Allocation.AllocationData, error) {
+	var beds []int
+	if bedNumber != 0 {
+		if bedNumber < 1 || bedNumber > room.Capacity {
+			return nil, ErrBedOutOfRange
+		}
+		beds = []int{bedNumber}
+	} else {
+		active, err := e.allocationDB.FetchActiveAllocations("", room.ID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		beds = FreeBeds(room, active)
+		if len(beds) == 0 {
+			return nil, ErrNoFreeBed
+		}
+	}
+
+	var lastErr error
+	for _, bed := range beds {
+		allocation, err := create(&Allocation.AllocationData{
+			StudentID:         student.ID,
+			CollageUniqueName: student.CollageUniqueName,
+			HostelID:          room.HostelID,
+			RoomID:            room.ID,
+			BedNumber:         bed,
+		})
+		if err == nil {
+			return allocation, nil
+		}
+		if !errors.Is(err, AllocationDB.ErrBedOccupied) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	if bedNumber == 0 {
+		return nil, ErrNoFreeBed
+	}
+	return nil, lastErr
+}
+
+func (e *AllocationEngine) create(ctx context.Context) func(allocation *Allocation.AllocationData) (*Allocation.AllocationData, error) {
+	return func(allocation *Allocation.AllocationData) (*Allocation.AllocationData, error) {
+		return e.allocationDB.CreateAllocation(allocation, ctx)
+	}
+}
+
+// Allocate put a student on a bed of a room, bedNumber 0 pick the first free bed
+func (e *AllocationEngine) Allocate(studentID string, roomID string, bedNumber int, ctx context.Context) (*Allocation.AllocationData, error) {
+	student, err := e.studentDB.FetchStudentByID(studentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	room, hostel, err := e.loadRoom(roomID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckPlacement(student, room, hostel); err != nil {
+		return nil, err
+	}
+	return e.claimBed(student, room, bedNumber, e.create(ctx), ctx)
+}
+
+// Transfer move an active allocation to another bed, the old allocation is
+// ended and the new bed claimed in one step so a failed claim leave it active
+func (e *AllocationEngine) Transfer(allocationID string, request *Allocation.TransferRequest, ctx context.Context) (*Allocation.AllocationData, error) {
+	current, err := e.allocationDB.FetchAllocationByID(allocationID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != Allocation.Active {
+		return nil, AllocationDB.ErrAllocationNotActive
+	}
+	student, err := e.studentDB.FetchStudentByID(current.StudentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	room, hostel, err := e.loadRoom(request.RoomID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckPlacement(student, room, hostel); err != nil {
+		return nil, err
+	}
+
+	transfer := func(next *Allocation.AllocationData) (*Allocation.AllocationData, error) {
+		next.PreviousID = current.ID
+		return e.allocationDB.TransferAllocation(current.ID, request.Reason, next, ctx)
+	}
+	allocation, err := e.claimBed(student, room, request.BedNumber, transfer, ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.PromoteWaitlist(current.HostelID, ctx)
+	return allocation, nil
+}
+
+// Vacate end an active allocation and give the freed bed to the waitlist
+func (e *AllocationEngine) Vacate(allocationID string, reason string, ctx context.Context) (*Allocation.AllocationData, error) {
+	allocation, err := e.allocationDB.EndAllocation(allocationID, Allocation.Vacated, reason, ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.PromoteWaitlist(allocation.HostelID, ctx)
+	return allocation, nil
+}
+
+// placeInHostel allocate the first free bed of a hostel matching the student and room type
+func (e *AllocationEngine) placeInHostel(student *Student.StudentData, hostel *Hostel.HostelData, roomType Hostel.RoomType, ctx context.Context) (*Allocation.AllocationData, error) {
+	rooms, err := e.hostelDB.FetchHostelRooms(hostel.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	active, err := e.allocationDB.FetchActiveAllocations(hostel.ID, "", ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rooms {
+		room := &rooms[i]
+		if roomType != "" && room.RoomType != roomType {
+			continue
+		}
+		if CheckPlacement(student, room, hostel) != nil || len(FreeBeds(room, active)) == 0 {
+			continue
+		}
+		allocation, err := e.claimBed(student, room, 0, e.create(ctx), ctx)
+		if errors.Is(err, ErrNoFreeBed) {
+			continue
+		}
+		return allocation, err
+	}
+	return nil, ErrNoFreeBed
+}
+
+// Waitlist allocate a bed right away when one matches, otherwise queue the student on the hostel
+func (e *AllocationEngine) Waitlist(entry *Allocation.WaitlistEntry, ctx context.Context) (*Allocation.AllocationData, *Allocation.WaitlistEntry, error) {
+	student, err := e.studentDB.FetchStudentByID(entry.StudentID, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if student.MarkAsDeleted {
+		return nil, nil, ErrStudentUnavailable
+	}
+	hostel, err := e.hostelDB.FetchHostelByID(entry.HostelID, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hostel.MarkAsDeleted {
+		return nil, nil, ErrRoomUnavailable
+	}
+	if hostel.CollageUniqueName != student.CollageUniqueName {
+		return nil, nil, ErrCollegeMismatch
+	}
+
+	allocation, err := e.placeInHostel(student, hostel, entry.RoomType, ctx)
+	if err == nil {
+		return allocation, nil, nil
+	}
+	if !errors.Is(err, ErrNoFreeBed) {
+		return nil, nil, err
+	}
+	queued, err := e.allocationDB.AddWaitlistEntry(entry, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, queued, nil
+}
+
+// PromoteWaitlist give free beds of a hostel to waiting students, first come first served.
+// Failures are only logged since it run after the vacate or transfer already succeeded
+func (e *AllocationEngine) PromoteWaitlist(hostelID string, ctx context.Context) {
+	entries, err := e.allocationDB.FetchWaitlist(hostelID, Allocation.Waiting, ctx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to fetch waitlist of hostel %s: %v", hostelID, err))
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+	hostel, err := e.hostelDB.FetchHostelByID(hostelID, ctx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("failed to fetch hostel %s for waitlist: %v", hostelID, err))
+		return
+	}
+	cancel := func(entry Allocation.WaitlistEntry) {
+		if _, err := e.allocationDB.UpdateWaitlistStatus(entry.ID, Allocation.Cancelled, "", ctx); err != nil && !errors.Is(err, AllocationDB.ErrWaitlistEntryNotWaiting) {
+			slog.Error(fmt.Sprintf("failed to cancel waitlist entry %s: %v", entry.ID, err))
+		}
+	}
+	for _, entry := range entries {
+		student, err := e.studentDB.FetchStudentByID(entry.StudentID, ctx)
+		if errors.Is(err, StudentDB.ErrStudentNotFound) || (err == nil && student.MarkAsDeleted) {
+			cancel(entry)
+			continue
+		}
+		if err != nil {
+			slog.Error(fmt.Sprintf("failed to fetch student %s for waitlist: %v", entry.StudentID, err))
+			continue
+		}
+		allocation, err := e.placeInHostel(student, hostel, entry.RoomType, ctx)
+		if errors.Is(err, AllocationDB.ErrStudentAllocated) {
+			// student got a bed some other way meanwhile
+			cancel(entry)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, ErrNoFreeBed) {
+				slog.Error(fmt.Sprintf("failed to place waitlist entry %s: %v", entry.ID, err))
+			}
+			continue
+		}
+		if _, err := e.allocationDB.UpdateWaitlistStatus(entry.ID, Allocation.Promoted, allocation.ID, ctx); err != nil {
+			// entry was cancelled concurrently, give the bed back
+			if _, endErr := e.allocationDB.EndAllocation(allocation.ID, Allocation.Vacated, "waitlist entry no longer waiting", ctx); endErr != nil {
+				slog.Error(fmt.Sprintf("waitlist entry %s is no longer waiting (%v) and its allocation %s could not be ended: %v", entry.ID, err, allocation.ID, endErr))
+			}
+		}
+	}
+}
+
+// RoomBeds return the state of every bed of a room
+func (e *AllocationEngine) RoomBeds(roomID string, ctx context.Context) ([]Allocation.BedStatus, error) {
+	room, err := e.hostelDB.FetchRoomByID(roomID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	active, err := e.allocationDB.FetchActiveAllocations("", room.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	beds := make([]Allocation.BedStatus, room.Capacity)
+	for i := range beds {
+		beds[i].BedNumber = i + 1
+	}
+	for _, allocation := range active {
+		if allocation.BedNumber < 1 || allocation.BedNumber > room.Capacity {
+			continue
+		}
+		beds[allocation.BedNumber-1] = Allocation.BedStatus{
+			BedNumber:    allocation.BedNumber,
+			Occupied:     true,
+			StudentID:    allocation.StudentID,
+			AllocationID: allocation.ID,
+		}
+	}
+	return beds, nil
+}
@@ -0,0 +1,128 @@
+package Allocation
+
+import (
+	"HostelApp/internal/database"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAllocationConcurrentInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	ctx := context.Background()
+	hostel, _ := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	rooms, _ := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Dorm, Capacity: 3, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx)
+	engine := NewAllocationEngine(db.AllocationDB, db.StudentDB, db.HostelDB)
+
+	var wg sync.WaitGroup
+	var allocated atomic.Int32
+	for i := 0; i < 10; i++ {
+		student, _ := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: fmt.Sprintf("R-%d", i), CollageUniqueName: "college-a", Gender: Student.Male}, ctx)
+		wg.Add(1)
+		go func(studentID string) {
+			defer wg.Done()
+			if _, err := engine.Allocate(studentID, rooms[0].ID, 0, ctx); err == nil {
+				allocated.Add(1)
+			}
+		}(student.ID)
+	}
+	wg.Wait()
+	if allocated.Load() != 3 {
+		t.Fatalf("concurrent allocation: expected 3 beds allocated; got %d", allocated.Load())
+	}
+}
+
+func TestPromoteWaitlist(t *testing.T) {
+	db := database.NewMemoryDBService()
+	ctx := context.Background()
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("AddHostel failed. Err: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx)
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("AddRooms failed %+v. Err: %v", rooms, err)
+	}
+	engine := NewAllocationEngine(db.AllocationDB, db.StudentDB, db.HostelDB)
+	addStudent := func(roll string) string {
+		t.Helper()
+		student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: roll, CollageUniqueName: "college-a", Gender: Student.Male}, ctx)
+		if err != nil {
+			t.Fatalf("AddStudent failed. Err: %v", err)
+		}
+		return student.ID
+	}
+	resident, deleted, waiting := addStudent("R-1"), addStudent("R-2"), addStudent("R-3")
+	current, err := engine.Allocate(resident, rooms[0].ID, 0, ctx)
+	if err != nil {
+		t.Fatalf("Allocate failed. Err: %v", err)
+	}
+	if _, entry, err := engine.Waitlist(&Allocation.WaitlistEntry{HostelID: hostel.ID, StudentID: deleted}, ctx); err != nil || entry == nil {
+		t.Fatalf("expected the student on the waitlist; got %+v. Err: %v", entry, err)
+	}
+	// the engine refuse unknown students, the store keep whatever it is given
+	if _, err = db.AllocationDB.AddWaitlistEntry(&Allocation.WaitlistEntry{HostelID: hostel.ID, StudentID: "missing-student"}, ctx); err != nil {
+		t.Fatalf("AddWaitlistEntry failed. Err: %v", err)
+	}
+	_, next, err := engine.Waitlist(&Allocation.WaitlistEntry{HostelID: hostel.ID, StudentID: waiting}, ctx)
+	if err != nil || next == nil {
+		t.Fatalf("expected the student on the waitlist; got %+v. Err: %v", next, err)
+	}
+	if err = db.StudentDB.DeleteStudent(deleted, ctx); err != nil {
+		t.Fatalf("DeleteStudent failed. Err: %v", err)
+	}
+
+	if _, err = engine.Vacate(current.ID, "", ctx); err != nil {
+		t.Fatalf("Vacate failed. Err: %v", err)
+	}
+	cancelled, err := db.AllocationDB.FetchWaitlist(hostel.ID, Allocation.Cancelled, ctx)
+	if err != nil || len(cancelled) != 2 {
+		t.Fatalf("expected the deleted and the missing student cancelled; got %+v. Err: %v", cancelled, err)
+	}
+	promoted, err := db.AllocationDB.FetchWaitlist(hostel.ID, Allocation.Promoted, ctx)
+	if err != nil || len(promoted) != 1 || promoted[0].ID != next.ID || promoted[0].AllocationID == "" {
+		t.Fatalf("expected the waiting student promoted; got %+v. Err: %v", promoted, err)
+	}
+}
+
+func TestRoommateGroups(t *testing.T) {
+	candidate := func(id string, year int, roommates ...string) *bulkCandidate {
+		return &bulkCandidate{
+			preference: Allocation.StudentPreference{StudentID: id, Roommates: roommates},
+			student:    &Student.StudentData{ID: id, RollNumber: id, Year: year},
+		}
+	}
+	candidates := []*bulkCandidate{
+		candidate("a", 1, "d"),
+		candidate("b", 3),
+		candidate("c", 2, "e"),
+		candidate("d", 4, "a"),
+		candidate("e", 2),
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return higherPriority(candidates[i], candidates[j]) })
+
+	plan := &Allocation.BulkAllocationPlan{}
+	var got [][]string
+	for _, group := range roommateGroups(candidates, plan) {
+		var ids []string
+		for _, member := range group {
+			ids = append(ids, member.student.ID)
+		}
+		got = append(got, ids)
+	}
+	// d is the most senior so its group with a come first, c asked for e
+	// without e asking back so both stay alone
+	want := [][]string{{"d", "a"}, {"b"}, {"c"}, {"e"}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected groups %v; got %v", want, got)
+	}
+	if len(plan.Warnings) != 1 || plan.Warnings[0].StudentID != "c" {
+		t.Fatalf("expected one warning for c; got %v", plan.Warnings)
+	}
+}
@@ -0,0 +1,331 @@
+package Allocation
+
+import (
+	AllocationDB "HostelApp/internal/database/Allocation"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"fmt"
+	"sort"
+)
+
+type bulkCandidate struct {
+	preference Allocation.StudentPreference
+	student    *Student.StudentData
+}
+
+type bulkRoom struct {
+	room   Hostel.RoomData
+	hostel *Hostel.HostelData
+	free   []int
+}
+
+// collegeRooms return every allocatable room of a college with its free beds,
+// ordered by hostel, block and room number
+func (e *AllocationEngine) collegeRooms(collageUniqueName string, ctx context.Context) ([]*bulkRoom, error) {
+	var rooms []*bulkRoom
+	const limit = 20
+	for page := int64(1); ; page++ {
+		hostels, err := e.hostelDB.FetchHostels(&Hostel.HostelFilter{Page: page, Limit: limit, CollageUniqueName: collageUniqueName}, ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := range hostels {
+			hostel := &hostels[i]
+			hostelRooms, err := e.hostelDB.FetchHostelRooms(hostel.ID, ctx)
+			if err != nil {
+				return nil, err
+			}
+			active, err := e.allocationDB.FetchActiveAllocations(hostel.ID, "", ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, room := range hostelRooms {
+				if room.MaintenanceStatus != Hostel.Available {
+					continue
+				}
+				if free := FreeBeds(&room, active); len(free) > 0 {
+					rooms = append(rooms, &bulkRoom{room: room, hostel: hostel, free: free})
+				}
+			}
+		}
+		if len(hostels) < limit {
+			return rooms, nil
+		}
+	}
+}
+
+// higherPriority order students by year, then home distance, the roll number
+// and id only make the order deterministic
+func higherPriority(a *bulkCandidate, b *bulkCandidate) bool {
+	if a.student.Year != b.student.Year {
+		return a.student.Year > b.student.Year
+	}
+	if a.preference.DistanceKm != b.preference.DistanceKm {
+		return a.preference.DistanceKm > b.preference.DistanceKm
+	}
+	if a.student.RollNumber != b.student.RollNumber {
+		return a.student.RollNumber < b.student.RollNumber
+	}
+	return a.student.ID < b.student.ID
+}
+
+// roommateGroups join students that requested each other, candidates must be
+// sorted by priority and the groups keep that order
+func roommateGroups(candidates []*bulkCandidate, plan *Allocation.BulkAllocationPlan) [][]*bulkCandidate {
+	index := make(map[string]int, len(candidates))
+	for i, candidate := range candidates {
+		index[candidate.student.ID] = i
+	}
+	requested := func(from *bulkCandidate, to string) bool {
+		for _, roommate := range from.preference.Roommates {
+			if roommate == to {
+				return true
+			}
+		}
+		return false
+	}
+
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, candidate := range candidates {
+		for _, roommate := range candidate.preference.Roommates {
+			j, ok := index[roommate]
+			if !ok || !requested(candidates[j], candidate.student.ID) {
+				plan.Warnings = append(plan.Warnings, Allocation.BulkConflict{
+					StudentID: candidate.student.ID,
+					Reason:    fmt.Sprintf("roommate request for %s is not mutual", roommate),
+				})
+				continue
+			}
+			// the lower index is the higher priority, keep it as root
+			ri, rj := root(i), root(j)
+			if ri < rj {
+				parent[rj] = ri
+			} else if rj < ri {
+				parent[ri] = rj
+			}
+		}
+	}
+
+	members := map[int][]*bulkCandidate{}
+	var order []int
+	for i, candidate := range candidates {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			order = append(order, r)
+		}
+		members[r] = append(members[r], candidate)
+	}
+	groups := make([][]*bulkCandidate, 0, len(order))
+	for _, r := range order {
+		groups = append(groups, members[r])
+	}
+	return groups
+}
+
+func preferenceScore(candidate *bulkCandidate, room *Hostel.RoomData) int {
+	score := 0
+	if candidate.preference.RoomType != "" && candidate.preference.RoomType == room.RoomType {
+		score += 2
+	}
+	if candidate.preference.Floor != nil && *candidate.preference.Floor == room.Floor {
+		score++
+	}
+	return score
+}
+
+// bestRoom pick the room with enough free beds for the whole group that match
+// the most preferences, ties go to the first room in hostel order
+func bestRoom(rooms []*bulkRoom, group []*bulkCandidate) *bulkRoom {
+	var best *bulkRoom
+	bestScore := -1
+	for _, candidateRoom := range rooms {
+		if len(candidateRoom.free) < len(group) {
+			continue
+		}
+		score := 0
+		fits := true
+		for _, member := range group {
+			if CheckPlacement(member.student, &candidateRoom.room, candidateRoom.hostel) != nil {
+				fits = false
+				break
+			}
+			score += preferenceScore(member, &candidateRoom.room)
+		}
+		if fits && score > bestScore {
+			best, bestScore = candidateRoom, score
+		}
+	}
+	return best
+}
+
+func assign(plan *Allocation.BulkAllocationPlan, target *bulkRoom, member *bulkCandidate, withRoommates bool) {
+	bed := target.free[0]
+	target.free = target.free[1:]
+	var matched []string
+	preference := member.preference
+	if preference.RoomType != "" {
+		if preference.RoomType == target.room.RoomType {
+			matched = append(matched, "room_type")
+		} else {
+			plan.Warnings = append(plan.Warnings, Allocation.BulkConflict{
+				StudentID: member.student.ID,
+				Reason:    fmt.Sprintf("no %s room left, placed in a %s room", preference.RoomType, target.room.RoomType),
+			})
+		}
+	}
+	if preference.Floor != nil {
+		if *preference.Floor == target.room.Floor {
+			matched = append(matched, "floor")
+		} else {
+			plan.Warnings = append(plan.Warnings, Allocation.BulkConflict{
+				StudentID: member.student.ID,
+				Reason:    fmt.Sprintf("no room left on floor %d, placed on floor %d", *preference.Floor, target.room.Floor),
+			})
+		}
+	}
+	if withRoommates {
+		matched = append(matched, "roommates")
+	}
+	plan.Assignments = append(plan.Assignments, Allocation.PlannedAllocation{
+		StudentID:          member.student.ID,
+		HostelID:           target.room.HostelID,
+		RoomID:             target.room.ID,
+		RoomNumber:         target.room.RoomNumber,
+		BedNumber:          bed,
+		MatchedPreferences: matched,
+	})
+}
+
+// PlanBulk compute a deterministic allocation plan: students are served by
+// priority, a roommate group is served with its highest priority member and
+// each one get the free bed matching most of its preferences. Nothing is stored
+func (e *AllocationEngine) PlanBulk(request *Allocation.BulkAllocationRequest, ctx context.Context) (*Allocation.BulkAllocationPlan, error) {
+	plan := &Allocation.BulkAllocationPlan{
+		CollageUniqueName: request.CollageUniqueName,
+		DryRun:            request.DryRun,
+		Assignments:       []Allocation.PlannedAllocation{},
+		Conflicts:         []Allocation.BulkConflict{},
+		Warnings:          []Allocation.BulkConflict{},
+	}
+	rooms, err := e.collegeRooms(request.CollageUniqueName, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conflict := func(studentID string, reason string) {
+		plan.Conflicts = append(plan.Conflicts, Allocation.BulkConflict{StudentID: studentID, Reason: reason})
+	}
+	seen := map[string]bool{}
+	var preferences []Allocation.StudentPreference
+	var ids []string
+	for _, preference := range request.Students {
+		if seen[preference.StudentID] {
+			conflict(preference.StudentID, "student is listed more than once")
+			continue
+		}
+		seen[preference.StudentID] = true
+		preferences = append(preferences, preference)
+		ids = append(ids, preference.StudentID)
+	}
+	fetched, err := e.studentDB.FetchStudentsByID(ids, ctx)
+	if err != nil {
+		return nil, err
+	}
+	students := make(map[string]*Student.StudentData, len(fetched))
+	for i := range fetched {
+		students[fetched[i].ID] = &fetched[i]
+	}
+	// an allocation in any hostel count, also one of another college
+	active, err := e.allocationDB.FetchStudentsActiveAllocations(ids, ctx)
+	if err != nil {
+		return nil, err
+	}
+	allocated := make(map[string]*Allocation.AllocationData, len(active))
+	for i := range active {
+		allocated[active[i].StudentID] = &active[i]
+	}
+
+	var candidates []*bulkCandidate
+	for _, preference := range preferences {
+		student, ok := students[preference.StudentID]
+		if !ok {
+			conflict(preference.StudentID, StudentDB.ErrStudentNotFound.Error())
+			continue
+		}
+		switch current := allocated[student.ID]; {
+		case student.MarkAsDeleted:
+			conflict(student.ID, ErrStudentUnavailable.Error())
+		case student.CollageUniqueName != request.CollageUniqueName:
+			conflict(student.ID, ErrCollegeMismatch.Error())
+		case current != nil:
+			conflict(student.ID, fmt.Sprintf("%v: bed %d of room %s in hostel %s", AllocationDB.ErrStudentAllocated, current.BedNumber, current.RoomID, current.HostelID))
+		default:
+			candidates = append(candidates, &bulkCandidate{preference: preference, student: student})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return higherPriority(candidates[i], candidates[j]) })
+
+	for _, group := range roommateGroups(candidates, plan) {
+		if len(group) > 1 {
+			if target := bestRoom(rooms, group); target != nil {
+				for _, member := range group {
+					assign(plan, target, member, true)
+				}
+				continue
+			}
+			for _, member := range group {
+				plan.Warnings = append(plan.Warnings, Allocation.BulkConflict{
+					StudentID: member.student.ID,
+					Reason:    "no room can hold the whole roommate group",
+				})
+			}
+		}
+		for _, member := range group {
+			target := bestRoom(rooms, []*bulkCandidate{member})
+			if target == nil {
+				conflict(member.student.ID, ErrNoFreeBed.Error())
+				continue
+			}
+			assign(plan, target, member, false)
+		}
+	}
+	return plan, nil
+}
+
+// BulkAllocate plan the request and, unless it is a dry run, store every
+// assignment at once. When any bed was taken meanwhile nothing is stored
+func (e *AllocationEngine) BulkAllocate(request *Allocation.BulkAllocationRequest, ctx context.Context) (*Allocation.BulkAllocationPlan, error) {
+	plan, err := e.PlanBulk(request, ctx)
+	if err != nil || request.DryRun || len(plan.Assignments) == 0 {
+		return plan, err
+	}
+	allocations := make([]Allocation.AllocationData, 0, len(plan.Assignments))
+	for _, assignment := range plan.Assignments {
+		allocations = append(allocations, Allocation.AllocationData{
+			StudentID:         assignment.StudentID,
+			CollageUniqueName: request.CollageUniqueName,
+			HostelID:          assignment.HostelID,
+			RoomID:            assignment.RoomID,
+			BedNumber:         assignment.BedNumber,
+		})
+	}
+	if _, err := e.allocationDB.CreateAllocations(allocations, ctx); err != nil {
+		return plan, err
+	}
+	plan.Committed = true
+	return plan, nil
+}
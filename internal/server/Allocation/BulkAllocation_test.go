@@ -0,0 +1,107 @@
+package Allocation_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBulkAllocationInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed, Blocks: []Hostel.BlockData{{Name: "A", Floors: 2}}}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{
+		{Block: "A", Floor: 0, RoomNumber: "A-001", RoomType: Hostel.Double, Capacity: 2, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+		{Block: "A", Floor: 1, RoomNumber: "A-101", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+	}, ctx)
+	if err != nil || len(rooms) != 2 {
+		t.Fatalf("add rooms: expected 2 rooms; got %v %v", rooms, err)
+	}
+	addStudent := func(roll string, year int) string {
+		student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: roll, CollageUniqueName: "college-a", Gender: Student.Female, Year: year}, ctx)
+		if err != nil {
+			t.Fatalf("add student %s: %v", roll, err)
+		}
+		return student.ID
+	}
+	senior, junior, friendA, friendB := addStudent("R-1", 4), addStudent("R-2", 1), addStudent("R-3", 2), addStudent("R-4", 2)
+
+	// both the senior and the junior want the single room, the senior win it
+	// and the mutual roommates get the double room
+	request := map[string]interface{}{
+		"collage_unique_name": "college-a",
+		"dry_run":             true,
+		"students": []map[string]interface{}{
+			{"student_id": junior, "room_type": "single"},
+			{"student_id": friendA, "roommates": []string{friendB}},
+			{"student_id": senior, "room_type": "single"},
+			{"student_id": friendB, "roommates": []string{friendA}},
+			{"student_id": "unknown"},
+		},
+	}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/allocation/bulk", token, request)
+	if status != http.StatusOK || body["committed"] != false {
+		t.Fatalf("dry run: expected status OK; got %d %v", status, body)
+	}
+	assignments := map[string]string{}
+	for _, raw := range testutil.Path[[]interface{}](t, body, "assignments") {
+		assignment := testutil.Path[map[string]interface{}](t, raw)
+		assignments[testutil.Path[string](t, assignment, "student_id")] = testutil.Path[string](t, assignment, "room_id")
+	}
+	if assignments[senior] != rooms[1].ID || assignments[friendA] != rooms[0].ID || assignments[friendB] != rooms[0].ID {
+		t.Fatalf("dry run: unexpected plan %v", body)
+	}
+	if _, ok := assignments[junior]; ok || len(testutil.Path[[]interface{}](t, body, "conflicts")) != 2 {
+		t.Fatalf("dry run: expected junior and unknown student in conflicts; got %v", body)
+	}
+	if active, _ := db.AllocationDB.FetchActiveAllocations(hostel.ID, "", ctx); len(active) != 0 {
+		t.Fatalf("dry run: expected nothing stored; got %v", active)
+	}
+
+	request["dry_run"] = false
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation/bulk", token, request)
+	if status != http.StatusOK || body["committed"] != true {
+		t.Fatalf("commit: expected status OK; got %d %v", status, body)
+	}
+	if active, _ := db.AllocationDB.FetchActiveAllocations(hostel.ID, "", ctx); len(active) != 3 {
+		t.Fatalf("commit: expected 3 allocations; got %v", active)
+	}
+
+	// a batch that conflict with a stored allocation is not stored at all
+	_, err = db.AllocationDB.CreateAllocations([]Allocation.AllocationData{
+		{StudentID: junior, HostelID: hostel.ID, RoomID: rooms[1].ID, BedNumber: 2},
+		{StudentID: "other", HostelID: hostel.ID, RoomID: rooms[1].ID, BedNumber: 1},
+	}, ctx)
+	if err == nil {
+		t.Fatalf("conflicting batch: expected an error")
+	}
+	if history, _ := db.AllocationDB.FetchAllocations(&Allocation.AllocationFilter{Page: 1, Limit: 10, StudentID: junior}, ctx); len(history) != 0 {
+		t.Fatalf("conflicting batch: expected nothing stored; got %v", history)
+	}
+
+	// a bed outside the college hostels still count as the student active allocation
+	elsewhere := addStudent("R-5", 3)
+	if _, err = db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: elsewhere, HostelID: "h-other", RoomID: "r-other", BedNumber: 1}, ctx); err != nil {
+		t.Fatalf("CreateAllocation failed. Err: %v", err)
+	}
+	request = map[string]interface{}{"collage_unique_name": "college-a", "dry_run": true, "students": []map[string]interface{}{{"student_id": elsewhere}}}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/allocation/bulk", token, request)
+	if status != http.StatusOK || len(testutil.Path[[]interface{}](t, body, "assignments")) != 0 ||
+		!strings.Contains(testutil.Path[string](t, body, "conflicts", 0, "reason"), "r-other") {
+		t.Fatalf("allocated elsewhere: expected a conflict naming the room; got %d %v", status, body)
+	}
+}
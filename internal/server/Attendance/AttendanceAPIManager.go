@@ -0,0 +1,354 @@
+package Attendance
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/ValidatorSystem"
+	AllocationDB "HostelApp/internal/database/Allocation"
+	AttendanceDB "HostelApp/internal/database/Attendance"
+	GateDB "HostelApp/internal/database/Gate"
+	HostelDB "HostelApp/internal/database/Hostel"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Attendance"
+	"HostelApp/internal/storageData/Gate"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrHostelUnavailable = errors.New("hostel is deleted")
+	ErrFutureDate        = errors.New("roll-call can not be marked for a future date")
+	ErrRollCallRejected  = errors.New("roll-call has invalid entries, nothing was recorded")
+	ErrInvalidRange      = errors.New("from must not be after to and the range must not exceed 366 days")
+)
+
+const maxReportDays = 366
+
+type AttendanceManager struct {
+	dbManager    AttendanceDB.IAttendanceDBService
+	hostelDB     HostelDB.IHostelDBService
+	allocationDB AllocationDB.IAllocationDBService
+	gateDB       GateDB.IGateDBService
+}
+
+func NewAttendanceManager(dbManager AttendanceDB.IAttendanceDBService, hostelDB HostelDB.IHostelDBService, allocationDB AllocationDB.IAllocationDBService, gateDB GateDB.IGateDBService) *AttendanceManager {
+	instance := &AttendanceManager{
+		dbManager:    dbManager,
+		hostelDB:     hostelDB,
+		allocationDB: allocationDB,
+		gateDB:       gateDB,
+	}
+	return instance
+}
+
+func (m *AttendanceManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/attendance", Method: internal.GET, Handler: m.GetRecords, Permission: internal.ReadPermission},
+		{Path: "/admin/attendance/roll-call", Method: internal.POST, Handler: m.RollCall, Permission: internal.WritePermission},
+		{Path: "/admin/attendance/report/student", Method: internal.GET, Handler: m.GetStudentReport, Permission: internal.ReadPermission},
+		{Path: "/admin/attendance/report/room", Method: internal.GET, Handler: m.GetRoomReport, Permission: internal.ReadPermission},
+	}
+}
+
+func attendanceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, HostelDB.ErrHostelNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrInvalidRange):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrHostelUnavailable), errors.Is(err, ErrFutureDate), errors.Is(err, ErrRollCallRejected):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// actor is the admin user _id behind the request
+func actor(c *fiber.Ctx) string {
+	if claims := JWTManager.GetClaims(c); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
+func (m *AttendanceManager) activeHostel(_id string, ctx context.Context) error {
+	hostel, err := m.hostelDB.FetchHostelByID(_id, ctx)
+	if err != nil {
+		return err
+	}
+	if hostel.MarkAsDeleted {
+		return ErrHostelUnavailable
+	}
+	return nil
+}
+
+// @Summary Record roll-call
+// @Description Mark present, absent or on_leave for many students of a hostel on one date. The whole request is rejected when one entry is invalid, sending it again overwrite the marks of the same date
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param roll_call body Attendance.RollCallRequest true "Marks of the roll-call"
+// @Success 200 {object} Attendance.RollCallResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/attendance/roll-call [post]
+func (m *AttendanceManager) RollCall(c *fiber.Ctx) error {
+	var request Attendance.RollCallRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse roll-call",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate roll-call",
+			"error":   err.Error(),
+		})
+	}
+
+	existing, _ := m.dbManager.FetchRange(request.HostelID, request.Date, request.Date, c.Context())
+	result, rejected, err := m.rollCall(&request, actor(c), c.Context())
+	if err != nil {
+		body := fiber.Map{
+			"message": "failed to record roll-call",
+			"error":   err.Error(),
+		}
+		if len(rejected) > 0 {
+			body["rejected"] = rejected
+		}
+		return c.Status(attendanceErrorStatus(err)).JSON(body)
+	}
+	// the entity is the roll-call of the hostel on that date, the changes are the
+	// marks by student, students not in the roll-call keep theirs
+	before, after := map[string]Attendance.Mark{}, map[string]Attendance.Mark{}
+	for _, record := range result.Recorded {
+		after[record.StudentID] = record.Mark
+	}
+	for _, record := range existing {
+		if _, ok := after[record.StudentID]; ok {
+			before[record.StudentID] = record.Mark
+		}
+	}
+	Audit.Record(c, request.HostelID+"/"+request.Date, before, after)
+	return c.JSON(result)
+}
+
+func (m *AttendanceManager) rollCall(request *Attendance.RollCallRequest, by string, ctx context.Context) (*Attendance.RollCallResult, []Attendance.RollCallRejection, error) {
+	date, _ := time.ParseInLocation(Attendance.DateLayout, request.Date, time.Local)
+	if date.After(time.Now()) {
+		return nil, nil, ErrFutureDate
+	}
+	if err := m.activeHostel(request.HostelID, ctx); err != nil {
+		return nil, nil, err
+	}
+	allocations, err := m.allocationDB.FetchActiveAllocations(request.HostelID, "", ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byStudent := make(map[string]Allocation.AllocationData, len(allocations))
+	for _, allocation := range allocations {
+		byStudent[allocation.StudentID] = allocation
+	}
+
+	var rejected []Attendance.RollCallRejection
+	records := make([]Attendance.AttendanceRecord, 0, len(request.Entries))
+	seen := map[string]bool{}
+	for _, entry := range request.Entries {
+		reason := ""
+		allocation, allocated := byStudent[entry.StudentID]
+		switch {
+		case seen[entry.StudentID]:
+			reason = "student is listed more than once"
+		case !allocated:
+			reason = "student has no active allocation in this hostel"
+		case entry.Mark == Attendance.OnLeave:
+			reason, err = m.checkLeave(entry.StudentID, entry.ApprovalRef, date, ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		seen[entry.StudentID] = true
+		if reason != "" {
+			rejected = append(rejected, Attendance.RollCallRejection{StudentID: entry.StudentID, Reason: reason})
+			continue
+		}
+		record := Attendance.AttendanceRecord{
+			StudentID:         entry.StudentID,
+			CollageUniqueName: allocation.CollageUniqueName,
+			HostelID:          request.HostelID,
+			RoomID:            allocation.RoomID,
+			Date:              request.Date,
+			Mark:              entry.Mark,
+			MarkedBy:          by,
+		}
+		if entry.Mark == Attendance.OnLeave {
+			record.ApprovalRef = entry.ApprovalRef
+		}
+		records = append(records, record)
+	}
+	if len(rejected) > 0 {
+		return nil, rejected, ErrRollCallRejected
+	}
+
+	recorded, err := m.dbManager.UpsertRecords(records, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Attendance.RollCallResult{HostelID: request.HostelID, Date: request.Date, Recorded: recorded}, nil, nil
+}
+
+// checkLeave return why the gate pass does not cover the student on that date, empty when it does
+func (m *AttendanceManager) checkLeave(studentID string, passID string, date time.Time, ctx context.Context) (string, error) {
+	pass, err := m.gateDB.FetchGatePassByID(passID, ctx)
+	if errors.Is(err, GateDB.ErrGatePassNotFound) {
+		return "approval reference is not a known gate pass", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if pass.StudentID != studentID {
+		return "approval reference belongs to another student", nil
+	}
+	if pass.Status != Gate.Approved && pass.Status != Gate.Out && pass.Status != Gate.Returned {
+		return fmt.Sprintf("gate pass is %s, not approved", pass.Status), nil
+	}
+	// compare calendar days so a leave starting in the evening cover that night
+	day := date.Format(Attendance.DateLayout)
+	if day < pass.LeaveAt.In(time.Local).Format(Attendance.DateLayout) || day > pass.ExpectedReturnAt.In(time.Local).Format(Attendance.DateLayout) {
+		return "gate pass does not cover this date", nil
+	}
+	return "", nil
+}
+
+// @Summary Get attendance records
+// @Description Fetch filtered roll-call marks, latest date first
+// @Tags attendance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param hostel_id query string false "Hostel id"
+// @Param room_id query string false "Room id"
+// @Param student_id query string false "Student id"
+// @Param date query string false "Date as YYYY-MM-DD"
+// @Param mark query string false "present, absent or on_leave"
+// @Success 200 {object} []Attendance.AttendanceRecord
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/attendance [get]
+func (m *AttendanceManager) GetRecords(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Attendance.AttendanceFilter{
+		Page:      page,
+		Limit:     limit,
+		HostelID:  c.Query("hostel_id", ""),
+		RoomID:    c.Query("room_id", ""),
+		StudentID: c.Query("student_id", ""),
+		Date:      c.Query("date", ""),
+		Mark:      Attendance.Mark(c.Query("mark", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	records, err := m.dbManager.FetchRecords(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch attendance",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(records)
+}
+
+// reportRange parse and check the hostel and date range of a report query
+func (m *AttendanceManager) reportRange(c *fiber.Ctx) (*Attendance.ReportRequest, []Attendance.AttendanceRecord, error) {
+	request := &Attendance.ReportRequest{
+		HostelID: c.Query("hostel_id", ""),
+		From:     c.Query("from", ""),
+		To:       c.Query("to", ""),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(request); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidRange, err)
+	}
+	from, _ := time.Parse(Attendance.DateLayout, request.From)
+	to, _ := time.Parse(Attendance.DateLayout, request.To)
+	if to.Before(from) || to.Sub(from) > maxReportDays*24*time.Hour {
+		return nil, nil, ErrInvalidRange
+	}
+	if _, err := m.hostelDB.FetchHostelByID(request.HostelID, c.Context()); err != nil {
+		return nil, nil, err
+	}
+	records, err := m.dbManager.FetchRange(request.HostelID, request.From, request.To, c.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, records, nil
+}
+
+// @Summary Get student attendance report
+// @Description Marks and absentee streaks of every student of a hostel over a date range, longest streak first
+// @Tags attendance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param from query string true "First date as YYYY-MM-DD"
+// @Param to query string true "Last date as YYYY-MM-DD"
+// @Success 200 {object} Attendance.StudentReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/attendance/report/student [get]
+func (m *AttendanceManager) GetStudentReport(c *fiber.Ctx) error {
+	request, records, err := m.reportRange(c)
+	if err != nil {
+		return c.Status(attendanceErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to build student report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(Attendance.StudentReport{
+		HostelID: request.HostelID,
+		From:     request.From,
+		To:       request.To,
+		Students: studentAttendance(records),
+	})
+}
+
+// @Summary Get room attendance report
+// @Description Marks and attendance rate of every room of a hostel over a date range, lowest rate first
+// @Tags attendance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param from query string true "First date as YYYY-MM-DD"
+// @Param to query string true "Last date as YYYY-MM-DD"
+// @Success 200 {object} Attendance.RoomReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/attendance/report/room [get]
+func (m *AttendanceManager) GetRoomReport(c *fiber.Ctx) error {
+	request, records, err := m.reportRange(c)
+	if err != nil {
+		return c.Status(attendanceErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to build room report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(Attendance.RoomReport{
+		HostelID: request.HostelID,
+		From:     request.From,
+		To:       request.To,
+		Rooms:    roomAttendance(records),
+	})
+}
@@ -0,0 +1,112 @@
+package Attendance_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Gate"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAttendanceFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{
+		{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Double, Capacity: 2, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+		{Block: "A", RoomNumber: "A-002", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+	}, ctx)
+	if err != nil || len(rooms) != 2 {
+		t.Fatalf("add rooms: expected 2 rooms; got %v %v", rooms, err)
+	}
+	var students []*Student.StudentData
+	for i, room := range []string{rooms[0].ID, rooms[0].ID, rooms[1].ID} {
+		student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: fmt.Sprintf("R-%d", i), CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+		if err != nil {
+			t.Fatalf("add student %d: %v", i, err)
+		}
+		if _, err = db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: student.ID, CollageUniqueName: "college-a", HostelID: hostel.ID, RoomID: room, BedNumber: i%2 + 1}, ctx); err != nil {
+			t.Fatalf("allocate student %d: %v", i, err)
+		}
+		students = append(students, student)
+	}
+	outsider, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "O", RollNumber: "R-9", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add outsider: %v", err)
+	}
+
+	now := time.Now()
+	leave, err := db.GateDB.CreateGatePass(&Gate.GatePassData{StudentID: students[2].ID, CollageUniqueName: "college-a", Kind: Gate.Leave, Reason: "Home",
+		Destination: "Home", LeaveAt: now.AddDate(0, 0, -3), ExpectedReturnAt: now.AddDate(0, 0, 2)}, ctx)
+	if err != nil {
+		t.Fatalf("add leave: %v", err)
+	}
+	if _, err = db.GateDB.TransitionGatePass(leave.ID, Gate.Pending, &Gate.GatePassChange{To: Gate.Approved}, ctx); err != nil {
+		t.Fatalf("approve leave: %v", err)
+	}
+
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format("2006-01-02") }
+	rollCall := func(date string, marks ...map[string]interface{}) (int, map[string]interface{}) {
+		return testutil.DoJSON(t, s, "POST", "/admin/attendance/roll-call", token, map[string]interface{}{"hostel_id": hostel.ID, "date": date, "entries": marks})
+	}
+	mark := func(student *Student.StudentData, value string) map[string]interface{} {
+		entry := map[string]interface{}{"student_id": student.ID, "mark": value}
+		if value == "on_leave" {
+			entry["approval_ref"] = leave.ID
+		}
+		return entry
+	}
+
+	status, body := rollCall(day(0), mark(students[0], "present"), mark(outsider, "present"), map[string]interface{}{"student_id": students[1].ID, "mark": "on_leave", "approval_ref": leave.ID})
+	if status != http.StatusUnprocessableEntity || len(testutil.Path[[]interface{}](t, body, "rejected")) != 2 {
+		t.Fatalf("invalid roll-call: expected two rejected entries; got %d %v", status, body)
+	}
+	if records, _ := db.AttendanceDB.FetchRange(hostel.ID, day(-10), day(0), ctx); len(records) != 0 {
+		t.Fatalf("invalid roll-call: expected nothing recorded; got %d", len(records))
+	}
+	if status, _ = rollCall(day(1), mark(students[0], "present")); status != http.StatusUnprocessableEntity {
+		t.Fatalf("future roll-call: expected status 422; got %d", status)
+	}
+
+	for offset, marks := range map[int][]string{-2: {"absent", "present", "on_leave"}, -1: {"absent", "present", "on_leave"}, 0: {"present", "absent", "on_leave"}} {
+		if status, body = rollCall(day(offset), mark(students[0], marks[0]), mark(students[1], marks[1]), mark(students[2], marks[2])); status != http.StatusOK {
+			t.Fatalf("roll-call %s: expected status OK; got %d %v", day(offset), status, body)
+		}
+	}
+	// marking the same day again replace the marks instead of adding records
+	if status, _ = rollCall(day(0), mark(students[0], "absent")); status != http.StatusOK {
+		t.Fatalf("roll-call again: expected status OK; got %d", status)
+	}
+	if records, _ := db.AttendanceDB.FetchRange(hostel.ID, day(-10), day(0), ctx); len(records) != 9 {
+		t.Fatalf("idempotent roll-call: expected 9 records; got %d", len(records))
+	}
+
+	query := "?hostel_id=" + hostel.ID + "&from=" + day(-6) + "&to=" + day(0)
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/attendance/report/student"+query, token, nil)
+	first := testutil.Path[map[string]interface{}](t, body, "students", 0)
+	if status != http.StatusOK || first["student_id"] != students[0].ID || testutil.Path[float64](t, first, "longest_absent_streak") != 3 || testutil.Path[float64](t, first, "current_absent_streak") != 3 {
+		t.Fatalf("student report: expected a 3 day streak first; got %d %v", status, body)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/attendance/report/room"+query, token, nil)
+	worst := testutil.Path[map[string]interface{}](t, body, "rooms", 0)
+	if status != http.StatusOK || worst["room_id"] != rooms[0].ID || testutil.Path[float64](t, worst, "absent") != 4 || testutil.Path[float64](t, worst, "present") != 2 {
+		t.Fatalf("room report: expected the double room first; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/attendance/report/room?hostel_id="+hostel.ID+"&from="+day(0)+"&to="+day(-6), token, nil); status != http.StatusBadRequest {
+		t.Fatalf("reversed range: expected status 400; got %d", status)
+	}
+}
@@ -0,0 +1,96 @@
+package Attendance
+
+import (
+	"HostelApp/internal/storageData/Attendance"
+	"sort"
+	"time"
+)
+
+func countMark(count *Attendance.MarkCount, mark Attendance.Mark) {
+	switch mark {
+	case Attendance.Present:
+		count.Present++
+	case Attendance.Absent:
+		count.Absent++
+	case Attendance.OnLeave:
+		count.OnLeave++
+	}
+}
+
+// followingDay tell if date is the day right after previous, a day without a
+// roll-call entry between two absences break the streak
+func followingDay(previous string, date string) bool {
+	day, err := time.Parse(Attendance.DateLayout, previous)
+	if err != nil {
+		return false
+	}
+	return day.AddDate(0, 0, 1).Format(Attendance.DateLayout) == date
+}
+
+// studentAttendance summarize records sorted by student then date, the worst
+// absentee streaks come first
+func studentAttendance(records []Attendance.AttendanceRecord) []Attendance.StudentAttendance {
+	students := []Attendance.StudentAttendance{}
+	for i := 0; i < len(records); {
+		summary := Attendance.StudentAttendance{StudentID: records[i].StudentID}
+		for ; i < len(records) && records[i].StudentID == summary.StudentID; i++ {
+			record := records[i]
+			countMark(&summary.MarkCount, record.Mark)
+			summary.RoomID = record.RoomID
+			if record.Mark != Attendance.Absent {
+				// an approved leave end the streak like a present mark
+				summary.CurrentAbsentStreak = 0
+				continue
+			}
+			if summary.CurrentAbsentStreak > 0 && !followingDay(summary.LastAbsentDate, record.Date) {
+				summary.CurrentAbsentStreak = 0
+			}
+			summary.CurrentAbsentStreak++
+			summary.LastAbsentDate = record.Date
+			if summary.CurrentAbsentStreak > summary.LongestAbsentStreak {
+				summary.LongestAbsentStreak = summary.CurrentAbsentStreak
+			}
+		}
+		students = append(students, summary)
+	}
+	sort.SliceStable(students, func(i, j int) bool {
+		if students[i].LongestAbsentStreak != students[j].LongestAbsentStreak {
+			return students[i].LongestAbsentStreak > students[j].LongestAbsentStreak
+		}
+		return students[i].CurrentAbsentStreak > students[j].CurrentAbsentStreak
+	})
+	return students
+}
+
+// roomAttendance summarize records per room, the lowest attendance rate come
+// first and rooms without a rate come last
+func roomAttendance(records []Attendance.AttendanceRecord) []Attendance.RoomAttendance {
+	counts := map[string]*Attendance.MarkCount{}
+	for _, record := range records {
+		count, ok := counts[record.RoomID]
+		if !ok {
+			count = &Attendance.MarkCount{}
+			counts[record.RoomID] = count
+		}
+		countMark(count, record.Mark)
+	}
+	rooms := make([]Attendance.RoomAttendance, 0, len(counts))
+	for roomID, count := range counts {
+		room := Attendance.RoomAttendance{RoomID: roomID, MarkCount: *count}
+		if counted := count.Present + count.Absent; counted > 0 {
+			rate := float64(count.Present) / float64(counted)
+			room.Rate = &rate
+		}
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		if (rooms[i].Rate == nil) != (rooms[j].Rate == nil) {
+			return rooms[j].Rate == nil
+		}
+		if rooms[i].Rate != nil && *rooms[i].Rate != *rooms[j].Rate {
+			return *rooms[i].Rate < *rooms[j].Rate
+		}
+		return rooms[i].RoomID < rooms[j].RoomID
+	})
+	return rooms
+}
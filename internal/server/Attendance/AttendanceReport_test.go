@@ -0,0 +1,55 @@
+package Attendance
+
+import (
+	"HostelApp/internal/storageData/Attendance"
+	"testing"
+)
+
+func TestStudentAttendance(t *testing.T) {
+	record := func(studentID string, date string, mark Attendance.Mark) Attendance.AttendanceRecord {
+		return Attendance.AttendanceRecord{StudentID: studentID, RoomID: "r1", Date: date, Mark: mark}
+	}
+	// sorted by student then date like FetchRange return them
+	students := studentAttendance([]Attendance.AttendanceRecord{
+		record("s1", "2026-03-01", Attendance.Absent),
+		record("s1", "2026-03-02", Attendance.OnLeave),
+		record("s1", "2026-03-03", Attendance.Absent),
+		record("s2", "2026-03-01", Attendance.Absent),
+		record("s2", "2026-03-02", Attendance.Absent),
+		record("s2", "2026-03-03", Attendance.Present),
+		// no roll-call on 03-02 and 03-03, the absences are not one streak
+		record("s3", "2026-03-01", Attendance.Absent),
+		record("s3", "2026-03-04", Attendance.Absent),
+	})
+	if len(students) != 3 {
+		t.Fatalf("expected 3 students; got %+v", students)
+	}
+	if gap := students[2]; gap.StudentID != "s3" || gap.LongestAbsentStreak != 1 || gap.CurrentAbsentStreak != 1 || gap.LastAbsentDate != "2026-03-04" {
+		t.Fatalf("expected the missing days to break the streak of s3; got %+v", gap)
+	}
+	worst, other := students[0], students[1]
+	if worst.StudentID != "s2" || worst.LongestAbsentStreak != 2 || worst.CurrentAbsentStreak != 0 || worst.LastAbsentDate != "2026-03-02" {
+		t.Fatalf("expected s2 first with a streak of 2 ended by a present mark; got %+v", worst)
+	}
+	if other.LongestAbsentStreak != 1 || other.CurrentAbsentStreak != 1 || other.OnLeave != 1 || other.Absent != 2 {
+		t.Fatalf("expected the leave to break the streak of s1; got %+v", other)
+	}
+}
+
+func TestRoomAttendance(t *testing.T) {
+	record := func(roomID string, mark Attendance.Mark) Attendance.AttendanceRecord {
+		return Attendance.AttendanceRecord{StudentID: "s1", RoomID: roomID, Mark: mark}
+	}
+	rooms := roomAttendance([]Attendance.AttendanceRecord{
+		record("r1", Attendance.Present),
+		record("r1", Attendance.Absent),
+		record("r2", Attendance.OnLeave),
+		record("r3", Attendance.Absent),
+	})
+	if len(rooms) != 3 || rooms[0].RoomID != "r3" || rooms[1].RoomID != "r1" || rooms[2].RoomID != "r2" {
+		t.Fatalf("expected r3, r1 then r2 without a rate; got %+v", rooms)
+	}
+	if rooms[1].Rate == nil || *rooms[1].Rate != 0.5 || rooms[2].Rate != nil {
+		t.Fatalf("expected a rate of 0.5 for r1 and none for r2; got %+v", rooms)
+	}
+}
@@ -0,0 +1,80 @@
+package Audit
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/ValidatorSystem"
+	AuditDB "HostelApp/internal/database/Audit"
+	"HostelApp/internal/storageData/Audit"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+// AuditManager record every mutating admin request and serve the audit log
+type AuditManager struct {
+	dbManager AuditDB.IAuditDBService
+}
+
+func NewAuditManager(dbManager AuditDB.IAuditDBService) *AuditManager {
+	return &AuditManager{
+		dbManager: dbManager,
+	}
+}
+
+func (m *AuditManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		// the log show what every admin did so it is kept to Full admins
+		{Path: "/admin/audit", Method: internal.GET, Handler: m.GetAudit, Permission: internal.ManageAdminPermission},
+	}
+}
+
+// @Summary Get audit log
+// @Description Fetch the audit log of mutating admin requests, latest first
+// @Tags audit
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string false "Page number"
+// @Param limit query string false "Items per page, at most 100"
+// @Param actor_id query string false "Admin who made the request"
+// @Param entity query string false "Entity, e.g. college, user or student"
+// @Param entity_id query string false "Id or unique name of the entity"
+// @Param from query string false "First day included, YYYY-MM-DD in UTC"
+// @Param to query string false "Last day included, YYYY-MM-DD in UTC"
+// @Success 200 {object} Listing.Page[Audit.AuditEntry]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/audit [get]
+func (m *AuditManager) GetAudit(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "20"), 10, 64)
+	filter := Audit.AuditFilter{
+		Page:     page,
+		Limit:    limit,
+		ActorID:  c.Query("actor_id", ""),
+		Entity:   c.Query("entity", ""),
+		EntityID: c.Query("entity_id", ""),
+		From:     c.Query("from", ""),
+		To:       c.Query("to", ""),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   "from must not be after to",
+		})
+	}
+
+	entries, err := m.dbManager.FetchEntries(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch audit log",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(entries)
+}
@@ -0,0 +1,79 @@
+package Audit_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuditLogInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	writer := map[string]interface{}{"username": "writer", "email": "writer@admin.com", "password": "password@123", "excess_level": 3}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/User", token, writer); status != http.StatusCreated {
+		t.Fatalf("create user: expected status 201; got %d %v", status, body)
+	}
+	writerToken, _ := testutil.Login(t, s, "writer", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+	if status, _ := testutil.DoJSON(t, s, "PATCH", "/admin/college/college-a", writerToken, map[string]interface{}{"pin_code": "110001"}); status != http.StatusOK {
+		t.Fatalf("update college: expected status OK; got %d", status)
+	}
+	if status, _ := testutil.DoJSON(t, s, "DELETE", "/admin/college/college-a/purge", writerToken, nil); status != http.StatusForbidden {
+		t.Fatalf("purge as read and write: expected status 403; got %d", status)
+	}
+	entries := func(body map[string]interface{}) []map[string]interface{} {
+		var items []map[string]interface{}
+		for _, item := range testutil.Path[[]interface{}](t, body, "items") {
+			items = append(items, testutil.Path[map[string]interface{}](t, item))
+		}
+		return items
+	}
+
+	if status, _ := testutil.DoJSON(t, s, "GET", "/admin/audit", writerToken, nil); status != http.StatusForbidden {
+		t.Fatalf("audit as read and write: expected status 403; got %d", status)
+	}
+	status, body := testutil.DoJSON(t, s, "GET", "/admin/audit?entity=college", token, nil)
+	college := entries(body)
+	if status != http.StatusOK || len(college) != 3 || testutil.Path[float64](t, body, "total") != 3 {
+		t.Fatalf("college audit: expected the add, update and denied purge; got %d %v", status, body)
+	}
+	// latest first, the denied purge is recorded with its status
+	purge, update := college[0], college[1]
+	if testutil.Path[float64](t, purge, "status") != http.StatusForbidden || purge["action"] != "DELETE /admin/college/:unique_name/purge" || purge["entity_id"] != "college-a" {
+		t.Fatalf("denied purge: unexpected entry %v", purge)
+	}
+	changes := testutil.Path[[]interface{}](t, update, "changes")
+	if update["actor_id"] == "" || update["actor_id"] == college[2]["actor_id"] || len(changes) != 1 ||
+		fmt.Sprint(changes[0]) != "map[after:110001 before:560001 field:pin_code]" {
+		t.Fatalf("update: expected the writer and the pin code change; got %v", update)
+	}
+
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/audit?entity=user&actor_id="+url.QueryEscape(testutil.Path[string](t, college, 2, "actor_id")), token, nil)
+	created := entries(body)
+	if status != http.StatusOK || len(created) != 1 || created[0]["entity_id"] != "writer" || created[0]["ip"] == "" {
+		t.Fatalf("user audit: expected the creation of writer; got %d %v", status, body)
+	}
+	for _, change := range testutil.Path[[]interface{}](t, created, 0, "changes") {
+		if field := testutil.Path[map[string]interface{}](t, change); field["field"] == "password" && field["after"] != "[redacted]" {
+			t.Fatalf("user audit: expected the password redacted; got %v", field)
+		}
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/audit?from="+today+"&to="+today, token, nil); status != http.StatusOK || testutil.Path[float64](t, body, "total") != 4 {
+		t.Fatalf("audit of today: expected every entry; got %d %v", status, body)
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/audit?from="+tomorrow, token, nil); status != http.StatusOK || testutil.Path[float64](t, body, "total") != 0 {
+		t.Fatalf("audit from tomorrow: expected no entry; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "GET", "/admin/audit?from="+tomorrow+"&to="+today, token, nil); status != http.StatusBadRequest {
+		t.Fatalf("inverted audit range: expected status 400; got %d", status)
+	}
+}
@@ -0,0 +1,137 @@
+package Audit
+
+import (
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/storageData/Audit"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const recordLocalKey = "auditRecord"
+
+// redactedFields never reach the audit log, a change to them is recorded without the values
+var redactedFields = map[string]bool{"password": true, "refresh_token": true, "refreshToken": true}
+
+type record struct {
+	entityID string
+	before   interface{}
+	after    interface{}
+}
+
+// Record report the entity a handler changed, before is nil for a creation and
+// after is nil for a removal. Without it the entry only carry the route and
+// the first path parameter as entity id
+func Record(c *fiber.Ctx, entityID string, before interface{}, after interface{}) {
+	c.Locals(recordLocalKey, &record{entityID: entityID, before: before, after: after})
+}
+
+// Middleware must run after JWTManager.Middleware, it append an entry for
+// the request once the handler has answered, whatever the outcome
+func (m *AuditManager) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		handlerErr := c.Next()
+
+		route := c.Route()
+		entry := &Audit.AuditEntry{
+			Action:    c.Method() + " " + route.Path,
+			Path:      c.Path(),
+			Entity:    entityOf(route.Path),
+			Status:    c.Response().StatusCode(),
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+		}
+		var fiberErr *fiber.Error
+		if errors.As(handlerErr, &fiberErr) {
+			entry.Status = fiberErr.Code
+		} else if handlerErr != nil {
+			entry.Status = fiber.StatusInternalServerError
+		}
+		if claims := JWTManager.GetClaims(c); claims != nil {
+			entry.ActorID = claims.Subject
+		}
+		if len(route.Params) > 0 {
+			entry.EntityID = c.Params(route.Params[0])
+		}
+		if recorded, ok := c.Locals(recordLocalKey).(*record); ok {
+			if recorded.entityID != "" {
+				entry.EntityID = recorded.entityID
+			}
+			entry.Changes = diff(recorded.before, recorded.after)
+		}
+		if err := m.dbManager.Append(entry, c.Context()); err != nil {
+			slog.Error(fmt.Sprintf("failed to append audit entry for %s: %v", entry.Action, err))
+		}
+		return handlerErr
+	}
+}
+
+// entityOf name the entity of a route after its first segment under /admin,
+// "/admin/college/:unique_name/restore" is "college"
+func entityOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && segments[0] == "admin" {
+		segments = segments[1:]
+	}
+	return strings.ToLower(segments[0])
+}
+
+// diff compare the top level json fields of before and after, values that are
+// not objects are compared as a single field named value
+func diff(before interface{}, after interface{}) []Audit.FieldChange {
+	old, updated := fieldsOf(before), fieldsOf(after)
+	names := make([]string, 0, len(old)+len(updated))
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range updated {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []Audit.FieldChange
+	for _, name := range names {
+		oldValue, hadOld := old[name]
+		newValue, hasNew := updated[name]
+		if hadOld && hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := Audit.FieldChange{Field: name, Before: oldValue, After: newValue}
+		if redactedFields[name] {
+			change.Before, change.After = redacted(hadOld), redacted(hasNew)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func redacted(present bool) interface{} {
+	if present {
+		return "[redacted]"
+	}
+	return nil
+}
+
+func fieldsOf(value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return map[string]interface{}{"value": fmt.Sprint(value)}
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(raw, &fields) == nil {
+		return fields
+	}
+	var decoded interface{}
+	_ = json.Unmarshal(raw, &decoded)
+	return map[string]interface{}{"value": decoded}
+}
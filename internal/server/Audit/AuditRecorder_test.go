@@ -0,0 +1,37 @@
+package Audit
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEntityOf(t *testing.T) {
+	for path, entity := range map[string]string{
+		"/admin/college/:unique_name/restore": "college",
+		"/admin/User":                         "user",
+		"/payment/webhook":                    "payment",
+	} {
+		if got := entityOf(path); got != entity {
+			t.Fatalf("%s: expected %s; got %s", path, entity, got)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	type user struct {
+		Name     string `json:"name"`
+		Level    int    `json:"level"`
+		Password string `json:"password"`
+	}
+	changes := diff(&user{Name: "a", Level: 1, Password: "old"}, &user{Name: "a", Level: 2, Password: "new"})
+	if fmt.Sprint(changes) != "[{level 1 2} {password [redacted] [redacted]}]" {
+		t.Fatalf("expected the level and a redacted password change; got %v", changes)
+	}
+	created := diff(nil, map[string]interface{}{"name": "a"})
+	if len(created) != 1 || created[0].Before != nil || created[0].After != "a" {
+		t.Fatalf("expected a creation without before values; got %v", created)
+	}
+	if removed := diff("draft", nil); len(removed) != 1 || removed[0].Field != "value" || removed[0].Before != "draft" {
+		t.Fatalf("expected a removed scalar as the value field; got %v", removed)
+	}
+}
@@ -0,0 +1,765 @@
+package Finance
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/ValidatorSystem"
+	AllocationDB "HostelApp/internal/database/Allocation"
+	FinanceDB "HostelApp/internal/database/Finance"
+	HostelDB "HostelApp/internal/database/Hostel"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvoiceStudentMismatch = errors.New("invoice does not belong to the student")
+	ErrStudentUnavailable     = errors.New("student is deleted")
+	ErrHostelUnavailable      = errors.New("hostel is deleted")
+	// ErrUnsupportedCurrency keep the ledger in one currency, balances add up
+	// minor units of every transaction of an account
+	ErrUnsupportedCurrency = errors.New("only " + Finance.DefaultCurrency + " fee structures are supported")
+)
+
+type FinanceManager struct {
+	dbManager    FinanceDB.IFinanceDBService
+	studentDB    StudentDB.IStudentDBService
+	hostelDB     HostelDB.IHostelDBService
+	allocationDB AllocationDB.IAllocationDBService
+}
+
+func NewFinanceManager(dbManager FinanceDB.IFinanceDBService, studentDB StudentDB.IStudentDBService, hostelDB HostelDB.IHostelDBService, allocationDB AllocationDB.IAllocationDBService) *FinanceManager {
+	instance := &FinanceManager{
+		dbManager:    dbManager,
+		studentDB:    studentDB,
+		hostelDB:     hostelDB,
+		allocationDB: allocationDB,
+	}
+	return instance
+}
+
+func (m *FinanceManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/finance/fee-structure", Method: internal.GET, Handler: m.GetFeeStructures, Permission: internal.ReadPermission},
+		{Path: "/admin/finance/fee-structure", Method: internal.POST, Handler: m.AddFeeStructure, Permission: internal.WritePermission},
+		{Path: "/admin/finance/invoice", Method: internal.GET, Handler: m.GetInvoices, Permission: internal.ReadPermission},
+		{Path: "/admin/finance/invoice/generate", Method: internal.POST, Handler: m.GenerateInvoices, Permission: internal.WritePermission},
+		{Path: "/admin/finance/invoice/:id", Method: internal.GET, Handler: m.GetInvoice, Permission: internal.ReadPermission},
+		{Path: "/admin/finance/invoice/:id/void", Method: internal.POST, Handler: m.VoidInvoice, Permission: internal.WritePermission},
+		{Path: "/admin/finance/payment", Method: internal.POST, Handler: m.RecordPayment, Permission: internal.WritePermission},
+		{Path: "/admin/finance/refund", Method: internal.POST, Handler: m.RecordRefund, Permission: internal.WritePermission},
+		{Path: "/admin/finance/adjustment", Method: internal.POST, Handler: m.RecordAdjustment, Permission: internal.WritePermission},
+		{Path: "/admin/finance/fine", Method: internal.POST, Handler: m.ChargeFine, Permission: internal.WritePermission},
+		{Path: "/admin/finance/ledger", Method: internal.GET, Handler: m.GetLedger, Permission: internal.ReadPermission},
+		{Path: "/admin/finance/outstanding", Method: internal.GET, Handler: m.GetOutstanding, Permission: internal.ReadPermission},
+		{Path: "/admin/finance/student/:id/balance", Method: internal.GET, Handler: m.GetStudentBalance, Permission: internal.ReadPermission},
+	}
+}
+
+func financeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, FinanceDB.ErrInvoiceNotFound), errors.Is(err, FinanceDB.ErrFeeStructureNotFound),
+		errors.Is(err, StudentDB.ErrStudentNotFound), errors.Is(err, HostelDB.ErrHostelNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, FinanceDB.ErrInvoiceDuplicate), errors.Is(err, FinanceDB.ErrInvoiceNotIssued),
+		errors.Is(err, FinanceDB.ErrDuplicateReference):
+		return fiber.StatusConflict
+	case errors.Is(err, FinanceDB.ErrUnbalancedTransaction), errors.Is(err, ErrInvoiceStudentMismatch),
+		errors.Is(err, ErrStudentUnavailable), errors.Is(err, ErrHostelUnavailable), errors.Is(err, ErrUnsupportedCurrency):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (m *FinanceManager) fetchStudent(studentID string, ctx context.Context) (*Student.StudentData, error) {
+	student, err := m.studentDB.FetchStudentByID(studentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if student.MarkAsDeleted {
+		return nil, ErrStudentUnavailable
+	}
+	return student, nil
+}
+
+// EffectiveStructure return the newest fee structure already effective at periodStart,
+// it is also used by the mess to price meal rebates
+func (m *FinanceManager) EffectiveStructure(hostelID string, periodStart time.Time, ctx context.Context) (*Finance.FeeStructure, error) {
+	structures, err := m.dbManager.FetchFeeStructures(hostelID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range structures {
+		if !structures[i].EffectiveFrom.After(periodStart) {
+			return &structures[i], nil
+		}
+	}
+	return nil, FinanceDB.ErrFeeStructureNotFound
+}
+
+// chargePostings credit every invoice line to its revenue account, or to the
+// held deposits, against the student receivable
+func chargePostings(studentID string, lines []Finance.FeeLine) []Finance.Posting {
+	var total int64
+	credits := map[string]int64{}
+	var accounts []string
+	for _, line := range lines {
+		account := Finance.RevenueAccount + string(line.Kind)
+		if line.Kind == Finance.Deposit {
+			account = Finance.DepositAccount
+		}
+		if _, ok := credits[account]; !ok {
+			accounts = append(accounts, account)
+		}
+		credits[account] += line.Amount
+		total += line.Amount
+	}
+	postings := []Finance.Posting{{Account: Finance.StudentAccount(studentID), Debit: total}}
+	for _, account := range accounts {
+		postings = append(postings, Finance.Posting{Account: account, Credit: credits[account]})
+	}
+	return postings
+}
+
+// reversePostings swap debit and credit to cancel a transaction
+func reversePostings(postings []Finance.Posting) []Finance.Posting {
+	reversed := make([]Finance.Posting, 0, len(postings))
+	for _, posting := range postings {
+		reversed = append(reversed, Finance.Posting{Account: posting.Account, Debit: posting.Credit, Credit: posting.Debit})
+	}
+	return reversed
+}
+
+// @Summary Add fee structure
+// @Description Add the fee structure of a hostel, amounts are integer minor units of the currency, only the ledger currency INR is accepted
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param structure body Finance.FeeStructure true "Fee structure"
+// @Success 201 {object} Finance.FeeStructure
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/finance/fee-structure [post]
+func (m *FinanceManager) AddFeeStructure(c *fiber.Ctx) error {
+	var structure Finance.FeeStructure
+	if err := c.BodyParser(&structure); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse fee structure",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&structure); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate fee structure",
+			"error":   err.Error(),
+		})
+	}
+	if structure.Currency != Finance.DefaultCurrency {
+		return c.Status(financeErrorStatus(ErrUnsupportedCurrency)).JSON(fiber.Map{
+			"message": "failed to validate fee structure",
+			"error":   ErrUnsupportedCurrency.Error(),
+		})
+	}
+	hostel, err := m.hostelDB.FetchHostelByID(structure.HostelID, c.Context())
+	if err == nil && hostel.MarkAsDeleted {
+		err = ErrHostelUnavailable
+	}
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch hostel",
+			"error":   err.Error(),
+		})
+	}
+
+	added, err := m.dbManager.AddFeeStructure(&structure, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add fee structure in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, added.ID, nil, added)
+	return c.Status(fiber.StatusCreated).JSON(added)
+}
+
+// @Summary Get fee structures
+// @Description Fetch every fee structure of a hostel, newest effective first
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Success 200 {object} []Finance.FeeStructure
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/finance/fee-structure [get]
+func (m *FinanceManager) GetFeeStructures(c *fiber.Ctx) error {
+	hostelID := c.Query("hostel_id", "")
+	if hostelID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   "hostel_id is required",
+		})
+	}
+	structures, err := m.dbManager.FetchFeeStructures(hostelID, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch fee structures",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(structures)
+}
+
+// @Summary Generate invoices
+// @Description Invoice every student with an active allocation in the hostel for a billing period, students already invoiced for the period are skipped
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param request body Finance.GenerateInvoicesRequest true "Hostel and period (YYYY-MM)"
+// @Success 200 {object} Finance.GenerateInvoicesResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/finance/invoice/generate [post]
+func (m *FinanceManager) GenerateInvoices(c *fiber.Ctx) error {
+	var request Finance.GenerateInvoicesRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse invoice request",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate invoice request",
+			"error":   err.Error(),
+		})
+	}
+	periodStart, _ := time.Parse("2006-01", request.Period)
+
+	result, err := m.generateInvoices(&request, periodStart, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to generate invoices",
+			"error":   err.Error(),
+			"result":  result,
+		})
+	}
+	Audit.Record(c, request.Period, nil, result)
+	return c.JSON(result)
+}
+
+func (m *FinanceManager) generateInvoices(request *Finance.GenerateInvoicesRequest, periodStart time.Time, ctx context.Context) (*Finance.GenerateInvoicesResult, error) {
+	result := &Finance.GenerateInvoicesResult{Created: []Finance.Invoice{}, Skipped: []Finance.InvoiceSkip{}}
+	hostel, err := m.hostelDB.FetchHostelByID(request.HostelID, ctx)
+	if err != nil {
+		return result, err
+	}
+	if hostel.MarkAsDeleted {
+		return result, ErrHostelUnavailable
+	}
+	structure, err := m.EffectiveStructure(hostel.ID, periodStart, ctx)
+	if err != nil {
+		return result, err
+	}
+	allocations, err := m.allocationDB.FetchActiveAllocations(hostel.ID, "", ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, allocation := range allocations {
+		previous, err := m.dbManager.FetchInvoices(&Finance.InvoiceFilter{Page: 1, Limit: 1, StudentID: allocation.StudentID, HostelID: hostel.ID, Status: Finance.Issued}, ctx)
+		if err != nil {
+			return result, err
+		}
+		var lines []Finance.FeeLine
+		var total int64
+		for _, line := range structure.Lines {
+			if line.Kind == Finance.Fine || (line.Kind == Finance.Deposit && len(previous) > 0) {
+				continue
+			}
+			lines = append(lines, line)
+			total += line.Amount
+		}
+		if total == 0 {
+			result.Skipped = append(result.Skipped, Finance.InvoiceSkip{StudentID: allocation.StudentID, Reason: "nothing to bill"})
+			continue
+		}
+
+		invoice, err := m.dbManager.CreateInvoice(&Finance.Invoice{
+			StudentID:         allocation.StudentID,
+			CollageUniqueName: hostel.CollageUniqueName,
+			HostelID:          hostel.ID,
+			AllocationID:      allocation.ID,
+			FeeStructureID:    structure.ID,
+			Period:            request.Period,
+			Currency:          structure.Currency,
+			Lines:             lines,
+			Total:             total,
+			DueDate:           request.DueDate,
+		}, ctx)
+		if errors.Is(err, FinanceDB.ErrInvoiceDuplicate) {
+			result.Skipped = append(result.Skipped, Finance.InvoiceSkip{StudentID: allocation.StudentID, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		_, err = m.dbManager.PostTransaction(&Finance.LedgerTransaction{
+			Kind:              Finance.InvoiceCharge,
+			StudentID:         invoice.StudentID,
+			CollageUniqueName: invoice.CollageUniqueName,
+			HostelID:          invoice.HostelID,
+			InvoiceID:         invoice.ID,
+			Currency:          invoice.Currency,
+			Amount:            invoice.Total,
+			Note:              fmt.Sprintf("invoice for %s", invoice.Period),
+			Postings:          chargePostings(invoice.StudentID, invoice.Lines),
+		}, ctx)
+		if err != nil {
+			// an invoice without its ledger charge must not stay issued
+			if _, voidErr := m.dbManager.VoidInvoice(invoice.ID, ctx); voidErr != nil {
+				slog.Error(fmt.Sprintf("failed to void invoice %s after ledger error: %v", invoice.ID, voidErr))
+			}
+			return result, err
+		}
+		result.Created = append(result.Created, *invoice)
+	}
+	return result, nil
+}
+
+// @Summary Get invoice list
+// @Description Fetch filtered list of invoices, newest period first
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param hostel_id query string false "Hostel id"
+// @Param collage_unique_name query string false "College unique name"
+// @Param period query string false "Billing period YYYY-MM"
+// @Param status query string false "issued or void"
+// @Success 200 {object} []Finance.Invoice
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/finance/invoice [get]
+func (m *FinanceManager) GetInvoices(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Finance.InvoiceFilter{
+		Page:              page,
+		Limit:             limit,
+		StudentID:         c.Query("student_id", ""),
+		HostelID:          c.Query("hostel_id", ""),
+		CollageUniqueName: c.Query("collage_unique_name", ""),
+		Period:            c.Query("period", ""),
+		Status:            Finance.InvoiceStatus(c.Query("status", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	invoices, err := m.dbManager.FetchInvoices(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch invoices",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(invoices)
+}
+
+// @Summary Get invoice
+// @Description Fetch an invoice with what was paid and what is still outstanding on it
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Invoice id"
+// @Success 200 {object} Finance.InvoiceSummary
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/finance/invoice/{id} [get]
+func (m *FinanceManager) GetInvoice(c *fiber.Ctx) error {
+	invoice, err := m.dbManager.FetchInvoiceByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch invoice",
+			"error":   err.Error(),
+		})
+	}
+	paid, err := m.dbManager.InvoicePaid(invoice.ID, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch invoice payments",
+			"error":   err.Error(),
+		})
+	}
+	summary := Finance.InvoiceSummary{Invoice: *invoice, Paid: paid}
+	if invoice.Status == Finance.Issued && paid < invoice.Total {
+		summary.Outstanding = invoice.Total - paid
+	}
+	return c.JSON(summary)
+}
+
+// @Summary Void invoice
+// @Description Void an issued invoice, its charge is reversed in the ledger and payments on it stay as student credit
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Invoice id"
+// @Success 200 {object} Finance.Invoice
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/finance/invoice/{id}/void [post]
+func (m *FinanceManager) VoidInvoice(c *fiber.Ctx) error {
+	before, _ := m.dbManager.FetchInvoiceByID(c.Params("id"), c.Context())
+	invoice, err := m.dbManager.VoidInvoice(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to void invoice",
+			"error":   err.Error(),
+		})
+	}
+	// the invoice is void even when its reversal below fail
+	Audit.Record(c, invoice.ID, before, invoice)
+	_, err = m.dbManager.PostTransaction(&Finance.LedgerTransaction{
+		Kind:              Finance.InvoiceVoid,
+		StudentID:         invoice.StudentID,
+		CollageUniqueName: invoice.CollageUniqueName,
+		HostelID:          invoice.HostelID,
+		InvoiceID:         invoice.ID,
+		Currency:          invoice.Currency,
+		Amount:            invoice.Total,
+		Note:              fmt.Sprintf("void invoice for %s", invoice.Period),
+		Postings:          reversePostings(chargePostings(invoice.StudentID, invoice.Lines)),
+	}, c.Context())
+	if err != nil {
+		slog.Error(fmt.Sprintf("invoice %s is void but its charge was not reversed: %v", invoice.ID, err))
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to reverse invoice charge",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(invoice)
+}
+
+// postMoneyMovement record a payment or a refund of a student, optionally against one of its invoices
+func (m *FinanceManager) postMoneyMovement(kind Finance.TransactionKind, request *Finance.PaymentRequest, ctx context.Context) (*Finance.LedgerTransaction, error) {
+	student, err := m.fetchStudent(request.StudentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	transaction := &Finance.LedgerTransaction{
+		Kind:              kind,
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		InvoiceID:         request.InvoiceID,
+		Currency:          Finance.DefaultCurrency,
+		Amount:            request.Amount,
+		Reference:         request.Reference,
+		Note:              request.Note,
+	}
+	if request.InvoiceID != "" {
+		invoice, err := m.dbManager.FetchInvoiceByID(request.InvoiceID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if invoice.StudentID != student.ID {
+			return nil, ErrInvoiceStudentMismatch
+		}
+		if kind == Finance.Payment && invoice.Status != Finance.Issued {
+			return nil, FinanceDB.ErrInvoiceNotIssued
+		}
+		transaction.HostelID = invoice.HostelID
+		transaction.Currency = invoice.Currency
+	}
+	account := Finance.StudentAccount(student.ID)
+	if kind == Finance.Payment {
+		transaction.Postings = []Finance.Posting{{Account: Finance.CashAccount, Debit: request.Amount}, {Account: account, Credit: request.Amount}}
+	} else {
+		transaction.Postings = []Finance.Posting{{Account: account, Debit: request.Amount}, {Account: Finance.CashAccount, Credit: request.Amount}}
+	}
+	return m.dbManager.PostTransaction(transaction, ctx)
+}
+
+// PostPayment record a payment received outside of the admin endpoints, like an online payment
+func (m *FinanceManager) PostPayment(request *Finance.PaymentRequest, ctx context.Context) (*Finance.LedgerTransaction, error) {
+	return m.postMoneyMovement(Finance.Payment, request, ctx)
+}
+
+func (m *FinanceManager) recordMoneyMovement(c *fiber.Ctx, kind Finance.TransactionKind) error {
+	var request Finance.PaymentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("failed to parse %s", kind),
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("failed to validate %s", kind),
+			"error":   err.Error(),
+		})
+	}
+
+	transaction, err := m.postMoneyMovement(kind, &request, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": fmt.Sprintf("failed to record %s", kind),
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, transaction.ID, nil, transaction)
+	return c.Status(fiber.StatusCreated).JSON(transaction)
+}
+
+// @Summary Record payment
+// @Description Record money received from a student, optionally against an invoice. A reference can only be used once
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param payment body Finance.PaymentRequest true "Payment"
+// @Success 201 {object} Finance.LedgerTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/finance/payment [post]
+func (m *FinanceManager) RecordPayment(c *fiber.Ctx) error {
+	return m.recordMoneyMovement(c, Finance.Payment)
+}
+
+// @Summary Record refund
+// @Description Record money paid back to a student, optionally against an invoice
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param refund body Finance.PaymentRequest true "Refund"
+// @Success 201 {object} Finance.LedgerTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/finance/refund [post]
+func (m *FinanceManager) RecordRefund(c *fiber.Ctx) error {
+	return m.recordMoneyMovement(c, Finance.Refund)
+}
+
+// @Summary Record adjustment
+// @Description Correct a student balance, a negative amount reduce what the student owe
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param adjustment body Finance.AdjustmentRequest true "Adjustment"
+// @Success 201 {object} Finance.LedgerTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/finance/adjustment [post]
+func (m *FinanceManager) RecordAdjustment(c *fiber.Ctx) error {
+	var request Finance.AdjustmentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse adjustment",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate adjustment",
+			"error":   err.Error(),
+		})
+	}
+	student, err := m.fetchStudent(request.StudentID, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch student",
+			"error":   err.Error(),
+		})
+	}
+
+	counter := request.Account
+	if counter == "" {
+		counter = Finance.AdjustmentAccount
+	}
+	account := Finance.StudentAccount(student.ID)
+	amount := request.Amount
+	postings := []Finance.Posting{{Account: account, Debit: amount}, {Account: counter, Credit: amount}}
+	if amount < 0 {
+		amount = -amount
+		postings = []Finance.Posting{{Account: counter, Debit: amount}, {Account: account, Credit: amount}}
+	}
+	transaction, err := m.dbManager.PostTransaction(&Finance.LedgerTransaction{
+		Kind:              Finance.Adjustment,
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		Currency:          Finance.DefaultCurrency,
+		Amount:            request.Amount,
+		Note:              request.Note,
+		Postings:          postings,
+	}, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to record adjustment",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, transaction.ID, nil, transaction)
+	return c.Status(fiber.StatusCreated).JSON(transaction)
+}
+
+// @Summary Charge fine
+// @Description Charge a fine to a student
+// @Tags finance
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param fine body Finance.FineRequest true "Fine"
+// @Success 201 {object} Finance.LedgerTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/finance/fine [post]
+func (m *FinanceManager) ChargeFine(c *fiber.Ctx) error {
+	var request Finance.FineRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse fine",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate fine",
+			"error":   err.Error(),
+		})
+	}
+	student, err := m.fetchStudent(request.StudentID, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch student",
+			"error":   err.Error(),
+		})
+	}
+
+	lines := []Finance.FeeLine{{Kind: Finance.Fine, Description: request.Description, Amount: request.Amount}}
+	transaction, err := m.dbManager.PostTransaction(&Finance.LedgerTransaction{
+		Kind:              Finance.FineCharge,
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		Currency:          Finance.DefaultCurrency,
+		Amount:            request.Amount,
+		Note:              request.Description,
+		Postings:          chargePostings(student.ID, lines),
+	}, c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to charge fine",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, transaction.ID, nil, transaction)
+	return c.Status(fiber.StatusCreated).JSON(transaction)
+}
+
+// @Summary Get ledger
+// @Description Fetch filtered ledger transactions, newest first
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param invoice_id query string false "Invoice id"
+// @Param kind query string false "invoice, invoice_void, fine, payment, refund or adjustment"
+// @Success 200 {object} []Finance.LedgerTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/finance/ledger [get]
+func (m *FinanceManager) GetLedger(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Finance.LedgerFilter{
+		Page:      page,
+		Limit:     limit,
+		StudentID: c.Query("student_id", ""),
+		InvoiceID: c.Query("invoice_id", ""),
+		Kind:      Finance.TransactionKind(c.Query("kind", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	transactions, err := m.dbManager.FetchTransactions(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch ledger",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(transactions)
+}
+
+// @Summary Get student balance
+// @Description Fetch the balance of a student, positive when the student owe money
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Student id"
+// @Success 200 {object} Finance.StudentBalance
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/finance/student/{id}/balance [get]
+func (m *FinanceManager) GetStudentBalance(c *fiber.Ctx) error {
+	student, err := m.studentDB.FetchStudentByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(financeErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch student",
+			"error":   err.Error(),
+		})
+	}
+	balance, err := m.dbManager.AccountBalance(Finance.StudentAccount(student.ID), c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch balance",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(Finance.StudentBalance{
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		Balance:           balance,
+	})
+}
+
+// @Summary Get outstanding balances
+// @Description Fetch every student that owe money, biggest balance first
+// @Tags finance
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param collage_unique_name query string false "College unique name"
+// @Success 200 {object} []Finance.StudentBalance
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/finance/outstanding [get]
+func (m *FinanceManager) GetOutstanding(c *fiber.Ctx) error {
+	filter := Finance.OutstandingFilter{CollageUniqueName: c.Query("collage_unique_name", "")}
+	balances, err := m.dbManager.OutstandingBalances(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch outstanding balances",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(balances)
+}
@@ -0,0 +1,110 @@
+package Finance_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestFinanceFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx)
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("add room: expected 1 room; got %v %v", rooms, err)
+	}
+	student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: "R-1", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add student: %v", err)
+	}
+	if _, err = db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: student.ID, CollageUniqueName: "college-a", HostelID: hostel.ID, RoomID: rooms[0].ID, BedNumber: 1}, ctx); err != nil {
+		t.Fatalf("allocate student: %v", err)
+	}
+
+	structure := map[string]interface{}{
+		"hostel_id": hostel.ID, "currency": "INR", "effective_from": "2026-01-01T00:00:00Z",
+		"lines": []map[string]interface{}{
+			{"kind": "rent", "description": "Monthly rent", "amount": 500000},
+			{"kind": "mess", "description": "Mess charges", "amount": 300000},
+			{"kind": "deposit", "description": "Security deposit", "amount": 1000000},
+			{"kind": "fine", "description": "Late return", "amount": 10000},
+		},
+	}
+	structure["currency"] = "USD"
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/fee-structure", token, structure); status != http.StatusUnprocessableEntity {
+		t.Fatalf("add fee structure in another currency: expected status 422; got %d %v", status, body)
+	}
+	structure["currency"] = "INR"
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/fee-structure", token, structure); status != http.StatusCreated {
+		t.Fatalf("add fee structure: expected status 201; got %d %v", status, body)
+	}
+
+	generate := func(period string) map[string]interface{} {
+		status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/invoice/generate", token, map[string]interface{}{"hostel_id": hostel.ID, "period": period, "due_date": "2026-12-10T00:00:00Z"})
+		if status != http.StatusOK {
+			t.Fatalf("generate %s: expected status OK; got %d %v", period, status, body)
+		}
+		return body
+	}
+	body := generate("2026-08")
+	august := testutil.Path[map[string]interface{}](t, body, "created", 0)
+	if testutil.Path[float64](t, august, "total") != 1800000 {
+		t.Fatalf("first invoice: expected rent, mess and deposit; got %v", august)
+	}
+	if body = generate("2026-08"); len(testutil.Path[[]interface{}](t, body, "skipped")) != 1 {
+		t.Fatalf("generate twice: expected the student to be skipped; got %v", body)
+	}
+	september := testutil.Path[map[string]interface{}](t, generate("2026-09"), "created", 0)
+	if testutil.Path[float64](t, september, "total") != 800000 {
+		t.Fatalf("second invoice: expected no deposit; got %v", september)
+	}
+
+	payment := map[string]interface{}{"student_id": student.ID, "invoice_id": august["id"], "amount": 1000000, "reference": "UTR-1"}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/payment", token, payment); status != http.StatusCreated {
+		t.Fatalf("payment: expected status 201; got %d %v", status, body)
+	}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/finance/payment", token, payment); status != http.StatusConflict {
+		t.Fatalf("payment with used reference: expected status 409; got %d", status)
+	}
+	status, body := testutil.DoJSON(t, s, "GET", "/admin/finance/invoice/"+testutil.Path[string](t, august, "id"), token, nil)
+	if status != http.StatusOK || testutil.Path[float64](t, body, "paid") != 1000000 || testutil.Path[float64](t, body, "outstanding") != 800000 {
+		t.Fatalf("invoice summary: expected 800000 outstanding; got %d %v", status, body)
+	}
+
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/finance/invoice/"+testutil.Path[string](t, september, "id")+"/void", token, nil); status != http.StatusOK {
+		t.Fatalf("void: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/finance/invoice/"+testutil.Path[string](t, september, "id")+"/void", token, nil); status != http.StatusConflict {
+		t.Fatalf("void twice: expected status 409; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/finance/fine", token, map[string]interface{}{"student_id": student.ID, "description": "Late return", "amount": 10000}); status != http.StatusCreated {
+		t.Fatalf("fine: expected status 201; got %d %v", status, body)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/finance/student/"+student.ID+"/balance", token, nil)
+	if status != http.StatusOK || testutil.Path[float64](t, body, "balance") != 810000 {
+		t.Fatalf("balance: expected 810000; got %d %v", status, body)
+	}
+
+	outstanding, _ := db.FinanceDB.OutstandingBalances(&Finance.OutstandingFilter{CollageUniqueName: "college-a"}, ctx)
+	if len(outstanding) != 1 || outstanding[0].Balance != 810000 {
+		t.Fatalf("outstanding: expected one student owing 810000; got %v", outstanding)
+	}
+	if cash, _ := db.FinanceDB.AccountBalance(Finance.CashAccount, ctx); cash != 1000000 {
+		t.Fatalf("cash: expected 1000000; got %d", cash)
+	}
+}
@@ -0,0 +1,60 @@
+package Gate
+
+import (
+	"HostelApp/LogColor"
+	"HostelApp/internal/storageData/Gate"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const DefaultCurfew = "22:00"
+
+// Curfew is the daily time students on an outing must be back by, in server local time
+type Curfew struct {
+	Hour   int
+	Minute int
+}
+
+func ParseCurfew(value string) (Curfew, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return Curfew{}, fmt.Errorf("curfew must be HH:MM: %v", err)
+	}
+	return Curfew{Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
+}
+
+// CurfewFromEnv read HOSTEL_CURFEW, DefaultCurfew is used when it is not set
+func CurfewFromEnv() Curfew {
+	value := os.Getenv("HOSTEL_CURFEW")
+	if value == "" {
+		value = DefaultCurfew
+	}
+	curfew, err := ParseCurfew(value)
+	if err != nil {
+		log.Panic(LogColor.Red("!!Panic!! invalid HOSTEL_CURFEW " + err.Error()))
+	}
+	return curfew
+}
+
+// On is the curfew of the day of the given time
+func (c Curfew) On(day time.Time) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, c.Hour, c.Minute, 0, 0, day.Location())
+}
+
+// ReturnBy is the deadline of a student going out now. An outing end at the
+// expected return or at the curfew of the day, whichever come first, a leave
+// only at the expected return. Going out after curfew need an explicit approval
+// so the expected return is kept in that case
+func (c Curfew) ReturnBy(pass *Gate.GatePassData, checkedOutAt time.Time) time.Time {
+	if pass.Kind == Gate.Leave {
+		return pass.ExpectedReturnAt
+	}
+	curfew := c.On(checkedOutAt)
+	if checkedOutAt.Before(curfew) && curfew.Before(pass.ExpectedReturnAt) {
+		return curfew
+	}
+	return pass.ExpectedReturnAt
+}
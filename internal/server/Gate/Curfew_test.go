@@ -0,0 +1,39 @@
+package Gate
+
+import (
+	"HostelApp/internal/storageData/Gate"
+	"testing"
+	"time"
+)
+
+func TestCurfewReturnBy(t *testing.T) {
+	curfew, err := ParseCurfew("22:00")
+	if err != nil {
+		t.Fatalf("ParseCurfew failed. Err: %v", err)
+	}
+	if _, err = ParseCurfew("10pm"); err == nil {
+		t.Fatalf("expected an error for a curfew not in HH:MM")
+	}
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2026, 3, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name     string
+		kind     Gate.PassKind
+		out      time.Time
+		expected time.Time
+		want     time.Time
+	}{
+		{"outing back before curfew", Gate.Outing, at(18, 0), at(20, 0), at(20, 0)},
+		{"outing cut at curfew", Gate.Outing, at(18, 0), at(23, 30), at(22, 0)},
+		{"outing approved after curfew", Gate.Outing, at(22, 30), at(23, 30), at(23, 30)},
+		{"leave ignore curfew", Gate.Leave, at(18, 0), at(23, 30).AddDate(0, 0, 2), at(23, 30).AddDate(0, 0, 2)},
+	}
+	for _, c := range cases {
+		pass := &Gate.GatePassData{Kind: c.kind, ExpectedReturnAt: c.expected}
+		if got := curfew.ReturnBy(pass, c.out); !got.Equal(c.want) {
+			t.Fatalf("%s: expected %v; got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,487 @@
+package Gate
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/ValidatorSystem"
+	GateDB "HostelApp/internal/database/Gate"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Gate"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrStudentUnavailable = errors.New("student is deleted")
+	ErrOutingTooLong      = errors.New("an outing must end within 24 hours, request a leave instead")
+	ErrInvalidTransition  = errors.New("gate pass status transition is not allowed")
+)
+
+const maxOuting = 24 * time.Hour
+
+type GateManager struct {
+	dbManager GateDB.IGateDBService
+	studentDB StudentDB.IStudentDBService
+	curfew    Curfew
+}
+
+func NewGateManager(dbManager GateDB.IGateDBService, studentDB StudentDB.IStudentDBService, curfew Curfew) *GateManager {
+	instance := &GateManager{
+		dbManager: dbManager,
+		studentDB: studentDB,
+		curfew:    curfew,
+	}
+	return instance
+}
+
+func (m *GateManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/visitor", Method: internal.GET, Handler: m.GetVisitors, Permission: internal.ReadPermission},
+		{Path: "/admin/visitor", Method: internal.POST, Handler: m.CheckInVisitor, Permission: internal.WritePermission},
+		{Path: "/admin/visitor/:id", Method: internal.GET, Handler: m.GetVisitor, Permission: internal.ReadPermission},
+		{Path: "/admin/visitor/:id/checkout", Method: internal.POST, Handler: m.CheckOutVisitor, Permission: internal.WritePermission},
+		{Path: "/admin/gate-pass", Method: internal.GET, Handler: m.GetGatePasses, Permission: internal.ReadPermission},
+		{Path: "/admin/gate-pass", Method: internal.POST, Handler: m.RequestGatePass, Permission: internal.WritePermission},
+		{Path: "/admin/gate-pass/past-curfew", Method: internal.GET, Handler: m.GetPastCurfew, Permission: internal.ReadPermission},
+		{Path: "/admin/gate-pass/:id", Method: internal.GET, Handler: m.GetGatePass, Permission: internal.ReadPermission},
+		{Path: "/admin/gate-pass/:id/decision", Method: internal.POST, Handler: m.DecideGatePass, Permission: internal.WritePermission},
+		{Path: "/admin/gate-pass/:id/cancel", Method: internal.POST, Handler: m.CancelGatePass, Permission: internal.WritePermission},
+		{Path: "/admin/gate-pass/:id/out", Method: internal.POST, Handler: m.MarkOut, Permission: internal.WritePermission},
+		{Path: "/admin/gate-pass/:id/return", Method: internal.POST, Handler: m.MarkReturned, Permission: internal.WritePermission},
+	}
+}
+
+func gateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, GateDB.ErrVisitorNotFound), errors.Is(err, GateDB.ErrGatePassNotFound), errors.Is(err, StudentDB.ErrStudentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, GateDB.ErrVisitorCheckedOut), errors.Is(err, GateDB.ErrActiveGatePass),
+		errors.Is(err, GateDB.ErrGatePassStatusChanged), errors.Is(err, ErrInvalidTransition):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrStudentUnavailable), errors.Is(err, ErrOutingTooLong):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// actor is the admin user _id behind the request
+func actor(c *fiber.Ctx) string {
+	if claims := JWTManager.GetClaims(c); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
+func (m *GateManager) activeStudent(_id string, ctx context.Context) (*Student.StudentData, error) {
+	student, err := m.studentDB.FetchStudentByID(_id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if student.MarkAsDeleted {
+		return nil, ErrStudentUnavailable
+	}
+	return student, nil
+}
+
+// @Summary Check in visitor
+// @Description Log a visitor entering the hostel to meet a student
+// @Tags gate
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param visitor body Gate.VisitorRequest true "Visitor"
+// @Success 201 {object} Gate.VisitorData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/visitor [post]
+func (m *GateManager) CheckInVisitor(c *fiber.Ctx) error {
+	var request Gate.VisitorRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse visitor",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate visitor",
+			"error":   err.Error(),
+		})
+	}
+
+	student, err := m.activeStudent(request.StudentID, c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to check in visitor",
+			"error":   err.Error(),
+		})
+	}
+	visitor := &Gate.VisitorData{
+		VisitorName:       request.VisitorName,
+		VisitorPhone:      request.VisitorPhone,
+		IDProofType:       request.IDProofType,
+		IDProofNumber:     request.IDProofNumber,
+		Relation:          request.Relation,
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		Purpose:           request.Purpose,
+		LoggedBy:          actor(c),
+		CheckInAt:         time.Now(),
+	}
+	visitor, err = m.dbManager.AddVisitor(visitor, c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to check in visitor",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, visitor.ID, nil, visitor)
+	return c.Status(fiber.StatusCreated).JSON(visitor)
+}
+
+// @Summary Get visitor list
+// @Description Fetch the visitor log, newest first
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student visited"
+// @Param inside query bool false "Only visitors not checked out"
+// @Success 200 {object} []Gate.VisitorData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/visitor [get]
+func (m *GateManager) GetVisitors(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Gate.VisitorFilter{
+		Page:      page,
+		Limit:     limit,
+		StudentID: c.Query("student_id", ""),
+		Inside:    c.QueryBool("inside", false),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	visitors, err := m.dbManager.FetchVisitors(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch visitors",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(visitors)
+}
+
+// @Summary Get visitor
+// @Description Fetch one entry of the visitor log
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Visitor id"
+// @Success 200 {object} Gate.VisitorData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/visitor/{id} [get]
+func (m *GateManager) GetVisitor(c *fiber.Ctx) error {
+	visitor, err := m.dbManager.FetchVisitorByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch visitor",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(visitor)
+}
+
+// @Summary Check out visitor
+// @Description Log a visitor leaving the hostel
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Visitor id"
+// @Success 200 {object} Gate.VisitorData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/visitor/{id}/checkout [post]
+func (m *GateManager) CheckOutVisitor(c *fiber.Ctx) error {
+	before, _ := m.dbManager.FetchVisitorByID(c.Params("id"), c.Context())
+	visitor, err := m.dbManager.CheckOutVisitor(c.Params("id"), time.Now(), c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to check out visitor",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, visitor.ID, before, visitor)
+	return c.JSON(visitor)
+}
+
+// @Summary Request gate pass
+// @Description Request an outing or a leave for a student, it waits for a warden decision
+// @Tags gate
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param pass body Gate.GatePassRequest true "Outing or leave"
+// @Success 201 {object} Gate.GatePassData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/gate-pass [post]
+func (m *GateManager) RequestGatePass(c *fiber.Ctx) error {
+	var request Gate.GatePassRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse gate pass",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate gate pass",
+			"error":   err.Error(),
+		})
+	}
+
+	pass, err := m.requestGatePass(&request, actor(c), c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to request gate pass",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, pass.ID, nil, pass)
+	return c.Status(fiber.StatusCreated).JSON(pass)
+}
+
+func (m *GateManager) requestGatePass(request *Gate.GatePassRequest, by string, ctx context.Context) (*Gate.GatePassData, error) {
+	if request.Kind == Gate.Outing && request.ExpectedReturnAt.Sub(request.LeaveAt) > maxOuting {
+		return nil, ErrOutingTooLong
+	}
+	student, err := m.activeStudent(request.StudentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	pass := &Gate.GatePassData{
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		Kind:              request.Kind,
+		Reason:            request.Reason,
+		Destination:       request.Destination,
+		LeaveAt:           request.LeaveAt,
+		ExpectedReturnAt:  request.ExpectedReturnAt,
+		RequestedBy:       by,
+	}
+	return m.dbManager.CreateGatePass(pass, ctx)
+}
+
+// @Summary Get gate pass list
+// @Description Fetch filtered list of gate passes, newest first
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param collage_unique_name query string false "College unique name"
+// @Param kind query string false "outing or leave"
+// @Param status query string false "pending, approved, rejected, cancelled, out or returned"
+// @Success 200 {object} []Gate.GatePassData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/gate-pass [get]
+func (m *GateManager) GetGatePasses(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Gate.GatePassFilter{
+		Page:              page,
+		Limit:             limit,
+		StudentID:         c.Query("student_id", ""),
+		CollageUniqueName: c.Query("collage_unique_name", ""),
+		Kind:              Gate.PassKind(c.Query("kind", "")),
+		Status:            Gate.PassStatus(c.Query("status", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	passes, err := m.dbManager.FetchGatePasses(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch gate passes",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(passes)
+}
+
+// @Summary Get students out past curfew
+// @Description Fetch the passes of students still out after their return deadline, most late first
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param collage_unique_name query string false "College unique name"
+// @Success 200 {object} []Gate.GatePassData
+// @Router /admin/gate-pass/past-curfew [get]
+func (m *GateManager) GetPastCurfew(c *fiber.Ctx) error {
+	passes, err := m.dbManager.FetchPastCurfew(time.Now(), c.Query("collage_unique_name", ""), c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch students past curfew",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(passes)
+}
+
+// @Summary Get gate pass
+// @Description Fetch one gate pass
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Gate pass id"
+// @Success 200 {object} Gate.GatePassData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/gate-pass/{id} [get]
+func (m *GateManager) GetGatePass(c *fiber.Ctx) error {
+	pass, err := m.dbManager.FetchGatePassByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch gate pass",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(pass)
+}
+
+// transition move a pass that must currently be in one of the from statuses,
+// the pass is returned as it was before and after
+func (m *GateManager) transition(_id string, from []Gate.PassStatus, change *Gate.GatePassChange, ctx context.Context) (*Gate.GatePassData, *Gate.GatePassData, error) {
+	pass, err := m.dbManager.FetchGatePassByID(_id, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	allowed := false
+	for _, status := range from {
+		allowed = allowed || pass.Status == status
+	}
+	if !allowed {
+		return nil, nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, pass.Status, change.To)
+	}
+	now := time.Now()
+	switch change.To {
+	case Gate.Approved, Gate.Rejected:
+		change.DecidedAt = &now
+	case Gate.Out:
+		returnBy := m.curfew.ReturnBy(pass, now)
+		change.CheckedOutAt = &now
+		change.ReturnBy = &returnBy
+	case Gate.Returned:
+		change.ReturnedAt = &now
+		change.ReturnedLate = pass.ReturnBy != nil && now.After(*pass.ReturnBy)
+	}
+	updated, err := m.dbManager.TransitionGatePass(pass.ID, pass.Status, change, ctx)
+	return pass, updated, err
+}
+
+func (m *GateManager) respondTransition(c *fiber.Ctx, from []Gate.PassStatus, change *Gate.GatePassChange) error {
+	before, pass, err := m.transition(c.Params("id"), from, change, c.Context())
+	if err != nil {
+		return c.Status(gateErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update gate pass",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, pass.ID, before, pass)
+	return c.JSON(pass)
+}
+
+// @Summary Decide gate pass
+// @Description Approve or reject a pending gate pass, the deciding warden is recorded
+// @Tags gate
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Gate pass id"
+// @Param decision body Gate.DecisionRequest true "Decision"
+// @Success 200 {object} Gate.GatePassData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/gate-pass/{id}/decision [post]
+func (m *GateManager) DecideGatePass(c *fiber.Ctx) error {
+	var request Gate.DecisionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse decision",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate decision",
+			"error":   err.Error(),
+		})
+	}
+	change := &Gate.GatePassChange{To: Gate.Rejected, DecidedBy: actor(c), DecisionNote: request.Note}
+	if request.Approve {
+		change.To = Gate.Approved
+	}
+	return m.respondTransition(c, []Gate.PassStatus{Gate.Pending}, change)
+}
+
+// @Summary Cancel gate pass
+// @Description Cancel a gate pass before the student goes out
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Gate pass id"
+// @Success 200 {object} Gate.GatePassData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/gate-pass/{id}/cancel [post]
+func (m *GateManager) CancelGatePass(c *fiber.Ctx) error {
+	return m.respondTransition(c, []Gate.PassStatus{Gate.Pending, Gate.Approved}, &Gate.GatePassChange{To: Gate.Cancelled})
+}
+
+// @Summary Mark student out
+// @Description Record the student leaving on an approved pass, the curfew deadline is fixed at this moment
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Gate pass id"
+// @Success 200 {object} Gate.GatePassData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/gate-pass/{id}/out [post]
+func (m *GateManager) MarkOut(c *fiber.Ctx) error {
+	return m.respondTransition(c, []Gate.PassStatus{Gate.Approved}, &Gate.GatePassChange{To: Gate.Out})
+}
+
+// @Summary Mark student returned
+// @Description Record the student coming back, a return after the deadline is flagged late
+// @Tags gate
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Gate pass id"
+// @Success 200 {object} Gate.GatePassData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/gate-pass/{id}/return [post]
+func (m *GateManager) MarkReturned(c *fiber.Ctx) error {
+	return m.respondTransition(c, []Gate.PassStatus{Gate.Out}, &Gate.GatePassChange{To: Gate.Returned})
+}
@@ -0,0 +1,106 @@
+package Gate_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Gate"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGateFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: "R-1", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add student: %v", err)
+	}
+	other, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "O", RollNumber: "R-2", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add other student: %v", err)
+	}
+
+	visitor := map[string]interface{}{"visitor_name": "Parent", "visitor_phone": "+919876543210", "id_proof_type": "aadhaar",
+		"id_proof_number": "1234-5678", "relation": "mother", "student_id": student.ID, "purpose": "Weekend visit"}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/visitor", token, visitor)
+	if status != http.StatusCreated || body["collage_unique_name"] != "college-a" {
+		t.Fatalf("check in: expected status 201; got %d %v", status, body)
+	}
+	visitorPath := "/admin/visitor/" + testutil.Path[string](t, body, "id") + "/checkout"
+	if inside, _ := db.GateDB.FetchVisitors(&Gate.VisitorFilter{Page: 1, Limit: 10, Inside: true}, ctx); len(inside) != 1 {
+		t.Fatalf("inside: expected one visitor; got %d", len(inside))
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", visitorPath, token, nil); status != http.StatusOK {
+		t.Fatalf("check out: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", visitorPath, token, nil); status != http.StatusConflict {
+		t.Fatalf("check out twice: expected status 409; got %d", status)
+	}
+
+	now := time.Now()
+	outing := map[string]interface{}{"student_id": student.ID, "kind": "outing", "reason": "Shopping", "destination": "Market",
+		"leave_at": now.Add(-3 * time.Hour), "expected_return_at": now.Add(-time.Hour)}
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/gate-pass", token, outing)
+	if status != http.StatusCreated || body["status"] != "pending" {
+		t.Fatalf("request outing: expected pending pass; got %d %v", status, body)
+	}
+	outingPath := "/admin/gate-pass/" + testutil.Path[string](t, body, "id")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/gate-pass", token, outing); status != http.StatusConflict {
+		t.Fatalf("second active pass: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", outingPath+"/out", token, nil); status != http.StatusConflict {
+		t.Fatalf("out before approval: expected status 409; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", outingPath+"/decision", token, map[string]interface{}{"approve": true}); status != http.StatusOK || body["status"] != "approved" || body["decided_by"] == nil {
+		t.Fatalf("approve: expected approved pass with the warden; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", outingPath+"/out", token, nil); status != http.StatusOK {
+		t.Fatalf("out: expected status OK; got %d", status)
+	}
+	_, body = testutil.DoJSON(t, s, "GET", "/admin/audit?entity=gate-pass&limit=1", token, nil)
+	transition := ""
+	for _, change := range testutil.Path[[]interface{}](t, body, "items", 0, "changes") {
+		if field := testutil.Path[map[string]interface{}](t, change); field["field"] == "status" {
+			transition = fmt.Sprint(field["before"], " ", field["after"])
+		}
+	}
+	if transition != "approved out" {
+		t.Fatalf("gate pass audit: expected the approved to out change; got %v", body)
+	}
+
+	leave := map[string]interface{}{"student_id": other.ID, "kind": "leave", "reason": "Festival", "destination": "Home",
+		"leave_at": now.Add(-time.Hour), "expected_return_at": now.Add(72 * time.Hour)}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/gate-pass", token, map[string]interface{}{"student_id": other.ID, "kind": "outing", "reason": "Festival",
+		"destination": "Home", "leave_at": now, "expected_return_at": now.Add(72 * time.Hour)}); status != http.StatusUnprocessableEntity {
+		t.Fatalf("long outing: expected status 422; got %d", status)
+	}
+	_, body = testutil.DoJSON(t, s, "POST", "/admin/gate-pass", token, leave)
+	leavePath := "/admin/gate-pass/" + testutil.Path[string](t, body, "id")
+	testutil.DoJSON(t, s, "POST", leavePath+"/decision", token, map[string]interface{}{"approve": true})
+	if status, _ = testutil.DoJSON(t, s, "POST", leavePath+"/out", token, nil); status != http.StatusOK {
+		t.Fatalf("leave out: expected status OK; got %d", status)
+	}
+
+	late, _ := db.GateDB.FetchPastCurfew(time.Now(), "college-a", ctx)
+	if len(late) != 1 || late[0].StudentID != student.ID {
+		t.Fatalf("past curfew: expected only the outing; got %v", late)
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", outingPath+"/return", token, nil); status != http.StatusOK || body["returned_late"] != true {
+		t.Fatalf("return: expected late return; got %d %v", status, body)
+	}
+	if late, _ = db.GateDB.FetchPastCurfew(time.Now(), "", ctx); len(late) != 0 {
+		t.Fatalf("past curfew after return: expected nobody; got %v", late)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/gate-pass", token, outing); status != http.StatusCreated {
+		t.Fatalf("new pass after return: expected status 201; got %d", status)
+	}
+}
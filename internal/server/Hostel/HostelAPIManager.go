@@ -0,0 +1,540 @@
+package Hostel
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	AllocationDB "HostelApp/internal/database/Allocation"
+	HostelDB "HostelApp/internal/database/Hostel"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+var (
+	ErrCapacityBelowBeds = errors.New("capacity does not cover the beds of the active allocations")
+	ErrGenderOccupied    = errors.New("gender of a room can not change while students live in it")
+	ErrRoomOccupied      = errors.New("room has active allocations")
+	ErrHostelOccupied    = errors.New("hostel has active allocations")
+)
+
+type HostelManager struct {
+	dbManager    HostelDB.IHostelDBService
+	collegeDB    AdminDB.ICollegeDBService
+	allocationDB AllocationDB.IAllocationDBService
+}
+
+func NewHostelManager(dbManager HostelDB.IHostelDBService, collegeDB AdminDB.ICollegeDBService, allocationDB AllocationDB.IAllocationDBService) *HostelManager {
+	instance := &HostelManager{
+		dbManager:    dbManager,
+		collegeDB:    collegeDB,
+		allocationDB: allocationDB,
+	}
+	return instance
+}
+
+func (m *HostelManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/hostel", Method: internal.GET, Handler: m.GetHostels, Permission: internal.ReadPermission},
+		{Path: "/admin/hostel", Method: internal.POST, Handler: m.AddHostel, Permission: internal.WritePermission},
+		{Path: "/admin/hostel/:id", Method: internal.GET, Handler: m.GetHostel, Permission: internal.ReadPermission},
+		{Path: "/admin/hostel/:id", Method: internal.DELETE, Handler: m.DeleteHostel, Permission: internal.WritePermission},
+		{Path: "/admin/hostel/:id/occupancy", Method: internal.GET, Handler: m.GetOccupancy, Permission: internal.ReadPermission},
+		{Path: "/admin/hostel/:id/room", Method: internal.GET, Handler: m.GetRooms, Permission: internal.ReadPermission},
+		{Path: "/admin/hostel/:id/room", Method: internal.POST, Handler: m.AddRooms, Permission: internal.WritePermission},
+		{Path: "/admin/room/:room_id", Method: internal.GET, Handler: m.GetRoom, Permission: internal.ReadPermission},
+		{Path: "/admin/room/:room_id", Method: internal.PATCH, Handler: m.UpdateRoom, Permission: internal.WritePermission},
+		{Path: "/admin/room/:room_id", Method: internal.DELETE, Handler: m.DeleteRoom, Permission: internal.WritePermission},
+	}
+}
+
+func hostelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, HostelDB.ErrHostelNotFound), errors.Is(err, HostelDB.ErrRoomNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, HostelDB.ErrRoomDuplicate), errors.Is(err, ErrCapacityBelowBeds), errors.Is(err, ErrGenderOccupied),
+		errors.Is(err, ErrRoomOccupied), errors.Is(err, ErrHostelOccupied):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// capacityMatchesType check that a room capacity is consistent with its type
+func capacityMatchesType(roomType Hostel.RoomType, capacity int) bool {
+	switch roomType {
+	case Hostel.Single:
+		return capacity == 1
+	case Hostel.Double:
+		return capacity == 2
+	case Hostel.Dorm:
+		return capacity >= 3
+	default:
+		return false
+	}
+}
+
+// genderAllowed check that a room restriction fit inside the hostel restriction
+func genderAllowed(hostel Hostel.GenderRestriction, room Hostel.GenderRestriction) bool {
+	if hostel == Hostel.Mixed {
+		return true
+	}
+	return room == hostel
+}
+
+// validateRoom check a room against the layout and the restriction of its hostel
+func validateRoom(hostel *Hostel.HostelData, room *Hostel.RoomData) error {
+	if err := ValidatorSystem.GetValidator().IsValid(room); err != nil {
+		return err
+	}
+	if !capacityMatchesType(room.RoomType, room.Capacity) {
+		return fmt.Errorf("room %s: capacity %d does not match room type %s", room.RoomNumber, room.Capacity, room.RoomType)
+	}
+	if !genderAllowed(hostel.Gender, room.Gender) {
+		return fmt.Errorf("room %s: gender %s not allowed in a %s hostel", room.RoomNumber, room.Gender, hostel.Gender)
+	}
+	for _, block := range hostel.Blocks {
+		if block.Name != room.Block {
+			continue
+		}
+		if room.Floor >= block.Floors {
+			return fmt.Errorf("room %s: block %s only has floors 0 to %d", room.RoomNumber, block.Name, block.Floors-1)
+		}
+		return nil
+	}
+	return fmt.Errorf("room %s: block %s does not exist in hostel", room.RoomNumber, room.Block)
+}
+
+// occupiedConflict check a room update against the students living in the room
+func occupiedConflict(room *Hostel.RoomData, update *Hostel.RoomUpdate, active []Allocation.AllocationData) error {
+	if len(active) == 0 {
+		return nil
+	}
+	if update.Gender != nil && *update.Gender != room.Gender {
+		return ErrGenderOccupied
+	}
+	if update.Capacity != nil {
+		for _, allocation := range active {
+			if allocation.BedNumber > *update.Capacity {
+				return fmt.Errorf("%w: bed %d is taken", ErrCapacityBelowBeds, allocation.BedNumber)
+			}
+		}
+	}
+	return nil
+}
+
+// @Summary Add hostel
+// @Description Add a hostel with its blocks to an existing college
+// @Tags hostel
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel body Hostel.HostelData true "Hostel to be added"
+// @Success 201 {object} Hostel.HostelData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/hostel [post]
+func (m *HostelManager) AddHostel(c *fiber.Ctx) error {
+	var hostel Hostel.HostelData
+	if err := c.BodyParser(&hostel); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse hostel",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&hostel); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate hostel",
+			"error":   err.Error(),
+		})
+	}
+	college, err := m.collegeDB.FetchCollegeByName(hostel.CollageUniqueName, c.Context())
+	if err != nil || college.MarkAsDeleted {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate hostel college",
+			"error":   fmt.Sprintf("college '%s' not found", hostel.CollageUniqueName),
+		})
+	}
+
+	added, err := m.dbManager.AddHostel(&hostel, c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add hostel in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, added.ID, nil, added)
+	return c.Status(fiber.StatusCreated).JSON(added)
+}
+
+// @Summary Get hostel list
+// @Description Fetch filtered list of hostels
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param collage_unique_name query string false "College unique name"
+// @Param mark_as_deleted query boolean false "Include deleted items"
+// @Success 200 {object} []Hostel.HostelData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/hostel [get]
+func (m *HostelManager) GetHostels(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	markAsDeleted, err := strconv.ParseBool(c.Query("mark_as_deleted", "false"))
+	if err != nil {
+		markAsDeleted = false
+	}
+	filter := Hostel.HostelFilter{
+		Page:              page,
+		Limit:             limit,
+		CollageUniqueName: c.Query("collage_unique_name", ""),
+		MarkAsDeleted:     markAsDeleted,
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	hostels, err := m.dbManager.FetchHostels(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch hostels",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(hostels)
+}
+
+// @Summary Get hostel
+// @Description Fetch a hostel by id
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Hostel id"
+// @Success 200 {object} Hostel.HostelData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/hostel/{id} [get]
+func (m *HostelManager) GetHostel(c *fiber.Ctx) error {
+	hostel, err := m.dbManager.FetchHostelByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch hostel",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(hostel)
+}
+
+// @Summary Delete hostel
+// @Description Soft delete a hostel, a hostel where students live can not be deleted
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Hostel id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/hostel/{id} [delete]
+func (m *HostelManager) DeleteHostel(c *fiber.Ctx) error {
+	active, err := m.allocationDB.FetchActiveAllocations(c.Params("id"), "", c.Context())
+	if err == nil && len(active) > 0 {
+		err = fmt.Errorf("%w: %d students", ErrHostelOccupied, len(active))
+	}
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete hostel",
+			"error":   err.Error(),
+		})
+	}
+	if err := m.dbManager.DeleteHostel(c.Params("id"), c.Context()); err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete hostel in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), fiber.Map{"mark_as_deleted": false}, fiber.Map{"mark_as_deleted": true})
+	return c.JSON(fiber.Map{
+		"message": "hostel deleted",
+	})
+}
+
+// @Summary Hostel occupancy summary
+// @Description Rooms, capacity, occupied and free beds of a hostel grouped by room type, gender and maintenance status
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Hostel id"
+// @Success 200 {object} Hostel.OccupancySummary
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/hostel/{id}/occupancy [get]
+func (m *HostelManager) GetOccupancy(c *fiber.Ctx) error {
+	active, err := m.allocationDB.FetchActiveAllocations(c.Params("id"), "", c.Context())
+	var summary *Hostel.OccupancySummary
+	if err == nil {
+		occupied := make(map[string]int, len(active))
+		for _, allocation := range active {
+			occupied[allocation.RoomID]++
+		}
+		summary, err = m.dbManager.OccupancySummary(c.Params("id"), occupied, c.Context())
+	}
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to compute occupancy",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(summary)
+}
+
+// @Summary Add rooms
+// @Description Add a list of rooms to a hostel, rooms must fit the hostel blocks and gender restriction
+// @Tags hostel
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Hostel id"
+// @Param rooms body []Hostel.RoomData true "Rooms to be added"
+// @Success 201 {object} []Hostel.RoomData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/hostel/{id}/room [post]
+func (m *HostelManager) AddRooms(c *fiber.Ctx) error {
+	hostel, err := m.dbManager.FetchHostelByID(c.Params("id"), c.Context())
+	if err != nil || hostel.MarkAsDeleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": "failed to fetch hostel",
+			"error":   HostelDB.ErrHostelNotFound.Error(),
+		})
+	}
+	var rooms []Hostel.RoomData
+	if err := c.BodyParser(&rooms); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse rooms",
+			"error":   err.Error(),
+		})
+	}
+	for i := range rooms {
+		if err := validateRoom(hostel, &rooms[i]); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "failed to validate rooms",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	added, err := m.dbManager.AddRooms(hostel.ID, &rooms, c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add rooms in database",
+			"error":   err.Error(),
+			"added":   added,
+		})
+	}
+	Audit.Record(c, hostel.ID, nil, added)
+	return c.Status(fiber.StatusCreated).JSON(added)
+}
+
+// @Summary Get room list
+// @Description Fetch filtered list of rooms of a hostel
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Hostel id"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param block query string false "Block name"
+// @Param floor query int false "Floor number"
+// @Param room_type query string false "single, double or dorm"
+// @Param gender query string false "male, female or any"
+// @Param maintenance_status query string false "available, under_maintenance or out_of_service"
+// @Param mark_as_deleted query boolean false "Include deleted items"
+// @Success 200 {object} []Hostel.RoomData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/hostel/{id}/room [get]
+func (m *HostelManager) GetRooms(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	markAsDeleted, err := strconv.ParseBool(c.Query("mark_as_deleted", "false"))
+	if err != nil {
+		markAsDeleted = false
+	}
+	filter := Hostel.RoomFilter{
+		Page:              page,
+		Limit:             limit,
+		HostelID:          c.Params("id"),
+		Block:             c.Query("block", ""),
+		RoomType:          Hostel.RoomType(c.Query("room_type", "")),
+		Gender:            Hostel.GenderRestriction(c.Query("gender", "")),
+		MaintenanceStatus: Hostel.MaintenanceStatus(c.Query("maintenance_status", "")),
+		MarkAsDeleted:     markAsDeleted,
+	}
+	if floorStr := c.Query("floor", ""); floorStr != "" {
+		floor, err := strconv.Atoi(floorStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "failed to validate filter",
+				"error":   err.Error(),
+			})
+		}
+		filter.Floor = &floor
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	rooms, err := m.dbManager.FetchRooms(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch rooms",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(rooms)
+}
+
+// @Summary Get room
+// @Description Fetch a room by id
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param room_id path string true "Room id"
+// @Success 200 {object} Hostel.RoomData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/room/{room_id} [get]
+func (m *HostelManager) GetRoom(c *fiber.Ctx) error {
+	room, err := m.dbManager.FetchRoomByID(c.Params("room_id"), c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch room",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(room)
+}
+
+// @Summary Update room
+// @Description Update type, capacity, gender, amenities or maintenance status of a room
+// @Tags hostel
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param room_id path string true "Room id"
+// @Param room body Hostel.RoomUpdate true "Fields to update"
+// @Success 200 {object} Hostel.RoomData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/room/{room_id} [patch]
+func (m *HostelManager) UpdateRoom(c *fiber.Ctx) error {
+	var update Hostel.RoomUpdate
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse room",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate room",
+			"error":   err.Error(),
+		})
+	}
+
+	room, err := m.dbManager.FetchRoomByID(c.Params("room_id"), c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch room",
+			"error":   err.Error(),
+		})
+	}
+	hostel, err := m.dbManager.FetchHostelByID(room.HostelID, c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch hostel",
+			"error":   err.Error(),
+		})
+	}
+	// validate the room as it will look after the update
+	merged := *room
+	if update.RoomType != nil {
+		merged.RoomType = *update.RoomType
+	}
+	if update.Capacity != nil {
+		merged.Capacity = *update.Capacity
+	}
+	if update.Gender != nil {
+		merged.Gender = *update.Gender
+	}
+	if err := validateRoom(hostel, &merged); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate room",
+			"error":   err.Error(),
+		})
+	}
+	active, err := m.allocationDB.FetchActiveAllocations("", room.ID, c.Context())
+	if err == nil {
+		err = occupiedConflict(room, &update, active)
+	}
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update room",
+			"error":   err.Error(),
+		})
+	}
+
+	updated, err := m.dbManager.UpdateRoom(room.ID, &update, c.Context())
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update room in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, room.ID, room, updated)
+	return c.JSON(updated)
+}
+
+// @Summary Delete room
+// @Description Soft delete a room, a room where students live can not be deleted
+// @Tags hostel
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param room_id path string true "Room id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/room/{room_id} [delete]
+func (m *HostelManager) DeleteRoom(c *fiber.Ctx) error {
+	active, err := m.allocationDB.FetchActiveAllocations("", c.Params("room_id"), c.Context())
+	if err == nil && len(active) > 0 {
+		err = fmt.Errorf("%w: %d students", ErrRoomOccupied, len(active))
+	}
+	if err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete room",
+			"error":   err.Error(),
+		})
+	}
+	if err := m.dbManager.DeleteRoom(c.Params("room_id"), c.Context()); err != nil {
+		return c.Status(hostelErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete room in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("room_id"), fiber.Map{"mark_as_deleted": false}, fiber.Map{"mark_as_deleted": true})
+	return c.JSON(fiber.Map{
+		"message": "room deleted",
+	})
+}
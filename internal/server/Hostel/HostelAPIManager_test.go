@@ -0,0 +1,149 @@
+package Hostel_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/testutil"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHostelFlowInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	hostel := map[string]interface{}{
+		"name":                "North Hostel",
+		"collage_unique_name": "college-a",
+		"gender":              "female",
+		"address":             "North campus",
+		"blocks":              []map[string]interface{}{{"name": "A", "floors": 2}},
+	}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/hostel", token, hostel)
+	if status != http.StatusCreated {
+		t.Fatalf("add hostel: expected status 201; got %d %v", status, body)
+	}
+	hostelID := testutil.Path[string](t, body, "id")
+
+	room := func(number string, floor int, roomType string, capacity int, gender string) map[string]interface{} {
+		return map[string]interface{}{
+			"block": "A", "floor": floor, "room_number": number, "room_type": roomType,
+			"capacity": capacity, "gender": gender, "amenities": []string{"fan"}, "maintenance_status": "available",
+		}
+	}
+	invalid := [][]map[string]interface{}{
+		{room("A-001", 0, "double", 3, "female")}, // capacity does not match type
+		{room("A-001", 0, "double", 2, "male")},   // male room in a female hostel
+		{room("A-301", 3, "double", 2, "female")}, // floor outside the block
+	}
+	for _, rooms := range invalid {
+		if status, _ = testutil.DoJSON(t, s, "POST", "/admin/hostel/"+hostelID+"/room", token, rooms); status != http.StatusBadRequest {
+			t.Fatalf("add invalid room %v: expected status 400; got %d", rooms, status)
+		}
+	}
+	rooms := []map[string]interface{}{room("A-001", 0, "double", 2, "female"), room("A-101", 1, "dorm", 6, "female")}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/hostel/"+hostelID+"/room", token, rooms); status != http.StatusCreated {
+		t.Fatalf("add rooms: expected status 201; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/hostel/"+hostelID+"/room", token, rooms[:1]); status != http.StatusConflict {
+		t.Fatalf("add duplicate room: expected status 409; got %d", status)
+	}
+
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/hostel/"+hostelID+"/occupancy", token, nil)
+	if status != http.StatusOK || testutil.Path[float64](t, body, "total_capacity") != 8 || testutil.Path[float64](t, body, "usable_capacity") != 8 {
+		t.Fatalf("occupancy: expected capacity 8; got %d %v", status, body)
+	}
+}
+
+func TestHostelOccupiedInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	hostel := map[string]interface{}{
+		"name": "North Hostel", "collage_unique_name": "college-a", "gender": "mixed", "address": "North campus",
+		"blocks": []map[string]interface{}{{"name": "A", "floors": 1}},
+	}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/hostel", token, hostel)
+	if status != http.StatusCreated {
+		t.Fatalf("add hostel: expected status 201; got %d %v", status, body)
+	}
+	hostelID := testutil.Path[string](t, body, "id")
+	rooms := []map[string]interface{}{
+		{"block": "A", "floor": 0, "room_number": "A-001", "room_type": "dorm", "capacity": 4, "gender": "female", "maintenance_status": "available"},
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/hostel/"+hostelID+"/room", token, rooms); status != http.StatusCreated {
+		t.Fatalf("add rooms: expected status 201; got %d %v", status, body)
+	}
+	added, err := db.HostelDB.FetchHostelRooms(hostelID, context.Background())
+	if err != nil || len(added) != 1 {
+		t.Fatalf("expected the room stored; got %+v. Err: %v", added, err)
+	}
+	roomID := added[0].ID
+	resident, err := db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: "s1", CollageUniqueName: "college-a",
+		HostelID: hostelID, RoomID: roomID, BedNumber: 4}, context.Background())
+	if err != nil {
+		t.Fatalf("CreateAllocation failed. Err: %v", err)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/hostel/"+hostelID+"/occupancy", token, nil)
+	if status != http.StatusOK || testutil.Path[float64](t, body, "occupied_beds") != 1 || testutil.Path[float64](t, body, "free_beds") != 3 ||
+		testutil.Path[float64](t, body, "by_room_type", "dorm", "free") != 3 {
+		t.Fatalf("occupancy: expected 1 occupied and 3 free beds; got %d %v", status, body)
+	}
+
+	conflicts := []map[string]interface{}{
+		{"capacity": 3},    // bed 4 is taken
+		{"gender": "male"}, // a student live in the room
+	}
+	for _, update := range conflicts {
+		if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/room/"+roomID, token, update); status != http.StatusConflict {
+			t.Fatalf("update occupied room %v: expected status 409; got %d %v", update, status, body)
+		}
+	}
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/room/"+roomID, token, map[string]interface{}{"gender": "female"}); status != http.StatusOK {
+		t.Fatalf("update occupied room with its gender: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/room/"+roomID, token, nil); status != http.StatusConflict {
+		t.Fatalf("delete occupied room: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/hostel/"+hostelID, token, nil); status != http.StatusConflict {
+		t.Fatalf("delete occupied hostel: expected status 409; got %d", status)
+	}
+
+	if _, err = db.AllocationDB.EndAllocation(resident.ID, Allocation.Vacated, "", context.Background()); err != nil {
+		t.Fatalf("EndAllocation failed. Err: %v", err)
+	}
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/room/"+roomID, token, map[string]interface{}{"gender": "male"}); status != http.StatusOK {
+		t.Fatalf("update empty room: expected status OK; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/room/"+roomID, token, nil); status != http.StatusOK {
+		t.Fatalf("delete empty room: expected status OK; got %d", status)
+	}
+	// latest first, the delete and the gender change of the room are audited
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/audit?entity=room&limit=2", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("room audit: expected status OK; got %d %v", status, body)
+	}
+	deleted := testutil.Path[[]interface{}](t, body, "items", 0, "changes")
+	if fmt.Sprint(deleted) != "[map[after:true before:false field:mark_as_deleted]]" {
+		t.Fatalf("room audit: expected the delete; got %v", body)
+	}
+	gender := ""
+	for _, change := range testutil.Path[[]interface{}](t, body, "items", 1, "changes") {
+		if field := testutil.Path[map[string]interface{}](t, change); field["field"] == "gender" {
+			gender = fmt.Sprint(field["before"], " ", field["after"])
+		}
+	}
+	if testutil.Path[string](t, body, "items", 1, "entity_id") != roomID || gender != "female male" {
+		t.Fatalf("room audit: expected the gender change of %s; got %v", roomID, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/hostel/"+hostelID, token, nil); status != http.StatusOK {
+		t.Fatalf("delete empty hostel: expected status OK; got %d", status)
+	}
+}
@@ -0,0 +1,36 @@
+package Mess
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/storageData/Mess"
+	"time"
+)
+
+const DefaultOptOutCutoff = "6h"
+
+// mealStarts is the time each meal start being served, in server local time
+var mealStarts = map[Mess.Meal]struct{ Hour, Minute int }{
+	Mess.Breakfast: {7, 30},
+	Mess.Lunch:     {12, 30},
+	Mess.Snacks:    {17, 0},
+	Mess.Dinner:    {20, 0},
+}
+
+// MealStart is the time the meal start on the given day
+func MealStart(day time.Time, meal Mess.Meal) time.Time {
+	start := mealStarts[meal]
+	year, month, date := day.Date()
+	return time.Date(year, month, date, start.Hour, start.Minute, 0, 0, day.Location())
+}
+
+// OptOutDeadline is the last moment a student can opt out of, or back into, the
+// meal so the kitchen can plan for the change
+func OptOutDeadline(day time.Time, meal Mess.Meal, cutoff time.Duration) time.Time {
+	return MealStart(day, meal).Add(-cutoff)
+}
+
+// CutoffFromEnv read MESS_OPT_OUT_CUTOFF as a duration before the meal start,
+// DefaultOptOutCutoff is used when it is not set
+func CutoffFromEnv() time.Duration {
+	return internal.DurationFromEnv("MESS_OPT_OUT_CUTOFF", DefaultOptOutCutoff)
+}
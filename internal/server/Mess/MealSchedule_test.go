@@ -0,0 +1,23 @@
+package Mess
+
+import (
+	"HostelApp/internal/storageData/Mess"
+	"testing"
+	"time"
+)
+
+func TestOptOutDeadline(t *testing.T) {
+	day := time.Date(2026, 3, 2, 23, 59, 0, 0, time.UTC)
+	if start := MealStart(day, Mess.Breakfast); !start.Equal(time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected breakfast at 07:30 the same day; got %v", start)
+	}
+	deadline := OptOutDeadline(day, Mess.Breakfast, 6*time.Hour)
+	if !deadline.Equal(time.Date(2026, 3, 2, 1, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the breakfast deadline at 01:30; got %v", deadline)
+	}
+	// a cutoff longer than the morning move the deadline to the day before
+	deadline = OptOutDeadline(day, Mess.Breakfast, 12*time.Hour)
+	if !deadline.Equal(time.Date(2026, 3, 1, 19, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the breakfast deadline at 19:30 the day before; got %v", deadline)
+	}
+}
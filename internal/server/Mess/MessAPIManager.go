@@ -0,0 +1,574 @@
+package Mess
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/ValidatorSystem"
+	AllocationDB "HostelApp/internal/database/Allocation"
+	FinanceDB "HostelApp/internal/database/Finance"
+	HostelDB "HostelApp/internal/database/Hostel"
+	MessDB "HostelApp/internal/database/Mess"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	FinanceServer "HostelApp/internal/server/Finance"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Mess"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrHostelUnavailable  = errors.New("hostel is deleted")
+	ErrStudentUnavailable = errors.New("student is deleted")
+	ErrNotAllocated       = errors.New("student has no active allocation")
+	ErrNotMonday          = errors.New("week_start must be a Monday")
+	ErrDuplicateMenuEntry = errors.New("menu lists a weekday or a meal of a day more than once")
+	ErrInvalidRange       = errors.New("from must not be after to and the range must not exceed 31 days")
+	ErrPastCutoff         = errors.New("opt-out cutoff of the meal has passed")
+	ErrNoMessFee          = errors.New("fee structure of the hostel has no mess line")
+)
+
+const maxOptOutDays = 31
+
+type MessManager struct {
+	dbManager    MessDB.IMessDBService
+	hostelDB     HostelDB.IHostelDBService
+	studentDB    StudentDB.IStudentDBService
+	allocationDB AllocationDB.IAllocationDBService
+	finance      *FinanceServer.FinanceManager
+	cutoff       time.Duration
+}
+
+func NewMessManager(dbManager MessDB.IMessDBService, hostelDB HostelDB.IHostelDBService, studentDB StudentDB.IStudentDBService, allocationDB AllocationDB.IAllocationDBService, finance *FinanceServer.FinanceManager, cutoff time.Duration) *MessManager {
+	instance := &MessManager{
+		dbManager:    dbManager,
+		hostelDB:     hostelDB,
+		studentDB:    studentDB,
+		allocationDB: allocationDB,
+		finance:      finance,
+		cutoff:       cutoff,
+	}
+	return instance
+}
+
+func (m *MessManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/mess/menu", Method: internal.GET, Handler: m.GetMenu, Permission: internal.ReadPermission},
+		{Path: "/admin/mess/menu", Method: internal.PUT, Handler: m.PutMenu, Permission: internal.WritePermission},
+		{Path: "/admin/mess/opt-out", Method: internal.GET, Handler: m.GetOptOuts, Permission: internal.ReadPermission},
+		{Path: "/admin/mess/opt-out", Method: internal.POST, Handler: m.OptOut, Permission: internal.WritePermission},
+		{Path: "/admin/mess/opt-out/:id", Method: internal.DELETE, Handler: m.CancelOptOut, Permission: internal.WritePermission},
+		{Path: "/admin/mess/headcount", Method: internal.GET, Handler: m.GetHeadcount, Permission: internal.ReadPermission},
+		{Path: "/admin/mess/rebate", Method: internal.GET, Handler: m.GetRebateReport, Permission: internal.ReadPermission},
+	}
+}
+
+func messErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, MessDB.ErrMenuNotFound), errors.Is(err, MessDB.ErrOptOutNotFound),
+		errors.Is(err, HostelDB.ErrHostelNotFound), errors.Is(err, StudentDB.ErrStudentNotFound),
+		errors.Is(err, FinanceDB.ErrFeeStructureNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrNotMonday), errors.Is(err, ErrDuplicateMenuEntry), errors.Is(err, ErrInvalidRange):
+		return fiber.StatusBadRequest
+	case errors.Is(err, ErrHostelUnavailable), errors.Is(err, ErrStudentUnavailable), errors.Is(err, ErrNotAllocated),
+		errors.Is(err, ErrPastCutoff), errors.Is(err, ErrNoMessFee):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// actor is the admin user _id behind the request
+func actor(c *fiber.Ctx) string {
+	if claims := JWTManager.GetClaims(c); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
+func (m *MessManager) activeHostel(_id string, ctx context.Context) error {
+	hostel, err := m.hostelDB.FetchHostelByID(_id, ctx)
+	if err != nil {
+		return err
+	}
+	if hostel.MarkAsDeleted {
+		return ErrHostelUnavailable
+	}
+	return nil
+}
+
+// weekStartOf is the Monday of the week of day
+func weekStartOf(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	year, month, date := day.Date()
+	return time.Date(year, month, date-offset, 0, 0, 0, 0, day.Location())
+}
+
+// checkMenu reject a menu listing the same weekday, or the same meal of a day, twice
+func checkMenu(request *Mess.MenuRequest) error {
+	weekStart, _ := time.Parse(Mess.DateLayout, request.WeekStart)
+	if weekStart.Weekday() != time.Monday {
+		return ErrNotMonday
+	}
+	weekdays := map[time.Weekday]bool{}
+	for _, day := range request.Days {
+		if weekdays[day.Weekday] {
+			return ErrDuplicateMenuEntry
+		}
+		weekdays[day.Weekday] = true
+		meals := map[Mess.Meal]bool{}
+		for _, meal := range day.Meals {
+			if meals[meal.Meal] {
+				return ErrDuplicateMenuEntry
+			}
+			meals[meal.Meal] = true
+		}
+	}
+	return nil
+}
+
+// @Summary Set weekly menu
+// @Description Create or replace the menu of a hostel for the week starting on a Monday
+// @Tags mess
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param menu body Mess.MenuRequest true "Menu of the week"
+// @Success 200 {object} Mess.MenuData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/mess/menu [put]
+func (m *MessManager) PutMenu(c *fiber.Ctx) error {
+	var request Mess.MenuRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse menu",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate menu",
+			"error":   err.Error(),
+		})
+	}
+
+	before, _ := m.dbManager.FetchMenu(request.HostelID, request.WeekStart, c.Context())
+	menu, err := m.putMenu(&request, actor(c), c.Context())
+	if err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to save menu",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, menu.ID, before, menu)
+	return c.JSON(menu)
+}
+
+func (m *MessManager) putMenu(request *Mess.MenuRequest, by string, ctx context.Context) (*Mess.MenuData, error) {
+	if err := checkMenu(request); err != nil {
+		return nil, err
+	}
+	if err := m.activeHostel(request.HostelID, ctx); err != nil {
+		return nil, err
+	}
+	return m.dbManager.UpsertMenu(&Mess.MenuData{
+		HostelID:  request.HostelID,
+		WeekStart: request.WeekStart,
+		Days:      request.Days,
+		UpdatedBy: by,
+	}, ctx)
+}
+
+// @Summary Get weekly menu
+// @Description Fetch the menu of a hostel for a week, the current week by default
+// @Tags mess
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param week_start query string false "Monday of the week as YYYY-MM-DD"
+// @Success 200 {object} Mess.MenuData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/mess/menu [get]
+func (m *MessManager) GetMenu(c *fiber.Ctx) error {
+	weekStart := c.Query("week_start", weekStartOf(time.Now()).Format(Mess.DateLayout))
+	day, err := time.Parse(Mess.DateLayout, weekStart)
+	if err == nil && day.Weekday() != time.Monday {
+		err = ErrNotMonday
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate week_start",
+			"error":   err.Error(),
+		})
+	}
+
+	menu, err := m.dbManager.FetchMenu(c.Query("hostel_id", ""), weekStart, c.Context())
+	if err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch menu",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(menu)
+}
+
+// activeAllocation is the current allocation of a student still in the college
+func (m *MessManager) activeAllocation(studentID string, ctx context.Context) (*Allocation.AllocationData, error) {
+	student, err := m.studentDB.FetchStudentByID(studentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if student.MarkAsDeleted {
+		return nil, ErrStudentUnavailable
+	}
+	allocations, err := m.allocationDB.FetchAllocations(&Allocation.AllocationFilter{Page: 1, Limit: 1, StudentID: studentID, Status: Allocation.Active}, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(allocations) == 0 {
+		return nil, ErrNotAllocated
+	}
+	return &allocations[0], nil
+}
+
+func uniqueMeals(meals []Mess.Meal) []Mess.Meal {
+	seen := map[Mess.Meal]bool{}
+	unique := make([]Mess.Meal, 0, len(meals))
+	for _, meal := range meals {
+		if !seen[meal] {
+			seen[meal] = true
+			unique = append(unique, meal)
+		}
+	}
+	return unique
+}
+
+// @Summary Opt out of meals
+// @Description Opt a student out of the listed meals, every meal by default, on each day of a range of at most 31 days. Meals already opted out of are skipped and meals past the cutoff are not recorded
+// @Tags mess
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param opt_out body Mess.OptOutRequest true "Student, days and meals"
+// @Success 201 {object} Mess.OptOutResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/mess/opt-out [post]
+func (m *MessManager) OptOut(c *fiber.Ctx) error {
+	var request Mess.OptOutRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse opt-out",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate opt-out",
+			"error":   err.Error(),
+		})
+	}
+
+	result, err := m.optOut(&request, actor(c), time.Now(), c.Context())
+	if err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to opt out",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, request.StudentID, nil, result)
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (m *MessManager) optOut(request *Mess.OptOutRequest, by string, now time.Time, ctx context.Context) (*Mess.OptOutResult, error) {
+	from, _ := time.ParseInLocation(Mess.DateLayout, request.From, time.Local)
+	to, _ := time.ParseInLocation(Mess.DateLayout, request.To, time.Local)
+	if to.Before(from) || to.Sub(from) >= maxOptOutDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+	meals := Mess.Meals
+	if len(request.Meals) > 0 {
+		meals = uniqueMeals(request.Meals)
+	}
+	allocation, err := m.activeAllocation(request.StudentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Mess.OptOutResult{}
+	var optOuts []Mess.OptOutData
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, meal := range meals {
+			if !now.Before(OptOutDeadline(day, meal, m.cutoff)) {
+				result.Late++
+				continue
+			}
+			optOuts = append(optOuts, Mess.OptOutData{
+				StudentID:         request.StudentID,
+				CollageUniqueName: allocation.CollageUniqueName,
+				HostelID:          allocation.HostelID,
+				Date:              day.Format(Mess.DateLayout),
+				Meal:              meal,
+				CreatedBy:         by,
+			})
+		}
+	}
+	if len(optOuts) == 0 {
+		return nil, ErrPastCutoff
+	}
+
+	created, err := m.dbManager.AddOptOuts(optOuts, ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Created = created
+	result.Skipped = len(optOuts) - len(created)
+	return result, nil
+}
+
+// @Summary Get meal opt-outs
+// @Description Fetch filtered list of meal opt-outs, latest date first
+// @Tags mess
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param hostel_id query string false "Hostel id"
+// @Param date query string false "Date as YYYY-MM-DD"
+// @Param meal query string false "breakfast, lunch, snacks or dinner"
+// @Success 200 {object} []Mess.OptOutData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/mess/opt-out [get]
+func (m *MessManager) GetOptOuts(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Mess.OptOutFilter{
+		Page:      page,
+		Limit:     limit,
+		StudentID: c.Query("student_id", ""),
+		HostelID:  c.Query("hostel_id", ""),
+		Date:      c.Query("date", ""),
+		Meal:      Mess.Meal(c.Query("meal", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	optOuts, err := m.dbManager.FetchOptOuts(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch opt-outs",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(optOuts)
+}
+
+// @Summary Cancel a meal opt-out
+// @Description Opt the student back into the meal, only until the cutoff of the meal
+// @Tags mess
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Opt-out id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/mess/opt-out/{id} [delete]
+func (m *MessManager) CancelOptOut(c *fiber.Ctx) error {
+	before, _ := m.dbManager.FetchOptOutByID(c.Params("id"), c.Context())
+	if err := m.cancelOptOut(c.Params("id"), time.Now(), c.Context()); err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to cancel opt-out",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), before, nil)
+	return c.JSON(fiber.Map{"message": "opt-out cancelled"})
+}
+
+func (m *MessManager) cancelOptOut(_id string, now time.Time, ctx context.Context) error {
+	optOut, err := m.dbManager.FetchOptOutByID(_id, ctx)
+	if err != nil {
+		return err
+	}
+	day, _ := time.ParseInLocation(Mess.DateLayout, optOut.Date, time.Local)
+	if !now.Before(OptOutDeadline(day, optOut.Meal, m.cutoff)) {
+		return ErrPastCutoff
+	}
+	return m.dbManager.DeleteOptOut(_id, ctx)
+}
+
+// @Summary Get headcount forecast
+// @Description Expected diners of every meal for the coming days, students with an active allocation today minus their opt-outs
+// @Tags mess
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param from query string false "First date as YYYY-MM-DD, today by default"
+// @Param days query string false "Number of days, 7 by default and at most 14"
+// @Success 200 {object} Mess.HeadcountForecast
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/mess/headcount [get]
+func (m *MessManager) GetHeadcount(c *fiber.Ctx) error {
+	days, _ := strconv.Atoi(c.Query("days", "7"))
+	request := Mess.HeadcountRequest{
+		HostelID: c.Query("hostel_id", ""),
+		From:     c.Query("from", time.Now().Format(Mess.DateLayout)),
+		Days:     days,
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate headcount query",
+			"error":   err.Error(),
+		})
+	}
+
+	forecast, err := m.headcount(&request, c.Context())
+	if err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to forecast headcount",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(forecast)
+}
+
+func (m *MessManager) headcount(request *Mess.HeadcountRequest, ctx context.Context) (*Mess.HeadcountForecast, error) {
+	if _, err := m.hostelDB.FetchHostelByID(request.HostelID, ctx); err != nil {
+		return nil, err
+	}
+	allocations, err := m.allocationDB.FetchActiveAllocations(request.HostelID, "", ctx)
+	if err != nil {
+		return nil, err
+	}
+	enrolled := make(map[string]bool, len(allocations))
+	for _, allocation := range allocations {
+		enrolled[allocation.StudentID] = true
+	}
+
+	from, _ := time.Parse(Mess.DateLayout, request.From)
+	to := from.AddDate(0, 0, request.Days-1)
+	optOuts, err := m.dbManager.FetchOptOutRange(request.HostelID, request.From, to.Format(Mess.DateLayout), ctx)
+	if err != nil {
+		return nil, err
+	}
+	// opt-outs of students who left the hostel since do not lower the headcount
+	skipped := map[string]int{}
+	for _, optOut := range optOuts {
+		if enrolled[optOut.StudentID] {
+			skipped[optOut.Date+"|"+string(optOut.Meal)]++
+		}
+	}
+
+	forecast := &Mess.HeadcountForecast{HostelID: request.HostelID, Enrolled: len(enrolled), Days: make([]Mess.DailyHeadcount, 0, request.Days)}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(Mess.DateLayout)
+		daily := Mess.DailyHeadcount{Date: date, Meals: make([]Mess.MealHeadcount, 0, len(Mess.Meals))}
+		for _, meal := range Mess.Meals {
+			optedOut := skipped[date+"|"+string(meal)]
+			daily.Meals = append(daily.Meals, Mess.MealHeadcount{
+				Meal:     meal,
+				Enrolled: len(enrolled),
+				OptedOut: optedOut,
+				Expected: len(enrolled) - optedOut,
+			})
+		}
+		forecast.Days = append(forecast.Days, daily)
+	}
+	return forecast, nil
+}
+
+// @Summary Get monthly rebate report
+// @Description Rebate owed to every student of a hostel for the meals skipped in a month, each meal is priced at the mess fee divided by the meals served in the month
+// @Tags mess
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param hostel_id query string true "Hostel id"
+// @Param month query string true "Month as YYYY-MM"
+// @Success 200 {object} Mess.RebateReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/mess/rebate [get]
+func (m *MessManager) GetRebateReport(c *fiber.Ctx) error {
+	request := Mess.RebateRequest{
+		HostelID: c.Query("hostel_id", ""),
+		Month:    c.Query("month", ""),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate rebate query",
+			"error":   err.Error(),
+		})
+	}
+
+	report, err := m.rebateReport(&request, c.Context())
+	if err != nil {
+		return c.Status(messErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to build rebate report",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(report)
+}
+
+func (m *MessManager) rebateReport(request *Mess.RebateRequest, ctx context.Context) (*Mess.RebateReport, error) {
+	if _, err := m.hostelDB.FetchHostelByID(request.HostelID, ctx); err != nil {
+		return nil, err
+	}
+	monthStart, _ := time.Parse(Mess.MonthLayout, request.Month)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	structure, err := m.finance.EffectiveStructure(request.HostelID, monthStart, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var messFee int64
+	for _, line := range structure.Lines {
+		if line.Kind == Finance.Mess {
+			messFee += line.Amount
+		}
+	}
+	if messFee == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoMessFee, structure.ID)
+	}
+
+	optOuts, err := m.dbManager.FetchOptOutRange(request.HostelID, monthStart.Format(Mess.DateLayout), monthEnd.Format(Mess.DateLayout), ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &Mess.RebateReport{
+		HostelID: request.HostelID,
+		Month:    request.Month,
+		Currency: structure.Currency,
+		// rounded down so the rebates of a month never exceed the mess fee
+		MealRate: messFee / int64(monthEnd.Day()*len(Mess.Meals)),
+		Students: []Mess.StudentRebate{},
+	}
+	// opt-outs are sorted by student so each student is one run
+	for i := 0; i < len(optOuts); {
+		rebate := Mess.StudentRebate{StudentID: optOuts[i].StudentID}
+		for ; i < len(optOuts) && optOuts[i].StudentID == rebate.StudentID; i++ {
+			rebate.MealsSkipped++
+		}
+		rebate.Amount = int64(rebate.MealsSkipped) * report.MealRate
+		report.Students = append(report.Students, rebate)
+		report.TotalMeals += rebate.MealsSkipped
+		report.Total += rebate.Amount
+	}
+	return report, nil
+}
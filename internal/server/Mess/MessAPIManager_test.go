@@ -0,0 +1,133 @@
+package Mess_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Mess"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMessFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{
+		{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Dorm, Capacity: 3, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available},
+	}, ctx)
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("add room: expected 1 room; got %v %v", rooms, err)
+	}
+	var students []*Student.StudentData
+	for i := 0; i < 3; i++ {
+		student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: fmt.Sprintf("R-%d", i), CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+		if err != nil {
+			t.Fatalf("add student %d: %v", i, err)
+		}
+		if _, err = db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: student.ID, CollageUniqueName: "college-a", HostelID: hostel.ID, RoomID: rooms[0].ID, BedNumber: i + 1}, ctx); err != nil {
+			t.Fatalf("allocate student %d: %v", i, err)
+		}
+		students = append(students, student)
+	}
+	outsider, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "O", RollNumber: "R-9", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add outsider: %v", err)
+	}
+
+	now := time.Now()
+	day := func(offset int) string { return now.AddDate(0, 0, offset).Format(Mess.DateLayout) }
+	monday := now.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+	menu := map[string]interface{}{"hostel_id": hostel.ID, "week_start": monday.AddDate(0, 0, 1).Format(Mess.DateLayout), "days": []map[string]interface{}{
+		{"weekday": 1, "meals": []map[string]interface{}{{"meal": "breakfast", "items": []string{"Poha", "Tea"}}, {"meal": "dinner", "items": []string{"Dal", "Rice"}}}},
+	}}
+	if status, _ := testutil.DoJSON(t, s, "PUT", "/admin/mess/menu", token, menu); status != http.StatusBadRequest {
+		t.Fatalf("menu not on a monday: expected status 400; got %d", status)
+	}
+	menu["week_start"] = monday.Format(Mess.DateLayout)
+	status, saved := testutil.DoJSON(t, s, "PUT", "/admin/mess/menu", token, menu)
+	if status != http.StatusOK {
+		t.Fatalf("put menu: expected status OK; got %d %v", status, saved)
+	}
+	menu["days"] = append(testutil.Path[[]map[string]interface{}](t, menu, "days"), map[string]interface{}{"weekday": 2, "meals": []map[string]interface{}{{"meal": "lunch", "items": []string{"Rajma", "Rice"}}}})
+	status, body := testutil.DoJSON(t, s, "PUT", "/admin/mess/menu", token, menu)
+	if status != http.StatusOK || body["id"] != saved["id"] {
+		t.Fatalf("replace menu: expected the same menu updated; got %d %v", status, body)
+	}
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/mess/menu?hostel_id="+hostel.ID, token, nil); status != http.StatusOK || len(testutil.Path[[]interface{}](t, body, "days")) != 2 {
+		t.Fatalf("get menu: expected the current week with 2 days; got %d %v", status, body)
+	}
+
+	optOut := func(student *Student.StudentData, from string, to string, meals ...string) (int, map[string]interface{}) {
+		return testutil.DoJSON(t, s, "POST", "/admin/mess/opt-out", token, map[string]interface{}{"student_id": student.ID, "from": from, "to": to, "meals": meals})
+	}
+	if status, body = optOut(students[0], day(1), day(1)); status != http.StatusCreated || len(testutil.Path[[]interface{}](t, body, "created")) != 4 {
+		t.Fatalf("opt out of a day: expected 4 meals; got %d %v", status, body)
+	}
+	// opting out again is idempotent
+	if status, body = optOut(students[0], day(1), day(1)); status != http.StatusCreated || len(testutil.Path[[]interface{}](t, body, "created")) != 0 || testutil.Path[float64](t, body, "skipped") != 4 {
+		t.Fatalf("opt out again: expected 4 skipped; got %d %v", status, body)
+	}
+	if status, body = optOut(students[1], day(1), day(2), "dinner"); status != http.StatusCreated || len(testutil.Path[[]interface{}](t, body, "created")) != 2 {
+		t.Fatalf("opt out of dinners: expected 2 meals; got %d %v", status, body)
+	}
+	if status, _ = optOut(students[2], day(-1), day(-1)); status != http.StatusUnprocessableEntity {
+		t.Fatalf("opt out past the cutoff: expected status 422; got %d", status)
+	}
+	if status, _ = optOut(outsider, day(1), day(1)); status != http.StatusUnprocessableEntity {
+		t.Fatalf("opt out without allocation: expected status 422; got %d", status)
+	}
+	if status, _ = optOut(students[2], day(1), day(40)); status != http.StatusBadRequest {
+		t.Fatalf("opt out of too many days: expected status 400; got %d", status)
+	}
+
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/mess/headcount?hostel_id="+hostel.ID+"&from="+day(1)+"&days=2", token, nil)
+	days := testutil.Path[[]interface{}](t, body, "days")
+	dinner := testutil.Path[map[string]interface{}](t, days, 0, "meals", 3)
+	lunch := testutil.Path[map[string]interface{}](t, days, 1, "meals", 1)
+	if status != http.StatusOK || len(days) != 2 || testutil.Path[float64](t, dinner, "expected") != 1 || testutil.Path[float64](t, lunch, "expected") != 3 {
+		t.Fatalf("headcount: expected 1 at the first dinner and 3 at the second lunch; got %d %v", status, body)
+	}
+
+	dinners, err := db.MessDB.FetchOptOuts(&Mess.OptOutFilter{Page: 1, Limit: 10, StudentID: students[1].ID, Date: day(2)}, ctx)
+	if err != nil || len(dinners) != 1 {
+		t.Fatalf("fetch opt-outs: expected the second dinner; got %v %v", dinners, err)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/mess/opt-out/"+dinners[0].ID, token, nil); status != http.StatusOK {
+		t.Fatalf("cancel opt-out: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/mess/opt-out/"+dinners[0].ID, token, nil); status != http.StatusNotFound {
+		t.Fatalf("cancel twice: expected status 404; got %d", status)
+	}
+
+	month := now.AddDate(0, 0, 1)
+	query := "/admin/mess/rebate?hostel_id=" + hostel.ID + "&month=" + month.Format(Mess.MonthLayout)
+	if status, _ = testutil.DoJSON(t, s, "GET", query, token, nil); status != http.StatusNotFound {
+		t.Fatalf("rebate without fee structure: expected status 404; got %d", status)
+	}
+	structure := map[string]interface{}{
+		"hostel_id": hostel.ID, "currency": "INR", "effective_from": "2026-01-01T00:00:00Z",
+		"lines": []map[string]interface{}{{"kind": "mess", "description": "Mess charges", "amount": 300000}},
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", "/admin/finance/fee-structure", token, structure); status != http.StatusCreated {
+		t.Fatalf("add fee structure: expected status 201; got %d %v", status, body)
+	}
+	rate := int64(300000) / int64(time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()*4)
+	status, body = testutil.DoJSON(t, s, "GET", query, token, nil)
+	if status != http.StatusOK || int64(testutil.Path[float64](t, body, "meal_rate")) != rate || testutil.Path[float64](t, body, "total_meals") != 5 || int64(testutil.Path[float64](t, body, "total")) != 5*rate {
+		t.Fatalf("rebate report: expected 5 meals at %d; got %d %v", rate, status, body)
+	}
+}
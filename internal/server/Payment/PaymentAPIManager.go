@@ -0,0 +1,360 @@
+package Payment
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/ValidatorSystem"
+	FinanceDB "HostelApp/internal/database/Finance"
+	PaymentDB "HostelApp/internal/database/Payment"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	FinanceServer "HostelApp/internal/server/Finance"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Payment"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"strconv"
+)
+
+var (
+	ErrInvoiceSettled      = errors.New("invoice has nothing left to pay")
+	ErrAmountTooHigh       = errors.New("amount is higher than the invoice outstanding")
+	ErrInvoiceNotPayable   = errors.New("invoice does not belong to the student or is not issued")
+	ErrPaymentNotConfirmed = errors.New("payment provider does not confirm the captured amount")
+)
+
+type PaymentManager struct {
+	dbManager PaymentDB.IPaymentDBService
+	financeDB FinanceDB.IFinanceDBService
+	studentDB StudentDB.IStudentDBService
+	finance   *FinanceServer.FinanceManager
+	provider  PaymentProvider.PaymentProvider
+}
+
+func NewPaymentManager(dbManager PaymentDB.IPaymentDBService, financeDB FinanceDB.IFinanceDBService, studentDB StudentDB.IStudentDBService, finance *FinanceServer.FinanceManager, provider PaymentProvider.PaymentProvider) *PaymentManager {
+	instance := &PaymentManager{
+		dbManager: dbManager,
+		financeDB: financeDB,
+		studentDB: studentDB,
+		finance:   finance,
+		provider:  provider,
+	}
+	return instance
+}
+
+func (m *PaymentManager) GetFiberRoutes() *[]internal.APIRoute {
+	routes := []internal.APIRoute{
+		{Path: "/admin/payment/order", Method: internal.GET, Handler: m.GetOrders, Permission: internal.ReadPermission},
+		{Path: "/admin/payment/order", Method: internal.POST, Handler: m.CreateOrder, Permission: internal.WritePermission},
+		{Path: "/admin/payment/order/:id", Method: internal.GET, Handler: m.GetOrder, Permission: internal.ReadPermission},
+		// called by the gateway, authenticated by the payload signature instead of a JWT
+		{Path: "/payment/webhook", Method: internal.POST, Handler: m.Webhook},
+	}
+	if fake, ok := m.provider.(*PaymentProvider.FakeProvider); ok && fake.CheckoutEnabled() {
+		routes = append(routes, internal.APIRoute{Path: "/admin/payment/fake/:provider_order_id", Method: internal.POST, Handler: m.FakeCheckout, Permission: internal.WritePermission})
+	}
+	return &routes
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, PaymentDB.ErrOrderNotFound), errors.Is(err, FinanceDB.ErrInvoiceNotFound),
+		errors.Is(err, StudentDB.ErrStudentNotFound), errors.Is(err, PaymentProvider.ErrUnknownOrder):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrInvoiceSettled):
+		return fiber.StatusConflict
+	case errors.Is(err, PaymentProvider.ErrInvalidSignature):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, ErrAmountTooHigh), errors.Is(err, ErrInvoiceNotPayable), errors.Is(err, FinanceServer.ErrStudentUnavailable), errors.Is(err, ErrPaymentNotConfirmed):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// @Summary Create payment order
+// @Description Open an online payment order on an invoice, without amount the whole outstanding is requested
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param order body Payment.CreateOrderRequest true "Invoice to pay"
+// @Success 201 {object} Payment.PaymentOrder
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/payment/order [post]
+func (m *PaymentManager) CreateOrder(c *fiber.Ctx) error {
+	var request Payment.CreateOrderRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse payment order",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate payment order",
+			"error":   err.Error(),
+		})
+	}
+
+	order, err := m.createOrder(&request, c.Context())
+	if err != nil {
+		return c.Status(paymentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to create payment order",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, order.ID, nil, order)
+	return c.Status(fiber.StatusCreated).JSON(order)
+}
+
+func (m *PaymentManager) createOrder(request *Payment.CreateOrderRequest, ctx context.Context) (*Payment.PaymentOrder, error) {
+	student, err := m.studentDB.FetchStudentByID(request.StudentID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if student.MarkAsDeleted {
+		return nil, FinanceServer.ErrStudentUnavailable
+	}
+	invoice, err := m.financeDB.FetchInvoiceByID(request.InvoiceID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.StudentID != student.ID || invoice.Status != Finance.Issued {
+		return nil, ErrInvoiceNotPayable
+	}
+	paid, err := m.financeDB.InvoicePaid(invoice.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	outstanding := invoice.Total - paid
+	if outstanding <= 0 {
+		return nil, ErrInvoiceSettled
+	}
+	amount := request.Amount
+	if amount == 0 {
+		amount = outstanding
+	}
+	if amount > outstanding {
+		return nil, ErrAmountTooHigh
+	}
+
+	order := &Payment.PaymentOrder{
+		StudentID:         student.ID,
+		CollageUniqueName: student.CollageUniqueName,
+		InvoiceID:         invoice.ID,
+		Amount:            amount,
+		Currency:          invoice.Currency,
+		Provider:          m.provider.Name(),
+	}
+	providerOrder, err := m.provider.CreateOrder(order, ctx)
+	if err != nil {
+		return nil, err
+	}
+	order.ProviderOrderID = providerOrder.ProviderOrderID
+	order.CheckoutURL = providerOrder.CheckoutURL
+	return m.dbManager.CreateOrder(order, ctx)
+}
+
+// @Summary Get payment order list
+// @Description Fetch filtered list of payment orders, newest first
+// @Tags payment
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param student_id query string false "Student id"
+// @Param invoice_id query string false "Invoice id"
+// @Param status query string false "created, paid or failed"
+// @Success 200 {object} []Payment.PaymentOrder
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/payment/order [get]
+func (m *PaymentManager) GetOrders(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Payment.OrderFilter{
+		Page:      page,
+		Limit:     limit,
+		StudentID: c.Query("student_id", ""),
+		InvoiceID: c.Query("invoice_id", ""),
+		Status:    Payment.OrderStatus(c.Query("status", "")),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	orders, err := m.dbManager.FetchOrders(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch payment orders",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(orders)
+}
+
+// @Summary Get payment order
+// @Description Fetch a payment order with its live status on the payment provider
+// @Tags payment
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Payment order id"
+// @Success 200 {object} Payment.PaymentOrder
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/payment/order/{id} [get]
+func (m *PaymentManager) GetOrder(c *fiber.Ctx) error {
+	order, err := m.dbManager.FetchOrderByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(paymentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch payment order",
+			"error":   err.Error(),
+		})
+	}
+	if remote, err := m.provider.FetchStatus(order.ProviderOrderID, c.Context()); err == nil {
+		order.ProviderStatus = remote.Status
+	} else {
+		slog.Warn(fmt.Sprintf("failed to fetch provider status of order %s: %v", order.ID, err))
+	}
+	return c.JSON(order)
+}
+
+// processEvent apply a verified webhook event. It is safe to call again with
+// the same event: a settled order is returned untouched and the ledger reject
+// a second payment with the same provider reference
+func (m *PaymentManager) processEvent(event *Payment.WebhookEvent, ctx context.Context) (*Payment.PaymentOrder, error) {
+	order, err := m.dbManager.FetchOrderByProviderID(m.provider.Name(), event.ProviderOrderID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != Payment.Created {
+		return order, nil
+	}
+
+	switch event.Type {
+	case Payment.PaymentCaptured:
+		// the event is signed but the gateway stay the source of truth for the amount
+		remote, err := m.provider.FetchStatus(order.ProviderOrderID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if remote.Status != Payment.Paid || remote.Amount != order.Amount || event.Amount != order.Amount {
+			return nil, ErrPaymentNotConfirmed
+		}
+		request := &Finance.PaymentRequest{
+			StudentID: order.StudentID,
+			InvoiceID: order.InvoiceID,
+			Amount:    order.Amount,
+			Reference: order.Provider + ":" + event.ProviderPaymentID,
+			Note:      "online payment " + order.ID,
+		}
+		transaction, err := m.finance.PostPayment(request, ctx)
+		if errors.Is(err, FinanceDB.ErrInvoiceNotIssued) {
+			// the money is captured anyway, keep it as student credit
+			request.InvoiceID = ""
+			transaction, err = m.finance.PostPayment(request, ctx)
+		}
+		transactionID := ""
+		if err == nil {
+			transactionID = transaction.ID
+		} else if !errors.Is(err, FinanceDB.ErrDuplicateReference) {
+			return nil, err
+		}
+		return m.settle(order, Payment.Paid, event.ProviderPaymentID, transactionID, ctx)
+	case Payment.PaymentFailed:
+		return m.settle(order, Payment.Failed, event.ProviderPaymentID, "", ctx)
+	default:
+		return order, nil
+	}
+}
+
+func (m *PaymentManager) settle(order *Payment.PaymentOrder, status Payment.OrderStatus, providerPaymentID string, transactionID string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	settled, err := m.dbManager.SettleOrder(order.ID, status, providerPaymentID, transactionID, ctx)
+	if errors.Is(err, PaymentDB.ErrOrderStatusChanged) {
+		// a concurrent delivery of the same event won
+		return m.dbManager.FetchOrderByID(order.ID, ctx)
+	}
+	return settled, err
+}
+
+func (m *PaymentManager) handleWebhook(payload []byte, signature string, ctx context.Context) (*Payment.PaymentOrder, error) {
+	if err := m.provider.VerifySignature(payload, signature); err != nil {
+		return nil, err
+	}
+	event, err := m.provider.ParseEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return m.processEvent(event, ctx)
+}
+
+// @Summary Payment webhook
+// @Description Receive a payment event from the provider, the body must be signed with the webhook secret. Duplicate deliveries are accepted and ignored
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /payment/webhook [post]
+func (m *PaymentManager) Webhook(c *fiber.Ctx) error {
+	payload := append([]byte(nil), c.Body()...)
+	order, err := m.handleWebhook(payload, c.Get(m.provider.SignatureHeader()), c.Context())
+	if err != nil {
+		status := paymentErrorStatus(err)
+		if status == fiber.StatusInternalServerError {
+			slog.Error(fmt.Sprintf("failed to process payment webhook: %v", err))
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"message": "failed to process webhook",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"message": "webhook processed",
+		"status":  order.Status,
+	})
+}
+
+// @Summary Fake checkout
+// @Description Development only, settle an order on the fake provider and deliver its signed webhook
+// @Tags payment
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param provider_order_id path string true "Provider order id"
+// @Param checkout body Payment.FakeCheckoutRequest true "Outcome of the payment"
+// @Success 200 {object} Payment.PaymentOrder
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/payment/fake/{provider_order_id} [post]
+func (m *PaymentManager) FakeCheckout(c *fiber.Ctx) error {
+	var request Payment.FakeCheckoutRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse checkout",
+			"error":   err.Error(),
+		})
+	}
+	before, _ := m.dbManager.FetchOrderByProviderID(m.provider.Name(), c.Params("provider_order_id"), c.Context())
+	fake := m.provider.(*PaymentProvider.FakeProvider)
+	payload, signature, err := fake.Complete(c.Params("provider_order_id"), request.Success)
+	if err == nil {
+		var order *Payment.PaymentOrder
+		if order, err = m.handleWebhook(payload, signature, c.Context()); err == nil {
+			Audit.Record(c, order.ID, before, order)
+			return c.JSON(order)
+		}
+	}
+	return c.Status(paymentErrorStatus(err)).JSON(fiber.Map{
+		"message": "failed to complete fake checkout",
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,127 @@
+package Payment_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/storageData/Allocation"
+	"HostelApp/internal/storageData/Finance"
+	"HostelApp/internal/storageData/Hostel"
+	PaymentData "HostelApp/internal/storageData/Payment"
+	"HostelApp/internal/storageData/Student"
+	"HostelApp/internal/testutil"
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+)
+
+func postWebhook(t *testing.T, s *server.FiberServer, payload []byte, signature string) int {
+	t.Helper()
+	req, _ := http.NewRequest("POST", "/payment/webhook", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Fake-Signature", signature)
+	resp, err := s.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestPaymentFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	provider := PaymentProvider.NewFakeProvider("test_secret").EnableCheckout()
+	s := server.NewFiberServer(db, provider)
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	// without the development flag the fake checkout is not exposed
+	locked := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	lockedToken, _ := testutil.Login(t, locked, "admin", "password@123")
+	if status, _ := testutil.DoRaw(t, locked, "POST", "/admin/payment/fake/any", lockedToken, "application/json", []byte(`{"success":true}`)); status != http.StatusNotFound {
+		t.Fatalf("fake checkout without the flag: expected status 404; got %d", status)
+	}
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx)
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("add room: expected 1 room; got %v %v", rooms, err)
+	}
+	student, err := db.StudentDB.AddStudent(&Student.StudentData{Name: "S", RollNumber: "R-1", CollageUniqueName: "college-a", Gender: Student.Female, Year: 1}, ctx)
+	if err != nil {
+		t.Fatalf("add student: %v", err)
+	}
+	if _, err = db.AllocationDB.CreateAllocation(&Allocation.AllocationData{StudentID: student.ID, CollageUniqueName: "college-a", HostelID: hostel.ID, RoomID: rooms[0].ID, BedNumber: 1}, ctx); err != nil {
+		t.Fatalf("allocate student: %v", err)
+	}
+	structure := map[string]interface{}{
+		"hostel_id": hostel.ID, "currency": "INR", "effective_from": "2026-01-01T00:00:00Z",
+		"lines": []map[string]interface{}{{"kind": "rent", "description": "Monthly rent", "amount": 500000}},
+	}
+	if status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/fee-structure", token, structure); status != http.StatusCreated {
+		t.Fatalf("add fee structure: expected status 201; got %d %v", status, body)
+	}
+	generate := func(period string) string {
+		status, body := testutil.DoJSON(t, s, "POST", "/admin/finance/invoice/generate", token, map[string]interface{}{"hostel_id": hostel.ID, "period": period, "due_date": "2026-12-10T00:00:00Z"})
+		if status != http.StatusOK {
+			t.Fatalf("generate %s: expected status OK; got %d %v", period, status, body)
+		}
+		return testutil.Path[string](t, body, "created", 0, "id")
+	}
+	invoiceID := generate("2026-08")
+
+	order := map[string]interface{}{"student_id": student.ID, "invoice_id": invoiceID, "amount": 600000}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/payment/order", token, order); status != http.StatusUnprocessableEntity {
+		t.Fatalf("order above outstanding: expected status 422; got %d", status)
+	}
+	delete(order, "amount")
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/payment/order", token, order)
+	if status != http.StatusCreated || testutil.Path[float64](t, body, "amount") != 500000 || body["status"] != string(PaymentData.Created) {
+		t.Fatalf("create order: expected the whole outstanding; got %d %v", status, body)
+	}
+	providerOrderID := testutil.Path[string](t, body, "provider_order_id")
+
+	payload, signature, err := provider.Complete(providerOrderID, true)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if status = postWebhook(t, s, payload, "bad"+signature); status != http.StatusUnauthorized {
+		t.Fatalf("forged webhook: expected status 401; got %d", status)
+	}
+	for i := 0; i < 3; i++ {
+		if status = postWebhook(t, s, payload, signature); status != http.StatusOK {
+			t.Fatalf("webhook delivery %d: expected status OK; got %d", i, status)
+		}
+	}
+	payments, _ := db.FinanceDB.FetchTransactions(&Finance.LedgerFilter{Page: 1, Limit: 10, StudentID: student.ID, Kind: Finance.Payment}, ctx)
+	if len(payments) != 1 {
+		t.Fatalf("duplicate webhooks: expected one ledger payment; got %d", len(payments))
+	}
+	if balance, _ := db.FinanceDB.AccountBalance(Finance.StudentAccount(student.ID), ctx); balance != 0 {
+		t.Fatalf("balance: expected 0; got %d", balance)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/payment/order/"+payments[0].ID, token, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("unknown order: expected status 404; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/payment/order", token, order); status != http.StatusConflict {
+		t.Fatalf("order on paid invoice: expected status 409; got %d", status)
+	}
+
+	secondID := generate("2026-09")
+	_, body = testutil.DoJSON(t, s, "POST", "/admin/payment/order", token, map[string]interface{}{"student_id": student.ID, "invoice_id": secondID})
+	status, body = testutil.DoJSON(t, s, "POST", "/admin/payment/fake/"+testutil.Path[string](t, body, "provider_order_id"), token, map[string]interface{}{"success": false})
+	if status != http.StatusOK || body["status"] != string(PaymentData.Failed) {
+		t.Fatalf("failed checkout: expected failed order; got %d %v", status, body)
+	}
+	status, body = testutil.DoJSON(t, s, "GET", "/admin/payment/order/"+testutil.Path[string](t, body, "id"), token, nil)
+	if status != http.StatusOK || body["provider_status"] != string(PaymentData.Failed) {
+		t.Fatalf("order status: expected failed on the provider; got %d %v", status, body)
+	}
+	if balance, _ := db.FinanceDB.AccountBalance(Finance.StudentAccount(student.ID), ctx); balance != 500000 {
+		t.Fatalf("balance after failed payment: expected 500000; got %d", balance)
+	}
+}
@@ -0,0 +1,246 @@
+package Student
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	StudentDB "HostelApp/internal/database/Student"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Student"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+)
+
+type StudentManager struct {
+	dbManager StudentDB.IStudentDBService
+	collegeDB AdminDB.ICollegeDBService
+}
+
+func NewStudentManager(dbManager StudentDB.IStudentDBService, collegeDB AdminDB.ICollegeDBService) *StudentManager {
+	instance := &StudentManager{
+		dbManager: dbManager,
+		collegeDB: collegeDB,
+	}
+	return instance
+}
+
+func (m *StudentManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/student", Method: internal.GET, Handler: m.GetStudents, Permission: internal.ReadPermission},
+		{Path: "/admin/student", Method: internal.POST, Handler: m.AddStudent, Permission: internal.WritePermission},
+		{Path: "/admin/student/:id", Method: internal.GET, Handler: m.GetStudent, Permission: internal.ReadPermission},
+		{Path: "/admin/student/:id", Method: internal.PATCH, Handler: m.UpdateStudent, Permission: internal.WritePermission},
+		{Path: "/admin/student/:id", Method: internal.DELETE, Handler: m.DeleteStudent, Permission: internal.WritePermission},
+	}
+}
+
+// checkCollege make sure the student is linked to an existing and not deleted college
+func (m *StudentManager) checkCollege(collageUniqueName string, ctx context.Context) error {
+	college, err := m.collegeDB.FetchCollegeByName(collageUniqueName, ctx)
+	if err != nil {
+		return err
+	}
+	if college.MarkAsDeleted {
+		return fmt.Errorf("college '%s' is deleted", collageUniqueName)
+	}
+	return nil
+}
+
+func studentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, StudentDB.ErrStudentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, StudentDB.ErrStudentDuplicate):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// @Summary Add student
+// @Description Register a new student in an existing college
+// @Tags student
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param student body Student.StudentData true "Student to be added"
+// @Success 201 {object} Student.StudentData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/student [post]
+func (m *StudentManager) AddStudent(c *fiber.Ctx) error {
+	var student Student.StudentData
+	if err := c.BodyParser(&student); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse student",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&student); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate student",
+			"error":   err.Error(),
+		})
+	}
+	if err := m.checkCollege(student.CollageUniqueName, c.Context()); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate student college",
+			"error":   err.Error(),
+		})
+	}
+
+	added, err := m.dbManager.AddStudent(&student, c.Context())
+	if err != nil {
+		return c.Status(studentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add student in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, added.ID, nil, added)
+	return c.Status(fiber.StatusCreated).JSON(added)
+}
+
+// @Summary Get student
+// @Description Fetch a student by id
+// @Tags student
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Student id"
+// @Success 200 {object} Student.StudentData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/student/{id} [get]
+func (m *StudentManager) GetStudent(c *fiber.Ctx) error {
+	student, err := m.dbManager.FetchStudentByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(studentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch student",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(student)
+}
+
+// @Summary Get student list
+// @Description Fetch filtered list of students
+// @Tags student
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param collage_unique_name query string false "College unique name"
+// @Param gender query string false "male, female or other"
+// @Param year query int false "Year of study"
+// @Param search query string false "Match on name or roll number"
+// @Param mark_as_deleted query boolean false "Include deleted items"
+// @Success 200 {object} []Student.StudentData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/student [get]
+func (m *StudentManager) GetStudents(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	year, _ := strconv.Atoi(c.Query("year", "0"))
+	filter := Student.StudentFilter{
+		Page:              page,
+		Limit:             limit,
+		CollageUniqueName: c.Query("collage_unique_name", ""),
+		Gender:            Student.Gender(c.Query("gender", "")),
+		Year:              year,
+		Search:            c.Query("search", ""),
+	}
+	markAsDeleted, err := strconv.ParseBool(c.Query("mark_as_deleted", "false"))
+	if err != nil {
+		markAsDeleted = false
+	}
+	filter.MarkAsDeleted = markAsDeleted
+
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	students, err := m.dbManager.FetchStudents(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch students",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(students)
+}
+
+// @Summary Update student
+// @Description Update only the fields present in the body
+// @Tags student
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Student id"
+// @Param student body Student.StudentUpdate true "Fields to update"
+// @Success 200 {object} Student.StudentData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/student/{id} [patch]
+func (m *StudentManager) UpdateStudent(c *fiber.Ctx) error {
+	var update Student.StudentUpdate
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse student",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate student",
+			"error":   err.Error(),
+		})
+	}
+	if update.CollageUniqueName != nil {
+		if err := m.checkCollege(*update.CollageUniqueName, c.Context()); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "failed to validate student college",
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	before, _ := m.dbManager.FetchStudentByID(c.Params("id"), c.Context())
+	student, err := m.dbManager.UpdateStudent(c.Params("id"), &update, c.Context())
+	if err != nil {
+		return c.Status(studentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update student in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, student.ID, before, student)
+	return c.JSON(student)
+}
+
+// @Summary Delete student
+// @Description Soft delete a student, it can still be listed with mark_as_deleted=true
+// @Tags student
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Student id"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/student/{id} [delete]
+func (m *StudentManager) DeleteStudent(c *fiber.Ctx) error {
+	if err := m.dbManager.DeleteStudent(c.Params("id"), c.Context()); err != nil {
+		return c.Status(studentErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to delete student in database",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, c.Params("id"), fiber.Map{"mark_as_deleted": false}, fiber.Map{"mark_as_deleted": true})
+	return c.JSON(fiber.Map{
+		"message": "student deleted",
+	})
+}
@@ -0,0 +1,52 @@
+package Student_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	"HostelApp/internal/testutil"
+	"net/http"
+	"testing"
+)
+
+func TestStudentFlowInMemory(t *testing.T) {
+	s := server.NewFiberServer(database.NewMemoryDBService(), PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	testutil.AddCollege(t, s, token, "college-a")
+
+	student := map[string]interface{}{
+		"name":                "Asha Rao",
+		"roll_number":         "CS-001",
+		"collage_unique_name": "college-a",
+		"gender":              "female",
+		"year":                2,
+		"guardians":           []map[string]string{{"name": "Ravi Rao", "relation": "father", "phone": "not-a-phone"}},
+		"id_proof_reference":  "aadhaar/1234",
+	}
+	if status, _ := testutil.DoJSON(t, s, "POST", "/admin/student", token, student); status != http.StatusBadRequest {
+		t.Fatalf("add student with invalid phone: expected status 400; got %d", status)
+	}
+	student["guardians"] = []map[string]string{{"name": "Ravi Rao", "relation": "father", "phone": "+919876543210"}}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/student", token, student)
+	if status != http.StatusCreated {
+		t.Fatalf("add student: expected status 201; got %d %v", status, body)
+	}
+	id := testutil.Path[string](t, body, "id")
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/student", token, student); status != http.StatusConflict {
+		t.Fatalf("add duplicate roll number: expected status 409; got %d", status)
+	}
+	student["collage_unique_name"] = "unknown"
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/student", token, student); status != http.StatusBadRequest {
+		t.Fatalf("add student to unknown college: expected status 400; got %d", status)
+	}
+
+	if status, body = testutil.DoJSON(t, s, "PATCH", "/admin/student/"+id, token, map[string]interface{}{"year": 3}); status != http.StatusOK || testutil.Path[float64](t, body, "year") != 3 {
+		t.Fatalf("update student: expected year 3; got %d %v", status, body)
+	}
+	if status, _ = testutil.DoJSON(t, s, "DELETE", "/admin/student/"+id, token, nil); status != http.StatusOK {
+		t.Fatalf("delete student: expected status OK; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "PATCH", "/admin/student/"+id, token, map[string]interface{}{"year": 4}); status != http.StatusNotFound {
+		t.Fatalf("update deleted student: expected status 404; got %d", status)
+	}
+}
@@ -0,0 +1,482 @@
+package Ticket
+
+import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/ValidatorSystem"
+	AdminDB "HostelApp/internal/database/Admin"
+	HostelDB "HostelApp/internal/database/Hostel"
+	StudentDB "HostelApp/internal/database/Student"
+	TicketDB "HostelApp/internal/database/Ticket"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/storageData/Ticket"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrRoomUnavailable     = errors.New("room is deleted")
+	ErrStudentUnavailable  = errors.New("student is deleted")
+	ErrAssigneeUnavailable = errors.New("assignee is not an admin user with write access")
+	ErrNotAssigned         = errors.New("ticket must be assigned before work can start")
+)
+
+type TicketManager struct {
+	dbManager TicketDB.ITicketDBService
+	hostelDB  HostelDB.IHostelDBService
+	studentDB StudentDB.IStudentDBService
+	loginDB   AdminDB.ILoginDBService
+}
+
+func NewTicketManager(dbManager TicketDB.ITicketDBService, hostelDB HostelDB.IHostelDBService, studentDB StudentDB.IStudentDBService, loginDB AdminDB.ILoginDBService) *TicketManager {
+	instance := &TicketManager{
+		dbManager: dbManager,
+		hostelDB:  hostelDB,
+		studentDB: studentDB,
+		loginDB:   loginDB,
+	}
+	return instance
+}
+
+func (m *TicketManager) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/admin/ticket", Method: internal.GET, Handler: m.GetTickets, Permission: internal.ReadPermission},
+		{Path: "/admin/ticket", Method: internal.POST, Handler: m.AddTicket, Permission: internal.WritePermission},
+		{Path: "/admin/ticket/:id", Method: internal.GET, Handler: m.GetTicket, Permission: internal.ReadPermission},
+		{Path: "/admin/ticket/:id/assign", Method: internal.POST, Handler: m.AssignTicket, Permission: internal.WritePermission},
+		{Path: "/admin/ticket/:id/status", Method: internal.POST, Handler: m.UpdateStatus, Permission: internal.WritePermission},
+		{Path: "/admin/ticket/:id/attachment", Method: internal.POST, Handler: m.AddAttachment, Permission: internal.WritePermission},
+		{Path: "/admin/ticket/:id/comment", Method: internal.GET, Handler: m.GetComments, Permission: internal.ReadPermission},
+		{Path: "/admin/ticket/:id/comment", Method: internal.POST, Handler: m.AddComment, Permission: internal.WritePermission},
+	}
+}
+
+func ticketErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, TicketDB.ErrTicketNotFound), errors.Is(err, HostelDB.ErrRoomNotFound), errors.Is(err, StudentDB.ErrStudentNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, TicketDB.ErrTicketStatusChanged):
+		return fiber.StatusConflict
+	case errors.Is(err, ErrRoomUnavailable), errors.Is(err, ErrStudentUnavailable), errors.Is(err, ErrAssigneeUnavailable),
+		errors.Is(err, ErrNotAssigned), errors.Is(err, TicketDB.ErrTooManyAttachments):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// actor is the admin user _id behind the request
+func actor(c *fiber.Ctx) string {
+	if claims := JWTManager.GetClaims(c); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
+
+func withOverdue(ticket *Ticket.TicketData, now time.Time) *Ticket.TicketData {
+	ticket.Overdue = IsOverdue(ticket, now)
+	return ticket
+}
+
+// @Summary Add ticket
+// @Description Open a complaint or maintenance ticket on a room, the SLA deadline follows the priority
+// @Tags ticket
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param ticket body Ticket.CreateTicketRequest true "Ticket to open"
+// @Success 201 {object} Ticket.TicketData
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/ticket [post]
+func (m *TicketManager) AddTicket(c *fiber.Ctx) error {
+	var request Ticket.CreateTicketRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse ticket",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate ticket",
+			"error":   err.Error(),
+		})
+	}
+
+	ticket, err := m.addTicket(&request, actor(c), c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add ticket",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, ticket.ID, nil, ticket)
+	return c.Status(fiber.StatusCreated).JSON(withOverdue(ticket, time.Now()))
+}
+
+func (m *TicketManager) addTicket(request *Ticket.CreateTicketRequest, by string, ctx context.Context) (*Ticket.TicketData, error) {
+	room, err := m.hostelDB.FetchRoomByID(request.RoomID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if room.MarkAsDeleted {
+		return nil, ErrRoomUnavailable
+	}
+	if request.StudentID != "" {
+		student, err := m.studentDB.FetchStudentByID(request.StudentID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if student.MarkAsDeleted {
+			return nil, ErrStudentUnavailable
+		}
+	}
+
+	now := time.Now()
+	for i := range request.Attachments {
+		request.Attachments[i].AddedBy = by
+		request.Attachments[i].AddedAt = now
+	}
+	ticket := &Ticket.TicketData{
+		Title:       request.Title,
+		Description: request.Description,
+		Category:    request.Category,
+		Priority:    request.Priority,
+		Status:      Ticket.Open,
+		HostelID:    room.HostelID,
+		RoomID:      room.ID,
+		StudentID:   request.StudentID,
+		ReportedBy:  by,
+		Attachments: request.Attachments,
+		History:     []Ticket.StatusChange{{To: Ticket.Open, By: by, At: now}},
+		DueAt:       DueAt(request.Priority, now),
+	}
+	return m.dbManager.CreateTicket(ticket, ctx)
+}
+
+// @Summary Get ticket list
+// @Description Fetch filtered list of tickets, closest deadline first
+// @Tags ticket
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Param status query string false "open, assigned, in_progress, resolved, closed or reopened"
+// @Param category query string false "electrical, plumbing, internet, furniture, cleaning or other"
+// @Param priority query string false "low, medium, high or urgent"
+// @Param hostel_id query string false "Hostel id"
+// @Param room_id query string false "Room id"
+// @Param assigned_to query string false "Admin user id"
+// @Param overdue query bool false "Only tickets past their SLA deadline"
+// @Success 200 {object} []Ticket.TicketData
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/ticket [get]
+func (m *TicketManager) GetTickets(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	filter := Ticket.TicketFilter{
+		Page:       page,
+		Limit:      limit,
+		Status:     Ticket.TicketStatus(c.Query("status", "")),
+		Category:   Ticket.Category(c.Query("category", "")),
+		Priority:   Ticket.Priority(c.Query("priority", "")),
+		HostelID:   c.Query("hostel_id", ""),
+		RoomID:     c.Query("room_id", ""),
+		AssignedTo: c.Query("assigned_to", ""),
+		Overdue:    c.QueryBool("overdue", false),
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&filter); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate filter",
+			"error":   err.Error(),
+		})
+	}
+
+	tickets, err := m.dbManager.FetchTickets(&filter, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch tickets",
+			"error":   err.Error(),
+		})
+	}
+	now := time.Now()
+	for i := range tickets {
+		withOverdue(&tickets[i], now)
+	}
+	return c.JSON(tickets)
+}
+
+// @Summary Get ticket
+// @Description Fetch a ticket with its attachments and status history
+// @Tags ticket
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Success 200 {object} Ticket.TicketData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/ticket/{id} [get]
+func (m *TicketManager) GetTicket(c *fiber.Ctx) error {
+	ticket, err := m.dbManager.FetchTicketByID(c.Params("id"), c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch ticket",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(withOverdue(ticket, time.Now()))
+}
+
+// @Summary Assign ticket
+// @Description Assign or reassign a ticket to an admin user with write access
+// @Tags ticket
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Param assign body Ticket.AssignRequest true "Staff to assign"
+// @Success 200 {object} Ticket.TicketData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/ticket/{id}/assign [post]
+func (m *TicketManager) AssignTicket(c *fiber.Ctx) error {
+	var request Ticket.AssignRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse assignment",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate assignment",
+			"error":   err.Error(),
+		})
+	}
+
+	before, _ := m.dbManager.FetchTicketByID(c.Params("id"), c.Context())
+	ticket, err := m.assign(c.Params("id"), &request, actor(c), c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to assign ticket",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, ticket.ID, before, ticket)
+	return c.JSON(withOverdue(ticket, time.Now()))
+}
+
+func (m *TicketManager) assign(_id string, request *Ticket.AssignRequest, by string, ctx context.Context) (*Ticket.TicketData, error) {
+	ticket, err := m.dbManager.FetchTicketByID(_id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTransition(ticket.Status, Ticket.Assigned); err != nil {
+		return nil, err
+	}
+	level, err := m.loginDB.FetchExcessLevel(request.AssignedTo, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAssigneeUnavailable, err)
+	}
+	if !internal.HasPermission(level, internal.WritePermission) {
+		return nil, ErrAssigneeUnavailable
+	}
+	change := &Ticket.TicketChange{
+		To:         Ticket.Assigned,
+		AssignedTo: &request.AssignedTo,
+		Entry: Ticket.StatusChange{
+			From:       ticket.Status,
+			To:         Ticket.Assigned,
+			By:         by,
+			AssignedTo: request.AssignedTo,
+			Note:       request.Note,
+			At:         time.Now(),
+		},
+	}
+	return m.dbManager.TransitionTicket(ticket.ID, ticket.Status, change, ctx)
+}
+
+// @Summary Update ticket status
+// @Description Move a ticket through in_progress, resolved, closed and reopened, reopening restart the SLA
+// @Tags ticket
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Param status body Ticket.StatusRequest true "New status"
+// @Success 200 {object} Ticket.TicketData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/ticket/{id}/status [post]
+func (m *TicketManager) UpdateStatus(c *fiber.Ctx) error {
+	var request Ticket.StatusRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse status",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate status",
+			"error":   err.Error(),
+		})
+	}
+
+	before, _ := m.dbManager.FetchTicketByID(c.Params("id"), c.Context())
+	ticket, err := m.updateStatus(c.Params("id"), &request, actor(c), c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to update ticket status",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, ticket.ID, before, ticket)
+	return c.JSON(withOverdue(ticket, time.Now()))
+}
+
+func (m *TicketManager) updateStatus(_id string, request *Ticket.StatusRequest, by string, ctx context.Context) (*Ticket.TicketData, error) {
+	ticket, err := m.dbManager.FetchTicketByID(_id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkTransition(ticket.Status, request.Status); err != nil {
+		return nil, err
+	}
+	if request.Status == Ticket.InProgress && ticket.AssignedTo == "" {
+		return nil, ErrNotAssigned
+	}
+
+	now := time.Now()
+	change := &Ticket.TicketChange{
+		To:    request.Status,
+		Entry: Ticket.StatusChange{From: ticket.Status, To: request.Status, By: by, Note: request.Note, At: now},
+	}
+	switch request.Status {
+	case Ticket.Resolved:
+		change.ResolvedAt = &now
+	case Ticket.Closed:
+		change.ClosedAt = &now
+	case Ticket.Reopened:
+		dueAt := DueAt(ticket.Priority, now)
+		change.DueAt = &dueAt
+	}
+	return m.dbManager.TransitionTicket(ticket.ID, ticket.Status, change, ctx)
+}
+
+// @Summary Add ticket attachment
+// @Description Attach a reference to a file stored elsewhere, at most 10 per ticket
+// @Tags ticket
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Param attachment body Ticket.Attachment true "Attachment"
+// @Success 200 {object} Ticket.TicketData
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Router /admin/ticket/{id}/attachment [post]
+func (m *TicketManager) AddAttachment(c *fiber.Ctx) error {
+	var attachment Ticket.Attachment
+	if err := c.BodyParser(&attachment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse attachment",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&attachment); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate attachment",
+			"error":   err.Error(),
+		})
+	}
+	attachment.AddedBy = actor(c)
+	attachment.AddedAt = time.Now()
+
+	before, _ := m.dbManager.FetchTicketByID(c.Params("id"), c.Context())
+	ticket, err := m.dbManager.AddAttachment(c.Params("id"), &attachment, c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add attachment",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, ticket.ID, before, ticket)
+	return c.JSON(withOverdue(ticket, time.Now()))
+}
+
+// @Summary Get ticket comments
+// @Description Fetch the comment thread of a ticket, oldest first
+// @Tags ticket
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Param page query string true "Page number"
+// @Param limit query string true "Items per page"
+// @Success 200 {object} []Ticket.CommentData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/ticket/{id}/comment [get]
+func (m *TicketManager) GetComments(c *fiber.Ctx) error {
+	page, _ := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	limit, _ := strconv.ParseInt(c.Query("limit", "10"), 10, 64)
+	if _, err := m.dbManager.FetchTicketByID(c.Params("id"), c.Context()); err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to fetch ticket",
+			"error":   err.Error(),
+		})
+	}
+
+	comments, err := m.dbManager.FetchComments(c.Params("id"), page, limit, c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to fetch comments",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(comments)
+}
+
+// @Summary Add ticket comment
+// @Description Add a comment to the thread of a ticket
+// @Tags ticket
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Ticket id"
+// @Param comment body Ticket.CommentRequest true "Comment"
+// @Success 201 {object} Ticket.CommentData
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/ticket/{id}/comment [post]
+func (m *TicketManager) AddComment(c *fiber.Ctx) error {
+	var request Ticket.CommentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to parse comment",
+			"error":   err.Error(),
+		})
+	}
+	if err := ValidatorSystem.GetValidator().IsValid(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to validate comment",
+			"error":   err.Error(),
+		})
+	}
+
+	comment := &Ticket.CommentData{TicketID: c.Params("id"), Author: actor(c), Body: request.Body}
+	comment, err := m.dbManager.AddComment(comment, c.Context())
+	if err != nil {
+		return c.Status(ticketErrorStatus(err)).JSON(fiber.Map{
+			"message": "failed to add comment",
+			"error":   err.Error(),
+		})
+	}
+	Audit.Record(c, comment.TicketID, nil, comment)
+	return c.Status(fiber.StatusCreated).JSON(comment)
+}
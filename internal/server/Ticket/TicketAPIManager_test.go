@@ -0,0 +1,98 @@
+package Ticket_test
+
+import (
+	"HostelApp/internal/PaymentProvider"
+	"HostelApp/internal/database"
+	"HostelApp/internal/server"
+	AdminData "HostelApp/internal/storageData/Admin"
+	"HostelApp/internal/storageData/Hostel"
+	"HostelApp/internal/storageData/Ticket"
+	"HostelApp/internal/testutil"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTicketFlowInMemory(t *testing.T) {
+	db := database.NewMemoryDBService()
+	s := server.NewFiberServer(db, PaymentProvider.NewFakeProvider("test_secret"))
+	token, _ := testutil.Login(t, s, "admin", "password@123")
+	ctx := context.Background()
+
+	hostel, err := db.HostelDB.AddHostel(&Hostel.HostelData{Name: "H", CollageUniqueName: "college-a", Gender: Hostel.Mixed}, ctx)
+	if err != nil {
+		t.Fatalf("add hostel: %v", err)
+	}
+	rooms, err := db.HostelDB.AddRooms(hostel.ID, &[]Hostel.RoomData{{Block: "A", RoomNumber: "A-001", RoomType: Hostel.Single, Capacity: 1, Gender: Hostel.AnyGender, MaintenanceStatus: Hostel.Available}}, ctx)
+	if err != nil || len(rooms) != 1 {
+		t.Fatalf("add room: expected 1 room; got %v %v", rooms, err)
+	}
+	adminID, err := db.AdminDB.LoginDB.IsValidCredentials(&AdminData.AdminLogin{Username: "admin", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("admin id: %v", err)
+	}
+	if err = db.AdminDB.LoginDB.UserCreate(&AdminData.AdminUserDetail{Username: "viewer", Email: "viewer@admin.com", Password: "password@123", ExcessLevel: AdminData.ReadOnly}, ctx); err != nil {
+		t.Fatalf("add viewer: %v", err)
+	}
+	viewerID, err := db.AdminDB.LoginDB.IsValidCredentials(&AdminData.AdminLogin{Username: "viewer", Password: "password@123"}, ctx)
+	if err != nil {
+		t.Fatalf("viewer id: %v", err)
+	}
+
+	request := map[string]interface{}{"title": "Fan broken", "category": "electrical", "priority": "high", "room_id": rooms[0].ID,
+		"attachments": []map[string]interface{}{{"name": "fan.jpg", "url": "https://files.example.com/fan.jpg", "content_type": "image/jpeg"}}}
+	status, body := testutil.DoJSON(t, s, "POST", "/admin/ticket", token, request)
+	if status != http.StatusCreated || body["status"] != "open" || body["hostel_id"] != hostel.ID || body["overdue"] != false {
+		t.Fatalf("add ticket: expected open ticket in the room hostel; got %d %v", status, body)
+	}
+	ticketID := testutil.Path[string](t, body, "id")
+	path := "/admin/ticket/" + ticketID
+
+	if status, _ = testutil.DoJSON(t, s, "POST", path+"/status", token, map[string]interface{}{"status": "in_progress"}); status != http.StatusConflict {
+		t.Fatalf("start unassigned ticket: expected status 409; got %d", status)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", path+"/assign", token, map[string]interface{}{"assigned_to": *viewerID}); status != http.StatusUnprocessableEntity {
+		t.Fatalf("assign to read only admin: expected status 422; got %d", status)
+	}
+	if status, body = testutil.DoJSON(t, s, "POST", path+"/assign", token, map[string]interface{}{"assigned_to": *adminID}); status != http.StatusOK || body["status"] != "assigned" {
+		t.Fatalf("assign: expected assigned; got %d %v", status, body)
+	}
+	for _, next := range []string{"in_progress", "resolved", "reopened", "in_progress", "resolved", "closed"} {
+		if status, body = testutil.DoJSON(t, s, "POST", path+"/status", token, map[string]interface{}{"status": next}); status != http.StatusOK || body["status"] != next {
+			t.Fatalf("move to %s: expected status OK; got %d %v", next, status, body)
+		}
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", path+"/status", token, map[string]interface{}{"status": "resolved"}); status != http.StatusConflict {
+		t.Fatalf("resolve closed ticket: expected status 409; got %d", status)
+	}
+	if history := testutil.Path[[]interface{}](t, body, "history"); len(history) != 8 {
+		t.Fatalf("history: expected 8 entries; got %d", len(history))
+	}
+
+	for _, text := range []string{"Electrician called", "Fan replaced"} {
+		if status, _ = testutil.DoJSON(t, s, "POST", path+"/comment", token, map[string]interface{}{"body": text}); status != http.StatusCreated {
+			t.Fatalf("comment: expected status 201; got %d", status)
+		}
+	}
+	comments, _ := db.TicketDB.FetchComments(ticketID, 1, 10, ctx)
+	if len(comments) != 2 || comments[0].Body != "Electrician called" || comments[0].Author != *adminID {
+		t.Fatalf("comments: expected the thread oldest first; got %v", comments)
+	}
+	if status, _ = testutil.DoJSON(t, s, "POST", "/admin/ticket/unknown/comment", token, map[string]interface{}{"body": "lost"}); status != http.StatusNotFound {
+		t.Fatalf("comment on unknown ticket: expected status 404; got %d", status)
+	}
+
+	late, err := db.TicketDB.CreateTicket(&Ticket.TicketData{Title: "Leak", Category: Ticket.Plumbing, Priority: Ticket.Urgent, Status: Ticket.Open,
+		HostelID: hostel.ID, RoomID: rooms[0].ID, DueAt: time.Now().Add(-time.Hour)}, ctx)
+	if err != nil {
+		t.Fatalf("add late ticket: %v", err)
+	}
+	overdue, _ := db.TicketDB.FetchTickets(&Ticket.TicketFilter{Page: 1, Limit: 10, Overdue: true}, ctx)
+	if len(overdue) != 1 || overdue[0].ID != late.ID {
+		t.Fatalf("overdue: expected only the late ticket; got %v", overdue)
+	}
+	if status, body = testutil.DoJSON(t, s, "GET", "/admin/ticket/"+late.ID, token, nil); status != http.StatusOK || body["overdue"] != true {
+		t.Fatalf("late ticket: expected overdue; got %d %v", status, body)
+	}
+}
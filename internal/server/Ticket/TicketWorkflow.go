@@ -0,0 +1,61 @@
+package Ticket
+
+import (
+	"HostelApp/internal/storageData/Ticket"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidTransition = errors.New("ticket status transition is not allowed")
+
+// transitions is the ticket state machine, assigned is only entered through an assignment
+var transitions = map[Ticket.TicketStatus][]Ticket.TicketStatus{
+	Ticket.Open:       {Ticket.Assigned},
+	Ticket.Assigned:   {Ticket.Assigned, Ticket.InProgress},
+	Ticket.InProgress: {Ticket.Assigned, Ticket.Resolved},
+	Ticket.Resolved:   {Ticket.Closed, Ticket.Reopened},
+	Ticket.Closed:     {Ticket.Reopened},
+	Ticket.Reopened:   {Ticket.Assigned, Ticket.InProgress},
+}
+
+// slaByPriority is the time allowed between opening, or reopening, and resolution
+var slaByPriority = map[Ticket.Priority]time.Duration{
+	Ticket.Urgent: 4 * time.Hour,
+	Ticket.High:   24 * time.Hour,
+	Ticket.Medium: 72 * time.Hour,
+	Ticket.Low:    7 * 24 * time.Hour,
+}
+
+// CanTransition tell if the state machine allow moving from one status to the other
+func CanTransition(from Ticket.TicketStatus, to Ticket.TicketStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func checkTransition(from Ticket.TicketStatus, to Ticket.TicketStatus) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// DueAt is the SLA deadline of a ticket opened at the given time
+func DueAt(priority Ticket.Priority, openedAt time.Time) time.Time {
+	return openedAt.Add(slaByPriority[priority])
+}
+
+// IsOverdue tell if the SLA of a ticket is breached at the given time, resolved
+// and closed tickets are never overdue
+func IsOverdue(ticket *Ticket.TicketData, now time.Time) bool {
+	switch ticket.Status {
+	case Ticket.Resolved, Ticket.Closed:
+		return false
+	default:
+		return now.After(ticket.DueAt)
+	}
+}
@@ -0,0 +1,49 @@
+package Ticket
+
+import (
+	"HostelApp/internal/storageData/Ticket"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTicketWorkflow(t *testing.T) {
+	allowed := [][2]Ticket.TicketStatus{
+		{Ticket.Open, Ticket.Assigned},
+		{Ticket.Assigned, Ticket.Assigned},
+		{Ticket.InProgress, Ticket.Resolved},
+		{Ticket.Resolved, Ticket.Reopened},
+		{Ticket.Closed, Ticket.Reopened},
+		{Ticket.Reopened, Ticket.InProgress},
+	}
+	for _, pair := range allowed {
+		if err := checkTransition(pair[0], pair[1]); err != nil {
+			t.Fatalf("%s to %s: expected allowed; got %v", pair[0], pair[1], err)
+		}
+	}
+	denied := [][2]Ticket.TicketStatus{
+		{Ticket.Open, Ticket.Resolved},
+		{Ticket.Open, Ticket.InProgress},
+		{Ticket.Resolved, Ticket.Open},
+		{Ticket.Closed, Ticket.Resolved},
+	}
+	for _, pair := range denied {
+		if err := checkTransition(pair[0], pair[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Fatalf("%s to %s: expected ErrInvalidTransition; got %v", pair[0], pair[1], err)
+		}
+	}
+
+	openedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	due := DueAt(Ticket.Urgent, openedAt)
+	if !due.Equal(openedAt.Add(4 * time.Hour)) {
+		t.Fatalf("urgent SLA: expected 4 hours; got %v", due.Sub(openedAt))
+	}
+	ticket := &Ticket.TicketData{Status: Ticket.InProgress, DueAt: due}
+	if IsOverdue(ticket, due) || !IsOverdue(ticket, due.Add(time.Second)) {
+		t.Fatalf("expected overdue only after the due date")
+	}
+	ticket.Status = Ticket.Resolved
+	if IsOverdue(ticket, due.Add(time.Hour)) {
+		t.Fatalf("expected a resolved ticket never overdue")
+	}
+}
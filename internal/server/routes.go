@@ -49,39 +49,55 @@ func (s *FiberServer) RegisterFiberRoutes(apiService internal.IAPIService) {
 	apiRoute := apiService.GetFiberRoutes()
 	for _, route := range *apiRoute {
 		slog.Info(LogColor.Orange(route.Method.String()) + ":" + LogColor.Pink(route.Path))
+		handlers := []fiber.Handler{route.Handler}
+		if route.Permission != internal.NoPermission {
+			handlers = append([]fiber.Handler{s.jwtManager.RequirePermission(route.Permission)}, handlers...)
+		}
+		protected := route.Protected || route.Permission != internal.NoPermission
+		if protected && s.auditor != nil && isMutating(route.Method) {
+			// after the jwt check so the actor is known, before the permission check so denials are recorded
+			handlers = append([]fiber.Handler{s.auditor.Middleware()}, handlers...)
+		}
+		if protected {
+			handlers = append([]fiber.Handler{s.jwtManager.Middleware()}, handlers...)
+		}
 		switch route.Method {
 		case internal.GET:
-			s.App.Get(route.Path, route.Handler)
+			s.App.Get(route.Path, handlers...)
 			break
 		case internal.POST:
-			s.App.Post(route.Path, route.Handler)
+			s.App.Post(route.Path, handlers...)
 			break
 		case internal.PUT:
-			s.App.Put(route.Path, route.Handler)
+			s.App.Put(route.Path, handlers...)
 			break
 		case internal.PATCH:
-			s.App.Patch(route.Path, route.Handler)
+			s.App.Patch(route.Path, handlers...)
 			break
 		case internal.DELETE:
-			s.App.Delete(route.Path)
+			s.App.Delete(route.Path, handlers...)
 			break
 		case internal.HEAD:
-			s.App.Head(route.Path, route.Handler)
+			s.App.Head(route.Path, handlers...)
 			break
 		case internal.OPTIONS:
-			s.App.Options(route.Path, route.Handler)
+			s.App.Options(route.Path, handlers...)
 			break
 		case internal.TRACE:
-			s.App.Trace(route.Path, route.Handler)
+			s.App.Trace(route.Path, handlers...)
 			break
 		case internal.CONNECT:
-			s.App.Connect(route.Path, route.Handler)
+			s.App.Connect(route.Path, handlers...)
 			break
 
 		}
 	}
 }
 
+func isMutating(method internal.HTTPMethod) bool {
+	return method == internal.POST || method == internal.PUT || method == internal.PATCH || method == internal.DELETE
+}
+
 func (s *FiberServer) HelloWorldHandler(c *fiber.Ctx) error {
 	resp := fiber.Map{
 		"message": "Hello everyone hostel server is live",
@@ -1,10 +1,14 @@
 package server
 
 import (
+	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/storageData/Admin"
 	"github.com/gofiber/fiber/v2"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestHandler(t *testing.T) {
@@ -28,7 +32,7 @@ func TestHandler(t *testing.T) {
 	if resp.StatusCode != http.StatusOK {
 		t.Errorf("expected status OK; got %v", resp.Status)
 	}
-	expected := "{\"message\":\"Hello World\"}"
+	expected := "{\"message\":\"Hello everyone hostel server is live\"}"
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		t.Fatalf("error reading response body. Err: %v", err)
@@ -37,3 +41,81 @@ func TestHandler(t *testing.T) {
 		t.Errorf("expected response body to be %v; got %v", expected, string(body))
 	}
 }
+
+type testAPIService struct{}
+
+func (t testAPIService) GetFiberRoutes() *[]internal.APIRoute {
+	return &[]internal.APIRoute{
+		{Path: "/public", Method: internal.GET, Handler: t.whoAmI},
+		{Path: "/protected", Method: internal.GET, Handler: t.whoAmI, Protected: true},
+		{Path: "/write", Method: internal.GET, Handler: t.whoAmI, Permission: internal.WritePermission},
+	}
+}
+
+func (t testAPIService) whoAmI(c *fiber.Ctx) error {
+	claims := JWTManager.GetClaims(c)
+	if claims == nil {
+		return c.SendString("anonymous")
+	}
+	return c.SendString(claims.Subject)
+}
+
+func TestRegisterFiberRoutesProtected(t *testing.T) {
+	keys, err := JWTManager.NewGeneratedKeySet(time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("error generating keys. Err: %v", err)
+	}
+	jwtManager := JWTManager.NewJWTManager(keys, 30, 30)
+	s := &FiberServer{App: fiber.New(), jwtManager: jwtManager}
+	s.RegisterFiberRoutes(testAPIService{})
+
+	token, err := jwtManager.GenerateToken("user-1", "session-1", []string{JWTManager.RoleAdmin}, Admin.ReadAndWrite)
+	if err != nil {
+		t.Fatalf("error generating token. Err: %v", err)
+	}
+	readOnlyToken, err := jwtManager.GenerateToken("user-2", "session-2", []string{JWTManager.RoleAdmin}, Admin.ReadOnly)
+	if err != nil {
+		t.Fatalf("error generating token. Err: %v", err)
+	}
+	refreshToken, err := jwtManager.GenerateRefreshToken("user-1", "session-1")
+	if err != nil {
+		t.Fatalf("error generating refresh token. Err: %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		authHeader string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/public", "", http.StatusOK, "anonymous"},
+		{"/protected", "", http.StatusUnauthorized, ""},
+		{"/protected", "Bearer " + refreshToken, http.StatusUnauthorized, ""},
+		{"/protected", "Bearer " + token, http.StatusOK, "user-1"},
+		{"/protected", "Bearer " + readOnlyToken, http.StatusOK, "user-2"},
+		{"/write", "", http.StatusUnauthorized, ""},
+		{"/write", "Bearer " + readOnlyToken, http.StatusForbidden, ""},
+		{"/write", "Bearer " + token, http.StatusOK, "user-1"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		if tt.authHeader != "" {
+			req.Header.Set("Authorization", tt.authHeader)
+		}
+		resp, err := s.App.Test(req)
+		if err != nil {
+			t.Fatalf("error making request to server. Err: %v", err)
+		}
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: expected status %d; got %d", tt.path, tt.wantStatus, resp.StatusCode)
+			continue
+		}
+		if tt.wantBody == "" {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != tt.wantBody {
+			t.Errorf("%s: expected body %v; got %v", tt.path, tt.wantBody, string(body))
+		}
+	}
+}
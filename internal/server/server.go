@@ -3,7 +3,20 @@ package server
 import (
 	"HostelApp/LogColor"
 	"HostelApp/internal"
+	"HostelApp/internal/JWTManager"
+	"HostelApp/internal/PaymentProvider"
 	"HostelApp/internal/server/Admin"
+	"HostelApp/internal/server/Admin/AuthenticationSystem"
+	"HostelApp/internal/server/Allocation"
+	"HostelApp/internal/server/Attendance"
+	"HostelApp/internal/server/Audit"
+	"HostelApp/internal/server/Finance"
+	"HostelApp/internal/server/Gate"
+	"HostelApp/internal/server/Hostel"
+	"HostelApp/internal/server/Mess"
+	"HostelApp/internal/server/Payment"
+	"HostelApp/internal/server/Student"
+	"HostelApp/internal/server/Ticket"
 	"github.com/gofiber/fiber/v2"
 	"log/slog"
 
@@ -13,24 +26,55 @@ import (
 type FiberServer struct {
 	*fiber.App
 	db          *database.DBService
+	jwtManager  *JWTManager.JWTManager
+	auditor     *Audit.AuditManager // record the mutating routes registered after it
 	apiServices []internal.IAPIService
 }
 
 func New() *FiberServer {
+	return NewFiberServer(database.NewDBService(), PaymentProvider.NewFromEnv())
+}
+
+// NewFiberServer register every module on db, the tests pass the memory
+// DBService and a fake payment provider
+func NewFiberServer(db *database.DBService, paymentProvider PaymentProvider.PaymentProvider) *FiberServer {
 	app := fiber.New(fiber.Config{
 		ServerHeader: "HostelAppServer",
 		AppName:      "HostelApp",
 	})
-	db := database.NewDBService()
+
+	jwtManager := JWTManager.NewJWTManager(JWTManager.KeySetFromEnv(), 30, 30)
 
 	server := &FiberServer{
-		App: app,
-		db:  db,
+		App:        app,
+		db:         db,
+		jwtManager: jwtManager,
+		auditor:    Audit.NewAuditManager(db.AuditDB),
 	}
 	server.registerDefaultFiberRoutes()
 	slog.Info(LogColor.Yellow("==FiberServer API List=="))
-	adminManager := Admin.NewAdminManager(db.AdminDB)
+	server.RegisterFiberRoutes(jwtManager)
+	server.RegisterFiberRoutes(server.auditor)
+	adminManager := Admin.NewAdminManager(db.AdminDB, jwtManager, AuthenticationSystem.LoginPolicyFromEnv())
 	server.RegisterFiberRoutes(adminManager)
+	studentManager := Student.NewStudentManager(db.StudentDB, db.AdminDB.CollegeDB)
+	server.RegisterFiberRoutes(studentManager)
+	hostelManager := Hostel.NewHostelManager(db.HostelDB, db.AdminDB.CollegeDB, db.AllocationDB)
+	server.RegisterFiberRoutes(hostelManager)
+	allocationManager := Allocation.NewAllocationManager(Allocation.NewAllocationEngine(db.AllocationDB, db.StudentDB, db.HostelDB), db.AllocationDB)
+	server.RegisterFiberRoutes(allocationManager)
+	financeManager := Finance.NewFinanceManager(db.FinanceDB, db.StudentDB, db.HostelDB, db.AllocationDB)
+	server.RegisterFiberRoutes(financeManager)
+	paymentManager := Payment.NewPaymentManager(db.PaymentDB, db.FinanceDB, db.StudentDB, financeManager, paymentProvider)
+	server.RegisterFiberRoutes(paymentManager)
+	ticketManager := Ticket.NewTicketManager(db.TicketDB, db.HostelDB, db.StudentDB, db.AdminDB.LoginDB)
+	server.RegisterFiberRoutes(ticketManager)
+	gateManager := Gate.NewGateManager(db.GateDB, db.StudentDB, Gate.CurfewFromEnv())
+	server.RegisterFiberRoutes(gateManager)
+	attendanceManager := Attendance.NewAttendanceManager(db.AttendanceDB, db.HostelDB, db.AllocationDB, db.GateDB)
+	server.RegisterFiberRoutes(attendanceManager)
+	messManager := Mess.NewMessManager(db.MessDB, db.HostelDB, db.StudentDB, db.AllocationDB, financeManager, Mess.CutoffFromEnv())
+	server.RegisterFiberRoutes(messManager)
 	slog.Info(LogColor.Green("==FiberServer API List=="))
 	return server
 }
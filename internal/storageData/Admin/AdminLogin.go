@@ -1,5 +1,7 @@
 package Admin
 
+import "time"
+
 type ExcessType int
 
 const (
@@ -9,14 +11,144 @@ const (
 )
 
 type AdminUserDetail struct {
-	Username     string     `json:"username" bson:"username" validate:"required,min=3,max=20"`
-	Email        string     `json:"email" bson:"email" validate:"required,email"`
-	Password     string     `json:"password" bson:"password" validate:"required,min=8,max=64"`
-	ExcessLevel  ExcessType `json:"excess_level" bson:"excess_level" validate:"required,oneof=0 1 2"`
-	RefreshToken string     `json:"refresh_token" bson:"refresh_token"`
+	Username    string     `json:"username" bson:"username" validate:"required,min=3,max=20"`
+	Email       string     `json:"email" bson:"email" validate:"required,email"`
+	Password    string     `json:"password" bson:"password" validate:"required,min=8,max=64"`
+	ExcessLevel ExcessType `json:"excess_level" bson:"excess_level" validate:"required,oneof=1 2 3"`
+}
+
+// AdminUser is an admin as shown by the API, it never carry the password hash or refresh tokens
+type AdminUser struct {
+	ID          string     `json:"id" bson:"_id"`
+	Username    string     `json:"username" bson:"username"`
+	Email       string     `json:"email" bson:"email"`
+	ExcessLevel ExcessType `json:"excess_level" bson:"excess_level"`
+	Disabled    bool       `json:"disabled" bson:"disabled"`
+	TwoFactor   bool       `json:"two_factor_enabled" bson:"totp_enabled"`
+	Created     time.Time  `json:"created" bson:"created"`
+}
+
+type AdminUserFilter struct {
+	Page        int64      `json:"page" bson:"page" validate:"required,min=1"`
+	Limit       int64      `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	ExcessLevel ExcessType `json:"excess_level" bson:"excess_level" validate:"omitempty,oneof=1 2 3"`
+}
+
+// AdminUserUpdate is the change a Full admin can make to another admin, nil
+// fields are left as they are. A disabled admin can not login anymore
+type AdminUserUpdate struct {
+	Email       *string     `json:"email" bson:"email,omitempty" validate:"omitnil,email"`
+	ExcessLevel *ExcessType `json:"excess_level" bson:"excess_level,omitempty" validate:"omitnil,oneof=1 2 3"`
+	Disabled    *bool       `json:"disabled" bson:"disabled,omitempty"`
+}
+
+func (u *AdminUserUpdate) IsEmpty() bool {
+	return u.Email == nil && u.ExcessLevel == nil && u.Disabled == nil
+}
+
+// DemotesFull tell if the update take away Full access from an admin that has it
+func (u *AdminUserUpdate) DemotesFull() bool {
+	return (u.ExcessLevel != nil && *u.ExcessLevel != Full) || (u.Disabled != nil && *u.Disabled)
+}
+
+// AdminProfileUpdate is the change an admin can make to their own account
+type AdminProfileUpdate struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordChange struct {
+	OldPassword string `json:"old_password" validate:"required,min=8,max=64"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=64,nefield=OldPassword"`
+}
+
+type PasswordReset struct {
+	NewPassword string `json:"new_password" validate:"required,min=8,max=64"`
 }
 
 type AdminLogin struct {
 	Username string `json:"username" bson:"username" validate:"required,min=3,max=20"`
 	Password string `json:"password" bson:"password" validate:"required,min=8,max=64"`
 }
+
+// TwoFactorState is the TOTP setup of an admin, PendingSecret wait for its first
+// code before it become Secret. RecoveryCodes are hashes of unused codes and
+// LastStep is the period of the last accepted code so it can not be replayed
+type TwoFactorState struct {
+	Enabled       bool     `bson:"totp_enabled"`
+	Secret        string   `bson:"totp_secret"`
+	PendingSecret string   `bson:"totp_pending_secret"`
+	LastStep      int64    `bson:"totp_last_step"`
+	RecoveryCodes []string `bson:"recovery_codes"`
+}
+
+// TwoFactorLogin finish a login with the challenge token returned by
+// /admin/login and a TOTP code or a recovery code
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,min=6,max=24"`
+}
+
+// TwoFactorEnrollment is used by an admin that must enable 2FA before its first
+// login, Code is only needed to confirm
+type TwoFactorEnrollment struct {
+	EnrollmentToken string `json:"enrollment_token" validate:"required"`
+	Code            string `json:"code" validate:"omitempty,len=6,numeric"`
+}
+
+type TOTPConfirm struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TwoFactorCode is a TOTP code or a recovery code
+type TwoFactorCode struct {
+	Code string `json:"code" validate:"required,min=6,max=24"`
+}
+
+type TwoFactorDisable struct {
+	Password string `json:"password" validate:"required,min=8,max=64"`
+	Code     string `json:"code" validate:"required,min=6,max=24"`
+}
+
+// AdminSession is one login of an admin on a device, ID is the sid carried by
+// its tokens. Only the SHA-256 of the current refresh token is stored
+type AdminSession struct {
+	ID          string    `json:"id" bson:"_id"`
+	AdminID     string    `json:"-" bson:"admin_id"`
+	Created     time.Time `json:"created" bson:"created"`
+	LastSeen    time.Time `json:"last_seen" bson:"last_seen"`
+	IP          string    `json:"ip" bson:"ip"`
+	UserAgent   string    `json:"user_agent" bson:"user_agent"`
+	RefreshHash string    `json:"-" bson:"refresh_hash"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+	Current     bool      `json:"current" bson:"-"` // set for the session of the request listing them
+}
+
+// SessionRotation replace the refresh token of a session on refresh, OldHash
+// must still be the stored one
+type SessionRotation struct {
+	OldHash   string
+	NewHash   string
+	IP        string
+	Now       time.Time
+	ExpiresAt time.Time
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" bson:"refresh_token" validate:"required"`
+}
+
+// LoginAttempt count the failed logins of a username or an IP, Key is made by
+// UserAttemptKey or IPAttemptKey
+type LoginAttempt struct {
+	Key         string    `json:"key" bson:"_id"`
+	Failures    int       `json:"failures" bson:"failures"`
+	LastFailure time.Time `json:"last_failure" bson:"last_failure"`
+}
+
+func UserAttemptKey(username string) string {
+	return "user:" + username
+}
+
+func IPAttemptKey(ip string) string {
+	return "ip:" + ip
+}
@@ -10,13 +10,73 @@ type CollegeData struct {
 	MarkAsDeleted     bool   `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
 }
 
+// ImportMode decide what happen to the valid rows of a college import when other
+// rows are invalid or already exist
+type ImportMode string
+
+const (
+	AllOrNothing ImportMode = "all_or_nothing" // insert every row or none of them
+	Partial      ImportMode = "partial"        // insert the valid rows and report the others
+)
+
+type ImportRowStatus string
+
+const (
+	RowInserted  ImportRowStatus = "inserted"
+	RowDuplicate ImportRowStatus = "duplicate"
+	RowInvalid   ImportRowStatus = "invalid"
+	RowSkipped   ImportRowStatus = "skipped" // valid but not inserted because the batch was rejected
+)
+
+// CollegeImportRow is the outcome of one row, Row count from 1 in request order
+type CollegeImportRow struct {
+	Row               int             `json:"row"`
+	CollageUniqueName string          `json:"collage_unique_name"`
+	Status            ImportRowStatus `json:"status"`
+	Reason            string          `json:"reason,omitempty"`
+}
+
+type CollegeImportReport struct {
+	Mode      ImportMode         `json:"mode"`
+	Inserted  int                `json:"inserted"`
+	Duplicate int                `json:"duplicate"`
+	Invalid   int                `json:"invalid"`
+	Skipped   int                `json:"skipped"`
+	Rows      []CollegeImportRow `json:"rows"`
+}
+
 type CollegeNameData struct {
 	CollageUniqueName string `json:"collage_unique_name" bson:"collage_unique_name" validate:"required,min=3,max=20"`
 }
 
+// CollegeSortFields are the fields a college list can be sorted on, the first is the default
+var CollegeSortFields = []string{"collage_unique_name", "collage_name", "collage_address", "pin_code", "collage_strength"}
+
+// CollegeFilter select colleges for a list. Search match words of the name or
+// address. Cursor continue after the previous page and take precedence over Page
 type CollegeFilter struct {
 	Page          int64  `json:"page" bson:"page" validate:"required,min=1"`
-	Limit         int64  `json:"limit" bson:"limit" validate:"required,min=1,max=20"`
+	Limit         int64  `json:"limit" bson:"limit" validate:"required,min=1,max=100"`
 	PinCode       string `json:"pin_code" bson:"pin_code"`
 	MarkAsDeleted bool   `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+	Search        string `json:"search" bson:"search" validate:"max=100"`
+	MinStrength   *int64 `json:"min_strength" bson:"min_strength" validate:"omitnil,min=0"`
+	MaxStrength   *int64 `json:"max_strength" bson:"max_strength" validate:"omitnil,min=0"`
+	Sort          string `json:"sort" bson:"sort"` // field of CollegeSortFields, prefixed by - for descending
+	Cursor        string `json:"cursor" bson:"cursor"`
+}
+
+// CollegeUpdate is a partial update of a college, only the fields present in
+// the body are changed. The unique name is the key and can not be changed
+type CollegeUpdate struct {
+	CollageName     *string `json:"collage_name" bson:"collage_name,omitempty" validate:"omitnil,min=3,max=20"`
+	CollageAddress  *string `json:"collage_address" bson:"collage_address,omitempty" validate:"omitnil,min=3,max=20"`
+	PinCode         *string `json:"pin_code" bson:"pin_code,omitempty" validate:"omitnil,min=3,max=20"`
+	CollageIcon     *string `json:"collage_icon" bson:"collage_icon,omitempty" validate:"omitnil,min=3,max=20"`
+	CollageStrength *int64  `json:"collage_strength" bson:"collage_strength,omitempty" validate:"omitnil,min=1,max=20"`
+}
+
+// IsEmpty is true when the update does not change any field
+func (u *CollegeUpdate) IsEmpty() bool {
+	return u.CollageName == nil && u.CollageAddress == nil && u.PinCode == nil && u.CollageIcon == nil && u.CollageStrength == nil
 }
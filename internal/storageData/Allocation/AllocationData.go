@@ -0,0 +1,123 @@
+package Allocation
+
+import (
+	"HostelApp/internal/storageData/Hostel"
+	"time"
+)
+
+type AllocationStatus string
+
+const (
+	Active      AllocationStatus = "active"
+	Transferred AllocationStatus = "transferred"
+	Vacated     AllocationStatus = "vacated"
+)
+
+// AllocationData is one stay of a student on one bed, the history of a
+// student is the list of its allocations, at most one of them is active
+type AllocationData struct {
+	ID                string           `json:"id" bson:"_id"`
+	StudentID         string           `json:"student_id" bson:"student_id"`
+	CollageUniqueName string           `json:"collage_unique_name" bson:"collage_unique_name"`
+	HostelID          string           `json:"hostel_id" bson:"hostel_id"`
+	RoomID            string           `json:"room_id" bson:"room_id"`
+	BedNumber         int              `json:"bed_number" bson:"bed_number"`
+	Status            AllocationStatus `json:"status" bson:"status"`
+	AllocatedAt       time.Time        `json:"allocated_at" bson:"allocated_at"`
+	EndedAt           *time.Time       `json:"ended_at,omitempty" bson:"ended_at,omitempty"`
+	EndReason         string           `json:"end_reason,omitempty" bson:"end_reason,omitempty"`
+	PreviousID        string           `json:"previous_id,omitempty" bson:"previous_id,omitempty"` // allocation this one was transferred from
+}
+
+type AllocationFilter struct {
+	Page      int64            `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64            `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID string           `json:"student_id" bson:"student_id"`
+	HostelID  string           `json:"hostel_id" bson:"hostel_id"`
+	RoomID    string           `json:"room_id" bson:"room_id"`
+	Status    AllocationStatus `json:"status" bson:"status" validate:"omitempty,oneof=active transferred vacated"`
+}
+
+type AllocateRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	RoomID    string `json:"room_id" validate:"required"`
+	BedNumber int    `json:"bed_number" validate:"min=0,max=20"` // 0 pick the first free bed
+}
+
+type TransferRequest struct {
+	RoomID    string `json:"room_id" validate:"required"`
+	BedNumber int    `json:"bed_number" validate:"min=0,max=20"` // 0 pick the first free bed
+	Reason    string `json:"reason" validate:"max=200"`
+}
+
+type VacateRequest struct {
+	Reason string `json:"reason" validate:"max=200"`
+}
+
+type WaitlistStatus string
+
+const (
+	Waiting   WaitlistStatus = "waiting"
+	Promoted  WaitlistStatus = "promoted"
+	Cancelled WaitlistStatus = "cancelled"
+)
+
+// WaitlistEntry queue a student on a hostel until a matching bed is freed
+type WaitlistEntry struct {
+	ID           string          `json:"id" bson:"_id"`
+	HostelID     string          `json:"hostel_id" bson:"hostel_id" validate:"required"`
+	StudentID    string          `json:"student_id" bson:"student_id" validate:"required"`
+	RoomType     Hostel.RoomType `json:"room_type,omitempty" bson:"room_type,omitempty" validate:"omitempty,oneof=single double dorm"`
+	Status       WaitlistStatus  `json:"status" bson:"status"`
+	AllocationID string          `json:"allocation_id,omitempty" bson:"allocation_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+type BedStatus struct {
+	BedNumber    int    `json:"bed_number"`
+	Occupied     bool   `json:"occupied"`
+	StudentID    string `json:"student_id,omitempty"`
+	AllocationID string `json:"allocation_id,omitempty"`
+}
+
+// StudentPreference is one student of a bulk allocation, preferences are best
+// effort, a student is still placed when no bed matches all of them
+type StudentPreference struct {
+	StudentID  string          `json:"student_id" validate:"required"`
+	RoomType   Hostel.RoomType `json:"room_type,omitempty" validate:"omitempty,oneof=single double dorm"`
+	Floor      *int            `json:"floor,omitempty" validate:"omitempty,min=0,max=50"`
+	Roommates  []string        `json:"roommates,omitempty" validate:"max=5"`             // student ids, only mutual requests are honoured
+	DistanceKm int             `json:"distance_km,omitempty" validate:"min=0,max=20000"` // home distance, break ties between students of the same year
+}
+
+type BulkAllocationRequest struct {
+	CollageUniqueName string              `json:"collage_unique_name" validate:"required,min=3,max=20"`
+	DryRun            bool                `json:"dry_run"`
+	Students          []StudentPreference `json:"students" validate:"required,min=1,max=1000,dive"`
+}
+
+type PlannedAllocation struct {
+	StudentID          string   `json:"student_id"`
+	HostelID           string   `json:"hostel_id"`
+	RoomID             string   `json:"room_id"`
+	RoomNumber         string   `json:"room_number"`
+	BedNumber          int      `json:"bed_number"`
+	MatchedPreferences []string `json:"matched_preferences"`
+}
+
+type BulkConflict struct {
+	StudentID string `json:"student_id"`
+	Reason    string `json:"reason"`
+}
+
+// BulkAllocationPlan is the result of a bulk allocation, Conflicts are the
+// students left without a bed and Warnings the preferences that were not met
+type BulkAllocationPlan struct {
+	CollageUniqueName string              `json:"collage_unique_name"`
+	DryRun            bool                `json:"dry_run"`
+	Committed         bool                `json:"committed"`
+	Assignments       []PlannedAllocation `json:"assignments"`
+	Conflicts         []BulkConflict      `json:"conflicts"`
+	Warnings          []BulkConflict      `json:"warnings"`
+}
@@ -0,0 +1,109 @@
+package Attendance
+
+import "time"
+
+// DateLayout is the format of roll-call dates, one roll-call per hostel and day
+const DateLayout = "2006-01-02"
+
+type Mark string
+
+const (
+	Present Mark = "present"
+	Absent  Mark = "absent"
+	OnLeave Mark = "on_leave"
+)
+
+// AttendanceRecord is the mark of one student at the roll-call of one day,
+// (student_id, date) is unique so marking again overwrite the previous mark
+type AttendanceRecord struct {
+	ID                string    `json:"id" bson:"_id"`
+	StudentID         string    `json:"student_id" bson:"student_id"`
+	CollageUniqueName string    `json:"collage_unique_name" bson:"collage_unique_name"`
+	HostelID          string    `json:"hostel_id" bson:"hostel_id"`
+	RoomID            string    `json:"room_id" bson:"room_id"`
+	Date              string    `json:"date" bson:"date"`
+	Mark              Mark      `json:"mark" bson:"mark"`
+	ApprovalRef       string    `json:"approval_ref,omitempty" bson:"approval_ref,omitempty"` // gate pass approving the leave
+	MarkedBy          string    `json:"marked_by" bson:"marked_by"`
+	CreatedAt         time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type RollCallEntry struct {
+	StudentID   string `json:"student_id" validate:"required"`
+	Mark        Mark   `json:"mark" validate:"required,oneof=present absent on_leave"`
+	ApprovalRef string `json:"approval_ref" validate:"required_if=Mark on_leave"`
+}
+
+// RollCallRequest carry the marks of a floor or a whole hostel for one day
+type RollCallRequest struct {
+	HostelID string          `json:"hostel_id" validate:"required"`
+	Date     string          `json:"date" validate:"required,datetime=2006-01-02"`
+	Entries  []RollCallEntry `json:"entries" validate:"required,min=1,max=500,dive"`
+}
+
+type RollCallRejection struct {
+	StudentID string `json:"student_id"`
+	Reason    string `json:"reason"`
+}
+
+type RollCallResult struct {
+	HostelID string             `json:"hostel_id"`
+	Date     string             `json:"date"`
+	Recorded []AttendanceRecord `json:"recorded"`
+}
+
+type AttendanceFilter struct {
+	Page      int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64  `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	HostelID  string `json:"hostel_id" bson:"hostel_id"`
+	RoomID    string `json:"room_id" bson:"room_id"`
+	StudentID string `json:"student_id" bson:"student_id"`
+	Date      string `json:"date" bson:"date" validate:"omitempty,datetime=2006-01-02"`
+	Mark      Mark   `json:"mark" bson:"mark" validate:"omitempty,oneof=present absent on_leave"`
+}
+
+type ReportRequest struct {
+	HostelID string `json:"hostel_id" validate:"required"`
+	From     string `json:"from" validate:"required,datetime=2006-01-02"`
+	To       string `json:"to" validate:"required,datetime=2006-01-02"`
+}
+
+type MarkCount struct {
+	Present int `json:"present"`
+	Absent  int `json:"absent"`
+	OnLeave int `json:"on_leave"`
+}
+
+// StudentAttendance summarize a student over a date range, an absentee streak
+// count consecutive days so a day without a roll-call entry break it
+type StudentAttendance struct {
+	StudentID string `json:"student_id"`
+	RoomID    string `json:"room_id"`
+	MarkCount
+	LongestAbsentStreak int    `json:"longest_absent_streak"`
+	CurrentAbsentStreak int    `json:"current_absent_streak"` // streak still running at the last entry
+	LastAbsentDate      string `json:"last_absent_date,omitempty"`
+}
+
+// RoomAttendance summarize a room over a date range, Rate is present over
+// present plus absent and stay null when every mark is on leave
+type RoomAttendance struct {
+	RoomID string `json:"room_id"`
+	MarkCount
+	Rate *float64 `json:"rate"`
+}
+
+type StudentReport struct {
+	HostelID string              `json:"hostel_id"`
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Students []StudentAttendance `json:"students"`
+}
+
+type RoomReport struct {
+	HostelID string           `json:"hostel_id"`
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Rooms    []RoomAttendance `json:"rooms"`
+}
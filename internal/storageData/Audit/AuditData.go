@@ -0,0 +1,40 @@
+package Audit
+
+import "time"
+
+// DateLayout is the format of the from and to dates of an audit query
+const DateLayout = "2006-01-02"
+
+// FieldChange is one top level field that differ between the entity before and after a request
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditEntry record one mutating request of an admin. Action is the method and
+// route, EntityID and Changes are only known when the handler report them
+type AuditEntry struct {
+	ID        string        `json:"id" bson:"_id"`
+	ActorID   string        `json:"actor_id" bson:"actor_id"`
+	Action    string        `json:"action" bson:"action"`
+	Path      string        `json:"path" bson:"path"`
+	Entity    string        `json:"entity" bson:"entity"`
+	EntityID  string        `json:"entity_id,omitempty" bson:"entity_id,omitempty"`
+	Changes   []FieldChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Status    int           `json:"status" bson:"status"`
+	IP        string        `json:"ip" bson:"ip"`
+	UserAgent string        `json:"user_agent" bson:"user_agent"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// AuditFilter select entries by actor, entity and the days from and to included
+type AuditFilter struct {
+	Page     int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit    int64  `json:"limit" bson:"limit" validate:"required,min=1,max=100"`
+	ActorID  string `json:"actor_id" bson:"actor_id"`
+	Entity   string `json:"entity" bson:"entity"`
+	EntityID string `json:"entity_id" bson:"entity_id"`
+	From     string `json:"from" bson:"from" validate:"omitempty,datetime=2006-01-02"`
+	To       string `json:"to" bson:"to" validate:"omitempty,datetime=2006-01-02"`
+}
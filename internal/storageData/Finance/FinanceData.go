@@ -0,0 +1,184 @@
+package Finance
+
+import "time"
+
+// Every amount is an integer count of the currency minor unit (paise for INR)
+
+// DefaultCurrency is the currency of the ledger, fee structures must use it so
+// the balance of an account is a sum of amounts in a single currency
+const DefaultCurrency = "INR"
+
+type FeeKind string
+
+const (
+	Rent    FeeKind = "rent"
+	Mess    FeeKind = "mess"
+	Deposit FeeKind = "deposit"
+	Fine    FeeKind = "fine"
+)
+
+type FeeLine struct {
+	Kind        FeeKind `json:"kind" bson:"kind" validate:"required,oneof=rent mess deposit fine"`
+	Description string  `json:"description" bson:"description" validate:"required,min=3,max=100"`
+	Amount      int64   `json:"amount" bson:"amount" validate:"required,min=1"`
+}
+
+// FeeStructure is the price list of a hostel from EffectiveFrom on, the newest
+// structure already effective at the start of a billing period is used.
+// Rent and mess are billed every period, the deposit on the first invoice of a
+// student in the hostel and fines only on demand
+type FeeStructure struct {
+	ID            string    `json:"id" bson:"_id"`
+	HostelID      string    `json:"hostel_id" bson:"hostel_id" validate:"required"`
+	Currency      string    `json:"currency" bson:"currency" validate:"required,len=3,uppercase"`
+	Lines         []FeeLine `json:"lines" bson:"lines" validate:"required,min=1,max=20,dive"`
+	EffectiveFrom time.Time `json:"effective_from" bson:"effective_from" validate:"required"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+}
+
+type InvoiceStatus string
+
+const (
+	Issued InvoiceStatus = "issued"
+	Void   InvoiceStatus = "void"
+)
+
+// Invoice bill one student for one billing period (YYYY-MM) of one hostel
+type Invoice struct {
+	ID                string        `json:"id" bson:"_id"`
+	StudentID         string        `json:"student_id" bson:"student_id"`
+	CollageUniqueName string        `json:"collage_unique_name" bson:"collage_unique_name"`
+	HostelID          string        `json:"hostel_id" bson:"hostel_id"`
+	AllocationID      string        `json:"allocation_id" bson:"allocation_id"`
+	FeeStructureID    string        `json:"fee_structure_id" bson:"fee_structure_id"`
+	Period            string        `json:"period" bson:"period"`
+	Currency          string        `json:"currency" bson:"currency"`
+	Lines             []FeeLine     `json:"lines" bson:"lines"`
+	Total             int64         `json:"total" bson:"total"`
+	Status            InvoiceStatus `json:"status" bson:"status"`
+	IssuedAt          time.Time     `json:"issued_at" bson:"issued_at"`
+	DueDate           time.Time     `json:"due_date" bson:"due_date"`
+}
+
+// InvoiceSummary add what was paid on an invoice to the invoice itself
+type InvoiceSummary struct {
+	Invoice
+	Paid        int64 `json:"paid"`
+	Outstanding int64 `json:"outstanding"`
+}
+
+type InvoiceFilter struct {
+	Page              int64         `json:"page" bson:"page" validate:"required,min=1"`
+	Limit             int64         `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID         string        `json:"student_id" bson:"student_id"`
+	HostelID          string        `json:"hostel_id" bson:"hostel_id"`
+	AllocationID      string        `json:"allocation_id" bson:"allocation_id"`
+	CollageUniqueName string        `json:"collage_unique_name" bson:"collage_unique_name"`
+	Period            string        `json:"period" bson:"period" validate:"omitempty,datetime=2006-01"`
+	Status            InvoiceStatus `json:"status" bson:"status" validate:"omitempty,oneof=issued void"`
+}
+
+type GenerateInvoicesRequest struct {
+	HostelID string    `json:"hostel_id" validate:"required"`
+	Period   string    `json:"period" validate:"required,datetime=2006-01"`
+	DueDate  time.Time `json:"due_date" validate:"required"`
+}
+
+type InvoiceSkip struct {
+	StudentID string `json:"student_id"`
+	Reason    string `json:"reason"`
+}
+
+type GenerateInvoicesResult struct {
+	Created []Invoice     `json:"created"`
+	Skipped []InvoiceSkip `json:"skipped"`
+}
+
+// Ledger accounts, the receivable account is suffixed with the student id
+const (
+	ReceivableAccount = "receivable:"
+	CashAccount       = "cash"
+	DepositAccount    = "deposits_held"
+	RevenueAccount    = "revenue:"
+	AdjustmentAccount = "adjustments"
+)
+
+func StudentAccount(studentID string) string {
+	return ReceivableAccount + studentID
+}
+
+type TransactionKind string
+
+const (
+	InvoiceCharge TransactionKind = "invoice"
+	InvoiceVoid   TransactionKind = "invoice_void"
+	FineCharge    TransactionKind = "fine"
+	Payment       TransactionKind = "payment"
+	Refund        TransactionKind = "refund"
+	Adjustment    TransactionKind = "adjustment"
+)
+
+type Posting struct {
+	Account string `json:"account" bson:"account"`
+	Debit   int64  `json:"debit" bson:"debit"`
+	Credit  int64  `json:"credit" bson:"credit"`
+}
+
+// LedgerTransaction is stored as one document so its postings, which must
+// balance, are written atomically. A positive receivable balance is owed by the student
+type LedgerTransaction struct {
+	ID                string          `json:"id" bson:"_id"`
+	Kind              TransactionKind `json:"kind" bson:"kind"`
+	StudentID         string          `json:"student_id" bson:"student_id"`
+	CollageUniqueName string          `json:"collage_unique_name" bson:"collage_unique_name"`
+	HostelID          string          `json:"hostel_id,omitempty" bson:"hostel_id,omitempty"`
+	InvoiceID         string          `json:"invoice_id,omitempty" bson:"invoice_id,omitempty"`
+	Currency          string          `json:"currency" bson:"currency"`
+	Amount            int64           `json:"amount" bson:"amount"`
+	Reference         string          `json:"reference,omitempty" bson:"reference,omitempty"` // external receipt or transfer id, unique when set
+	Note              string          `json:"note,omitempty" bson:"note,omitempty"`
+	Postings          []Posting       `json:"postings" bson:"postings"`
+	CreatedAt         time.Time       `json:"created_at" bson:"created_at"`
+}
+
+type LedgerFilter struct {
+	Page      int64           `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64           `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID string          `json:"student_id" bson:"student_id"`
+	InvoiceID string          `json:"invoice_id" bson:"invoice_id"`
+	Kind      TransactionKind `json:"kind" bson:"kind" validate:"omitempty,oneof=invoice invoice_void fine payment refund adjustment"`
+}
+
+// PaymentRequest is used for payments and refunds
+type PaymentRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	InvoiceID string `json:"invoice_id"`
+	Amount    int64  `json:"amount" validate:"required,min=1"`
+	Reference string `json:"reference" validate:"max=100"`
+	Note      string `json:"note" validate:"max=200"`
+}
+
+// AdjustmentRequest correct a student balance, a negative amount reduce what the
+// student owe. With the deposits_held account it release a held deposit so it can be refunded
+type AdjustmentRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	Amount    int64  `json:"amount" validate:"required"`
+	Account   string `json:"account" validate:"omitempty,oneof=adjustments deposits_held"`
+	Note      string `json:"note" validate:"required,min=3,max=200"`
+}
+
+type FineRequest struct {
+	StudentID   string `json:"student_id" validate:"required"`
+	Description string `json:"description" validate:"required,min=3,max=100"`
+	Amount      int64  `json:"amount" validate:"required,min=1"`
+}
+
+type StudentBalance struct {
+	StudentID         string `json:"student_id"`
+	CollageUniqueName string `json:"collage_unique_name"`
+	Balance           int64  `json:"balance"` // positive when the student owe money
+}
+
+type OutstandingFilter struct {
+	CollageUniqueName string `json:"collage_unique_name" bson:"collage_unique_name"`
+}
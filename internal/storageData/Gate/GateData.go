@@ -0,0 +1,114 @@
+package Gate
+
+import "time"
+
+// VisitorData is one visit logged at the gate, CheckOutAt stay nil while the visitor is inside
+type VisitorData struct {
+	ID                string     `json:"id" bson:"_id"`
+	VisitorName       string     `json:"visitor_name" bson:"visitor_name"`
+	VisitorPhone      string     `json:"visitor_phone" bson:"visitor_phone"`
+	IDProofType       string     `json:"id_proof_type" bson:"id_proof_type"`
+	IDProofNumber     string     `json:"id_proof_number" bson:"id_proof_number"`
+	Relation          string     `json:"relation" bson:"relation"`
+	StudentID         string     `json:"student_id" bson:"student_id"`
+	CollageUniqueName string     `json:"collage_unique_name" bson:"collage_unique_name"`
+	Purpose           string     `json:"purpose" bson:"purpose"`
+	LoggedBy          string     `json:"logged_by" bson:"logged_by"`
+	CheckInAt         time.Time  `json:"check_in_at" bson:"check_in_at"`
+	CheckOutAt        *time.Time `json:"check_out_at,omitempty" bson:"check_out_at,omitempty"`
+}
+
+type VisitorRequest struct {
+	VisitorName   string `json:"visitor_name" validate:"required,min=2,max=100"`
+	VisitorPhone  string `json:"visitor_phone" validate:"required,phone"`
+	IDProofType   string `json:"id_proof_type" validate:"required,oneof=aadhaar passport driving_licence voter_id other"`
+	IDProofNumber string `json:"id_proof_number" validate:"required,min=4,max=30"`
+	Relation      string `json:"relation" validate:"max=50"`
+	StudentID     string `json:"student_id" validate:"required"`
+	Purpose       string `json:"purpose" validate:"required,min=3,max=200"`
+}
+
+type VisitorFilter struct {
+	Page      int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64  `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID string `json:"student_id" bson:"student_id"`
+	Inside    bool   `json:"inside" bson:"inside"` // only visitors not checked out yet
+}
+
+type PassKind string
+
+const (
+	Outing PassKind = "outing"
+	Leave  PassKind = "leave"
+)
+
+type PassStatus string
+
+const (
+	Pending   PassStatus = "pending"
+	Approved  PassStatus = "approved"
+	Rejected  PassStatus = "rejected"
+	Cancelled PassStatus = "cancelled"
+	Out       PassStatus = "out"
+	Returned  PassStatus = "returned"
+)
+
+// GatePassData is an outing or leave request of a student, Active is kept in
+// the document so a student can only hold one pending, approved or out pass
+type GatePassData struct {
+	ID                string     `json:"id" bson:"_id"`
+	StudentID         string     `json:"student_id" bson:"student_id"`
+	CollageUniqueName string     `json:"collage_unique_name" bson:"collage_unique_name"`
+	Kind              PassKind   `json:"kind" bson:"kind"`
+	Reason            string     `json:"reason" bson:"reason"`
+	Destination       string     `json:"destination" bson:"destination"`
+	LeaveAt           time.Time  `json:"leave_at" bson:"leave_at"`
+	ExpectedReturnAt  time.Time  `json:"expected_return_at" bson:"expected_return_at"`
+	Status            PassStatus `json:"status" bson:"status"`
+	Active            bool       `json:"-" bson:"active"`
+	RequestedBy       string     `json:"requested_by" bson:"requested_by"`
+	DecidedBy         string     `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	DecisionNote      string     `json:"decision_note,omitempty" bson:"decision_note,omitempty"`
+	DecidedAt         *time.Time `json:"decided_at,omitempty" bson:"decided_at,omitempty"`
+	CheckedOutAt      *time.Time `json:"checked_out_at,omitempty" bson:"checked_out_at,omitempty"`
+	ReturnBy          *time.Time `json:"return_by,omitempty" bson:"return_by,omitempty"` // curfew deadline fixed when the student goes out
+	ReturnedAt        *time.Time `json:"returned_at,omitempty" bson:"returned_at,omitempty"`
+	ReturnedLate      bool       `json:"returned_late" bson:"returned_late"`
+	CreatedAt         time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+type GatePassRequest struct {
+	StudentID        string    `json:"student_id" validate:"required"`
+	Kind             PassKind  `json:"kind" validate:"required,oneof=outing leave"`
+	Reason           string    `json:"reason" validate:"required,min=3,max=200"`
+	Destination      string    `json:"destination" validate:"required,min=2,max=200"`
+	LeaveAt          time.Time `json:"leave_at" validate:"required"`
+	ExpectedReturnAt time.Time `json:"expected_return_at" validate:"required,gtfield=LeaveAt"`
+}
+
+type DecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note" validate:"max=500"`
+}
+
+type GatePassFilter struct {
+	Page              int64      `json:"page" bson:"page" validate:"required,min=1"`
+	Limit             int64      `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID         string     `json:"student_id" bson:"student_id"`
+	CollageUniqueName string     `json:"collage_unique_name" bson:"collage_unique_name"`
+	Kind              PassKind   `json:"kind" bson:"kind" validate:"omitempty,oneof=outing leave"`
+	Status            PassStatus `json:"status" bson:"status" validate:"omitempty,oneof=pending approved rejected cancelled out returned"`
+}
+
+// GatePassChange is what a transition write on the pass, nil fields are left untouched
+type GatePassChange struct {
+	To           PassStatus
+	DecidedBy    string
+	DecisionNote string
+	DecidedAt    *time.Time
+	CheckedOutAt *time.Time
+	ReturnBy     *time.Time
+	ReturnedAt   *time.Time
+	ReturnedLate bool
+}
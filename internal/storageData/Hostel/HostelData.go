@@ -0,0 +1,105 @@
+package Hostel
+
+import "time"
+
+type GenderRestriction string
+
+const (
+	MaleOnly   GenderRestriction = "male"
+	FemaleOnly GenderRestriction = "female"
+	Mixed      GenderRestriction = "mixed" // hostel level only, rooms inside can be of any restriction
+	AnyGender  GenderRestriction = "any"   // room level only
+)
+
+type RoomType string
+
+const (
+	Single RoomType = "single"
+	Double RoomType = "double"
+	Dorm   RoomType = "dorm"
+)
+
+type MaintenanceStatus string
+
+const (
+	Available        MaintenanceStatus = "available"
+	UnderMaintenance MaintenanceStatus = "under_maintenance"
+	OutOfService     MaintenanceStatus = "out_of_service"
+)
+
+type BlockData struct {
+	Name   string `json:"name" bson:"name" validate:"required,min=1,max=20"`
+	Floors int    `json:"floors" bson:"floors" validate:"required,min=1,max=50"`
+}
+
+type HostelData struct {
+	ID                string            `json:"id" bson:"_id"`
+	Name              string            `json:"name" bson:"name" validate:"required,min=3,max=50"`
+	CollageUniqueName string            `json:"collage_unique_name" bson:"collage_unique_name" validate:"required,min=3,max=20"`
+	Gender            GenderRestriction `json:"gender" bson:"gender" validate:"required,oneof=male female mixed"`
+	Address           string            `json:"address" bson:"address" validate:"required,min=3,max=100"`
+	Blocks            []BlockData       `json:"blocks" bson:"blocks" validate:"required,min=1,dive"`
+	CreatedAt         time.Time         `json:"created_at" bson:"created_at"`
+	MarkAsDeleted     bool              `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
+
+type HostelFilter struct {
+	Page              int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit             int64  `json:"limit" bson:"limit" validate:"required,min=1,max=20"`
+	CollageUniqueName string `json:"collage_unique_name" bson:"collage_unique_name"`
+	MarkAsDeleted     bool   `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
+
+type RoomData struct {
+	ID                string            `json:"id" bson:"_id"`
+	HostelID          string            `json:"hostel_id" bson:"hostel_id"`
+	Block             string            `json:"block" bson:"block" validate:"required,min=1,max=20"`
+	Floor             int               `json:"floor" bson:"floor" validate:"min=0,max=50"`
+	RoomNumber        string            `json:"room_number" bson:"room_number" validate:"required,min=1,max=20"`
+	RoomType          RoomType          `json:"room_type" bson:"room_type" validate:"required,oneof=single double dorm"`
+	Capacity          int               `json:"capacity" bson:"capacity" validate:"required,min=1,max=20"`
+	Gender            GenderRestriction `json:"gender" bson:"gender" validate:"required,oneof=male female any"`
+	Amenities         []string          `json:"amenities" bson:"amenities" validate:"max=20,dive,min=2,max=30"`
+	MaintenanceStatus MaintenanceStatus `json:"maintenance_status" bson:"maintenance_status" validate:"required,oneof=available under_maintenance out_of_service"`
+	MarkAsDeleted     bool              `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
+
+// RoomUpdate only carry the fields to change, nil fields are left untouched
+type RoomUpdate struct {
+	RoomType          *RoomType          `json:"room_type,omitempty" validate:"omitempty,oneof=single double dorm"`
+	Capacity          *int               `json:"capacity,omitempty" validate:"omitempty,min=1,max=20"`
+	Gender            *GenderRestriction `json:"gender,omitempty" validate:"omitempty,oneof=male female any"`
+	Amenities         *[]string          `json:"amenities,omitempty" validate:"omitempty,max=20,dive,min=2,max=30"`
+	MaintenanceStatus *MaintenanceStatus `json:"maintenance_status,omitempty" validate:"omitempty,oneof=available under_maintenance out_of_service"`
+}
+
+type RoomFilter struct {
+	Page              int64             `json:"page" bson:"page" validate:"required,min=1"`
+	Limit             int64             `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	HostelID          string            `json:"hostel_id" bson:"hostel_id" validate:"required"`
+	Block             string            `json:"block" bson:"block"`
+	Floor             *int              `json:"floor" bson:"floor"`
+	RoomType          RoomType          `json:"room_type" bson:"room_type" validate:"omitempty,oneof=single double dorm"`
+	Gender            GenderRestriction `json:"gender" bson:"gender" validate:"omitempty,oneof=male female any"`
+	MaintenanceStatus MaintenanceStatus `json:"maintenance_status" bson:"maintenance_status" validate:"omitempty,oneof=available under_maintenance out_of_service"`
+	MarkAsDeleted     bool              `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
+
+type CapacitySummary struct {
+	Rooms    int `json:"rooms"`
+	Capacity int `json:"capacity"`
+	Occupied int `json:"occupied"` // beds of active allocations
+	Free     int `json:"free"`     // beds left in rooms with maintenance_status available
+}
+
+type OccupancySummary struct {
+	HostelID       string                                `json:"hostel_id"`
+	TotalRooms     int                                   `json:"total_rooms"`
+	TotalCapacity  int                                   `json:"total_capacity"`
+	UsableCapacity int                                   `json:"usable_capacity"` // capacity of rooms with maintenance_status available
+	OccupiedBeds   int                                   `json:"occupied_beds"`
+	FreeBeds       int                                   `json:"free_beds"` // beds left in rooms with maintenance_status available
+	ByRoomType     map[RoomType]CapacitySummary          `json:"by_room_type"`
+	ByGender       map[GenderRestriction]CapacitySummary `json:"by_gender"`
+	ByMaintenance  map[MaintenanceStatus]CapacitySummary `json:"by_maintenance"`
+}
@@ -0,0 +1,86 @@
+package Listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("cursor is invalid or was made for another sort")
+	ErrInvalidSort   = errors.New("sort field is not supported")
+)
+
+// Page is the envelope of listing endpoints, NextCursor is empty on the last page
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"` // items matching the filter over every page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageOf clamp the page to at least 1 and the limit to 1..maxLimit, an out of
+// range limit fall back to 10
+func PageOf(page int64, limit int64, maxLimit int64) (int64, int64) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxLimit {
+		limit = 10
+	}
+	return page, limit
+}
+
+// Sort is a field and a direction, written "field" or "-field" for descending
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort read a sort parameter against the sortable fields, the first field
+// is the default when the parameter is empty
+func ParseSort(value string, fields ...string) (Sort, error) {
+	if value == "" {
+		return Sort{Field: fields[0]}, nil
+	}
+	sort := Sort{Field: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+	for _, field := range fields {
+		if field == sort.Field {
+			return sort, nil
+		}
+	}
+	return Sort{}, ErrInvalidSort
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Cursor is the position after the last item of a page, the sort value of the
+// item and its unique key so items with the same value are not skipped
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Key   string `json:"k"`
+}
+
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor read a cursor made by EncodeCursor for the same sort
+func DecodeCursor(value string, sort Sort) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err = json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort.String() || cursor.Key == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
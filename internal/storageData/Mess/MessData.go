@@ -0,0 +1,131 @@
+package Mess
+
+import "time"
+
+// DateLayout is the format of meal dates and MonthLayout of rebate months
+const (
+	DateLayout  = "2006-01-02"
+	MonthLayout = "2006-01"
+)
+
+type Meal string
+
+const (
+	Breakfast Meal = "breakfast"
+	Lunch     Meal = "lunch"
+	Snacks    Meal = "snacks"
+	Dinner    Meal = "dinner"
+)
+
+// Meals is the order meals are served in a day
+var Meals = []Meal{Breakfast, Lunch, Snacks, Dinner}
+
+type MealMenu struct {
+	Meal  Meal     `json:"meal" bson:"meal" validate:"required,oneof=breakfast lunch snacks dinner"`
+	Items []string `json:"items" bson:"items" validate:"required,min=1,max=20,dive,min=2,max=60"`
+}
+
+// DayMenu is the menu of one day of the week, Weekday 0 is Sunday like time.Weekday
+type DayMenu struct {
+	Weekday time.Weekday `json:"weekday" bson:"weekday" validate:"min=0,max=6"`
+	Meals   []MealMenu   `json:"meals" bson:"meals" validate:"required,min=1,max=4,dive"`
+}
+
+// MenuData is the menu of a hostel for the week starting WeekStart, a Monday
+type MenuData struct {
+	ID        string    `json:"id" bson:"_id"`
+	HostelID  string    `json:"hostel_id" bson:"hostel_id"`
+	WeekStart string    `json:"week_start" bson:"week_start"`
+	Days      []DayMenu `json:"days" bson:"days"`
+	UpdatedBy string    `json:"updated_by" bson:"updated_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type MenuRequest struct {
+	HostelID  string    `json:"hostel_id" validate:"required"`
+	WeekStart string    `json:"week_start" validate:"required,datetime=2006-01-02"`
+	Days      []DayMenu `json:"days" validate:"required,min=1,max=7,dive"`
+}
+
+// OptOutData is one meal a student will skip, (student_id, date, meal) is unique
+type OptOutData struct {
+	ID                string    `json:"id" bson:"_id"`
+	StudentID         string    `json:"student_id" bson:"student_id"`
+	CollageUniqueName string    `json:"collage_unique_name" bson:"collage_unique_name"`
+	HostelID          string    `json:"hostel_id" bson:"hostel_id"`
+	Date              string    `json:"date" bson:"date"`
+	Meal              Meal      `json:"meal" bson:"meal"`
+	CreatedBy         string    `json:"created_by" bson:"created_by"`
+	CreatedAt         time.Time `json:"created_at" bson:"created_at"`
+}
+
+// OptOutRequest skip the listed meals, or every meal when empty, on each day from From to To
+type OptOutRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	From      string `json:"from" validate:"required,datetime=2006-01-02"`
+	To        string `json:"to" validate:"required,datetime=2006-01-02"`
+	Meals     []Meal `json:"meals" validate:"max=4,dive,oneof=breakfast lunch snacks dinner"`
+}
+
+type OptOutResult struct {
+	Created []OptOutData `json:"created"`
+	Skipped int          `json:"skipped"` // meals the student had already opted out of
+	Late    int          `json:"late"`    // meals past the opt-out cutoff, not recorded
+}
+
+type OptOutFilter struct {
+	Page      int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64  `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID string `json:"student_id" bson:"student_id"`
+	HostelID  string `json:"hostel_id" bson:"hostel_id"`
+	Date      string `json:"date" bson:"date" validate:"omitempty,datetime=2006-01-02"`
+	Meal      Meal   `json:"meal" bson:"meal" validate:"omitempty,oneof=breakfast lunch snacks dinner"`
+}
+
+type HeadcountRequest struct {
+	HostelID string `json:"hostel_id" validate:"required"`
+	From     string `json:"from" validate:"required,datetime=2006-01-02"`
+	Days     int    `json:"days" validate:"min=1,max=14"`
+}
+
+type MealHeadcount struct {
+	Meal     Meal `json:"meal"`
+	Enrolled int  `json:"enrolled"`
+	OptedOut int  `json:"opted_out"`
+	Expected int  `json:"expected"`
+}
+
+type DailyHeadcount struct {
+	Date  string          `json:"date"`
+	Meals []MealHeadcount `json:"meals"`
+}
+
+type HeadcountForecast struct {
+	HostelID string           `json:"hostel_id"`
+	Enrolled int              `json:"enrolled"` // students with an active allocation today
+	Days     []DailyHeadcount `json:"days"`
+}
+
+type RebateRequest struct {
+	HostelID string `json:"hostel_id" validate:"required"`
+	Month    string `json:"month" validate:"required,datetime=2006-01"`
+}
+
+type StudentRebate struct {
+	StudentID    string `json:"student_id"`
+	MealsSkipped int    `json:"meals_skipped"`
+	Amount       int64  `json:"amount"`
+}
+
+// RebateReport price every skipped meal of a month at the mess fee of the
+// hostel divided by the number of meals served in the month
+type RebateReport struct {
+	HostelID   string          `json:"hostel_id"`
+	Month      string          `json:"month"`
+	Currency   string          `json:"currency"`
+	MealRate   int64           `json:"meal_rate"`
+	Students   []StudentRebate `json:"students"`
+	TotalMeals int             `json:"total_meals"`
+	Total      int64           `json:"total"`
+}
@@ -0,0 +1,77 @@
+package Payment
+
+import "time"
+
+type OrderStatus string
+
+const (
+	Created OrderStatus = "created"
+	Paid    OrderStatus = "paid"
+	Failed  OrderStatus = "failed"
+)
+
+// PaymentOrder is one online payment attempt of a student for an invoice,
+// amounts are integer minor units like the rest of the finance data
+type PaymentOrder struct {
+	ID                  string      `json:"id" bson:"_id"`
+	StudentID           string      `json:"student_id" bson:"student_id"`
+	CollageUniqueName   string      `json:"collage_unique_name" bson:"collage_unique_name"`
+	InvoiceID           string      `json:"invoice_id" bson:"invoice_id"`
+	Amount              int64       `json:"amount" bson:"amount"`
+	Currency            string      `json:"currency" bson:"currency"`
+	Provider            string      `json:"provider" bson:"provider"`
+	ProviderOrderID     string      `json:"provider_order_id" bson:"provider_order_id"`
+	ProviderPaymentID   string      `json:"provider_payment_id,omitempty" bson:"provider_payment_id,omitempty"`
+	LedgerTransactionID string      `json:"ledger_transaction_id,omitempty" bson:"ledger_transaction_id,omitempty"`
+	CheckoutURL         string      `json:"checkout_url,omitempty" bson:"checkout_url,omitempty"`
+	Status              OrderStatus `json:"status" bson:"status"`
+	ProviderStatus      OrderStatus `json:"provider_status,omitempty" bson:"-"` // live status from the gateway, only set on single order fetch
+	CreatedAt           time.Time   `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at" bson:"updated_at"`
+}
+
+type OrderFilter struct {
+	Page      int64       `json:"page" bson:"page" validate:"required,min=1"`
+	Limit     int64       `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	StudentID string      `json:"student_id" bson:"student_id"`
+	InvoiceID string      `json:"invoice_id" bson:"invoice_id"`
+	Status    OrderStatus `json:"status" bson:"status" validate:"omitempty,oneof=created paid failed"`
+}
+
+// CreateOrderRequest open an order on an invoice, without amount the whole outstanding is requested
+type CreateOrderRequest struct {
+	StudentID string `json:"student_id" validate:"required"`
+	InvoiceID string `json:"invoice_id" validate:"required"`
+	Amount    int64  `json:"amount" validate:"min=0"`
+}
+
+type FakeCheckoutRequest struct {
+	Success bool `json:"success"`
+}
+
+// ProviderOrder is the state of an order on the gateway side
+type ProviderOrder struct {
+	ProviderOrderID   string      `json:"provider_order_id"`
+	ProviderPaymentID string      `json:"provider_payment_id,omitempty"`
+	Amount            int64       `json:"amount"`
+	Currency          string      `json:"currency"`
+	Status            OrderStatus `json:"status"`
+	CheckoutURL       string      `json:"checkout_url,omitempty"`
+}
+
+type EventType string
+
+const (
+	PaymentCaptured EventType = "payment.captured"
+	PaymentFailed   EventType = "payment.failed"
+)
+
+// WebhookEvent is the provider independent content of a verified webhook
+type WebhookEvent struct {
+	EventID           string    `json:"event_id"`
+	Type              EventType `json:"type"`
+	ProviderOrderID   string    `json:"provider_order_id"`
+	ProviderPaymentID string    `json:"provider_payment_id"`
+	Amount            int64     `json:"amount"`
+	Currency          string    `json:"currency"`
+}
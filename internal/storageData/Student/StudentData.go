@@ -0,0 +1,55 @@
+package Student
+
+import "time"
+
+type Gender string
+
+const (
+	Male   Gender = "male"
+	Female Gender = "female"
+	Other  Gender = "other"
+)
+
+type GuardianContact struct {
+	Name     string `json:"name" bson:"name" validate:"required,min=3,max=50"`
+	Relation string `json:"relation" bson:"relation" validate:"required,min=3,max=20"`
+	Phone    string `json:"phone" bson:"phone" validate:"required,phone"`
+	Email    string `json:"email,omitempty" bson:"email,omitempty" validate:"omitempty,email"`
+}
+
+type StudentData struct {
+	ID                string            `json:"id" bson:"_id"`
+	Name              string            `json:"name" bson:"name" validate:"required,min=3,max=50"`
+	RollNumber        string            `json:"roll_number" bson:"roll_number" validate:"required,min=1,max=20"`
+	CollageUniqueName string            `json:"collage_unique_name" bson:"collage_unique_name" validate:"required,min=3,max=20"`
+	Gender            Gender            `json:"gender" bson:"gender" validate:"required,oneof=male female other"`
+	Year              int               `json:"year" bson:"year" validate:"required,min=1,max=6"`
+	Phone             string            `json:"phone,omitempty" bson:"phone,omitempty" validate:"omitempty,phone"`
+	Guardians         []GuardianContact `json:"guardians" bson:"guardians" validate:"required,min=1,max=3,dive"`
+	IDProofReference  string            `json:"id_proof_reference" bson:"id_proof_reference" validate:"required,min=3,max=100"`
+	CreatedAt         time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at" bson:"updated_at"`
+	MarkAsDeleted     bool              `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
+
+// StudentUpdate only carry the fields to change, nil fields are left untouched
+type StudentUpdate struct {
+	Name              *string            `json:"name,omitempty" validate:"omitempty,min=3,max=50"`
+	RollNumber        *string            `json:"roll_number,omitempty" validate:"omitempty,min=1,max=20"`
+	CollageUniqueName *string            `json:"collage_unique_name,omitempty" validate:"omitempty,min=3,max=20"`
+	Gender            *Gender            `json:"gender,omitempty" validate:"omitempty,oneof=male female other"`
+	Year              *int               `json:"year,omitempty" validate:"omitempty,min=1,max=6"`
+	Phone             *string            `json:"phone,omitempty" validate:"omitempty,phone"`
+	Guardians         *[]GuardianContact `json:"guardians,omitempty" validate:"omitempty,min=1,max=3,dive"`
+	IDProofReference  *string            `json:"id_proof_reference,omitempty" validate:"omitempty,min=3,max=100"`
+}
+
+type StudentFilter struct {
+	Page              int64  `json:"page" bson:"page" validate:"required,min=1"`
+	Limit             int64  `json:"limit" bson:"limit" validate:"required,min=1,max=20"`
+	CollageUniqueName string `json:"collage_unique_name" bson:"collage_unique_name"`
+	Gender            Gender `json:"gender" bson:"gender" validate:"omitempty,oneof=male female other"`
+	Year              int    `json:"year" bson:"year" validate:"omitempty,min=1,max=6"`
+	Search            string `json:"search" bson:"search" validate:"omitempty,max=50"` // matches name or roll number
+	MarkAsDeleted     bool   `json:"mark_as_deleted" bson:"mark_as_deleted" default:"false"`
+}
@@ -0,0 +1,130 @@
+package Ticket
+
+import "time"
+
+type Category string
+
+const (
+	Electrical Category = "electrical"
+	Plumbing   Category = "plumbing"
+	Internet   Category = "internet"
+	Furniture  Category = "furniture"
+	Cleaning   Category = "cleaning"
+	Other      Category = "other"
+)
+
+type Priority string
+
+const (
+	Low    Priority = "low"
+	Medium Priority = "medium"
+	High   Priority = "high"
+	Urgent Priority = "urgent"
+)
+
+type TicketStatus string
+
+const (
+	Open       TicketStatus = "open"
+	Assigned   TicketStatus = "assigned"
+	InProgress TicketStatus = "in_progress"
+	Resolved   TicketStatus = "resolved"
+	Closed     TicketStatus = "closed"
+	Reopened   TicketStatus = "reopened"
+)
+
+// Attachment only reference a file stored elsewhere, the api does not accept uploads
+type Attachment struct {
+	Name        string    `json:"name" bson:"name" validate:"required,min=1,max=100"`
+	URL         string    `json:"url" bson:"url" validate:"required,url,max=500"`
+	ContentType string    `json:"content_type" bson:"content_type" validate:"max=100"`
+	AddedBy     string    `json:"added_by" bson:"added_by"`
+	AddedAt     time.Time `json:"added_at" bson:"added_at"`
+}
+
+// StatusChange is one entry of the ticket history, By is the admin user _id
+type StatusChange struct {
+	From       TicketStatus `json:"from" bson:"from"`
+	To         TicketStatus `json:"to" bson:"to"`
+	By         string       `json:"by" bson:"by"`
+	AssignedTo string       `json:"assigned_to,omitempty" bson:"assigned_to,omitempty"`
+	Note       string       `json:"note,omitempty" bson:"note,omitempty"`
+	At         time.Time    `json:"at" bson:"at"`
+}
+
+type TicketData struct {
+	ID          string         `json:"id" bson:"_id"`
+	Title       string         `json:"title" bson:"title"`
+	Description string         `json:"description" bson:"description"`
+	Category    Category       `json:"category" bson:"category"`
+	Priority    Priority       `json:"priority" bson:"priority"`
+	Status      TicketStatus   `json:"status" bson:"status"`
+	HostelID    string         `json:"hostel_id" bson:"hostel_id"`
+	RoomID      string         `json:"room_id" bson:"room_id"`
+	StudentID   string         `json:"student_id,omitempty" bson:"student_id,omitempty"`
+	ReportedBy  string         `json:"reported_by" bson:"reported_by"`
+	AssignedTo  string         `json:"assigned_to,omitempty" bson:"assigned_to,omitempty"`
+	Attachments []Attachment   `json:"attachments" bson:"attachments"`
+	History     []StatusChange `json:"history" bson:"history"`
+	DueAt       time.Time      `json:"due_at" bson:"due_at"`
+	Overdue     bool           `json:"overdue" bson:"-"` // computed on fetch from DueAt and the status
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+	ClosedAt    *time.Time     `json:"closed_at,omitempty" bson:"closed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+type TicketFilter struct {
+	Page       int64        `json:"page" bson:"page" validate:"required,min=1"`
+	Limit      int64        `json:"limit" bson:"limit" validate:"required,min=1,max=50"`
+	Status     TicketStatus `json:"status" bson:"status" validate:"omitempty,oneof=open assigned in_progress resolved closed reopened"`
+	Category   Category     `json:"category" bson:"category" validate:"omitempty,oneof=electrical plumbing internet furniture cleaning other"`
+	Priority   Priority     `json:"priority" bson:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	HostelID   string       `json:"hostel_id" bson:"hostel_id"`
+	RoomID     string       `json:"room_id" bson:"room_id"`
+	AssignedTo string       `json:"assigned_to" bson:"assigned_to"`
+	Overdue    bool         `json:"overdue" bson:"overdue"` // only active tickets past their due date
+}
+
+type CreateTicketRequest struct {
+	Title       string       `json:"title" validate:"required,min=3,max=100"`
+	Description string       `json:"description" validate:"max=2000"`
+	Category    Category     `json:"category" validate:"required,oneof=electrical plumbing internet furniture cleaning other"`
+	Priority    Priority     `json:"priority" validate:"required,oneof=low medium high urgent"`
+	RoomID      string       `json:"room_id" validate:"required"`
+	StudentID   string       `json:"student_id"`
+	Attachments []Attachment `json:"attachments" validate:"max=10,dive"`
+}
+
+type AssignRequest struct {
+	AssignedTo string `json:"assigned_to" validate:"required"`
+	Note       string `json:"note" validate:"max=500"`
+}
+
+// StatusRequest move a ticket, assigned is only reachable through AssignRequest
+type StatusRequest struct {
+	Status TicketStatus `json:"status" validate:"required,oneof=in_progress resolved closed reopened"`
+	Note   string       `json:"note" validate:"max=500"`
+}
+
+// TicketChange is what a transition write on the ticket, nil fields are left untouched
+type TicketChange struct {
+	To         TicketStatus
+	AssignedTo *string
+	DueAt      *time.Time
+	ResolvedAt *time.Time
+	ClosedAt   *time.Time
+	Entry      StatusChange
+}
+
+type CommentData struct {
+	ID        string    `json:"id" bson:"_id"`
+	TicketID  string    `json:"ticket_id" bson:"ticket_id"`
+	Author    string    `json:"author" bson:"author"`
+	Body      string    `json:"body" bson:"body"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=2000"`
+}
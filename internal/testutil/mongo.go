@@ -0,0 +1,60 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
+)
+
+// MongoT is T with what MongoClient need to skip without Docker and clean up
+type MongoT interface {
+	T
+	Skipf(format string, args ...interface{})
+	Cleanup(f func())
+}
+
+// mongoURI start one single node replica set for the whole test binary so
+// transactions are available, the container is removed when the binary exit
+var mongoURI = sync.OnceValues(func() (uri string, err error) {
+	// testcontainers panic when no Docker host is found
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	ctx := context.Background()
+	container, err := mongodb.Run(ctx, "mongo:latest", mongodb.WithReplicaSet("rs0"))
+	if err != nil {
+		return "", err
+	}
+	return container.ConnectionString(ctx)
+})
+
+// MongoClient connect to the test MongoDB container and drop the databases of
+// the managers when the test end, the test is skipped when Docker is not available
+func MongoClient(t MongoT) *mongo.Client {
+	t.Helper()
+	uri, err := mongoURI()
+	if err != nil {
+		t.Skipf("MongoDB container not available: %v", err)
+		return nil
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetDirect(true))
+	if err != nil {
+		t.Fatalf("failed to connect to the MongoDB container. Err: %v", err)
+		return nil
+	}
+	t.Cleanup(func() {
+		names, _ := client.ListDatabaseNames(ctx, bson.M{"name": bson.M{"$nin": bson.A{"admin", "config", "local"}}})
+		for _, name := range names {
+			_ = client.Database(name).Drop(ctx)
+		}
+		_ = client.Disconnect(ctx)
+	})
+	return client
+}
@@ -0,0 +1,149 @@
+// Package testutil hold the request helpers of the API tests of every module.
+// It is only imported from _test files and does not import testing, so nothing
+// of it is linked into the server
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// T is the part of testing.TB the helpers use
+type T interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// App is what the helpers send requests to, *server.FiberServer through its fiber.App
+type App interface {
+	Test(req *http.Request, msTimeout ...int) (*http.Response, error)
+}
+
+// DoJSON send body as JSON and decode the JSON response body
+func DoJSON(t T, app App, method string, path string, token string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+		return 0, nil
+	}
+	result := map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// DoRaw send a non JSON body and return the raw response body
+func DoRaw(t T, app App, method string, path string, token string, contentType string, body []byte) (int, []byte) {
+	t.Helper()
+	req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("error making request to server. Err: %v", err)
+		return 0, nil
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, raw
+}
+
+// Login return the access and refresh tokens of username
+func Login(t T, app App, username string, password string) (string, string) {
+	t.Helper()
+	status, body := DoJSON(t, app, "POST", "/admin/login", "", map[string]string{"username": username, "password": password})
+	if status != http.StatusOK {
+		t.Fatalf("login %s: expected status OK; got %d %v", username, status, body)
+	}
+	return Path[string](t, body, "jwtToken"), Path[string](t, body, "refreshToken")
+}
+
+// CollegeRow is a valid college body with the given unique name
+func CollegeRow(uniqueName string) map[string]interface{} {
+	return map[string]interface{}{"collage_name": "College", "collage_unique_name": uniqueName, "collage_address": "MG Road",
+		"pin_code": "560001", "collage_icon": "icon.png", "collage_strength": 10}
+}
+
+// AddCollege add the college students and hostels belong to
+func AddCollege(t T, app App, token string, uniqueName string) {
+	t.Helper()
+	colleges := []map[string]interface{}{CollegeRow(uniqueName)}
+	if status, body := DoJSON(t, app, "POST", "/admin/college", token, colleges); status != http.StatusOK {
+		t.Fatalf("add college: expected status OK; got %d %v", status, body)
+	}
+}
+
+// Path walk a decoded JSON value by object keys and array indexes and return
+// what it find as a V. A missing step or a value of another type fail the test
+// instead of panicking
+func Path[V any](t T, v interface{}, path ...interface{}) V {
+	t.Helper()
+	var zero V
+	for i, step := range path {
+		var ok bool
+		switch key := step.(type) {
+		case string:
+			var object map[string]interface{}
+			if object, ok = v.(map[string]interface{}); !ok {
+				t.Fatalf("%s: expected an object; got %T", pathString(path[:i]), v)
+				return zero
+			}
+			if v, ok = object[key]; !ok {
+				t.Fatalf("%s: missing in %v", pathString(path[:i+1]), object)
+				return zero
+			}
+		case int:
+			var list []interface{}
+			switch value := v.(type) {
+			case []interface{}:
+				list = value
+			case []map[string]interface{}:
+				for _, item := range value {
+					list = append(list, item)
+				}
+			default:
+				t.Fatalf("%s: expected an array; got %T", pathString(path[:i]), v)
+				return zero
+			}
+			if key < 0 || key >= len(list) {
+				t.Fatalf("%s: out of range of %d items", pathString(path[:i+1]), len(list))
+				return zero
+			}
+			v = list[key]
+		default:
+			t.Fatalf("path step %v must be a key or an index", step)
+			return zero
+		}
+	}
+	value, ok := v.(V)
+	if !ok {
+		t.Fatalf("%s: expected %T; got %T %v", pathString(path), zero, v, v)
+	}
+	return value
+}
+
+func pathString(path []interface{}) string {
+	text := "$"
+	for _, step := range path {
+		if key, ok := step.(string); ok {
+			text += "." + key
+		} else {
+			text += fmt.Sprintf("[%v]", step)
+		}
+	}
+	return text
+}